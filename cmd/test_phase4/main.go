@@ -52,7 +52,7 @@ func main() {
 	})
 	
 	log.Println("   > Agent 'hanging-agent' simulating crash. Retrieving state...")
-	reconst, err := c.GetStateReconstitution(ctx, &pb.HandshakeRequest{AgentId: "hanging-agent"})
+	reconst, err := c.GetStateReconstitution(ctx, &pb.ReconstitutionRequest{AgentId: "hanging-agent"})
 	if err != nil {
 		log.Fatalf("❌ RECONSTITUTION FAILED: %v", err)
 	}
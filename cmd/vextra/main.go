@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/groovy-byte/agent-mesh-core/internal/backend"
+	"github.com/groovy-byte/agent-mesh-core/internal/config"
+	"github.com/groovy-byte/agent-mesh-core/internal/controller"
+	"github.com/groovy-byte/agent-mesh-core/internal/openai"
+	"github.com/groovy-byte/agent-mesh-core/internal/server"
+	"github.com/groovy-byte/agent-mesh-core/internal/topology"
+	pb "github.com/groovy-byte/agent-mesh-core/proto"
+	"github.com/nats-io/nats.go"
+)
+
+func main() {
+	cfg := config.LoadConfig()
+
+	// Hardware profile for ScheInfer: cache and NUMA layout from sysfs, SIMD
+	// tier from CPU feature flags, GPU details from the CUDA bridge (stubbed
+	// without the cuda build tag).
+	profile, err := topology.Detect()
+	if err != nil {
+		log.Printf("[Vextra] ⚠️ Topology probe incomplete: %v", err)
+	}
+	if profile.GPUName == "" {
+		log.Printf("[Vextra] No CUDA device detected")
+	}
+	if cfg.L3CacheMB > 0 {
+		profile.L3CacheBytes = uint64(cfg.L3CacheMB) * 1024 * 1024
+	}
+	scheduler := controller.NewScheInferFromProfile(profile, 16*1024*1024)
+	scheduler.ConfigureLoad(controller.LoadPolicy{
+		MaxQueueDepth:  cfg.RouteMaxQueue,
+		MaxUtilization: cfg.RouteMaxUtil,
+	})
+	// The ggml Vextra backend routes through the package-level scheduler.
+	controller.SetGlobalScheduler(scheduler)
+	log.Printf("[Vextra] Node capability: %s", scheduler.GetMeshCapability())
+
+	lis, err := net.Listen("tcp", ":"+cfg.GRPCPort)
+	if err != nil {
+		log.Fatalf("[Vextra] Failed to listen on port %s: %v", cfg.GRPCPort, err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var store controller.MeshStore = controller.NewMemoryStore()
+	if cfg.StateDBPath != "" {
+		sqliteStore, err := controller.NewSQLiteStore(cfg.StateDBPath)
+		if err != nil {
+			log.Fatalf("[Vextra] Failed to open state store %s: %v", cfg.StateDBPath, err)
+		}
+		defer sqliteStore.Close()
+		store = sqliteStore
+		log.Printf("[Vextra] Persisting mesh state to %s", cfg.StateDBPath)
+	}
+
+	srv, err := server.NewServerWithStore(scheduler, store, cfg.StateHistory)
+	if err != nil {
+		log.Fatalf("[Vextra] Failed to restore mesh state: %v", err)
+	}
+	switch cfg.InferenceBackend {
+	case "llama":
+		if cfg.LlamaURL == "" {
+			log.Fatalf("[Vextra] The llama inference backend needs LLAMA_URL; set INFERENCE_BACKEND=fake to serve canned completions instead")
+		}
+		llama := backend.NewLlamaCPP(cfg.LlamaURL, nil)
+		if info, err := llama.ModelInfo(ctx); err != nil {
+			log.Printf("[Vextra] ⚠️ llama.cpp at %s not reachable yet: %v", cfg.LlamaURL, err)
+		} else {
+			log.Printf("[Vextra] Serving inference with %s (%s, ctx %d)", info.Name, info.Backend, info.ContextLength)
+		}
+		srv.Inference().UseDefaultBackend(llama)
+	case "fake":
+		log.Printf("[Vextra] ⚠️ Serving inference with the fake backend: completions echo the prompt")
+	default:
+		log.Fatalf("[Vextra] Unknown inference backend %q (want llama or fake)", cfg.InferenceBackend)
+	}
+	batchMax := cfg.BatchMaxSize
+	if cfg.RouteMaxQueue > 0 && batchMax > cfg.RouteMaxQueue {
+		log.Printf("[Vextra] ⚠️ Batch size %d exceeds the route queue depth; capping it at %d", batchMax, cfg.RouteMaxQueue)
+		batchMax = cfg.RouteMaxQueue
+	}
+	srv.Inference().ConfigureBatching(controller.BatchPolicy{
+		Window:  cfg.BatchWindow,
+		MaxSize: batchMax,
+	})
+	budgets := controller.DefaultBudgetPolicy()
+	budgets.TokensPerMinute = uint32(cfg.AgentTPM)
+	budgets.MaxConcurrent = cfg.AgentConcurrency
+	srv.Admission().Configure(budgets)
+	var embedder backend.Embedder
+	if cfg.CacheEmbedURL != "" {
+		embedder = backend.NewLlamaCPP(cfg.CacheEmbedURL, nil)
+	}
+	srv.Inference().ConfigureCache(controller.CachePolicy{
+		MaxEntries:     cfg.CacheEntries,
+		TTL:            cfg.CacheTTL,
+		MaxTemperature: float32(cfg.CacheMaxTemperature),
+		Similarity:     cfg.CacheSimilarity,
+	}, embedder)
+
+	// Operational plane: heartbeats arrive over NATS. The strategic gRPC
+	// plane still runs if the broker is unavailable.
+	nc, err := nats.Connect(cfg.NATSURL)
+	if err != nil {
+		if cfg.LockBackend == "jetstream" {
+			log.Fatalf("[Vextra] JetStream lock backend requires NATS at %s: %v", cfg.NATSURL, err)
+		}
+		log.Printf("[Vextra] ⚠️ NATS unavailable at %s, heartbeat liveness disabled: %v", cfg.NATSURL, err)
+	} else {
+		defer nc.Close()
+		if cfg.LockBackend == "jetstream" {
+			lockCfg := controller.DefaultJetStreamLockConfig()
+			lockCfg.Bucket = cfg.LockBucket
+			locks, err := controller.NewJetStreamLockBackend(nc, lockCfg)
+			if err != nil {
+				log.Fatalf("[Vextra] Failed to initialize JetStream lock backend: %v", err)
+			}
+			srv.Arbiter().UseLockBackend(locks)
+		}
+		scheduler.ConfigureNetwork(controller.NetworkCost{GBs: cfg.NetworkGBs, RTT: controller.DefaultNetworkRTT})
+		peers := controller.NewCapabilityDirectory(3 * cfg.CapabilityInterval)
+		exchange := controller.NewCapabilityExchange(nc, cfg.NodeID, peers, func() *pb.NodeCapability {
+			return scheduler.Capability(cfg.NodeID, cfg.AdvertiseAddr)
+		}, cfg.CapabilityInterval)
+		if err := exchange.Start(); err != nil {
+			log.Printf("[Vextra] ⚠️ Capability exchange failed to start: %v", err)
+		} else {
+			defer exchange.Stop()
+			if cfg.MeshRouting {
+				srv.UseMeshRouting(cfg.NodeID, peers, nil)
+			}
+		}
+		// Evicted agents do not come back for their KV-cache history.
+		if js, err := nc.JetStream(); err != nil {
+			log.Printf("[Vextra] ⚠️ JetStream unavailable, evicted agents keep their KV state: %v", err)
+		} else {
+			controller.NewKVCacheController(js).PurgeOnEvict(srv.Registry())
+		}
+		monitor := controller.NewHeartbeatMonitor(nc, srv.Registry(), controller.HeartbeatConfig{
+			Interval:     cfg.HeartbeatInterval,
+			SuspectAfter: cfg.SuspectAfter,
+			DeadAfter:    cfg.DeadAfter,
+		})
+		if err := monitor.Start(); err != nil {
+			log.Printf("[Vextra] ⚠️ Heartbeat monitor failed to start: %v", err)
+		} else {
+			defer monitor.Stop()
+		}
+	}
+
+	if cfg.OpenAIAddr != "" {
+		httpLis, err := net.Listen("tcp", cfg.OpenAIAddr)
+		if err != nil {
+			log.Fatalf("[Vextra] Failed to listen on %s: %v", cfg.OpenAIAddr, err)
+		}
+		facade := openai.NewHandler(srv, cfg.OpenAIModel)
+		go func() {
+			if err := facade.Run(ctx, httpLis); err != nil {
+				log.Printf("[Vextra] ⚠️ OpenAI facade stopped: %v", err)
+			}
+		}()
+	}
+
+	if err := srv.Run(ctx, lis); err != nil {
+		log.Fatalf("[Vextra] Server error: %v", err)
+	}
+	log.Printf("[Vextra] Shutdown complete")
+}
@@ -0,0 +1,158 @@
+// Package backend defines the inference engines InferenceController can run
+// prompts on, selected per hardware path by ScheInfer's routing decision.
+package backend
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+var ErrEmptyPrompt = errors.New("backend: prompt is empty")
+
+// Request is one completion call.
+type Request struct {
+	Prompt      string
+	MaxTokens   int // 0 lets the backend choose.
+	Temperature float32
+}
+
+// Result is a completed generation.
+type Result struct {
+	Text             string
+	PromptTokens     int
+	CompletionTokens int
+}
+
+// TokensUsed is the prompt plus completion token count.
+func (r Result) TokensUsed() int {
+	return r.PromptTokens + r.CompletionTokens
+}
+
+// ModelInfo describes the model a backend serves.
+type ModelInfo struct {
+	Name          string
+	ContextLength int
+	Backend       string // e.g. "fake", "llama.cpp".
+}
+
+// Backend is an inference engine.
+type Backend interface {
+	Generate(ctx context.Context, req Request) (Result, error)
+	Tokenize(ctx context.Context, text string) ([]int, error)
+	ModelInfo(ctx context.Context) (ModelInfo, error)
+}
+
+// TokenFunc receives generated text as it is produced. Returning an error
+// aborts generation with that error.
+type TokenFunc func(text string) error
+
+// Streamer is implemented by backends that can emit tokens incrementally.
+// Concatenating every emitted piece yields Result.Text.
+type Streamer interface {
+	GenerateStream(ctx context.Context, req Request, emit TokenFunc) (Result, error)
+}
+
+// Stream runs req on b, incrementally when b is a Streamer and otherwise as
+// a single piece once Generate returns.
+func Stream(ctx context.Context, b Backend, req Request, emit TokenFunc) (Result, error) {
+	if s, ok := b.(Streamer); ok {
+		return s.GenerateStream(ctx, req, emit)
+	}
+	res, err := b.Generate(ctx, req)
+	if err != nil {
+		return Result{}, err
+	}
+	if err := emit(res.Text); err != nil {
+		return Result{}, err
+	}
+	return res, nil
+}
+
+// BatchResult is one request's outcome within a batch.
+type BatchResult struct {
+	Result
+	Err error
+}
+
+// BatchGenerator is implemented by backends that can run several requests
+// as one batch. Results are returned in request order.
+type BatchGenerator interface {
+	GenerateBatch(ctx context.Context, reqs []Request) []BatchResult
+}
+
+// Batch runs reqs on b as one batch when b is a BatchGenerator and otherwise
+// as concurrent Generate calls.
+func Batch(ctx context.Context, b Backend, reqs []Request) []BatchResult {
+	if bg, ok := b.(BatchGenerator); ok {
+		return bg.GenerateBatch(ctx, reqs)
+	}
+	out := make([]BatchResult, len(reqs))
+	var wg sync.WaitGroup
+	for i, req := range reqs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			out[i].Result, out[i].Err = b.Generate(ctx, req)
+		}()
+	}
+	wg.Wait()
+	return out
+}
+
+// Embedder maps text to a vector; similar texts give vectors with a high
+// cosine similarity.
+type Embedder interface {
+	Embed(ctx context.Context, text string) ([]float32, error)
+}
+
+// Tokens runs req on b and returns the text split into the pieces b emits,
+// which are single tokens for a token-streaming backend.
+func Tokens(ctx context.Context, b Backend, req Request) ([]string, error) {
+	var out []string
+	_, err := Stream(ctx, b, req, func(text string) error {
+		out = append(out, text)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Verifier is implemented by backends that can check a draft continuation
+// in a single call, as the target model of speculative decoding.
+type Verifier interface {
+	// Verify returns how many leading draft tokens the model would also
+	// generate after req.Prompt, and the token it generates after them.
+	// next is empty when the completion ends there.
+	Verify(ctx context.Context, req Request, draft []string) (accepted int, next string, err error)
+}
+
+// Verify checks draft against b, natively when b is a Verifier and otherwise
+// by generating len(draft)+1 tokens and comparing them with the draft.
+func Verify(ctx context.Context, b Backend, req Request, draft []string) (int, string, error) {
+	if v, ok := b.(Verifier); ok {
+		return v.Verify(ctx, req, draft)
+	}
+	req.MaxTokens = len(draft) + 1
+	got, err := Tokens(ctx, b, req)
+	if err != nil {
+		return 0, "", err
+	}
+	accepted, next := matchDraft(draft, got)
+	return accepted, next, nil
+}
+
+// matchDraft compares draft with the tokens the target generated: how many
+// leading tokens agree, and the target's token after them ("" when it ended).
+func matchDraft(draft, got []string) (int, string) {
+	accepted := 0
+	for accepted < len(draft) && accepted < len(got) && got[accepted] == draft[accepted] {
+		accepted++
+	}
+	if accepted < len(got) {
+		return accepted, got[accepted]
+	}
+	return accepted, ""
+}
@@ -0,0 +1,250 @@
+package backend
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFakeIsDeterministic(t *testing.T) {
+	f := NewFake("tiny")
+	ctx := context.Background()
+
+	res, err := f.Generate(ctx, Request{Prompt: "route this tensor please", MaxTokens: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Text != "[tiny] route this" || res.PromptTokens != 4 || res.CompletionTokens != 2 {
+		t.Errorf("Unexpected fake result: %+v", res)
+	}
+
+	a, _ := f.Tokenize(ctx, "hello mesh hello")
+	b, _ := f.Tokenize(ctx, "hello mesh hello")
+	if len(a) != 3 || !reflect.DeepEqual(a, b) || a[0] != a[2] {
+		t.Errorf("Expected stable per-word tokens, got %v and %v", a, b)
+	}
+
+	if _, err := f.Generate(ctx, Request{Prompt: "  "}); !errors.Is(err, ErrEmptyPrompt) {
+		t.Errorf("Expected ErrEmptyPrompt, got %v", err)
+	}
+}
+
+// llamaStub mimics the llama.cpp server endpoints the backend uses.
+func llamaStub(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /completion", func(w http.ResponseWriter, r *http.Request) {
+		var req llamaCompletionRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if req.NProbs > 0 {
+			// Verification: the stub's tokens, in both server formats.
+			if req.NPredict != 4 || !req.CachePrompt {
+				http.Error(w, "unexpected verify request", http.StatusBadRequest)
+				return
+			}
+			w.Write([]byte(`{"content":"stub says: hi","stop":true,"completion_probabilities":[{"content":"stub"},{"content":" says:"},{"token":" hi"}]}`))
+			return
+		}
+		if req.NPredict != 8 || req.Temperature != 0.5 {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		if req.Stream {
+			w.Header().Set("Content-Type", "text/event-stream")
+			for _, tok := range []string{"stub", " says:", " " + req.Prompt} {
+				data, _ := json.Marshal(llamaCompletionResponse{Content: tok})
+				fmt.Fprintf(w, "data: %s\n\n", data)
+			}
+			data, _ := json.Marshal(llamaCompletionResponse{Stop: true, TokensPredicted: 3, TokensEvaluated: 5})
+			fmt.Fprintf(w, "data: %s\n\n", data)
+			return
+		}
+		json.NewEncoder(w).Encode(llamaCompletionResponse{
+			Content:         "stub says: " + req.Prompt,
+			TokensPredicted: 3,
+			TokensEvaluated: 5,
+		})
+	})
+	mux.HandleFunc("POST /tokenize", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string][]int{"tokens": {1, 2, 3}})
+	})
+	mux.HandleFunc("POST /embedding", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"index":0,"embedding":[[0.5,0.25,-1]]}]`))
+	})
+	mux.HandleFunc("GET /props", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"model_path":"/models/llama-3-8b.Q4_K.gguf","default_generation_settings":{"n_ctx":8192}}`))
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestLlamaCPPAgainstStub(t *testing.T) {
+	l := NewLlamaCPP(llamaStub(t).URL+"/", nil)
+	ctx := context.Background()
+
+	res, err := l.Generate(ctx, Request{Prompt: "hi", MaxTokens: 8, Temperature: 0.5})
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if res.Text != "stub says: hi" || res.TokensUsed() != 8 {
+		t.Errorf("Unexpected result: %+v", res)
+	}
+
+	tokens, err := l.Tokenize(ctx, "hi")
+	if err != nil || !reflect.DeepEqual(tokens, []int{1, 2, 3}) {
+		t.Errorf("Unexpected tokens %v (%v)", tokens, err)
+	}
+
+	vec, err := l.Embed(ctx, "hi")
+	if err != nil || !reflect.DeepEqual(vec, []float32{0.5, 0.25, -1}) {
+		t.Errorf("Unexpected embedding %v (%v)", vec, err)
+	}
+
+	info, err := l.ModelInfo(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Name != "llama-3-8b.Q4_K.gguf" || info.ContextLength != 8192 || info.Backend != "llama.cpp" {
+		t.Errorf("Unexpected model info: %+v", info)
+	}
+
+	// Server errors surface with their status.
+	if _, err := l.Generate(ctx, Request{Prompt: "hi"}); err == nil || !strings.Contains(err.Error(), "400") {
+		t.Errorf("Expected a 400 error, got %v", err)
+	}
+}
+
+func TestLlamaCPPLeavesDeadlinesToContext(t *testing.T) {
+	release := make(chan struct{})
+	stalled := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	t.Cleanup(stalled.Close)
+	t.Cleanup(func() { close(release) })
+
+	l := NewLlamaCPP(stalled.URL, nil)
+	if l.client.Timeout != 0 {
+		t.Errorf("Expected no client-wide deadline to cut off long streams, got %v", l.client.Timeout)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := l.Generate(ctx, Request{Prompt: "hi"}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the context deadline to end the request, got %v", err)
+	}
+}
+
+func TestStreamingBackends(t *testing.T) {
+	ctx := context.Background()
+	l := NewLlamaCPP(llamaStub(t).URL, nil)
+	f := NewFake("tiny")
+
+	for _, b := range []Backend{l, f} {
+		var pieces []string
+		res, err := Stream(ctx, b, Request{Prompt: "hi there", MaxTokens: 8, Temperature: 0.5}, func(text string) error {
+			pieces = append(pieces, text)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Stream failed: %v", err)
+		}
+		if len(pieces) != 3 || strings.Join(pieces, "") != res.Text {
+			t.Errorf("Expected 3 pieces adding up to %q, got %q", res.Text, pieces)
+		}
+		if res.TokensUsed() == 0 {
+			t.Errorf("Expected token counts, got %+v", res)
+		}
+	}
+
+	// An emit error aborts the stream.
+	stop := errors.New("client gone")
+	calls := 0
+	_, err := Stream(ctx, f, Request{Prompt: "a b c d"}, func(string) error {
+		calls++
+		return stop
+	})
+	if !errors.Is(err, stop) || calls != 1 {
+		t.Errorf("Expected the stream to stop after one piece, got %d calls and %v", calls, err)
+	}
+
+	// Backends without streaming deliver the whole completion at once.
+	calls = 0
+	res, err := Stream(ctx, generateOnly{f}, Request{Prompt: "a b c d"}, func(string) error {
+		calls++
+		return nil
+	})
+	if err != nil || calls != 1 || res.Text != "[tiny] a b c d" {
+		t.Errorf("Expected one piece from a non-streaming backend, got %d (%+v, %v)", calls, res, err)
+	}
+}
+
+// generateOnly hides the Streamer implementation of the wrapped backend.
+type generateOnly struct{ Backend }
+
+// streamOnly hides every optional interface of the wrapped backend but Streamer.
+type streamOnly struct {
+	Backend
+	Streamer
+}
+
+func TestVerify(t *testing.T) {
+	ctx := context.Background()
+	words := strings.Fields("a quick brown fox jumps")
+	f := &Fake{Name: "target", Script: words}
+	req := Request{Prompt: "Tell me about a"}
+
+	// Native and regenerating verification agree.
+	for _, b := range []Backend{f, streamOnly{f, f}} {
+		for _, tc := range []struct {
+			draft    []string
+			accepted int
+			next     string
+		}{
+			{[]string{" quick", " brown", " cat"}, 2, " fox"},
+			{[]string{" quick", " brown", " fox"}, 3, " jumps"},
+			{[]string{" quick", " brown", " fox", " jumps"}, 4, ""},
+			{nil, 0, " quick"},
+		} {
+			accepted, next, err := Verify(ctx, b, req, tc.draft)
+			if err != nil || accepted != tc.accepted || next != tc.next {
+				t.Errorf("%T: expected %d accepted and %q next for %q, got %d and %q (%v)", b, tc.accepted, tc.next, tc.draft, accepted, next, err)
+			}
+		}
+	}
+
+	// Continuing the prompt with part of the answer yields the rest of it.
+	res, err := f.Generate(ctx, Request{Prompt: req.Prompt + " quick brown", MaxTokens: 1})
+	if err != nil || res.Text != " fox" {
+		t.Errorf("Expected the script to continue with \" fox\", got %q (%v)", res.Text, err)
+	}
+
+	l := NewLlamaCPP(llamaStub(t).URL, nil)
+	accepted, next, err := l.Verify(ctx, Request{Prompt: "hi", Temperature: 0.5}, []string{"stub", " says:", " no"})
+	if err != nil || accepted != 2 || next != " hi" {
+		t.Errorf("Expected llama.cpp to accept 2 tokens then \" hi\", got %d and %q (%v)", accepted, next, err)
+	}
+}
+
+func TestFakeStreamHonorsCancellation(t *testing.T) {
+	f := &Fake{Name: "slow", TokenDelay: 10 * time.Millisecond}
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	_, err := f.GenerateStream(ctx, Request{Prompt: strings.Repeat("w ", 100)}, func(string) error {
+		if calls++; calls == 2 {
+			cancel()
+		}
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) || calls != 2 {
+		t.Errorf("Expected cancellation after 2 pieces, got %d and %v", calls, err)
+	}
+}
@@ -0,0 +1,182 @@
+package backend
+
+import (
+	"context"
+	"hash/fnv"
+	"strings"
+	"time"
+)
+
+// Fake is a deterministic Backend for tests and nodes without a model: it
+// tokenizes on whitespace and answers by echoing the prompt.
+//
+// With a Script it is a bigram model over the script's words instead: the
+// prompt's last word is followed by the word after its first occurrence in
+// Script, a prompt ending in any other word starts from the beginning, and
+// the last word ends the completion. Unlike an echo, continuing a prompt
+// with part of an answer yields the rest of that answer, as speculative
+// decoding expects of a model.
+type Fake struct {
+	Name       string
+	Latency    time.Duration // Simulated generation time; honors ctx.
+	TokenDelay time.Duration // Delay before each streamed token; honors ctx.
+	Script     []string
+}
+
+func NewFake(name string) *Fake {
+	return &Fake{Name: name}
+}
+
+// Generate returns "[<name>] " followed by at most MaxTokens prompt words.
+func (f *Fake) Generate(ctx context.Context, req Request) (Result, error) {
+	if strings.TrimSpace(req.Prompt) == "" {
+		return Result{}, ErrEmptyPrompt
+	}
+	if err := sleep(ctx, f.Latency); err != nil {
+		return Result{}, err
+	}
+	return f.answer(req), nil
+}
+
+// GenerateBatch answers every request after a single Latency, the way a
+// batched forward pass costs about as much as one request.
+func (f *Fake) GenerateBatch(ctx context.Context, reqs []Request) []BatchResult {
+	out := make([]BatchResult, len(reqs))
+	err := sleep(ctx, f.Latency)
+	for i, req := range reqs {
+		switch {
+		case strings.TrimSpace(req.Prompt) == "":
+			out[i].Err = ErrEmptyPrompt
+		case err != nil:
+			out[i].Err = err
+		default:
+			out[i].Result = f.answer(req)
+		}
+	}
+	return out
+}
+
+func (f *Fake) answer(req Request) Result {
+	res, _ := f.pieces(req)
+	return res
+}
+
+// pieces generates req and returns the text split the way GenerateStream
+// emits it: "[<name>]" then " <word>" per echoed word, or " <word>" per
+// script word.
+func (f *Fake) pieces(req Request) (Result, []string) {
+	words := strings.Fields(req.Prompt)
+	var out, pieces []string
+	if len(f.Script) > 0 {
+		out = f.Script[f.scriptPos(words):]
+	} else {
+		out = words
+		pieces = append(pieces, "["+f.Name+"]")
+	}
+	if req.MaxTokens > 0 && len(out) > req.MaxTokens {
+		out = out[:req.MaxTokens]
+	}
+	for _, w := range out {
+		pieces = append(pieces, " "+w)
+	}
+	return Result{
+		Text:             strings.Join(pieces, ""),
+		PromptTokens:     len(words),
+		CompletionTokens: len(out),
+	}, pieces
+}
+
+// scriptPos is where Script continues after prompt.
+func (f *Fake) scriptPos(prompt []string) int {
+	if len(prompt) == 0 {
+		return 0
+	}
+	last := prompt[len(prompt)-1]
+	for i, w := range f.Script {
+		if w == last {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// GenerateStream emits the Generate text one token at a time.
+func (f *Fake) GenerateStream(ctx context.Context, req Request, emit TokenFunc) (Result, error) {
+	if strings.TrimSpace(req.Prompt) == "" {
+		return Result{}, ErrEmptyPrompt
+	}
+	if err := sleep(ctx, f.Latency); err != nil {
+		return Result{}, err
+	}
+	res, pieces := f.pieces(req)
+	for _, p := range pieces {
+		if err := sleep(ctx, f.TokenDelay); err != nil {
+			return Result{}, err
+		}
+		if err := emit(p); err != nil {
+			return Result{}, err
+		}
+	}
+	return res, nil
+}
+
+// Verify checks draft against the tokens Generate would produce, costing a
+// single Latency like one batched forward pass.
+func (f *Fake) Verify(ctx context.Context, req Request, draft []string) (int, string, error) {
+	if strings.TrimSpace(req.Prompt) == "" {
+		return 0, "", ErrEmptyPrompt
+	}
+	if err := sleep(ctx, f.Latency); err != nil {
+		return 0, "", err
+	}
+	req.MaxTokens = len(draft) + 1
+	_, got := f.pieces(req)
+	accepted, next := matchDraft(draft, got)
+	return accepted, next, nil
+}
+
+// Tokenize hashes each whitespace-separated word into a 32k vocabulary.
+func (f *Fake) Tokenize(ctx context.Context, text string) ([]int, error) {
+	words := strings.Fields(text)
+	tokens := make([]int, len(words))
+	for i, w := range words {
+		h := fnv.New32a()
+		h.Write([]byte(w))
+		tokens[i] = int(h.Sum32() % 32000)
+	}
+	return tokens, nil
+}
+
+// FakeEmbeddingDims is the length of Fake embeddings.
+const FakeEmbeddingDims = 256
+
+// Embed hashes lower-cased words into a bag-of-words vector, so prompts
+// that share most of their words come out similar.
+func (f *Fake) Embed(ctx context.Context, text string) ([]float32, error) {
+	vec := make([]float32, FakeEmbeddingDims)
+	for _, w := range strings.Fields(strings.ToLower(text)) {
+		h := fnv.New32a()
+		h.Write([]byte(w))
+		vec[h.Sum32()%FakeEmbeddingDims]++
+	}
+	return vec, nil
+}
+
+func (f *Fake) ModelInfo(ctx context.Context) (ModelInfo, error) {
+	return ModelInfo{Name: f.Name, ContextLength: 4096, Backend: "fake"}, nil
+}
+
+// sleep waits for d, returning early with ctx's error if it ends first.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,271 @@
+package backend
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+)
+
+// DefaultConnectTimeout bounds dialing a llama.cpp server and the TLS
+// handshake. Nothing bounds generation but the caller's context, so a long
+// stream is never cut off mid-answer.
+const DefaultConnectTimeout = 10 * time.Second
+
+// LlamaCPP talks to a llama.cpp server (or anything exposing its /completion,
+// /tokenize and /props endpoints).
+type LlamaCPP struct {
+	baseURL string
+	client  *http.Client
+}
+
+// NewLlamaCPP targets baseURL, e.g. "http://127.0.0.1:8080". A nil client
+// uses one that gives up connecting after DefaultConnectTimeout.
+func NewLlamaCPP(baseURL string, client *http.Client) *LlamaCPP {
+	if client == nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.DialContext = (&net.Dialer{Timeout: DefaultConnectTimeout, KeepAlive: 30 * time.Second}).DialContext
+		transport.TLSHandshakeTimeout = DefaultConnectTimeout
+		client = &http.Client{Transport: transport}
+	}
+	return &LlamaCPP{baseURL: strings.TrimRight(baseURL, "/"), client: client}
+}
+
+type llamaCompletionRequest struct {
+	Prompt      string  `json:"prompt"`
+	NPredict    int     `json:"n_predict,omitempty"`
+	Temperature float32 `json:"temperature"`
+	Stream      bool    `json:"stream"`
+	NProbs      int     `json:"n_probs,omitempty"`
+	CachePrompt bool    `json:"cache_prompt,omitempty"`
+}
+
+type llamaCompletionResponse struct {
+	Content         string           `json:"content"`
+	Stop            bool             `json:"stop"`
+	TokensPredicted int              `json:"tokens_predicted"`
+	TokensEvaluated int              `json:"tokens_evaluated"`
+	Probabilities   []llamaTokenProb `json:"completion_probabilities,omitempty"`
+}
+
+// llamaTokenProb is one generated token as n_probs reports it: older
+// servers name its text "content", newer ones "token".
+type llamaTokenProb struct {
+	Content string `json:"content"`
+	Token   string `json:"token"`
+}
+
+func (l *LlamaCPP) Generate(ctx context.Context, req Request) (Result, error) {
+	if strings.TrimSpace(req.Prompt) == "" {
+		return Result{}, ErrEmptyPrompt
+	}
+	var resp llamaCompletionResponse
+	err := l.do(ctx, http.MethodPost, "/completion", llamaCompletionRequest{
+		Prompt:      req.Prompt,
+		NPredict:    req.MaxTokens,
+		Temperature: req.Temperature,
+	}, &resp)
+	if err != nil {
+		return Result{}, err
+	}
+	return Result{
+		Text:             resp.Content,
+		PromptTokens:     resp.TokensEvaluated,
+		CompletionTokens: resp.TokensPredicted,
+	}, nil
+}
+
+// GenerateStream uses the server's SSE mode: one "data: {...}" event per
+// token, the last with stop set and the token counts.
+func (l *LlamaCPP) GenerateStream(ctx context.Context, req Request, emit TokenFunc) (Result, error) {
+	if strings.TrimSpace(req.Prompt) == "" {
+		return Result{}, ErrEmptyPrompt
+	}
+	resp, err := l.post(ctx, "/completion", llamaCompletionRequest{
+		Prompt:      req.Prompt,
+		NPredict:    req.MaxTokens,
+		Temperature: req.Temperature,
+		Stream:      true,
+	})
+	if err != nil {
+		return Result{}, err
+	}
+	defer resp.Body.Close()
+
+	var text strings.Builder
+	var res Result
+	sc := bufio.NewScanner(resp.Body)
+	for sc.Scan() {
+		data, ok := strings.CutPrefix(sc.Text(), "data: ")
+		if !ok {
+			continue
+		}
+		var ev llamaCompletionResponse
+		if err := json.Unmarshal([]byte(data), &ev); err != nil {
+			return Result{}, fmt.Errorf("llama.cpp: bad stream event: %w", err)
+		}
+		if ev.Content != "" {
+			text.WriteString(ev.Content)
+			if err := emit(ev.Content); err != nil {
+				return Result{}, err
+			}
+		}
+		if ev.Stop {
+			res.PromptTokens, res.CompletionTokens = ev.TokensEvaluated, ev.TokensPredicted
+			res.Text = text.String()
+			return res, nil
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return Result{}, fmt.Errorf("llama.cpp: stream interrupted: %w", err)
+	}
+	if err := ctx.Err(); err != nil {
+		return Result{}, err
+	}
+	return Result{}, fmt.Errorf("llama.cpp: stream ended without a stop event")
+}
+
+// Verify decodes len(draft)+1 tokens in one request with n_probs set, so
+// the server reports them one by one, and compares them with draft. The
+// prompt's KV cache is kept between calls, so each speculative round only
+// evaluates the text accepted since the last one.
+func (l *LlamaCPP) Verify(ctx context.Context, req Request, draft []string) (int, string, error) {
+	if strings.TrimSpace(req.Prompt) == "" {
+		return 0, "", ErrEmptyPrompt
+	}
+	var resp llamaCompletionResponse
+	err := l.do(ctx, http.MethodPost, "/completion", llamaCompletionRequest{
+		Prompt:      req.Prompt,
+		NPredict:    len(draft) + 1,
+		Temperature: req.Temperature,
+		NProbs:      1,
+		CachePrompt: true,
+	}, &resp)
+	if err != nil {
+		return 0, "", err
+	}
+	if len(resp.Probabilities) == 0 && resp.Content != "" {
+		return 0, "", fmt.Errorf("llama.cpp: /completion returned no completion_probabilities")
+	}
+	got := make([]string, len(resp.Probabilities))
+	for i, p := range resp.Probabilities {
+		got[i] = p.Content
+		if got[i] == "" {
+			got[i] = p.Token
+		}
+	}
+	accepted, next := matchDraft(draft, got)
+	return accepted, next, nil
+}
+
+func (l *LlamaCPP) Tokenize(ctx context.Context, text string) ([]int, error) {
+	var resp struct {
+		Tokens []int `json:"tokens"`
+	}
+	if err := l.do(ctx, http.MethodPost, "/tokenize", map[string]string{"content": text}, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Tokens, nil
+}
+
+// Embed calls /embedding, which needs a server started with --embeddings.
+// Both the legacy {"embedding": [...]} reply and the newer per-input array
+// are accepted; per-token embeddings are not pooled and are rejected.
+func (l *LlamaCPP) Embed(ctx context.Context, text string) ([]float32, error) {
+	var raw json.RawMessage
+	if err := l.do(ctx, http.MethodPost, "/embedding", map[string]string{"content": text}, &raw); err != nil {
+		return nil, err
+	}
+	var single struct {
+		Embedding json.RawMessage `json:"embedding"`
+	}
+	if err := json.Unmarshal(raw, &single); err != nil {
+		var list []struct {
+			Embedding json.RawMessage `json:"embedding"`
+		}
+		if err := json.Unmarshal(raw, &list); err != nil || len(list) == 0 {
+			return nil, fmt.Errorf("llama.cpp: unexpected /embedding response")
+		}
+		single.Embedding = list[0].Embedding
+	}
+
+	var vec []float32
+	if err := json.Unmarshal(single.Embedding, &vec); err == nil {
+		return vec, nil
+	}
+	var pooled [][]float32
+	if err := json.Unmarshal(single.Embedding, &pooled); err != nil || len(pooled) != 1 {
+		return nil, fmt.Errorf("llama.cpp: /embedding did not return one pooled vector")
+	}
+	return pooled[0], nil
+}
+
+func (l *LlamaCPP) ModelInfo(ctx context.Context) (ModelInfo, error) {
+	var resp struct {
+		ModelPath string `json:"model_path"`
+		Settings  struct {
+			NCtx int `json:"n_ctx"`
+		} `json:"default_generation_settings"`
+	}
+	if err := l.do(ctx, http.MethodGet, "/props", nil, &resp); err != nil {
+		return ModelInfo{}, err
+	}
+	return ModelInfo{
+		Name:          path.Base(resp.ModelPath),
+		ContextLength: resp.Settings.NCtx,
+		Backend:       "llama.cpp",
+	}, nil
+}
+
+func (l *LlamaCPP) do(ctx context.Context, method, endpoint string, body, out any) error {
+	resp, err := l.request(ctx, method, endpoint, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("llama.cpp: failed to decode %s response: %w", endpoint, err)
+	}
+	return nil
+}
+
+func (l *LlamaCPP) post(ctx context.Context, endpoint string, body any) (*http.Response, error) {
+	return l.request(ctx, http.MethodPost, endpoint, body)
+}
+
+// request sends body as JSON and returns the response when it is 200 OK.
+func (l *LlamaCPP) request(ctx context.Context, method, endpoint string, body any) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("llama.cpp: failed to encode %s request: %w", endpoint, err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, l.baseURL+endpoint, reader)
+	if err != nil {
+		return nil, fmt.Errorf("llama.cpp: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := l.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("llama.cpp: %s failed: %w", endpoint, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("llama.cpp: %s returned %s: %s", endpoint, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return resp, nil
+}
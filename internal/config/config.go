@@ -3,6 +3,8 @@ package config
 import (
 	"flag"
 	"os"
+	"strconv"
+	"time"
 )
 
 type Config struct {
@@ -11,6 +13,67 @@ type Config struct {
 	StoreName string
 	DBPath    string
 	SyncDir   string
+	L3CacheMB int
+
+	// InferenceBackend is "llama" to serve every hardware path from the
+	// llama.cpp server at LlamaURL, or "fake" for canned completions in
+	// development and tests.
+	InferenceBackend string
+	LlamaURL         string
+
+	// OpenAIAddr serves the OpenAI-compatible HTTP API (empty disables it);
+	// OpenAIModel is the model name it reports.
+	OpenAIAddr  string
+	OpenAIModel string
+
+	// RouteMaxQueue and RouteMaxUtil are ScheInfer's spillover thresholds:
+	// in-flight tasks per provider and share of GPU free memory in use.
+	RouteMaxQueue int
+	RouteMaxUtil  float64
+
+	// BatchWindow is how long inference requests on one hardware path wait to
+	// be batched together (0 disables batching); BatchMaxSize caps a batch.
+	// A path never holds more than RouteMaxQueue requests, so a larger
+	// BatchMaxSize is never reached.
+	BatchWindow  time.Duration
+	BatchMaxSize int
+
+	// AgentTPM and AgentConcurrency are an operational agent's inference
+	// budget at full CPU; handshake limits and the STRATEGIC role scale them.
+	AgentTPM         int
+	AgentConcurrency int
+
+	// CacheEntries bounds the inference response cache (0 disables it).
+	// Requests above CacheMaxTemperature skip it. CacheEmbedURL is a llama.cpp
+	// server started with --embeddings that enables matching near-identical
+	// prompts at CacheSimilarity or above.
+	CacheEntries        int
+	CacheTTL            time.Duration
+	CacheMaxTemperature float64
+	CacheEmbedURL       string
+	CacheSimilarity     float64
+
+	// StateDBPath is the SQLite file for registry/arbiter persistence; empty keeps state in memory.
+	StateDBPath string
+	// StateHistory is how many reconstitution snapshots are kept per agent.
+	StateHistory int
+
+	// LockBackend is "memory" for a single controller or "jetstream" to share
+	// strategic locks across controllers through a NATS KV bucket.
+	LockBackend string
+	LockBucket  string
+
+	// NodeID names this controller on mesh.capability.<node_id>; AdvertiseAddr
+	// is the gRPC address peers forward to (empty: never a forwarding target).
+	NodeID             string
+	AdvertiseAddr      string
+	CapabilityInterval time.Duration
+	MeshRouting        bool
+	NetworkGBs         float64
+
+	HeartbeatInterval time.Duration
+	SuspectAfter      int
+	DeadAfter         int
 }
 
 func LoadConfig() *Config {
@@ -21,6 +84,34 @@ func LoadConfig() *Config {
 	flag.StringVar(&c.StoreName, "store-name", getEnv("STORE_NAME", "fileSearchStores/agentmeshresearchcore-1jsf1t5e0494"), "Gemini File Search Store name")
 	flag.StringVar(&c.DBPath, "db-path", getEnv("DB_PATH", "/home/groovy-byte/agent_mesh.db"), "Path to SQLite database")
 	flag.StringVar(&c.SyncDir, "sync-dir", getEnv("SYNC_DIR", "/home/groovy-byte/agent-mesh-core/tmp_sync"), "Directory for sync files")
+	flag.StringVar(&c.StateDBPath, "state-db", getEnv("STATE_DB", ""), "SQLite file for persistent mesh state (empty for in-memory)")
+	flag.IntVar(&c.StateHistory, "state-history", getEnvInt("STATE_HISTORY", 32), "Reconstitution snapshots kept per agent")
+	flag.StringVar(&c.LockBackend, "lock-backend", getEnv("LOCK_BACKEND", "memory"), "Strategic lock backend: memory or jetstream")
+	flag.StringVar(&c.LockBucket, "lock-bucket", getEnv("LOCK_BUCKET", "MESH_LOCKS"), "JetStream KV bucket for strategic locks")
+	flag.StringVar(&c.InferenceBackend, "inference-backend", getEnv("INFERENCE_BACKEND", "llama"), "Inference backend: llama or fake")
+	flag.StringVar(&c.LlamaURL, "llama-url", getEnv("LLAMA_URL", ""), "llama.cpp server URL for the llama inference backend")
+	flag.StringVar(&c.OpenAIAddr, "openai-addr", getEnv("OPENAI_ADDR", ""), "Listen address for the OpenAI-compatible HTTP API, e.g. :8081 (empty disables it)")
+	flag.StringVar(&c.OpenAIModel, "openai-model", getEnv("OPENAI_MODEL", "vextra"), "Model name reported by the OpenAI-compatible HTTP API")
+	flag.IntVar(&c.L3CacheMB, "l3-cache-mb", getEnvInt("L3_CACHE_MB", 0), "CPU L3 cache size in MB used by ScheInfer (0 = detect from sysfs)")
+	flag.IntVar(&c.RouteMaxQueue, "route-max-queue", getEnvInt("ROUTE_MAX_QUEUE", 4), "In-flight tasks per provider before ScheInfer spills over (0 = unlimited)")
+	flag.Float64Var(&c.RouteMaxUtil, "route-max-util", getEnvFloat("ROUTE_MAX_UTIL", 0.9), "GPU free-memory share in flight before ScheInfer spills over (0 = unlimited)")
+	flag.DurationVar(&c.BatchWindow, "batch-window", getEnvDuration("BATCH_WINDOW", 5*time.Millisecond), "Wait for coalescing inference requests per hardware path (0 = no batching)")
+	flag.IntVar(&c.BatchMaxSize, "batch-max-size", getEnvInt("BATCH_MAX_SIZE", 4), "Maximum inference requests per batch (at most route-max-queue)")
+	flag.IntVar(&c.AgentTPM, "agent-tpm", getEnvInt("AGENT_TPM", 20000), "Inference tokens per minute per operational agent (0 = unlimited)")
+	flag.IntVar(&c.AgentConcurrency, "agent-concurrency", getEnvInt("AGENT_CONCURRENCY", 2), "Concurrent inference requests per operational agent (0 = unlimited)")
+	flag.IntVar(&c.CacheEntries, "cache-entries", getEnvInt("CACHE_ENTRIES", 1024), "Inference responses kept in the response cache (0 = no cache)")
+	flag.DurationVar(&c.CacheTTL, "cache-ttl", getEnvDuration("CACHE_TTL", 10*time.Minute), "How long cached inference responses stay valid")
+	flag.Float64Var(&c.CacheMaxTemperature, "cache-max-temperature", getEnvFloat("CACHE_MAX_TEMPERATURE", 0), "Highest request temperature served from the response cache")
+	flag.StringVar(&c.CacheEmbedURL, "cache-embed-url", getEnv("CACHE_EMBED_URL", ""), "llama.cpp embeddings server for similarity cache hits (empty = exact matches only)")
+	flag.Float64Var(&c.CacheSimilarity, "cache-similarity", getEnvFloat("CACHE_SIMILARITY", 0.95), "Cosine similarity for a similarity cache hit")
+	flag.StringVar(&c.NodeID, "node-id", getEnv("NODE_ID", defaultNodeID()), "Node name published in the mesh capability profile")
+	flag.StringVar(&c.AdvertiseAddr, "advertise-addr", getEnv("ADVERTISE_ADDR", ""), "gRPC address peers use to forward actions to this node")
+	flag.DurationVar(&c.CapabilityInterval, "capability-interval", getEnvDuration("CAPABILITY_INTERVAL", 10*time.Second), "Interval between capability profile publications")
+	flag.BoolVar(&c.MeshRouting, "mesh-routing", getEnvBool("MESH_ROUTING", false), "Forward strategic actions to better-suited peers")
+	flag.Float64Var(&c.NetworkGBs, "network-gbs", getEnvFloat("NETWORK_GBS", 1.25), "Node-to-node bandwidth in GB/s used to cost forwarding")
+	flag.DurationVar(&c.HeartbeatInterval, "heartbeat-interval", getEnvDuration("HEARTBEAT_INTERVAL", 5*time.Second), "Expected interval between agent heartbeats")
+	flag.IntVar(&c.SuspectAfter, "suspect-after", getEnvInt("SUSPECT_AFTER", 2), "Missed heartbeat intervals before an agent is SUSPECT")
+	flag.IntVar(&c.DeadAfter, "dead-after", getEnvInt("DEAD_AFTER", 5), "Missed heartbeat intervals before an agent is evicted")
 
 	flag.Parse()
 	return c
@@ -32,3 +123,46 @@ func getEnv(key, fallback string) string {
 	}
 	return fallback
 }
+
+func getEnvInt(key string, fallback int) int {
+	if value, ok := os.LookupEnv(key); ok {
+		if n, err := strconv.Atoi(value); err == nil {
+			return n
+		}
+	}
+	return fallback
+}
+
+func getEnvBool(key string, fallback bool) bool {
+	if value, ok := os.LookupEnv(key); ok {
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return fallback
+}
+
+func defaultNodeID() string {
+	if host, err := os.Hostname(); err == nil {
+		return host
+	}
+	return "vextra"
+}
+
+func getEnvFloat(key string, fallback float64) float64 {
+	if value, ok := os.LookupEnv(key); ok {
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return f
+		}
+	}
+	return fallback
+}
+
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	if value, ok := os.LookupEnv(key); ok {
+		if d, err := time.ParseDuration(value); err == nil {
+			return d
+		}
+	}
+	return fallback
+}
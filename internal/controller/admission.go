@@ -0,0 +1,247 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	pb "github.com/groovy-byte/agent-mesh-core/proto"
+)
+
+var ErrBudgetExceeded = errors.New("admission: budget exceeded")
+
+// AgentBudget bounds one agent's inference use.
+type AgentBudget struct {
+	TokensPerMinute uint32
+	MaxConcurrent   int
+}
+
+// BudgetPolicy holds the operational-role budget at a 100% CPU limit.
+// Strategic agents get StrategicScale times as much. Zero limits are unlimited.
+type BudgetPolicy struct {
+	TokensPerMinute  uint32
+	MaxConcurrent    int
+	StrategicScale   float64
+	DefaultMaxTokens uint32        // Charged when a request leaves max_tokens unset.
+	ConcurrencyRetry time.Duration // Retry hint when the concurrency budget is full.
+}
+
+func DefaultBudgetPolicy() BudgetPolicy {
+	return BudgetPolicy{
+		TokensPerMinute:  20000,
+		MaxConcurrent:    2,
+		StrategicScale:   2,
+		DefaultMaxTokens: 256,
+		ConcurrencyRetry: time.Second,
+	}
+}
+
+// Budget derives an agent's budget from its role and the resource limits it
+// was granted at handshake: the CPU share scales both the token rate and
+// the concurrency, which never drops below one request.
+func (p BudgetPolicy) Budget(role pb.AgentRole, limits *pb.OSResources) AgentBudget {
+	scale := 1.0
+	if limits != nil && limits.CpuUsagePercent > 0 {
+		scale = min(limits.CpuUsagePercent/100, 1)
+	}
+	if role == pb.AgentRole_STRATEGIC && p.StrategicScale > 0 {
+		scale *= p.StrategicScale
+	}
+	b := AgentBudget{TokensPerMinute: uint32(math.Round(float64(p.TokensPerMinute) * scale))}
+	if p.MaxConcurrent > 0 {
+		b.MaxConcurrent = max(1, int(math.Round(float64(p.MaxConcurrent)*scale)))
+	}
+	return b
+}
+
+// Charge estimates a request's token cost before it runs: whitespace-split
+// prompt words plus the completion it may produce.
+func (p BudgetPolicy) Charge(req *pb.InferenceRequest) uint32 {
+	completion := req.MaxTokens
+	if completion == 0 {
+		completion = p.DefaultMaxTokens
+	}
+	return uint32(len(strings.Fields(req.Prompt))) + completion
+}
+
+// BudgetError reports which budget rejected a request. RetryAfter is zero
+// when waiting cannot help, i.e. the request alone exceeds the budget.
+type BudgetError struct {
+	AgentID    string
+	Reason     string
+	RetryAfter time.Duration
+}
+
+func (e *BudgetError) Error() string {
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("agent %s: %s (retry after %v)", e.AgentID, e.Reason, e.RetryAfter)
+	}
+	return fmt.Sprintf("agent %s: %s", e.AgentID, e.Reason)
+}
+
+func (e *BudgetError) Unwrap() error {
+	return ErrBudgetExceeded
+}
+
+// admissionSweepEvery is how often Admit drops idle agents' usage.
+const admissionSweepEvery = time.Minute
+
+// AdmissionController enforces per-agent budgets with a token bucket that
+// refills at TokensPerMinute and a count of in-flight requests. Agent IDs
+// come from clients, so usage is dropped once it holds nothing a new agent
+// would not start with, and when the registry evicts the agent.
+type AdmissionController struct {
+	mu        sync.Mutex
+	policy    BudgetPolicy
+	agents    map[string]*agentUsage
+	now       func() time.Time
+	lastSweep time.Time
+}
+
+type agentUsage struct {
+	tokens   float64 // Bucket level; negative after an under-estimated request.
+	capacity float64 // TokensPerMinute of the last budget applied.
+	updated  time.Time
+	inFlight int
+}
+
+// idle reports whether u is back to a new agent's full bucket at now.
+func (u *agentUsage) idle(now time.Time) bool {
+	return u.inFlight == 0 && u.tokens+now.Sub(u.updated).Minutes()*u.capacity >= u.capacity
+}
+
+func NewAdmissionController(policy BudgetPolicy) *AdmissionController {
+	return &AdmissionController{
+		policy: policy,
+		agents: make(map[string]*agentUsage),
+		now:    time.Now,
+	}
+}
+
+// Policy returns the budget policy in force.
+func (a *AdmissionController) Policy() BudgetPolicy {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.policy
+}
+
+// Configure replaces the budget policy; usage already recorded is kept.
+func (a *AdmissionController) Configure(p BudgetPolicy) {
+	a.mu.Lock()
+	a.policy = p
+	a.mu.Unlock()
+}
+
+// Admission is an admitted request. Done settles the estimated charge
+// against the tokens actually used.
+type Admission struct {
+	ctrl    *AdmissionController
+	agentID string
+	usage   *agentUsage
+	budget  AgentBudget
+	charge  uint32
+	once    sync.Once
+}
+
+// Admit reserves charge tokens and a concurrency slot for agentID, or
+// returns a *BudgetError. A TokensPerMinute of zero means unlimited tokens.
+func (a *AdmissionController) Admit(agentID string, budget AgentBudget, charge uint32) (*Admission, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.sweepLocked()
+	u := a.usageLocked(agentID, budget)
+	if budget.MaxConcurrent > 0 && u.inFlight >= budget.MaxConcurrent {
+		return nil, &BudgetError{
+			AgentID:    agentID,
+			Reason:     fmt.Sprintf("%d concurrent requests already in flight", u.inFlight),
+			RetryAfter: a.policy.ConcurrencyRetry,
+		}
+	}
+	if budget.TokensPerMinute > 0 {
+		if charge > budget.TokensPerMinute {
+			return nil, &BudgetError{
+				AgentID: agentID,
+				Reason:  fmt.Sprintf("request needs %d tokens, budget is %d per minute", charge, budget.TokensPerMinute),
+			}
+		}
+		if short := float64(charge) - u.tokens; short > 0 {
+			return nil, &BudgetError{
+				AgentID:    agentID,
+				Reason:     fmt.Sprintf("token budget exhausted (%d per minute)", budget.TokensPerMinute),
+				RetryAfter: time.Duration(short / float64(budget.TokensPerMinute) * float64(time.Minute)),
+			}
+		}
+		u.tokens -= float64(charge)
+	}
+	u.inFlight++
+	return &Admission{ctrl: a, agentID: agentID, usage: u, budget: budget, charge: charge}, nil
+}
+
+// usageLocked returns agentID's usage with its bucket refilled up to now.
+// New agents start with a full bucket. Callers must hold a.mu.
+func (a *AdmissionController) usageLocked(agentID string, budget AgentBudget) *agentUsage {
+	now := a.now()
+	capacity := float64(budget.TokensPerMinute)
+	u, ok := a.agents[agentID]
+	if !ok {
+		u = &agentUsage{tokens: capacity, capacity: capacity, updated: now}
+		a.agents[agentID] = u
+		return u
+	}
+	refill := now.Sub(u.updated).Minutes() * capacity
+	u.tokens = min(capacity, u.tokens+refill)
+	u.capacity = capacity
+	u.updated = now
+	return u
+}
+
+// sweepLocked drops idle usage at most once per admissionSweepEvery.
+// Callers must hold a.mu.
+func (a *AdmissionController) sweepLocked() {
+	now := a.now()
+	if now.Sub(a.lastSweep) < admissionSweepEvery {
+		return
+	}
+	a.lastSweep = now
+	for id, u := range a.agents {
+		if u.idle(now) {
+			delete(a.agents, id)
+		}
+	}
+}
+
+// Forget drops agentID's usage, so an agent registering again under the
+// same ID starts with a full budget. Requests still in flight settle
+// against nothing.
+func (a *AdmissionController) Forget(agentID string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	delete(a.agents, agentID)
+}
+
+// ForgetOnEvict forgets each agent's usage when r evicts it.
+func (a *AdmissionController) ForgetOnEvict(r *MeshRegistry) {
+	r.OnEvict(a.Forget)
+}
+
+// Done releases the concurrency slot and refunds the unused part of the
+// charge, or bills the overrun. It is safe to call more than once.
+func (ad *Admission) Done(tokensUsed uint32) {
+	ad.once.Do(func() {
+		a := ad.ctrl
+		a.mu.Lock()
+		defer a.mu.Unlock()
+		if a.agents[ad.agentID] != ad.usage {
+			return // Forgotten while in flight.
+		}
+		u := a.usageLocked(ad.agentID, ad.budget)
+		u.inFlight--
+		if ad.budget.TokensPerMinute > 0 {
+			u.tokens = min(float64(ad.budget.TokensPerMinute), u.tokens+float64(ad.charge)-float64(tokensUsed))
+		}
+	})
+}
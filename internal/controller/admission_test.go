@@ -0,0 +1,155 @@
+package controller
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	pb "github.com/groovy-byte/agent-mesh-core/proto"
+)
+
+func TestBudgetFollowsRoleAndLimits(t *testing.T) {
+	p := DefaultBudgetPolicy()
+
+	op := p.Budget(pb.AgentRole_OPERATIONAL, HandshakeLimits())
+	if op.TokensPerMinute != 15000 || op.MaxConcurrent != 2 {
+		t.Errorf("Expected 75%% of the operational budget, got %+v", op)
+	}
+	st := p.Budget(pb.AgentRole_STRATEGIC, HandshakeLimits())
+	if st.TokensPerMinute != 30000 || st.MaxConcurrent != 3 {
+		t.Errorf("Expected strategic agents to get twice as much, got %+v", st)
+	}
+	if full := p.Budget(pb.AgentRole_OPERATIONAL, nil); full.TokensPerMinute != 20000 {
+		t.Errorf("Expected the full budget without limits, got %+v", full)
+	}
+
+	if c := p.Charge(&pb.InferenceRequest{Prompt: "three word prompt", MaxTokens: 10}); c != 13 {
+		t.Errorf("Expected a charge of 13, got %d", c)
+	}
+	if c := p.Charge(&pb.InferenceRequest{Prompt: "hi"}); c != 1+p.DefaultMaxTokens {
+		t.Errorf("Expected unset max_tokens to charge the default, got %d", c)
+	}
+}
+
+func TestAdmissionTokenBucket(t *testing.T) {
+	a := NewAdmissionController(DefaultBudgetPolicy())
+	now := time.Unix(1000, 0)
+	a.now = func() time.Time { return now }
+	budget := AgentBudget{TokensPerMinute: 600, MaxConcurrent: 2}
+
+	first, err := a.Admit("coder", budget, 400)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first.Done(400)
+
+	// 200 tokens left; 300 more need 100 tokens of refill at 10 per second.
+	_, err = a.Admit("coder", budget, 300)
+	var be *BudgetError
+	if !errors.As(err, &be) || !errors.Is(err, ErrBudgetExceeded) {
+		t.Fatalf("Expected a BudgetError, got %v", err)
+	}
+	if be.RetryAfter != 10*time.Second {
+		t.Errorf("Expected a 10s retry hint, got %v", be.RetryAfter)
+	}
+
+	now = now.Add(10 * time.Second)
+	second, err := a.Admit("coder", budget, 300)
+	if err != nil {
+		t.Fatalf("Expected the refilled bucket to admit, got %v", err)
+	}
+	// Unused tokens are refunded.
+	second.Done(100)
+	second.Done(100)
+	if _, err := a.Admit("coder", budget, 200); err != nil {
+		t.Errorf("Expected the refund to cover another 200 tokens, got %v", err)
+	}
+
+	// A request larger than the whole budget can never succeed.
+	_, err = a.Admit("other", budget, 601)
+	if !errors.As(err, &be) || be.RetryAfter != 0 {
+		t.Errorf("Expected a rejection without a retry hint, got %v", err)
+	}
+}
+
+func TestAdmissionConcurrency(t *testing.T) {
+	a := NewAdmissionController(DefaultBudgetPolicy())
+	budget := AgentBudget{MaxConcurrent: 1}
+
+	held, err := a.Admit("coder", budget, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var be *BudgetError
+	if _, err := a.Admit("coder", budget, 10); !errors.As(err, &be) || be.RetryAfter != time.Second {
+		t.Errorf("Expected a concurrency rejection with a 1s hint, got %v", err)
+	}
+	if _, err := a.Admit("other", budget, 10); err != nil {
+		t.Errorf("Expected budgets to be per agent, got %v", err)
+	}
+	held.Done(10)
+	if _, err := a.Admit("coder", budget, 10); err != nil {
+		t.Errorf("Expected the released slot to admit, got %v", err)
+	}
+}
+
+func TestAdmissionDropsIdleUsage(t *testing.T) {
+	a := NewAdmissionController(DefaultBudgetPolicy())
+	now := time.Unix(1000, 0)
+	a.now = func() time.Time { return now }
+	budget := AgentBudget{TokensPerMinute: 600, MaxConcurrent: 2}
+
+	for _, id := range []string{"spent", "busy", "refilled"} {
+		ad, err := a.Admit(id, budget, 300)
+		if err != nil {
+			t.Fatal(err)
+		}
+		switch id {
+		case "spent":
+			ad.Done(900) // Overran by 600, leaving the bucket at -300.
+		case "refilled":
+			ad.Done(300)
+		}
+	}
+
+	// After a minute "refilled" holds nothing a new agent would not; "busy"
+	// still has a request in flight and "spent" is still refilling.
+	now = now.Add(admissionSweepEvery)
+	if _, err := a.Admit("new", budget, 10); err != nil {
+		t.Fatal(err)
+	}
+	if len(a.agents) != 3 || a.agents["refilled"] != nil {
+		t.Errorf("Expected spent, busy and new to remain, got %v", a.agents)
+	}
+	if _, err := a.Admit("spent", budget, 400); err == nil {
+		t.Error("Expected the overrun to still count against spent")
+	}
+}
+
+func TestAdmissionForgetsEvictedAgents(t *testing.T) {
+	a := NewAdmissionController(DefaultBudgetPolicy())
+	r := NewMeshRegistry()
+	a.ForgetOnEvict(r)
+	r.RegisterAgent(&pb.HandshakeRequest{AgentId: "coder"})
+	budget := AgentBudget{TokensPerMinute: 600, MaxConcurrent: 1}
+
+	held, err := a.Admit("coder", budget, 600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.EvictAgent("coder")
+	if len(a.agents) != 0 {
+		t.Fatalf("Expected eviction to drop the usage, got %v", a.agents)
+	}
+
+	// The same ID starts over, and the old request settles against nothing.
+	again, err := a.Admit("coder", budget, 600)
+	if err != nil {
+		t.Fatalf("Expected a full budget after eviction, got %v", err)
+	}
+	held.Done(600)
+	if u := a.agents["coder"]; u.inFlight != 1 {
+		t.Errorf("Expected only the new request in flight, got %d", u.inFlight)
+	}
+	again.Done(600)
+}
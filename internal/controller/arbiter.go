@@ -1,6 +1,9 @@
 package controller
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log"
 	"sync"
 	"time"
@@ -8,66 +11,237 @@ import (
 	pb "github.com/groovy-byte/agent-mesh-core/proto"
 )
 
+// LockTTL is the default strategic lock lease.
 const LockTTL = 30 * time.Second
 
+// MaxLockLease caps the lease an agent may request for a single grant or renewal.
+const MaxLockLease = 5 * time.Minute
+
+// DefaultLockDomain is the global strategic planning domain used when no goal or resource is named.
+const DefaultLockDomain = "strategic"
+
+var (
+	ErrLockNotHeld = errors.New("arbiter: strategic lock not held by agent")
+	ErrStaleToken  = errors.New("arbiter: stale fencing token")
+	ErrLockExpired = errors.New("arbiter: strategic lock lease expired")
+)
+
+// LockGrant describes a strategic lock lease. Token is a fencing token that
+// strictly increases with every new grant, so a holder whose lease was
+// reclaimed can be told apart from the current one.
+type LockGrant struct {
+	Domain    string
+	Holder    string
+	Token     uint64
+	ExpiresAt time.Time
+}
+
+// LockDomainStats reports contention on one lock domain.
+type LockDomainStats struct {
+	Holder        string
+	Grants        uint64        // Leases handed out, including hand-offs to waiters.
+	Contended     uint64        // Requests that found the domain held (denied or queued).
+	Timeouts      uint64        // Waiters that gave up on a context deadline or cancellation.
+	Reclaims      uint64        // Expired leases taken back from a stalled holder.
+	QueueDepth    int           // Agents currently waiting.
+	MaxQueueDepth int           // High-water mark of QueueDepth.
+	TotalWait     time.Duration // Time waiters spent queued before being granted.
+}
+
+// LockBackend arbitrates strategic lock domains. MemoryLockBackend serves a
+// single controller; JetStreamLockBackend shares leases across controllers.
+type LockBackend interface {
+	// AcquireLock grants domain to agentID without waiting; on denial it returns the holder's grant.
+	AcquireLock(domain, agentID string, lease time.Duration) (LockGrant, bool)
+	// WaitForLock blocks until domain is granted or ctx is done.
+	WaitForLock(ctx context.Context, domain, agentID string, priority int32, lease time.Duration) (LockGrant, error)
+	RenewLock(domain, agentID string, token uint64, lease time.Duration) (LockGrant, error)
+	ValidateToken(domain, agentID string, token uint64) error
+	// ReleaseLock frees every domain held by agentID.
+	ReleaseLock(agentID string)
+	ReleaseFencedLock(domain, agentID string, token uint64) error
+	LockHolder(domain string) (LockGrant, bool)
+	HeldDomains(agentID string) []string
+	LockStats() map[string]LockDomainStats
+}
+
 // Arbiter manages global strategic locks and state recovery
 type Arbiter struct {
-	mu            sync.Mutex
-	strategicLock string // Agent ID that currently holds the Strategic planning lock
-	lockTime      time.Time
-	lastStates    map[string]*pb.AgentAction
+	LockBackend
+
+	mu         sync.Mutex
+	lastStates map[string]*pb.AgentAction
+	history    map[string]*stateRing // Agent -> bounded snapshot history.
+	versions   map[string]uint64     // Agent -> last assigned state version.
+	depth      int
+	store      MeshStore
+	now        func() time.Time
 }
 
 func NewArbiter() *Arbiter {
 	return &Arbiter{
-		lastStates: make(map[string]*pb.AgentAction),
+		LockBackend: NewMemoryLockBackend(),
+		lastStates:  make(map[string]*pb.AgentAction),
+		history:     make(map[string]*stateRing),
+		versions:    make(map[string]uint64),
+		depth:       DefaultStateHistoryDepth,
+		store:       NewMemoryStore(),
+		now:         time.Now,
+	}
+}
+
+// NewArbiterWithStore creates an Arbiter that persists saved states to store
+// and reloads them so reconstitution survives a controller crash. Restored
+// histories keep historyDepth snapshots per agent, as ConfigureHistory would;
+// zero keeps DefaultStateHistoryDepth.
+func NewArbiterWithStore(store MeshStore, historyDepth int) (*Arbiter, error) {
+	if historyDepth <= 0 {
+		historyDepth = DefaultStateHistoryDepth
 	}
+	states, err := store.LoadStates()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load agent states: %w", err)
+	}
+	history, err := store.LoadStateHistory()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load state history: %w", err)
+	}
+	if len(states) > 0 {
+		log.Printf("[Arbiter] ♻️ Restored %d reconstitution snapshots from persistent store", len(states))
+	}
+
+	a := &Arbiter{
+		LockBackend: NewMemoryLockBackend(),
+		lastStates:  states,
+		history:     make(map[string]*stateRing),
+		versions:    make(map[string]uint64),
+		depth:       historyDepth,
+		store:       store,
+		now:         time.Now,
+	}
+	for id, snaps := range history {
+		ring := newStateRing(a.depth)
+		for _, snap := range snaps {
+			ring.push(snap)
+			a.versions[id] = max(a.versions[id], snap.Version)
+		}
+		a.history[id] = ring
+	}
+	return a, nil
+}
+
+// ConfigureHistory sets how many snapshots are kept per agent. Existing
+// histories are trimmed to their newest entries.
+func (a *Arbiter) ConfigureHistory(depth int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if depth <= 0 {
+		depth = DefaultStateHistoryDepth
+	}
+	a.depth = depth
+	for id, ring := range a.history {
+		a.history[id] = ring.resize(depth)
+	}
+}
+
+// UseLockBackend replaces the lock backend, e.g. with JetStream KV so several
+// controllers share one counterbalance. Call it before serving requests.
+func (a *Arbiter) UseLockBackend(b LockBackend) {
+	a.LockBackend = b
 }
 
 // RequestStrategicLock implements the Counterbalance mechanism to prevent 'Too many bosses'
 func (a *Arbiter) RequestStrategicLock(agentID string) bool {
+	_, ok := a.AcquireLock(DefaultLockDomain, agentID, LockTTL)
+	return ok
+}
+
+// SaveState records the last known operational state for reconstitution (Task 4.2)
+// and appends it to the agent's versioned history.
+func (a *Arbiter) SaveState(state *pb.AgentAction) StateSnapshot {
 	a.mu.Lock()
 	defer a.mu.Unlock()
 
-	// Check for stale lock
-	if a.strategicLock != "" && a.strategicLock != agentID {
-		if time.Since(a.lockTime) > LockTTL {
-			log.Printf("[Arbiter] ⚠️ Reclaiming stale lock from %s (Expired after %v)", a.strategicLock, LockTTL)
-			a.strategicLock = ""
-		}
+	state = cloneAction(state)
+	a.versions[state.AgentId]++
+	snap := StateSnapshot{Version: a.versions[state.AgentId], SavedAt: a.now(), Action: state}
+
+	ring, ok := a.history[state.AgentId]
+	if !ok {
+		ring = newStateRing(a.depth)
+		a.history[state.AgentId] = ring
 	}
+	ring.push(snap)
+	a.lastStates[state.AgentId] = state
 
-	if a.strategicLock == "" || a.strategicLock == agentID {
-		a.strategicLock = agentID
-		a.lockTime = time.Now()
-		log.Printf("[Arbiter] 🔑 Strategic Lock granted to %s", agentID)
-		return true
+	if err := a.store.SaveState(state); err != nil {
+		log.Printf("[Arbiter] ⚠️ Failed to persist state for %s: %v", state.AgentId, err)
 	}
+	if err := a.store.AppendStateSnapshot(snap, a.depth); err != nil {
+		log.Printf("[Arbiter] ⚠️ Failed to persist state history for %s: %v", state.AgentId, err)
+	}
+	return snap
+}
 
-	log.Printf("[Arbiter] ⚠️ Strategic Lock denied to %s (Held by %s)", agentID, a.strategicLock)
-	return false
+func (a *Arbiter) GetState(agentID string) (*pb.AgentAction, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	state, ok := a.lastStates[agentID]
+	return state, ok
 }
 
-// ReleaseLock frees the strategic reasoning path
-func (a *Arbiter) ReleaseLock(agentID string) {
+// GetStateVersion returns a specific retained snapshot.
+func (a *Arbiter) GetStateVersion(agentID string, version uint64) (StateSnapshot, error) {
 	a.mu.Lock()
 	defer a.mu.Unlock()
-	if a.strategicLock == agentID {
-		a.strategicLock = ""
-		log.Printf("[Arbiter] 🔓 Strategic Lock released by %s", agentID)
+	if ring, ok := a.history[agentID]; ok {
+		for _, snap := range ring.snapshots() {
+			if snap.Version == version {
+				return snap, nil
+			}
+		}
 	}
+	return StateSnapshot{}, fmt.Errorf("agent %s version %d: %w", agentID, version, ErrVersionNotFound)
 }
 
-// SaveState records the last known operational state for reconstitution (Task 4.2)
-func (a *Arbiter) SaveState(state *pb.AgentAction) {
+// GetStateAt returns the newest snapshot saved at or before t.
+func (a *Arbiter) GetStateAt(agentID string, t time.Time) (StateSnapshot, error) {
 	a.mu.Lock()
 	defer a.mu.Unlock()
-	a.lastStates[state.AgentId] = state
+	found, ok := StateSnapshot{}, false
+	if ring, exists := a.history[agentID]; exists {
+		for _, snap := range ring.snapshots() {
+			if snap.SavedAt.After(t) {
+				break
+			}
+			found, ok = snap, true
+		}
+	}
+	if !ok {
+		return StateSnapshot{}, fmt.Errorf("agent %s at %s: %w", agentID, t.Format(time.RFC3339Nano), ErrVersionNotFound)
+	}
+	return found, nil
 }
 
-func (a *Arbiter) GetState(agentID string) (*pb.AgentAction, bool) {
+// StateHistory returns the retained snapshots for agentID, oldest first.
+func (a *Arbiter) StateHistory(agentID string) []StateSnapshot {
 	a.mu.Lock()
 	defer a.mu.Unlock()
-	state, ok := a.lastStates[agentID]
-	return state, ok
+	if ring, ok := a.history[agentID]; ok {
+		return ring.snapshots()
+	}
+	return []StateSnapshot{}
+}
+
+// DiffStates compares two retained versions of an agent's state.
+func (a *Arbiter) DiffStates(agentID string, from, to uint64) ([]StateChange, error) {
+	before, err := a.GetStateVersion(agentID, from)
+	if err != nil {
+		return nil, err
+	}
+	after, err := a.GetStateVersion(agentID, to)
+	if err != nil {
+		return nil, err
+	}
+	return DiffActions(before.Action, after.Action), nil
 }
@@ -0,0 +1,280 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	pb "github.com/groovy-byte/agent-mesh-core/proto"
+)
+
+// newTestArbiter returns an Arbiter driven by a manual clock.
+func newTestArbiter() (*Arbiter, *time.Time) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	a := NewArbiter()
+	a.now = func() time.Time { return now }
+	a.LockBackend.(*MemoryLockBackend).now = a.now
+	return a, &now
+}
+
+func TestArbiterFencingTokensMonotonic(t *testing.T) {
+	a, _ := newTestArbiter()
+
+	first, ok := a.AcquireLock(DefaultLockDomain, "boss-a", time.Minute)
+	if !ok || first.Token == 0 {
+		t.Fatalf("Expected boss-a to be granted a token, got %+v", first)
+	}
+	again, _ := a.AcquireLock(DefaultLockDomain, "boss-a", time.Minute)
+	if again.Token != first.Token {
+		t.Errorf("Re-acquire by the holder should keep token %d, got %d", first.Token, again.Token)
+	}
+
+	if held, ok := a.AcquireLock(DefaultLockDomain, "boss-b", time.Minute); ok || held.Holder != "boss-a" {
+		t.Errorf("Expected boss-b denied while boss-a holds the lock, got %+v", held)
+	}
+
+	if err := a.ReleaseFencedLock(DefaultLockDomain, "boss-a", first.Token); err != nil {
+		t.Fatal(err)
+	}
+	second, ok := a.AcquireLock(DefaultLockDomain, "boss-b", time.Minute)
+	if !ok || second.Token <= first.Token {
+		t.Errorf("Expected a larger token than %d for the next grant, got %+v", first.Token, second)
+	}
+}
+
+func TestArbiterRenewAndExpiry(t *testing.T) {
+	a, now := newTestArbiter()
+
+	grant, _ := a.AcquireLock(DefaultLockDomain, "planner", 10*time.Second)
+	*now = now.Add(8 * time.Second)
+	renewed, err := a.RenewLock(DefaultLockDomain, "planner", grant.Token, 10*time.Second)
+	if err != nil {
+		t.Fatalf("Renew failed: %v", err)
+	}
+	if !renewed.ExpiresAt.Equal(now.Add(10 * time.Second)) {
+		t.Errorf("Unexpected renewed expiry %v", renewed.ExpiresAt)
+	}
+
+	// Lease lapses without renewal.
+	*now = now.Add(11 * time.Second)
+	if err := a.ValidateToken(DefaultLockDomain, "planner", grant.Token); !errors.Is(err, ErrLockExpired) {
+		t.Errorf("Expected ErrLockExpired, got %v", err)
+	}
+	if _, err := a.RenewLock(DefaultLockDomain, "planner", grant.Token, 0); !errors.Is(err, ErrLockExpired) {
+		t.Errorf("Expected renewal of an expired lease to fail, got %v", err)
+	}
+}
+
+func TestArbiterRejectsStaleHolder(t *testing.T) {
+	a, now := newTestArbiter()
+
+	stale, _ := a.AcquireLock(DefaultLockDomain, "sleepy", LockTTL)
+	*now = now.Add(LockTTL + time.Second)
+
+	fresh, ok := a.AcquireLock(DefaultLockDomain, "eager", LockTTL)
+	if !ok || fresh.Token <= stale.Token {
+		t.Fatalf("Expected eager to reclaim the expired lock with a newer token, got %+v", fresh)
+	}
+
+	// The stalled holder wakes up and tries to act on its old token.
+	if err := a.ValidateToken(DefaultLockDomain, "sleepy", stale.Token); !errors.Is(err, ErrStaleToken) {
+		t.Errorf("Expected ErrStaleToken for reclaimed holder, got %v", err)
+	}
+	if _, err := a.RenewLock(DefaultLockDomain, "sleepy", stale.Token, LockTTL); !errors.Is(err, ErrStaleToken) {
+		t.Errorf("Expected stale renewal to fail, got %v", err)
+	}
+	if err := a.ReleaseFencedLock(DefaultLockDomain, "sleepy", stale.Token); !errors.Is(err, ErrStaleToken) {
+		t.Errorf("Expected stale release to fail, got %v", err)
+	}
+	if holder, ok := a.LockHolder(DefaultLockDomain); !ok || holder.Holder != "eager" {
+		t.Errorf("Stale holder disturbed the current lease: %+v", holder)
+	}
+}
+
+func TestArbiterLeaseClamped(t *testing.T) {
+	a, now := newTestArbiter()
+
+	grant, _ := a.AcquireLock(DefaultLockDomain, "greedy", time.Hour)
+	if !grant.ExpiresAt.Equal(now.Add(MaxLockLease)) {
+		t.Errorf("Expected lease capped at %v, got expiry %v", MaxLockLease, grant.ExpiresAt)
+	}
+}
+
+func TestArbiterIndependentDomains(t *testing.T) {
+	a, _ := newTestArbiter()
+
+	if _, ok := a.AcquireLock("goal:compile", "boss-a", LockTTL); !ok {
+		t.Fatal("Expected boss-a to lock goal:compile")
+	}
+	if _, ok := a.AcquireLock("goal:research", "boss-b", LockTTL); !ok {
+		t.Error("Unrelated goals should not block each other")
+	}
+	if _, ok := a.AcquireLock("goal:compile", "boss-b", LockTTL); ok {
+		t.Error("Expected boss-b denied on goal:compile")
+	}
+
+	a.ReleaseLock("boss-a")
+	if held := a.HeldDomains("boss-b"); len(held) != 1 || held[0] != "goal:research" {
+		t.Errorf("Expected boss-b to hold only goal:research, got %v", held)
+	}
+	stats := a.LockStats()
+	if stats["goal:compile"].Contended != 1 || stats["goal:research"].Contended != 0 {
+		t.Errorf("Unexpected per-domain contention: %+v", stats)
+	}
+}
+
+func TestArbiterWaitQueueOrder(t *testing.T) {
+	a := NewArbiter()
+	holder, _ := a.AcquireLock("gpu:0", "holder", LockTTL)
+
+	order := make(chan string, 3)
+	enqueue := func(id string, priority int32) {
+		go func() {
+			grant, err := a.WaitForLock(context.Background(), "gpu:0", id, priority, LockTTL)
+			if err != nil {
+				t.Errorf("%s: %v", id, err)
+				return
+			}
+			order <- id
+			a.ReleaseFencedLock("gpu:0", id, grant.Token)
+		}()
+		// Wait until queued so arrival order is deterministic.
+		for a.LockStats()["gpu:0"].QueueDepth == 0 || !queued(a, "gpu:0", id) {
+			time.Sleep(time.Millisecond)
+		}
+	}
+	enqueue("first", 0)
+	enqueue("second", 0)
+	enqueue("urgent", 5)
+
+	if st := a.LockStats()["gpu:0"]; st.QueueDepth != 3 || st.MaxQueueDepth != 3 {
+		t.Errorf("Expected 3 waiters, got %+v", st)
+	}
+	a.ReleaseFencedLock("gpu:0", "holder", holder.Token)
+
+	got := []string{<-order, <-order, <-order}
+	want := []string{"urgent", "first", "second"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Expected grant order %v, got %v", want, got)
+		}
+	}
+	if st := a.LockStats()["gpu:0"]; st.Grants != 4 || st.QueueDepth != 0 {
+		t.Errorf("Unexpected stats after drain: %+v", st)
+	}
+}
+
+func TestArbiterWaitHonorsDeadline(t *testing.T) {
+	a := NewArbiter()
+	a.AcquireLock("", "holder", LockTTL)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := a.WaitForLock(ctx, "", "impatient", 0, LockTTL); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected DeadlineExceeded, got %v", err)
+	}
+	st := a.LockStats()[DefaultLockDomain]
+	if st.Timeouts != 1 || st.QueueDepth != 0 {
+		t.Errorf("Expected timed-out waiter to leave the queue, got %+v", st)
+	}
+}
+
+func TestArbiterWaiterReclaimsExpiredLease(t *testing.T) {
+	a := NewArbiter()
+	stale, _ := a.AcquireLock("", "sleepy", 30*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	grant, err := a.WaitForLock(ctx, "", "patient", 0, LockTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if grant.Holder != "patient" || grant.Token <= stale.Token {
+		t.Errorf("Expected patient to take over with a newer token, got %+v", grant)
+	}
+	if a.LockStats()[DefaultLockDomain].Reclaims != 1 {
+		t.Errorf("Expected one reclaim")
+	}
+}
+
+// queued reports whether agentID is waiting on domain.
+func queued(a *Arbiter, domain, agentID string) bool {
+	m := a.LockBackend.(*MemoryLockBackend)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, w := range m.domains[domain].waiters {
+		if w.agentID == agentID {
+			return true
+		}
+	}
+	return false
+}
+
+func TestArbiterStateHistory(t *testing.T) {
+	a, now := newTestArbiter()
+	a.ConfigureHistory(3)
+	start := *now
+
+	for i, step := range []string{"step 1", "step 2", "step 3", "step 4 (corrupt)"} {
+		*now = start.Add(time.Duration(i) * time.Minute)
+		snap := a.SaveState(&pb.AgentAction{AgentId: "coder", ActionType: "REASONING", ReasoningChain: step})
+		if snap.Version != uint64(i+1) {
+			t.Fatalf("Expected version %d, got %d", i+1, snap.Version)
+		}
+	}
+
+	history := a.StateHistory("coder")
+	if len(history) != 3 || history[0].Version != 2 || history[2].Version != 4 {
+		t.Fatalf("Expected versions 2..4 retained, got %+v", history)
+	}
+	if _, err := a.GetStateVersion("coder", 1); !errors.Is(err, ErrVersionNotFound) {
+		t.Errorf("Expected version 1 to be evicted, got %v", err)
+	}
+
+	// Roll back past the corrupt last step.
+	prev, err := a.GetStateVersion("coder", 3)
+	if err != nil || prev.Action.ReasoningChain != "step 3" {
+		t.Errorf("Unexpected version 3: %+v (%v)", prev, err)
+	}
+	at, err := a.GetStateAt("coder", start.Add(90*time.Second))
+	if err != nil || at.Version != 2 {
+		t.Errorf("Expected version 2 at t+90s, got %+v (%v)", at, err)
+	}
+	if _, err := a.GetStateAt("coder", start.Add(-time.Hour)); !errors.Is(err, ErrVersionNotFound) {
+		t.Errorf("Expected no snapshot before history began, got %v", err)
+	}
+
+	latest, _ := a.GetState("coder")
+	if latest.ReasoningChain != "step 4 (corrupt)" {
+		t.Errorf("GetState should still return the latest snapshot, got %q", latest.ReasoningChain)
+	}
+}
+
+func TestArbiterDiffStates(t *testing.T) {
+	a := NewArbiter()
+	a.SaveState(&pb.AgentAction{AgentId: "coder", ActionType: "REASONING", ReasoningChain: "plan"})
+	a.SaveState(&pb.AgentAction{AgentId: "coder", ActionType: "REASONING", ReasoningChain: "plan, then build", DataSizeBytes: 4096})
+
+	changes, err := a.DiffStates("coder", 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]StateChange{}
+	for _, c := range changes {
+		got[c.Field] = c
+	}
+	if len(got) != 2 {
+		t.Fatalf("Expected 2 changed fields, got %+v", changes)
+	}
+	if c := got["reasoning_chain"]; c.Before != `"plan"` || c.After != `"plan, then build"` {
+		t.Errorf("Unexpected reasoning_chain change: %+v", c)
+	}
+	if c := got["data_size_bytes"]; c.Before != "" || c.After != "4096" {
+		t.Errorf("Unexpected data_size_bytes change: %+v", c)
+	}
+
+	if _, err := a.DiffStates("coder", 1, 9); !errors.Is(err, ErrVersionNotFound) {
+		t.Errorf("Expected ErrVersionNotFound, got %v", err)
+	}
+}
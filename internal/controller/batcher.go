@@ -0,0 +1,202 @@
+package controller
+
+import (
+	"context"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/groovy-byte/agent-mesh-core/internal/backend"
+)
+
+// DefaultMaxBatchSize caps a batch when BatchPolicy.MaxSize is unset.
+const DefaultMaxBatchSize = 8
+
+// BatchPolicy controls how InferenceController coalesces requests that share
+// a hardware path and backend. A zero Window disables batching. Backends that are not a
+// backend.BatchGenerator are never batched, since the window would only add
+// latency to requests they run one by one anyway.
+type BatchPolicy struct {
+	Window  time.Duration // How long the first request waits for company.
+	MaxSize int           // Dispatch as soon as this many are queued.
+}
+
+// BatchHistogram counts dispatched batches by size for one hardware path.
+type BatchHistogram struct {
+	Batches  uint64
+	Requests uint64
+	Expired  uint64         // Requests whose context ended before dispatch.
+	Sizes    map[int]uint64 // Batch size -> number of batches.
+}
+
+// batcher collects requests per hardware path and backend and runs them
+// through backend.Batch when the window closes or the batch fills up.
+type batcher struct {
+	mu      sync.Mutex
+	policy  BatchPolicy
+	pending map[batchKey]*pendingBatch
+	stats   map[string]*BatchHistogram
+}
+
+// batchKey separates requests for one path that resolved to different
+// backends, e.g. across a RegisterBackend.
+type batchKey struct {
+	path    string
+	backend backend.Backend
+}
+
+type pendingBatch struct {
+	key     batchKey
+	items   []*batchItem
+	flushAt time.Time
+	timer   *time.Timer
+}
+
+type batchItem struct {
+	ctx  context.Context
+	req  backend.Request
+	done chan backend.BatchResult
+}
+
+func newBatcher() *batcher {
+	return &batcher{
+		pending: make(map[batchKey]*pendingBatch),
+		stats:   make(map[string]*BatchHistogram),
+	}
+}
+
+func (b *batcher) configure(p BatchPolicy) {
+	if p.MaxSize <= 0 {
+		p.MaxSize = DefaultMaxBatchSize
+	}
+	b.mu.Lock()
+	b.policy = p
+	b.mu.Unlock()
+}
+
+// submit queues req behind hardwarePath and waits for its share of the
+// batch. A request whose deadline falls inside the window dispatches the
+// batch early rather than wait past it.
+func (b *batcher) submit(ctx context.Context, hardwarePath string, be backend.Backend, req backend.Request) (backend.Result, error) {
+	_, batches := be.(backend.BatchGenerator)
+	b.mu.Lock()
+	if b.policy.Window <= 0 || !batches {
+		b.mu.Unlock()
+		return be.Generate(ctx, req)
+	}
+
+	item := &batchItem{ctx: ctx, req: req, done: make(chan backend.BatchResult, 1)}
+	key := batchKey{path: hardwarePath, backend: be}
+	pend := b.pending[key]
+	if pend == nil {
+		pend = &pendingBatch{key: key, flushAt: time.Now().Add(b.policy.Window)}
+		pend.timer = time.AfterFunc(b.policy.Window, func() { b.flush(pend) })
+		b.pending[key] = pend
+	}
+	pend.items = append(pend.items, item)
+
+	dispatch := len(pend.items) >= b.policy.MaxSize
+	if deadline, ok := ctx.Deadline(); ok && deadline.Before(pend.flushAt) {
+		dispatch = true
+	}
+	if dispatch {
+		pend.timer.Stop()
+		delete(b.pending, key)
+	}
+	b.mu.Unlock()
+
+	if dispatch {
+		go b.run(pend)
+	}
+
+	select {
+	case res := <-item.done:
+		return res.Result, res.Err
+	case <-ctx.Done():
+		return backend.Result{}, ctx.Err()
+	}
+}
+
+// flush runs pend when its window closes, unless it was already dispatched.
+func (b *batcher) flush(pend *pendingBatch) {
+	b.mu.Lock()
+	if b.pending[pend.key] != pend {
+		b.mu.Unlock()
+		return
+	}
+	delete(b.pending, pend.key)
+	b.mu.Unlock()
+	b.run(pend)
+}
+
+// run drops requests that already gave up, generates the rest as one batch
+// and hands each result back. The batch is cancelled once every member has.
+func (b *batcher) run(pend *pendingBatch) {
+	live := make([]*batchItem, 0, len(pend.items))
+	for _, item := range pend.items {
+		if item.ctx.Err() == nil {
+			live = append(live, item)
+		}
+	}
+	b.record(pend.key.path, len(live), len(pend.items)-len(live))
+	if len(live) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	remaining := int32(len(live))
+	for _, item := range live {
+		stop := context.AfterFunc(item.ctx, func() {
+			if atomic.AddInt32(&remaining, -1) == 0 {
+				cancel()
+			}
+		})
+		defer stop()
+	}
+
+	reqs := make([]backend.Request, len(live))
+	for i, item := range live {
+		reqs[i] = item.req
+	}
+	if len(live) > 1 {
+		log.Printf("[Inference] 📦 Batch of %d on %s", len(live), pend.key.path)
+	}
+	results := backend.Batch(ctx, pend.key.backend, reqs)
+	for i, item := range live {
+		item.done <- results[i]
+	}
+}
+
+func (b *batcher) record(hardwarePath string, size, expired int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	h := b.stats[hardwarePath]
+	if h == nil {
+		h = &BatchHistogram{Sizes: make(map[int]uint64)}
+		b.stats[hardwarePath] = h
+	}
+	h.Expired += uint64(expired)
+	if size == 0 {
+		return
+	}
+	h.Batches++
+	h.Requests += uint64(size)
+	h.Sizes[size]++
+}
+
+// snapshot copies the histograms, keyed by hardware path.
+func (b *batcher) snapshot() map[string]BatchHistogram {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	out := make(map[string]BatchHistogram, len(b.stats))
+	for path, h := range b.stats {
+		sizes := make(map[int]uint64, len(h.Sizes))
+		for size, n := range h.Sizes {
+			sizes[size] = n
+		}
+		out[path] = BatchHistogram{Batches: h.Batches, Requests: h.Requests, Expired: h.Expired, Sizes: sizes}
+	}
+	return out
+}
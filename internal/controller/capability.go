@@ -0,0 +1,188 @@
+package controller
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	pb "github.com/groovy-byte/agent-mesh-core/proto"
+	"github.com/nats-io/nats.go"
+	"google.golang.org/protobuf/proto"
+)
+
+// CapabilitySubjectPrefix is the NATS subject root; nodes publish on mesh.capability.<node_id>.
+const CapabilitySubjectPrefix = "mesh.capability"
+
+// DefaultCapabilityInterval is how often a node republishes its profile.
+const DefaultCapabilityInterval = 10 * time.Second
+
+// CapabilityDirectory holds the latest profile from every peer. Profiles older
+// than maxAge are treated as gone.
+type CapabilityDirectory struct {
+	mu     sync.RWMutex
+	maxAge time.Duration
+	nodes  map[string]capabilityEntry
+}
+
+type capabilityEntry struct {
+	cap        *pb.NodeCapability
+	receivedAt time.Time
+}
+
+func NewCapabilityDirectory(maxAge time.Duration) *CapabilityDirectory {
+	if maxAge <= 0 {
+		maxAge = 3 * DefaultCapabilityInterval
+	}
+	return &CapabilityDirectory{
+		maxAge: maxAge,
+		nodes:  make(map[string]capabilityEntry),
+	}
+}
+
+// Update stores c as the latest profile for its node.
+func (d *CapabilityDirectory) Update(c *pb.NodeCapability, now time.Time) {
+	d.mu.Lock()
+	d.nodes[c.NodeId] = capabilityEntry{cap: c, receivedAt: now}
+	d.mu.Unlock()
+}
+
+// Get returns a node's latest profile, fresh or not.
+func (d *CapabilityDirectory) Get(nodeID string) (*pb.NodeCapability, bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	e, ok := d.nodes[nodeID]
+	return e.cap, ok
+}
+
+// Peers returns the profiles received within maxAge of now, ordered by node ID.
+func (d *CapabilityDirectory) Peers(now time.Time) []*pb.NodeCapability {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	peers := make([]*pb.NodeCapability, 0, len(d.nodes))
+	for _, e := range d.nodes {
+		if now.Sub(e.receivedAt) <= d.maxAge {
+			peers = append(peers, e.cap)
+		}
+	}
+	sort.Slice(peers, func(i, j int) bool { return peers[i].NodeId < peers[j].NodeId })
+	return peers
+}
+
+// CapabilityExchange publishes this node's profile on an interval and records
+// every other node's profile in a CapabilityDirectory.
+type CapabilityExchange struct {
+	nc       *nats.Conn
+	nodeID   string
+	dir      *CapabilityDirectory
+	source   func() *pb.NodeCapability
+	interval time.Duration
+
+	mu   sync.Mutex
+	sub  *nats.Subscription
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewCapabilityExchange publishes whatever source returns; source's node_id must be nodeID.
+func NewCapabilityExchange(nc *nats.Conn, nodeID string, dir *CapabilityDirectory, source func() *pb.NodeCapability, interval time.Duration) *CapabilityExchange {
+	if interval <= 0 {
+		interval = DefaultCapabilityInterval
+	}
+	return &CapabilityExchange{
+		nc:       nc,
+		nodeID:   nodeID,
+		dir:      dir,
+		source:   source,
+		interval: interval,
+	}
+}
+
+// Start subscribes to mesh.capability.*, publishes immediately and then on every interval.
+func (x *CapabilityExchange) Start() error {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if x.sub != nil {
+		return fmt.Errorf("capability exchange already started")
+	}
+	sub, err := x.nc.Subscribe(CapabilitySubjectPrefix+".*", x.handle)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to capabilities: %w", err)
+	}
+	x.sub = sub
+	x.stop = make(chan struct{})
+	x.done = make(chan struct{})
+
+	x.publish()
+	go x.publishLoop(x.stop, x.done)
+	log.Printf("[Mesh] 🌐 Publishing %s.%s every %v", CapabilitySubjectPrefix, x.nodeID, x.interval)
+	return nil
+}
+
+// Stop unsubscribes and halts publishing.
+func (x *CapabilityExchange) Stop() {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if x.sub == nil {
+		return
+	}
+	x.sub.Unsubscribe()
+	close(x.stop)
+	<-x.done
+	x.sub = nil
+}
+
+func (x *CapabilityExchange) handle(msg *nats.Msg) {
+	c := &pb.NodeCapability{}
+	if err := proto.Unmarshal(msg.Data, c); err != nil {
+		log.Printf("[Mesh] Dropping malformed capability on %s: %v", msg.Subject, err)
+		return
+	}
+	subjectID := strings.TrimPrefix(msg.Subject, CapabilitySubjectPrefix+".")
+	if c.NodeId != subjectID {
+		log.Printf("[Mesh] Dropping capability for %s published on %s", c.NodeId, msg.Subject)
+		return
+	}
+	if c.NodeId == x.nodeID {
+		return
+	}
+	x.dir.Update(c, time.Now())
+}
+
+func (x *CapabilityExchange) publish() {
+	if err := PublishCapability(x.nc, x.source()); err != nil {
+		log.Printf("[Mesh] ⚠️ %v", err)
+	}
+}
+
+func (x *CapabilityExchange) publishLoop(stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(x.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			x.publish()
+		}
+	}
+}
+
+// PublishCapability sends a node profile on its mesh.capability.<node_id> subject.
+func PublishCapability(nc *nats.Conn, c *pb.NodeCapability) error {
+	data, err := proto.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("failed to marshal capability: %w", err)
+	}
+	if err := nc.Publish(fmt.Sprintf("%s.%s", CapabilitySubjectPrefix, c.NodeId), data); err != nil {
+		return fmt.Errorf("failed to publish capability: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/groovy-byte/agent-mesh-core/internal/topology"
+	pb "github.com/groovy-byte/agent-mesh-core/proto"
+)
+
+func TestRouteTaskGlobal(t *testing.T) {
+	// Node A routes; B has a GTX 1070 (Vulkan path), C an AVX-512 CPU.
+	local := NewScheInfer(16*1024*1024, "", 0, false)
+	local.ConfigureNetwork(NetworkCost{GBs: 100, RTT: 100 * time.Microsecond})
+	local.ConfigureLoad(LoadPolicy{MaxQueueDepth: 4})
+
+	nodeB := NewScheInferFromProfile(topology.HardwareProfile{
+		L3CacheBytes: 8 << 20,
+		GPUs:         []topology.GPUDevice{{Index: 0, Name: "GTX 1070", VRAMBytes: 8 << 30, FreeBytes: 8 << 30, ComputeCap: 61}},
+	}, 8<<20)
+	nodeC := NewScheInfer(12*1024*1024, "", 0, true)
+	capB := nodeB.Capability("node-b", "10.0.0.2:50051")
+	capC := nodeC.Capability("node-c", "10.0.0.3:50051")
+	if capB.SimdTier != "AVX2" || len(capB.Gpus) != 1 || capC.SimdTier != "AVX512" {
+		t.Fatalf("Unexpected capability profiles: %v / %v", capB, capC)
+	}
+	peers := []*pb.NodeCapability{capB, capC}
+
+	if g := local.RouteTaskGlobal(1<<30, peers); g.String() != "node-c/CPU_AVX512" || g.Addr != "10.0.0.3:50051" {
+		t.Errorf("Expected node-c/CPU_AVX512 for a 1GB task, got %s", g)
+	}
+
+	// A saturated AVX-512 node loses to B's GPU.
+	capC.Load[ProviderCPUAVX512] = &pb.ProviderLoadMetrics{InFlight: 4}
+	if g := local.RouteTaskGlobal(1<<30, peers); g.String() != "node-b/GPU_VULKAN:0" {
+		t.Errorf("Expected node-b/GPU_VULKAN:0 with node-c saturated, got %s", g)
+	}
+
+	// B's GPU cannot hold a 10GB tensor and C is busy; A keeps the work.
+	if g := local.RouteTaskGlobal(10<<30, peers); g.Remote() {
+		t.Errorf("Expected local placement, got %s", g)
+	}
+	if g := local.RouteTaskGlobal(64*1024, peers); g.Remote() || g.String() != ProviderCPUAVX2 {
+		t.Errorf("Expected small tasks to stay local, got %s", g)
+	}
+
+	// Nodes that do not accept forwarded work are ignored.
+	capC.Load = nil
+	capC.GrpcAddr = ""
+	if g := local.RouteTaskGlobal(1<<30, peers); g.Node == "node-c" {
+		t.Errorf("Expected node-c without an address to be skipped, got %s", g)
+	}
+}
+
+func TestCapabilityExchange(t *testing.T) {
+	nc := runEmbeddedNATS(t)
+
+	dirA := NewCapabilityDirectory(time.Minute)
+	dirB := NewCapabilityDirectory(time.Minute)
+	schedA := NewScheInfer(16*1024*1024, "", 0, true)
+	schedB := NewScheInfer(8*1024*1024, "", 0, false)
+	a := NewCapabilityExchange(nc, "node-a", dirA, func() *pb.NodeCapability { return schedA.Capability("node-a", "a:50051") }, time.Hour)
+	b := NewCapabilityExchange(nc, "node-b", dirB, func() *pb.NodeCapability { return schedB.Capability("node-b", "b:50051") }, time.Hour)
+	if err := a.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer a.Stop()
+	if err := b.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer b.Stop()
+
+	// b published after a subscribed; a re-publishes so b sees it too.
+	a.publish()
+	nc.Flush()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if len(dirA.Peers(time.Now())) == 1 && len(dirB.Peers(time.Now())) == 1 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	peers := dirA.Peers(time.Now())
+	if len(peers) != 1 || peers[0].NodeId != "node-b" || peers[0].L3CacheBytes != 8<<20 {
+		t.Fatalf("Expected node-a to know only node-b, got %v", peers)
+	}
+	if c, ok := dirB.Get("node-a"); !ok || c.SimdTier != "AVX512" || c.GrpcAddr != "a:50051" {
+		t.Errorf("Expected node-b to know node-a's profile, got %v", c)
+	}
+
+	// Profiles past maxAge drop out of the peer list.
+	if peers := dirA.Peers(time.Now().Add(2 * time.Minute)); len(peers) != 0 {
+		t.Errorf("Expected stale peers to be dropped, got %d", len(peers))
+	}
+}
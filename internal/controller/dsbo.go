@@ -0,0 +1,198 @@
+package controller
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// DSBOConfig tunes Distributed Submodular Bandit Optimization (DSBO) neighbor selection.
+type DSBOConfig struct {
+	InitialNeighbors int     // Neighbors granted at handshake.
+	MaxNeighbors     int     // Upper bound when novel context expands a neighborhood.
+	Exploration      float64 // UCB exploration coefficient.
+	CapabilityWeight float64 // Prior weight of capability overlap; the rest goes to VoC.
+	Redundancy       float64 // Discount (0..1) for capabilities already covered by chosen neighbors.
+	Seed             int64   // Seeds tie-breaking so selection is reproducible.
+}
+
+func DefaultDSBOConfig() DSBOConfig {
+	return DSBOConfig{
+		InitialNeighbors: 2,
+		MaxNeighbors:     4,
+		Exploration:      0.5,
+		CapabilityWeight: 0.5,
+		Redundancy:       0.5,
+		Seed:             1,
+	}
+}
+
+// NeighborCandidate is an agent that may be chosen as a communication neighbor.
+type NeighborCandidate struct {
+	ID           string
+	Capabilities []string
+	VoC          float64 // Value of Contribution between the candidate and the selecting agent.
+}
+
+// NeighborEdge describes one directed edge of the neighbor graph.
+type NeighborEdge struct {
+	TargetID   string
+	Score      float64 // Current UCB estimate.
+	Pulls      uint32  // Evaluation rounds this edge has been observed.
+	MeanReward float64
+}
+
+type banditArm struct {
+	pulls  uint32
+	reward float64 // Cumulative reward.
+}
+
+// NeighborSelector scores candidate neighbors with a UCB bandit whose prior
+// combines capability overlap and VoC, then picks a neighborhood greedily by
+// marginal gain so that redundant capabilities are diminished (submodularity).
+// It is not safe for concurrent use; MeshRegistry guards it with its own lock.
+type NeighborSelector struct {
+	cfg    DSBOConfig
+	rng    *rand.Rand
+	arms   map[string]map[string]*banditArm // Agent -> {Neighbor: Arm}
+	rounds map[string]uint32                // Agent -> total observations
+}
+
+func NewNeighborSelector(cfg DSBOConfig) *NeighborSelector {
+	return &NeighborSelector{
+		cfg:    cfg,
+		rng:    rand.New(rand.NewSource(cfg.Seed)),
+		arms:   make(map[string]map[string]*banditArm),
+		rounds: make(map[string]uint32),
+	}
+}
+
+// Select returns up to k neighbor IDs for agentID.
+func (s *NeighborSelector) Select(agentID string, agentCaps []string, candidates []NeighborCandidate, k int) []string {
+	if k <= 0 || len(candidates) == 0 {
+		return []string{}
+	}
+
+	// Deterministic order for a given seed: sort, then shuffle so ties are broken fairly.
+	remaining := make([]NeighborCandidate, 0, len(candidates))
+	for _, c := range candidates {
+		if c.ID != agentID {
+			remaining = append(remaining, c)
+		}
+	}
+	sort.Slice(remaining, func(i, j int) bool { return remaining[i].ID < remaining[j].ID })
+	s.rng.Shuffle(len(remaining), func(i, j int) { remaining[i], remaining[j] = remaining[j], remaining[i] })
+
+	scores := make(map[string]float64, len(remaining))
+	for _, c := range remaining {
+		scores[c.ID] = s.Score(agentID, agentCaps, c)
+	}
+
+	covered := make(map[string]bool)
+	chosen := []string{}
+	for len(chosen) < k && len(remaining) > 0 {
+		best, bestGain := -1, math.Inf(-1)
+		for i, c := range remaining {
+			gain := scores[c.ID] * (1 - s.cfg.Redundancy*redundancy(c.Capabilities, covered))
+			if gain > bestGain {
+				best, bestGain = i, gain
+			}
+		}
+		pick := remaining[best]
+		chosen = append(chosen, pick.ID)
+		for _, capability := range pick.Capabilities {
+			covered[capability] = true
+		}
+		remaining = append(remaining[:best], remaining[best+1:]...)
+	}
+	return chosen
+}
+
+// Score is the UCB estimate for agentID communicating with candidate. The prior
+// counts as one pseudo-observation so unexplored arms are ranked by fit, not at random.
+func (s *NeighborSelector) Score(agentID string, agentCaps []string, c NeighborCandidate) float64 {
+	prior := s.cfg.CapabilityWeight*jaccard(agentCaps, c.Capabilities) + (1-s.cfg.CapabilityWeight)*c.VoC
+
+	var pulls uint32
+	var reward float64
+	if arm, ok := s.arms[agentID][c.ID]; ok {
+		pulls, reward = arm.pulls, arm.reward
+	}
+	n := float64(pulls + 1)
+	estimate := (reward + prior) / n
+	bonus := s.cfg.Exploration * math.Sqrt(math.Log(float64(s.rounds[agentID])+2)/n)
+	return estimate + bonus
+}
+
+// Record feeds an observed reward (clamped to [0, 1]) for the agentID -> neighborID edge.
+func (s *NeighborSelector) Record(agentID, neighborID string, reward float64) {
+	reward = math.Max(0, math.Min(1, reward))
+	if _, ok := s.arms[agentID]; !ok {
+		s.arms[agentID] = make(map[string]*banditArm)
+	}
+	arm, ok := s.arms[agentID][neighborID]
+	if !ok {
+		arm = &banditArm{}
+		s.arms[agentID][neighborID] = arm
+	}
+	arm.pulls++
+	arm.reward += reward
+	s.rounds[agentID]++
+}
+
+// Stats returns the observation count and mean reward for an edge.
+func (s *NeighborSelector) Stats(agentID, neighborID string) (uint32, float64) {
+	arm, ok := s.arms[agentID][neighborID]
+	if !ok || arm.pulls == 0 {
+		return 0, 0
+	}
+	return arm.pulls, arm.reward / float64(arm.pulls)
+}
+
+// Forget drops all bandit state involving agentID.
+func (s *NeighborSelector) Forget(agentID string) {
+	delete(s.arms, agentID)
+	delete(s.rounds, agentID)
+	for _, arms := range s.arms {
+		delete(arms, agentID)
+	}
+}
+
+// jaccard is the capability overlap |A∩B| / |A∪B|.
+func jaccard(a, b []string) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 0
+	}
+	set := make(map[string]bool, len(a))
+	for _, x := range a {
+		set[x] = true
+	}
+	inter, union := 0, len(set)
+	seen := make(map[string]bool, len(b))
+	for _, x := range b {
+		if seen[x] {
+			continue
+		}
+		seen[x] = true
+		if set[x] {
+			inter++
+		} else {
+			union++
+		}
+	}
+	return float64(inter) / float64(union)
+}
+
+// redundancy is the fraction of caps already covered by the chosen neighborhood.
+func redundancy(caps []string, covered map[string]bool) float64 {
+	if len(caps) == 0 {
+		return 0
+	}
+	n := 0
+	for _, c := range caps {
+		if covered[c] {
+			n++
+		}
+	}
+	return float64(n) / float64(len(caps))
+}
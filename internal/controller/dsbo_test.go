@@ -0,0 +1,143 @@
+package controller
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	pb "github.com/groovy-byte/agent-mesh-core/proto"
+)
+
+func TestDSBOPrefersCapabilityOverlap(t *testing.T) {
+	r := NewMeshRegistry()
+	r.RegisterAgent(&pb.HandshakeRequest{AgentId: "artist", Capabilities: []string{"ART"}})
+	r.RegisterAgent(&pb.HandshakeRequest{AgentId: "painter", Capabilities: []string{"ART"}})
+	r.RegisterAgent(&pb.HandshakeRequest{AgentId: "coder-1", Capabilities: []string{"CODE"}})
+	r.RegisterAgent(&pb.HandshakeRequest{AgentId: "coder-2", Capabilities: []string{"CODE", "TEST"}})
+
+	agent, _ := r.GetAgent("coder-2")
+	if len(agent.Neighbors) != 2 {
+		t.Fatalf("Expected 2 initial neighbors, got %v", agent.Neighbors)
+	}
+	if agent.Neighbors[0] != "coder-1" {
+		t.Errorf("Expected coder-1 as best neighbor by capability overlap, got %v", agent.Neighbors)
+	}
+}
+
+func TestDSBOPrefersHighVoC(t *testing.T) {
+	r := NewMeshRegistry()
+	for _, id := range []string{"a", "b", "helper", "d"} {
+		r.RegisterAgent(&pb.HandshakeRequest{AgentId: id})
+	}
+	r.RecordContribution("helper", "newcomer", 0.9)
+
+	r.RegisterAgent(&pb.HandshakeRequest{AgentId: "newcomer"})
+	agent, _ := r.GetAgent("newcomer")
+	if len(agent.Neighbors) == 0 || agent.Neighbors[0] != "helper" {
+		t.Errorf("Expected helper (highest VoC) as first neighbor, got %v", agent.Neighbors)
+	}
+}
+
+func TestDSBOSubmodularDiversity(t *testing.T) {
+	s := NewNeighborSelector(DefaultDSBOConfig())
+	candidates := []NeighborCandidate{
+		{ID: "coder-a", Capabilities: []string{"CODE"}},
+		{ID: "coder-b", Capabilities: []string{"CODE"}},
+		{ID: "scout", Capabilities: []string{"SEARCH"}},
+	}
+
+	got := s.Select("lead", []string{"CODE", "SEARCH"}, candidates, 2)
+	hasScout, coders := false, 0
+	for _, id := range got {
+		switch id {
+		case "scout":
+			hasScout = true
+		case "coder-a", "coder-b":
+			coders++
+		}
+	}
+	if !hasScout || coders != 1 {
+		t.Errorf("Expected one coder plus scout (diminishing returns on CODE), got %v", got)
+	}
+}
+
+func TestDSBOBanditLearnsFromReward(t *testing.T) {
+	s := NewNeighborSelector(DefaultDSBOConfig())
+	candidates := []NeighborCandidate{{ID: "good"}, {ID: "bad"}}
+
+	for i := 0; i < 20; i++ {
+		s.Record("agent", "good", 1.0)
+		s.Record("agent", "bad", 0.0)
+	}
+
+	if got := s.Select("agent", nil, candidates, 1); len(got) != 1 || got[0] != "good" {
+		t.Errorf("Expected bandit to exploit the rewarding neighbor, got %v", got)
+	}
+	pulls, mean := s.Stats("agent", "good")
+	if pulls != 20 || mean != 1.0 {
+		t.Errorf("Unexpected arm stats: pulls=%d mean=%f", pulls, mean)
+	}
+}
+
+func TestDSBODeterministicUnderSeed(t *testing.T) {
+	build := func(seed int64) map[string][]string {
+		r := NewMeshRegistry()
+		cfg := DefaultDSBOConfig()
+		cfg.Seed = seed
+		r.ConfigureDSBO(cfg)
+		for i := 0; i < 8; i++ {
+			r.RegisterAgent(&pb.HandshakeRequest{AgentId: fmt.Sprintf("agent-%d", i)})
+		}
+		for i := 0; i < 8; i++ {
+			r.ReevaluateNeighbors(fmt.Sprintf("agent-%d", i), i%2 == 0, nil)
+		}
+		out := make(map[string][]string)
+		for i := 0; i < 8; i++ {
+			agent, _ := r.GetAgent(fmt.Sprintf("agent-%d", i))
+			out[agent.ID] = agent.Neighbors
+		}
+		return out
+	}
+
+	first, second := build(7), build(7)
+	if !reflect.DeepEqual(first, second) {
+		t.Errorf("Neighbor selection not deterministic under the same seed:\n%v\n%v", first, second)
+	}
+}
+
+func TestDSBOGrowAndPrune(t *testing.T) {
+	r := NewMeshRegistry()
+	for i := 0; i < 6; i++ {
+		r.RegisterAgent(&pb.HandshakeRequest{AgentId: fmt.Sprintf("peer-%d", i)})
+	}
+	r.RegisterAgent(&pb.HandshakeRequest{AgentId: "focus"})
+
+	// Novel context grows the neighborhood up to MaxNeighbors.
+	for i := 0; i < 5; i++ {
+		r.ReevaluateNeighbors("focus", true, nil)
+	}
+	agent, _ := r.GetAgent("focus")
+	if len(agent.Neighbors) != DefaultDSBOConfig().MaxNeighbors {
+		t.Fatalf("Expected neighborhood to grow to %d, got %v", DefaultDSBOConfig().MaxNeighbors, agent.Neighbors)
+	}
+
+	// Redundant context erodes utility until a neighbor is pruned.
+	before := len(agent.Neighbors)
+	for agent.UtilityScore >= 0.5 && len(agent.Neighbors) == before {
+		r.ReevaluateNeighbors("focus", false, nil)
+		agent, _ = r.GetAgent("focus")
+	}
+	if len(agent.Neighbors) != before-1 {
+		t.Errorf("Expected one neighbor pruned, got %v", agent.Neighbors)
+	}
+	if agent.UtilityScore != 0.8 {
+		t.Errorf("Expected utility reset to 0.8 after pruning, got %f", agent.UtilityScore)
+	}
+
+	graph := r.NeighborGraph("focus")
+	for _, e := range graph["focus"] {
+		if e.Pulls == 0 {
+			t.Errorf("Edge %s has no recorded observations", e.TargetID)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package controller
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	pb "github.com/groovy-byte/agent-mesh-core/proto"
+	"github.com/nats-io/nats.go"
+	"google.golang.org/protobuf/proto"
+)
+
+// HeartbeatSubjectPrefix is the NATS subject root; agents publish on mesh.heartbeat.<agent_id>.
+const HeartbeatSubjectPrefix = "mesh.heartbeat"
+
+// HeartbeatConfig controls how missed heartbeats translate into liveness states.
+type HeartbeatConfig struct {
+	Interval     time.Duration // Expected time between heartbeats.
+	SuspectAfter int           // Missed intervals before an agent is SUSPECT.
+	DeadAfter    int           // Missed intervals before an agent is DEAD and evicted.
+}
+
+func DefaultHeartbeatConfig() HeartbeatConfig {
+	return HeartbeatConfig{
+		Interval:     5 * time.Second,
+		SuspectAfter: 2,
+		DeadAfter:    5,
+	}
+}
+
+// HeartbeatMonitor ingests operational heartbeats over NATS and evicts silent agents.
+type HeartbeatMonitor struct {
+	nc       *nats.Conn
+	registry *MeshRegistry
+	cfg      HeartbeatConfig
+
+	mu   sync.Mutex
+	sub  *nats.Subscription
+	stop chan struct{}
+	done chan struct{}
+}
+
+func NewHeartbeatMonitor(nc *nats.Conn, registry *MeshRegistry, cfg HeartbeatConfig) *HeartbeatMonitor {
+	return &HeartbeatMonitor{
+		nc:       nc,
+		registry: registry,
+		cfg:      cfg,
+	}
+}
+
+// Start subscribes to mesh.heartbeat.* and begins the liveness sweep loop.
+func (m *HeartbeatMonitor) Start() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.sub != nil {
+		return fmt.Errorf("heartbeat monitor already started")
+	}
+
+	sub, err := m.nc.Subscribe(HeartbeatSubjectPrefix+".*", m.handle)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to heartbeats: %w", err)
+	}
+	m.sub = sub
+	m.stop = make(chan struct{})
+	m.done = make(chan struct{})
+
+	go m.sweepLoop(m.stop, m.done)
+	log.Printf("[Heartbeat] 💓 Monitoring %s.* (interval %v, suspect after %d, dead after %d)",
+		HeartbeatSubjectPrefix, m.cfg.Interval, m.cfg.SuspectAfter, m.cfg.DeadAfter)
+	return nil
+}
+
+// Stop unsubscribes and halts the sweep loop.
+func (m *HeartbeatMonitor) Stop() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.sub == nil {
+		return
+	}
+	m.sub.Unsubscribe()
+	close(m.stop)
+	<-m.done
+	m.sub = nil
+}
+
+func (m *HeartbeatMonitor) handle(msg *nats.Msg) {
+	hb := &pb.Heartbeat{}
+	if err := proto.Unmarshal(msg.Data, hb); err != nil {
+		log.Printf("[Heartbeat] Dropping malformed heartbeat on %s: %v", msg.Subject, err)
+		return
+	}
+
+	subjectID := strings.TrimPrefix(msg.Subject, HeartbeatSubjectPrefix+".")
+	if hb.AgentId == "" {
+		hb.AgentId = subjectID
+	} else if hb.AgentId != subjectID {
+		log.Printf("[Heartbeat] Dropping heartbeat for %s published on %s", hb.AgentId, msg.Subject)
+		return
+	}
+
+	if !m.registry.RecordHeartbeat(hb, time.Now()) {
+		log.Printf("[Heartbeat] Heartbeat from unregistered agent %s ignored", hb.AgentId)
+	}
+}
+
+func (m *HeartbeatMonitor) sweepLoop(stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(m.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			m.registry.SweepLiveness(now, m.cfg)
+		}
+	}
+}
+
+// PublishHeartbeat sends an agent heartbeat on its mesh.heartbeat.<agent_id> subject.
+func PublishHeartbeat(nc *nats.Conn, hb *pb.Heartbeat) error {
+	data, err := proto.Marshal(hb)
+	if err != nil {
+		return fmt.Errorf("failed to marshal heartbeat: %w", err)
+	}
+	if err := nc.Publish(fmt.Sprintf("%s.%s", HeartbeatSubjectPrefix, hb.AgentId), data); err != nil {
+		return fmt.Errorf("failed to publish heartbeat: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	pb "github.com/groovy-byte/agent-mesh-core/proto"
+	natsserver "github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+	"google.golang.org/protobuf/proto"
+)
+
+// runEmbeddedNATS starts an in-process NATS server with JetStream enabled
+// and returns a client connection to it.
+func runEmbeddedNATS(t *testing.T) *nats.Conn {
+	t.Helper()
+
+	ns, err := natsserver.NewServer(&natsserver.Options{
+		Host:      "127.0.0.1",
+		Port:      -1,
+		NoLog:     true,
+		NoSigs:    true,
+		JetStream: true,
+		StoreDir:  t.TempDir(),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create embedded NATS server: %v", err)
+	}
+	go ns.Start()
+	if !ns.ReadyForConnections(5 * time.Second) {
+		t.Fatal("Embedded NATS server not ready")
+	}
+	t.Cleanup(ns.Shutdown)
+
+	nc, err := nats.Connect(ns.ClientURL())
+	if err != nil {
+		t.Fatalf("Failed to connect to embedded NATS: %v", err)
+	}
+	t.Cleanup(nc.Close)
+	return nc
+}
+
+func TestHeartbeatIngestion(t *testing.T) {
+	nc := runEmbeddedNATS(t)
+	r := NewMeshRegistry()
+	r.RegisterAgent(&pb.HandshakeRequest{AgentId: "worker"})
+
+	m := NewHeartbeatMonitor(nc, r, HeartbeatConfig{Interval: time.Hour, SuspectAfter: 2, DeadAfter: 4})
+	if err := m.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer m.Stop()
+
+	before, _ := r.GetAgent("worker")
+	err := PublishHeartbeat(nc, &pb.Heartbeat{
+		AgentId:     "worker",
+		CurrentLoad: &pb.OSResources{CpuUsagePercent: 42.5},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	nc.Flush()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		agent, _ := r.GetAgent("worker")
+		if agent.CurrentLoad != nil {
+			if agent.CurrentLoad.CpuUsagePercent != 42.5 {
+				t.Errorf("Expected CPU load 42.5, got %f", agent.CurrentLoad.CpuUsagePercent)
+			}
+			if !agent.LastSeen.After(before.LastSeen) {
+				t.Errorf("LastSeen was not advanced by heartbeat")
+			}
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("Heartbeat was not ingested")
+}
+
+func TestHeartbeatSubjectMismatchDropped(t *testing.T) {
+	nc := runEmbeddedNATS(t)
+	r := NewMeshRegistry()
+	r.RegisterAgent(&pb.HandshakeRequest{AgentId: "victim"})
+
+	m := NewHeartbeatMonitor(nc, r, HeartbeatConfig{Interval: time.Hour, SuspectAfter: 2, DeadAfter: 4})
+	if err := m.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer m.Stop()
+
+	// Spoofed heartbeat: payload claims "victim" but arrives on another agent's subject.
+	data, err := proto.Marshal(&pb.Heartbeat{AgentId: "victim", CurrentLoad: &pb.OSResources{CpuUsagePercent: 99}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := nc.Publish(HeartbeatSubjectPrefix+".intruder", data); err != nil {
+		t.Fatal(err)
+	}
+	nc.Flush()
+	time.Sleep(100 * time.Millisecond)
+
+	agent, _ := r.GetAgent("victim")
+	if agent.CurrentLoad != nil {
+		t.Errorf("Spoofed heartbeat should have been dropped")
+	}
+}
+
+func TestLivenessSweepAndEviction(t *testing.T) {
+	r := NewMeshRegistry()
+	cfg := HeartbeatConfig{Interval: time.Second, SuspectAfter: 2, DeadAfter: 4}
+
+	r.RegisterAgent(&pb.HandshakeRequest{AgentId: "a"})
+	r.RegisterAgent(&pb.HandshakeRequest{AgentId: "b"})
+	r.RegisterAgent(&pb.HandshakeRequest{AgentId: "c"}) // Neighbors: a, b
+
+	base := time.Now()
+	r.RecordHeartbeat(&pb.Heartbeat{AgentId: "a"}, base)
+	r.RecordHeartbeat(&pb.Heartbeat{AgentId: "b"}, base)
+	r.RecordHeartbeat(&pb.Heartbeat{AgentId: "c"}, base)
+
+	// 1. Within the interval: everyone alive.
+	if evicted := r.SweepLiveness(base.Add(500*time.Millisecond), cfg); len(evicted) != 0 {
+		t.Fatalf("Expected no evictions, got %v", evicted)
+	}
+
+	// 2. "a" keeps beating, the others go quiet past the suspect threshold.
+	r.RecordHeartbeat(&pb.Heartbeat{AgentId: "a"}, base.Add(2*time.Second))
+	r.SweepLiveness(base.Add(2500*time.Millisecond), cfg)
+
+	if a, _ := r.GetAgent("a"); a.Liveness != LivenessAlive {
+		t.Errorf("Expected a to be ALIVE, got %s", a.Liveness)
+	}
+	if b, _ := r.GetAgent("b"); b.Liveness != LivenessSuspect {
+		t.Errorf("Expected b to be SUSPECT, got %s", b.Liveness)
+	}
+
+	// 3. A suspect that beats again recovers.
+	r.RecordHeartbeat(&pb.Heartbeat{AgentId: "c"}, base.Add(3*time.Second))
+	if c, _ := r.GetAgent("c"); c.Liveness != LivenessAlive {
+		t.Errorf("Expected c to recover to ALIVE, got %s", c.Liveness)
+	}
+
+	// 4. Past the dead threshold "b" is evicted and removed from neighbor lists.
+	evicted := r.SweepLiveness(base.Add(4500*time.Millisecond), cfg)
+	if len(evicted) != 1 || evicted[0] != "b" {
+		t.Fatalf("Expected only b to be evicted, got %v", evicted)
+	}
+	if _, ok := r.GetAgent("b"); ok {
+		t.Errorf("Dead agent b still registered")
+	}
+	c, _ := r.GetAgent("c")
+	for _, n := range c.Neighbors {
+		if n == "b" {
+			t.Errorf("Evicted agent b still listed as neighbor of c")
+		}
+	}
+}
@@ -0,0 +1,264 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/groovy-byte/agent-mesh-core/internal/backend"
+	pb "github.com/groovy-byte/agent-mesh-core/proto"
+)
+
+func TestInferenceSelectsBackendByHardwarePath(t *testing.T) {
+	s := NewScheInfer(16*1024*1024, "NVIDIA GeForce RTX 3070 Laptop GPU", 86, false)
+	c := NewInferenceController(s)
+	c.RegisterBackend(ProviderCPUAVX2, backend.NewFake("cpu"))
+	c.RegisterBackend(ProviderGPUCUDA, backend.NewFake("gpu"))
+	ctx := context.Background()
+
+	res, err := c.Generate(ctx, &pb.InferenceRequest{AgentId: "a", Prompt: "short prompt"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.HardwarePath != ProviderCPUAVX2 || res.Text != "[cpu] short prompt" || res.TokensUsed != 4 || res.PromptTokens != 2 || res.CompletionTokens != 2 {
+		t.Errorf("Unexpected CPU response: %+v", res)
+	}
+
+	// A large KV cache hint routes to the GPU and its backend.
+	res, err = c.Generate(ctx, &pb.InferenceRequest{AgentId: "a", Prompt: "big context", ExpectedKvCacheBytes: 256 << 20})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.HardwarePath != ProviderGPUCUDA || !strings.HasPrefix(res.Text, "[gpu]") {
+		t.Errorf("Unexpected GPU response: %+v", res)
+	}
+
+	// Device placements fall back to their provider's backend, then the default.
+	if got := c.Backend("GPU_CUDA:1"); got != c.Backend(ProviderGPUCUDA) {
+		t.Error("Expected GPU_CUDA:1 to use the GPU_CUDA backend")
+	}
+	c.UseDefaultBackend(backend.NewFake("default"))
+	if info, _ := c.Backend(ProviderGPUVulkan).ModelInfo(ctx); info.Name != "default" {
+		t.Errorf("Expected the default backend for an unregistered path, got %s", info.Name)
+	}
+
+	if _, err := c.Generate(ctx, &pb.InferenceRequest{AgentId: "a"}); err == nil {
+		t.Error("Expected an empty prompt to fail")
+	}
+}
+
+func TestInferenceFeedsCostModel(t *testing.T) {
+	s := NewScheInfer(16*1024*1024, "NVIDIA GeForce RTX 3070 Laptop GPU", 86, false)
+	c := NewInferenceController(s)
+	c.UseDefaultBackend(&backend.Fake{Name: "slow", Latency: 50 * time.Millisecond})
+	ctx := context.Background()
+
+	const kv = 256 << 20
+	prior, _ := s.EstimateLatency(ProviderGPUCUDA, kv)
+	res, err := c.Generate(ctx, &pb.InferenceRequest{AgentId: "a", Prompt: "big context", ExpectedKvCacheBytes: kv})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.HardwarePath != ProviderGPUCUDA || res.ThroughputGbs <= 0 {
+		t.Fatalf("Unexpected response: %+v", res)
+	}
+	// 256MB took at least the backend's 50ms.
+	if limit := gigabytes(kv) / 0.05; float64(res.ThroughputGbs) > limit {
+		t.Errorf("Expected at most %.2f GB/s, got %.2f", limit, res.ThroughputGbs)
+	}
+
+	// 256MB in ~50ms is far slower than the GPU prior.
+	learned, _ := s.EstimateLatency(ProviderGPUCUDA, kv)
+	if learned <= prior {
+		t.Errorf("Expected the measured run to raise the estimate above %v, got %v", prior, learned)
+	}
+}
+
+func TestInferenceGenerateStream(t *testing.T) {
+	s := NewScheInfer(16*1024*1024, "NVIDIA GeForce RTX 3070 Laptop GPU", 86, false)
+	c := NewInferenceController(s)
+	ctx := context.Background()
+
+	var pieces []string
+	res, err := c.GenerateStream(ctx, &pb.InferenceRequest{AgentId: "a", Prompt: "one two three"}, func(text string) error {
+		pieces = append(pieces, text)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pieces) != 4 || strings.Join(pieces, "") != res.Text || res.HardwarePath != ProviderCPUAVX2 {
+		t.Errorf("Unexpected stream %q for %+v", pieces, res)
+	}
+
+	// A failing emit aborts generation and frees the hardware path.
+	_, err = c.GenerateStream(ctx, &pb.InferenceRequest{AgentId: "a", Prompt: "one two three"}, func(string) error {
+		return context.Canceled
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the emit error, got %v", err)
+	}
+	if ld := s.LoadStats()[ProviderCPUAVX2]; ld.Acquired != 2 || ld.InFlight != 0 {
+		t.Errorf("Expected both leases released, got %+v", ld)
+	}
+}
+
+func TestInferenceBatchesConcurrentRequests(t *testing.T) {
+	s := NewScheInfer(16*1024*1024, "NVIDIA GeForce RTX 3070 Laptop GPU", 86, false)
+	c := NewInferenceController(s)
+	c.UseDefaultBackend(&backend.Fake{Name: "batch", Latency: 20 * time.Millisecond})
+	c.ConfigureBatching(BatchPolicy{Window: time.Second, MaxSize: 4})
+	ctx := context.Background()
+
+	// A full batch dispatches without waiting out the window.
+	start := time.Now()
+	var wg sync.WaitGroup
+	errs := make(chan error, 4)
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res, err := c.Generate(ctx, &pb.InferenceRequest{AgentId: "a", Prompt: fmt.Sprintf("prompt %d", i)})
+			if err == nil && res.Text != fmt.Sprintf("[batch] prompt %d", i) {
+				err = fmt.Errorf("request %d got %q", i, res.Text)
+			}
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Expected a full batch to dispatch immediately, took %v", elapsed)
+	}
+
+	// A deadline inside the window dispatches the batch early.
+	dctx, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+	defer cancel()
+	if _, err := c.Generate(dctx, &pb.InferenceRequest{AgentId: "a", Prompt: "urgent"}); err != nil {
+		t.Errorf("Expected the deadline-bound request to run before its deadline, got %v", err)
+	}
+
+	h := c.BatchStats()[ProviderCPUAVX2]
+	if h.Batches != 2 || h.Requests != 5 || h.Sizes[4] != 1 || h.Sizes[1] != 1 {
+		t.Errorf("Unexpected histogram: %+v", h)
+	}
+}
+
+func TestInferenceBatchWindowAndExpiry(t *testing.T) {
+	s := NewScheInfer(16*1024*1024, "NVIDIA GeForce RTX 3070 Laptop GPU", 86, false)
+	c := NewInferenceController(s)
+	c.ConfigureBatching(BatchPolicy{Window: 100 * time.Millisecond, MaxSize: 8})
+
+	// One caller gives up while queued; the other is served when the window closes.
+	ctx, cancel := context.WithCancel(context.Background())
+	abandoned := make(chan error, 1)
+	go func() {
+		_, err := c.Generate(ctx, &pb.InferenceRequest{AgentId: "a", Prompt: "never mind"})
+		abandoned <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	if err := <-abandoned; !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the abandoned request to return Canceled, got %v", err)
+	}
+
+	res, err := c.Generate(context.Background(), &pb.InferenceRequest{AgentId: "a", Prompt: "still here"})
+	if err != nil || res.Text != "[vextra-fake] still here" {
+		t.Fatalf("Unexpected result %+v (%v)", res, err)
+	}
+
+	h := c.BatchStats()[ProviderCPUAVX2]
+	if h.Batches != 1 || h.Sizes[1] != 1 || h.Expired != 1 {
+		t.Errorf("Expected one batch of 1 with one expired request, got %+v", h)
+	}
+	if ld := s.LoadStats()[ProviderCPUAVX2]; ld.InFlight != 0 {
+		t.Errorf("Expected all leases released, got %+v", ld)
+	}
+}
+
+func TestBatcherKeepsBackendsApart(t *testing.T) {
+	b := newBatcher()
+	b.configure(BatchPolicy{Window: 50 * time.Millisecond})
+	old, swapped := backend.NewFake("old"), backend.NewFake("new")
+
+	// A RegisterBackend between two requests on one path leaves each on
+	// the backend it was routed to.
+	var wg sync.WaitGroup
+	texts := make([]string, 2)
+	for i, be := range []backend.Backend{old, swapped} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res, err := b.submit(context.Background(), ProviderCPUAVX2, be, backend.Request{Prompt: "hi"})
+			if err != nil {
+				t.Error(err)
+			}
+			texts[i] = res.Text
+		}()
+	}
+	wg.Wait()
+	if texts[0] != "[old] hi" || texts[1] != "[new] hi" {
+		t.Errorf("Expected each request on its own backend, got %q", texts)
+	}
+	if h := b.snapshot()[ProviderCPUAVX2]; h.Batches != 2 || h.Requests != 2 {
+		t.Errorf("Expected two batches of one on the path, got %+v", h)
+	}
+}
+
+func TestInferenceSkipsBatchingWithoutBatchGenerator(t *testing.T) {
+	c := NewInferenceController(NewScheInfer(16*1024*1024, "NVIDIA GeForce RTX 3070 Laptop GPU", 86, false))
+	// Embedding only the Backend interface hides Fake's GenerateBatch.
+	c.UseDefaultBackend(struct{ backend.Backend }{&backend.Fake{Name: "solo"}})
+	c.ConfigureBatching(BatchPolicy{Window: time.Second, MaxSize: 4})
+
+	start := time.Now()
+	res, err := c.Generate(context.Background(), &pb.InferenceRequest{AgentId: "a", Prompt: "now"})
+	if err != nil || res.Text != "[solo] now" {
+		t.Fatalf("Unexpected result %+v (%v)", res, err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Expected no batching window, took %v", elapsed)
+	}
+	if h := c.BatchStats()[ProviderCPUAVX2]; h.Batches != 0 {
+		t.Errorf("Expected no batches, got %+v", h)
+	}
+}
+
+func TestInferenceServesCachedResponses(t *testing.T) {
+	s := NewScheInfer(16*1024*1024, "NVIDIA GeForce RTX 3070 Laptop GPU", 86, false)
+	c := NewInferenceController(s)
+	c.ConfigureCache(DefaultCachePolicy(), nil)
+	ctx := context.Background()
+
+	first, err := c.Generate(ctx, &pb.InferenceRequest{AgentId: "a", Prompt: "repeat after me"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := c.Generate(ctx, &pb.InferenceRequest{AgentId: "b", Prompt: "repeat after me"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !second.Cached || second.HardwarePath != HardwarePathCache || second.Text != first.Text || first.Cached {
+		t.Errorf("Expected the second request to be a cache hit, got %+v", second)
+	}
+	if _, err := c.Generate(ctx, &pb.InferenceRequest{AgentId: "a", Prompt: "repeat after me", Temperature: 0.8}); err != nil {
+		t.Fatal(err)
+	}
+
+	stats, ok := c.CacheStats()
+	if !ok || stats.Hits != 1 || stats.Misses != 1 || stats.Bypassed != 1 {
+		t.Errorf("Unexpected cache stats: %+v", stats)
+	}
+	if ld := s.LoadStats()[ProviderCPUAVX2]; ld.Acquired != 2 {
+		t.Errorf("Expected only the two uncached requests to be routed, got %+v", ld)
+	}
+}
@@ -1,48 +1,536 @@
 package controller
 
 import (
+	"errors"
 	"fmt"
+	"hash/crc32"
 	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	pb "github.com/groovy-byte/agent-mesh-core/proto"
 	"github.com/nats-io/nats.go"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// KV sync runs over the MESH_STATE stream: deltas on
+// mesh.kv_cache.<agent_id>.delta and full snapshots on
+// mesh.kv_cache.<agent_id>.snapshot, each wrapped in a KVCacheEnvelope.
+const (
+	KVStreamName    = "MESH_STATE"
+	KVSubjectPrefix = "mesh.kv_cache"
+
+	// DefaultKVSnapshotEvery is how many deltas go out between snapshots
+	// once a snapshot source is configured.
+	DefaultKVSnapshotEvery = 64
+
+	// kvFirstChunkHeader carries the stream sequence of a message's first
+	// chunk on each later chunk, so Resume can start a snapshot at chunk 0.
+	kvFirstChunkHeader = "Kv-First-Chunk"
+)
+
+var (
+	ErrKVGap      = errors.New("kv: sequence gap")
+	ErrKVChecksum = errors.New("kv: checksum mismatch")
 )
 
+// KVGapError reports a message that does not follow the last one applied.
+// Deltas are dropped after it until a snapshot resynchronizes the agent.
+type KVGapError struct {
+	AgentID  string
+	Expected uint64
+	Got      uint64
+}
+
+func (e *KVGapError) Error() string {
+	return fmt.Sprintf("agent %s: expected KV sequence %d, got %d", e.AgentID, e.Expected, e.Got)
+}
+
+func (e *KVGapError) Unwrap() error {
+	return ErrKVGap
+}
+
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// kvChecksum is the CRC-32C of an envelope payload.
+func kvChecksum(payload []byte) uint32 {
+	return crc32.Checksum(payload, castagnoli)
+}
+
+// KVSnapshotFunc returns an agent's full KV state for a periodic snapshot.
+type KVSnapshotFunc func(agentID string) ([]byte, error)
+
+// KVHandler receives an agent's KV messages in sequence order. Exactly one
+// of env and err is set; err is a *KVGapError or wraps ErrKVChecksum or
+// ErrKVIncomplete. A message with a new epoch starts over at sequence 1
+// because the agent's history was purged.
+type KVHandler func(env *pb.KVCacheEnvelope, err error)
+
 // KVCacheController handles high-speed conversation state sync across the mesh
 type KVCacheController struct {
 	js nats.JetStreamContext
+
+	mu            sync.Mutex
+	agents        map[string]*kvAgent
+	snapshotEvery uint64
+	snapshotState KVSnapshotFunc
+	transport     KVTransportPolicy
+	stream        KVStreamPolicy
+	stats         KVTransportStats
 }
 
+// kvAgent is the publishing side of one agent's sequence.
+type kvAgent struct {
+	mu            sync.Mutex
+	loaded        bool
+	seq           uint64
+	base          uint64 // Sequence of the latest snapshot.
+	sinceSnapshot uint64
+	stored        int64  // Bytes published since the last compaction.
+	epoch         string // Stamped on envelopes; a Purge starts a new one.
+}
+
+// NewKVCacheController syncs over a MESH_STATE stream with the default
+// policy, logging rather than failing when the stream cannot be set up.
 func NewKVCacheController(js nats.JetStreamContext) *KVCacheController {
-	// Ensure the stream exists for KV sync
-	_, err := js.AddStream(&nats.StreamConfig{
-		Name:     "MESH_STATE",
-		Subjects: []string{"mesh.kv_cache.>"},
-		Storage:  nats.MemoryStorage, // High-speed, transient state
-	})
+	k, err := NewKVCacheControllerWithPolicy(js, DefaultKVStreamPolicy())
 	if err != nil {
 		log.Printf("[KV] Warning: Could not create/verify MESH_STATE stream: %v", err)
 	}
+	return k
+}
+
+// NewKVCacheControllerWithPolicy creates the MESH_STATE stream, or brings an
+// existing one in line with policy. The controller is usable even when the
+// error is non-nil, against the stream as it is.
+func NewKVCacheControllerWithPolicy(js nats.JetStreamContext, policy KVStreamPolicy) (*KVCacheController, error) {
+	k := &KVCacheController{
+		js:            js,
+		agents:        make(map[string]*kvAgent),
+		snapshotEvery: DefaultKVSnapshotEvery,
+		transport:     DefaultKVTransportPolicy(),
+		stream:        policy,
+	}
+	return k, k.ensureStream(policy)
+}
+
+// ConfigureTransport sets quantization, compression and chunking for
+// published payloads and the reassembly timeout of subscriptions started
+// afterwards.
+func (k *KVCacheController) ConfigureTransport(p KVTransportPolicy) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.transport = p
+}
+
+// TransportStats returns what has been published so far.
+func (k *KVCacheController) TransportStats() KVTransportStats {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.stats
+}
 
-	return &KVCacheController{js: js}
+// ConfigureSnapshots publishes a snapshot from state after every `every`
+// deltas of an agent. A nil state or zero every turns periodic snapshots off.
+func (k *KVCacheController) ConfigureSnapshots(every uint64, state KVSnapshotFunc) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.snapshotEvery = every
+	k.snapshotState = state
 }
 
-// BroadcastDelta sends a state fragment to all nodes in the mesh
+func kvDeltaSubject(agentID string) string {
+	return fmt.Sprintf("%s.%s.delta", KVSubjectPrefix, agentID)
+}
+
+func kvSnapshotSubject(agentID string) string {
+	return fmt.Sprintf("%s.%s.snapshot", KVSubjectPrefix, agentID)
+}
+
+// kvAgentSubjects matches both of an agent's subjects.
+func kvAgentSubjects(agentID string) string {
+	return fmt.Sprintf("%s.%s.*", KVSubjectPrefix, agentID)
+}
+
+// BroadcastDelta sends a state fragment to all nodes in the mesh, followed by
+// a snapshot when one is due or the agent's history is over its byte budget.
+// Sequences assume one publisher per agent.
 func (k *KVCacheController) BroadcastDelta(agentID string, delta []byte) error {
-	subject := fmt.Sprintf("mesh.kv_cache.%s", agentID)
-	_, err := k.js.Publish(subject, delta)
-	if err != nil {
+	a := k.agent(agentID)
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if _, err := k.publishLocked(agentID, a, delta, false); err != nil {
 		return fmt.Errorf("failed to broadcast KV delta: %w", err)
 	}
-	
-	log.Printf("[KV] 📡 Broadcasted %d bytes for agent %s", len(delta), agentID)
+	log.Printf("[KV] 📡 Broadcasted %d bytes for agent %s (seq %d)", len(delta), agentID, a.seq)
+
+	k.mu.Lock()
+	every, state := k.snapshotEvery, k.snapshotState
+	k.mu.Unlock()
+	if state == nil || !(every > 0 && a.sinceSnapshot >= every || k.overBudgetLocked(a)) {
+		return nil
+	}
+	full, err := state(agentID)
+	if err != nil {
+		log.Printf("[KV] ⚠️ Snapshot of agent %s skipped: %v", agentID, err)
+		return nil
+	}
+	if err := k.snapshotLocked(agentID, a, full); err != nil {
+		log.Printf("[KV] ⚠️ Snapshot of agent %s failed: %v", agentID, err)
+	}
 	return nil
 }
 
+// Snapshot publishes an agent's full state. Resume starts from the latest one.
+func (k *KVCacheController) Snapshot(agentID string, state []byte) error {
+	a := k.agent(agentID)
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if err := k.snapshotLocked(agentID, a, state); err != nil {
+		return fmt.Errorf("failed to publish KV snapshot: %w", err)
+	}
+	return nil
+}
+
+// snapshotLocked publishes state and compacts the history before it when
+// the agent is over its byte budget. Callers must hold a.mu.
+func (k *KVCacheController) snapshotLocked(agentID string, a *kvAgent, state []byte) error {
+	before := a.stored
+	streamSeq, err := k.publishLocked(agentID, a, state, true)
+	if err != nil {
+		return err
+	}
+	log.Printf("[KV] 📸 Snapshot of %d bytes for agent %s (seq %d)", len(state), agentID, a.seq)
+	if k.overBudgetLocked(a) {
+		k.compactLocked(agentID, a, streamSeq, a.stored-before)
+	}
+	return nil
+}
+
+func (k *KVCacheController) agent(agentID string) *kvAgent {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	a, ok := k.agents[agentID]
+	if !ok {
+		a = &kvAgent{}
+		k.agents[agentID] = a
+	}
+	return a
+}
+
+// publishLocked wraps payload in the agent's next envelope, quantized (deltas
+// only), compressed and chunked per the transport policy, and returns the
+// stream sequence of its first chunk. The first publish picks the sequence
+// up from the stream, so a restarted publisher continues where it left off. Callers must hold a.mu.
+func (k *KVCacheController) publishLocked(agentID string, a *kvAgent, payload []byte, snapshot bool) (uint64, error) {
+	if !a.loaded {
+		if err := k.loadSequence(agentID, a); err != nil {
+			return 0, err
+		}
+	}
+	k.mu.Lock()
+	policy := k.transport
+	k.mu.Unlock()
+
+	seq := a.seq + 1
+	base := a.base
+	subject := kvDeltaSubject(agentID)
+	if snapshot {
+		base = seq
+		subject = kvSnapshotSubject(agentID)
+	}
+	quantization := pb.KVQuantization_QUANT_NONE
+	encoded, qerr := payload, kvQuantError{}
+	if !snapshot {
+		var err error
+		if quantization, encoded, qerr, err = quantizeKV(policy.Quantization, payload); err != nil {
+			return 0, err
+		}
+	}
+	compression, wire := compressKV(policy.Compression, encoded)
+	chunks := splitKV(wire, policy.MaxChunkBytes)
+	checksum := kvChecksum(encoded)
+	now := timestamppb.Now()
+
+	var first uint64
+	var stored int64
+	for i, chunk := range chunks {
+		env := &pb.KVCacheEnvelope{
+			AgentId:        agentID,
+			Sequence:       seq,
+			BaseSnapshotId: base,
+			Checksum:       checksum,
+			Snapshot:       snapshot,
+			Payload:        chunk,
+			PublishedAt:    now,
+			Compression:    compression,
+			PayloadSize:    uint64(len(encoded)),
+			Quantization:   quantization,
+			Epoch:          a.epoch,
+		}
+		if quantization != pb.KVQuantization_QUANT_NONE {
+			env.ValueCount = uint64(len(payload) / 4)
+		}
+		msgID := fmt.Sprintf("%s:%s:%d", agentID, a.epoch, seq)
+		if len(chunks) > 1 {
+			env.ChunkIndex = uint32(i)
+			env.ChunkCount = uint32(len(chunks))
+			msgID = fmt.Sprintf("%s:%d", msgID, i)
+		}
+		data, err := proto.Marshal(env)
+		if err != nil {
+			return 0, err
+		}
+		msg := nats.NewMsg(subject)
+		msg.Data = data
+		if i > 0 {
+			msg.Header.Set(kvFirstChunkHeader, strconv.FormatUint(first, 10))
+		}
+		// The message ID lets JetStream drop a retried publish of the same chunk.
+		ack, err := k.js.PublishMsg(msg, nats.MsgId(msgID))
+		if err != nil {
+			if i > 0 {
+				// Subscribers will report the sequence incomplete; never reuse it.
+				a.seq = seq
+				a.stored += stored
+			}
+			return 0, err
+		}
+		if i == 0 {
+			first = ack.Sequence
+		}
+		stored += int64(len(data))
+	}
+
+	a.seq = seq
+	if snapshot {
+		a.base = seq
+		a.sinceSnapshot = 0
+	} else {
+		a.sinceSnapshot++
+	}
+	a.stored += stored
+	k.mu.Lock()
+	k.stats.Messages++
+	k.stats.Chunks += uint64(len(chunks))
+	k.stats.RawBytes += uint64(len(payload))
+	k.stats.WireBytes += uint64(len(wire))
+	if quantization != pb.KVQuantization_QUANT_NONE {
+		k.stats.QuantizedValues += uint64(len(payload) / 4)
+		k.stats.QuantizedRawBytes += uint64(len(payload))
+		k.stats.QuantizedBytes += uint64(len(encoded))
+		k.stats.QuantSquaredError += qerr.squared
+		k.stats.QuantMaxError = max(k.stats.QuantMaxError, qerr.max)
+	}
+	k.mu.Unlock()
+	return first, nil
+}
+
+func newKVEpoch() string {
+	return strconv.FormatInt(time.Now().UnixNano(), 36)
+}
+
+// loadSequence picks an agent's sequence, epoch and stored bytes up from
+// the stream. Callers must hold a.mu.
+func (k *KVCacheController) loadSequence(agentID string, a *kvAgent) error {
+	var newest *pb.KVCacheEnvelope
+	for _, subject := range []string{kvDeltaSubject(agentID), kvSnapshotSubject(agentID)} {
+		env, _, err := k.lastEnvelope(subject)
+		if err != nil {
+			return err
+		}
+		if env == nil {
+			continue
+		}
+		if newest == nil || env.Sequence > newest.Sequence {
+			newest = env
+		}
+		a.base = max(a.base, env.BaseSnapshotId)
+	}
+	a.epoch = newKVEpoch()
+	if newest != nil {
+		a.seq = newest.Sequence
+		// Keep the epoch, or subscribers would take the restart for a purge.
+		if newest.Epoch != "" {
+			a.epoch = newest.Epoch
+		}
+		if k.budgetLocked() > 0 {
+			stored, err := k.storedBytes(agentID)
+			if err != nil {
+				return err
+			}
+			a.stored = stored
+		}
+	}
+	a.sinceSnapshot = a.seq - a.base
+	a.loaded = true
+	return nil
+}
+
+// lastEnvelope returns the newest envelope on subject and the stream
+// message holding it, or nil when the subject is empty.
+func (k *KVCacheController) lastEnvelope(subject string) (*pb.KVCacheEnvelope, *nats.RawStreamMsg, error) {
+	msg, err := k.js.GetLastMsg(KVStreamName, subject)
+	if errors.Is(err, nats.ErrMsgNotFound) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read %s: %w", subject, err)
+	}
+	env := &pb.KVCacheEnvelope{}
+	if err := proto.Unmarshal(msg.Data, env); err != nil {
+		return nil, nil, fmt.Errorf("malformed KV envelope on %s: %w", subject, err)
+	}
+	return env, msg, nil
+}
+
+// firstChunk returns the stream sequence of the first chunk of env, the
+// chunk stored in msg. Chunks after the first record it in a header;
+// without one it is where chunk 0 would be if no other agent's messages
+// came between the chunks. If chunk 0 is gone the replay reports the
+// message incomplete.
+func firstChunk(env *pb.KVCacheEnvelope, msg *nats.RawStreamMsg) uint64 {
+	if env.ChunkIndex == 0 {
+		return msg.Sequence
+	}
+	if first, err := strconv.ParseUint(msg.Header.Get(kvFirstChunkHeader), 10, 64); err == nil && first > 0 && first < msg.Sequence {
+		return first
+	}
+	return msg.Sequence - uint64(env.ChunkIndex)
+}
+
+// Resume replays an agent's KV state to a node that has applied everything
+// up to fromSeq (0 for nothing), then keeps delivering live messages. It
+// starts at the latest snapshot through JetStream's deliver-by-sequence and
+// skips messages at or below fromSeq, so the snapshot is only handed over
+// when the node is behind it. Chunked messages are reassembled and
+// decompressed before handler sees them.
+func (k *KVCacheController) Resume(agentID string, fromSeq uint64, handler KVHandler) (*nats.Subscription, error) {
+	opts := []nats.SubOpt{nats.OrderedConsumer()}
+	snap, msg, err := k.lastEnvelope(kvSnapshotSubject(agentID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to resume KV state: %w", err)
+	}
+	if snap != nil {
+		opts = append(opts, nats.StartSequence(firstChunk(snap, msg)))
+	} else {
+		opts = append(opts, nats.DeliverAll())
+	}
+
+	r := k.newReplay(agentID, fromSeq, handler)
+	sub, err := k.js.Subscribe(kvAgentSubjects(agentID), r.receive, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resume KV state: %w", err)
+	}
+	log.Printf("[KV] ⏪ Resuming agent %s after seq %d", agentID, fromSeq)
+	return sub, nil
+}
+
+// kvReplay orders one subscriber's view of an agent's sequence.
+type kvReplay struct {
+	agentID string
+	timeout time.Duration
+	handler KVHandler
+	// lenient subscribers take any newer message without gap checks.
+	lenient bool
+
+	mu      sync.Mutex
+	epoch   string // Epoch of the last message seen.
+	last    uint64 // Highest sequence applied.
+	broken  bool   // A gap or corrupt message was seen; wait for a snapshot.
+	pending map[uint64]*kvAssembly
+}
+
+func (k *KVCacheController) newReplay(agentID string, fromSeq uint64, handler KVHandler) *kvReplay {
+	k.mu.Lock()
+	timeout := k.transport.ReassemblyTimeout
+	k.mu.Unlock()
+	if timeout <= 0 {
+		timeout = DefaultKVReassemblyTimeout
+	}
+	return &kvReplay{
+		agentID: agentID,
+		timeout: timeout,
+		handler: handler,
+		last:    fromSeq,
+		pending: make(map[uint64]*kvAssembly),
+	}
+}
+
+func (r *kvReplay) receive(m *nats.Msg) {
+	env := &pb.KVCacheEnvelope{}
+	if err := proto.Unmarshal(m.Data, env); err != nil {
+		log.Printf("[KV] Dropping malformed envelope on %s: %v", m.Subject, err)
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if env.Epoch != "" && env.Epoch != r.epoch {
+		if r.epoch != "" {
+			r.restartLocked()
+		}
+		r.epoch = env.Epoch
+	}
+	if env.Sequence <= r.last {
+		return // Already applied.
+	}
+	r.abandonLocked(env.Sequence)
+	env, err := r.assembleLocked(env)
+	if err != nil {
+		r.failLocked(err)
+		return
+	}
+	if env == nil {
+		return // Waiting for more chunks.
+	}
+
+	switch {
+	case env.Snapshot || r.lenient:
+		// A snapshot replaces everything before it, gaps included.
+	case r.broken:
+		return
+	case env.Sequence != r.last+1:
+		r.failLocked(&KVGapError{AgentID: r.agentID, Expected: r.last + 1, Got: env.Sequence})
+		return
+	}
+	r.last = env.Sequence
+	r.broken = false
+	r.handler(env, nil)
+}
+
+// restartLocked forgets the old sequence after the agent's history was
+// purged, so the new one is applied from 1.
+func (r *kvReplay) restartLocked() {
+	log.Printf("[KV] 🔄 Agent %s restarted its KV sequence after seq %d", r.agentID, r.last)
+	for s, a := range r.pending {
+		r.dropLocked(s, a)
+	}
+	r.last = 0
+	r.broken = false
+}
+
+func (r *kvReplay) failLocked(err error) {
+	r.broken = true
+	r.handler(nil, err)
+}
+
 // SubscribeToDeltas allows a node to listen for conversation state updates
+// published from now on. Use Resume to rebuild state from history.
 func (k *KVCacheController) SubscribeToDeltas(agentID string, handler func([]byte)) (*nats.Subscription, error) {
-	subject := fmt.Sprintf("mesh.kv_cache.%s", agentID)
-	sub, err := k.js.Subscribe(subject, func(m *nats.Msg) {
-		handler(m.Data)
+	r := k.newReplay(agentID, 0, func(env *pb.KVCacheEnvelope, err error) {
+		if err != nil {
+			log.Printf("[KV] ⚠️ Dropping delta: %v", err)
+			return
+		}
+		handler(env.Payload)
 	})
+	r.lenient = true
+	sub, err := k.js.Subscribe(kvDeltaSubject(agentID), r.receive, nats.DeliverNew())
 	if err != nil {
 		return nil, fmt.Errorf("failed to subscribe to KV deltas: %w", err)
 	}
@@ -1,49 +1,198 @@
 package controller
 
 import (
+	"errors"
+	"fmt"
 	"testing"
 	"time"
+
+	pb "github.com/groovy-byte/agent-mesh-core/proto"
 	"github.com/nats-io/nats.go"
+	"google.golang.org/protobuf/proto"
 )
 
-func TestKVCacheSync(t *testing.T) {
-	// 1. Connect to NATS
-	nc, err := nats.Connect(nats.DefaultURL)
+func newKVCache(t *testing.T) (*KVCacheController, nats.JetStreamContext) {
+	t.Helper()
+	js, err := runEmbeddedNATS(t).JetStream()
 	if err != nil {
-		t.Skip("NATS not running, skipping TDD test")
-		return
+		t.Fatal(err)
 	}
-	defer nc.Close()
+	return NewKVCacheController(js), js
+}
 
-	js, err := nc.JetStream()
+// kvEvent is one KVHandler call.
+type kvEvent struct {
+	env *pb.KVCacheEnvelope
+	err error
+}
+
+func resume(t *testing.T, k *KVCacheController, agentID string, fromSeq uint64) <-chan kvEvent {
+	t.Helper()
+	events := make(chan kvEvent, 64)
+	sub, err := k.Resume(agentID, fromSeq, func(env *pb.KVCacheEnvelope, err error) {
+		events <- kvEvent{env, err}
+	})
 	if err != nil {
 		t.Fatal(err)
 	}
+	t.Cleanup(func() { sub.Unsubscribe() })
+	return events
+}
+
+func next(t *testing.T, events <-chan kvEvent) kvEvent {
+	t.Helper()
+	select {
+	case ev := <-events:
+		return ev
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for a KV message")
+		return kvEvent{}
+	}
+}
+
+// expectSeqs reads envelopes and checks their sequences and payloads.
+func expectSeqs(t *testing.T, events <-chan kvEvent, seqs ...uint64) {
+	t.Helper()
+	for _, want := range seqs {
+		ev := next(t, events)
+		if ev.err != nil {
+			t.Fatalf("Expected seq %d, got error %v", want, ev.err)
+		}
+		if ev.env.Sequence != want || string(ev.env.Payload) != fmt.Sprintf("p%d", want) {
+			t.Fatalf("Expected seq %d, got %d (%q)", want, ev.env.Sequence, ev.env.Payload)
+		}
+	}
+}
+
+func TestKVCacheSync(t *testing.T) {
+	k, js := newKVCache(t)
 
-	// 2. Initialize KV Sync
-	k := NewKVCacheController(js)
-	
 	agentID := "test-agent"
 	delta := []byte("The conversation context is building...")
 
-	// 3. Broadcast Delta
-	err = k.BroadcastDelta(agentID, delta)
-	if err != nil {
+	if err := k.BroadcastDelta(agentID, delta); err != nil {
 		t.Fatal(err)
 	}
 
-	// 4. Verification: Subscribe to the mesh channel
+	// Verification: read the envelope off the mesh channel
 	sub, err := js.SubscribeSync("mesh.kv_cache.>", nats.DeliverLast())
 	if err != nil {
 		t.Fatal(err)
 	}
-	
 	msg, err := sub.NextMsg(1 * time.Second)
 	if err != nil {
 		t.Fatal("Did not receive KV cache delta broadcast")
 	}
-	
-	if string(msg.Data) != string(delta) {
-		t.Errorf("Received data mismatch: %s", msg.Data)
+	env := &pb.KVCacheEnvelope{}
+	if err := proto.Unmarshal(msg.Data, env); err != nil {
+		t.Fatal(err)
+	}
+	if string(env.Payload) != string(delta) || env.AgentId != agentID || env.Sequence != 1 || env.Checksum == 0 {
+		t.Errorf("Unexpected envelope: %+v", env)
+	}
+}
+
+func TestKVCacheResume(t *testing.T) {
+	k, js := newKVCache(t)
+	const agent = "planner"
+	for seq := uint64(1); seq <= 5; seq++ {
+		var err error
+		if seq == 3 {
+			err = k.Snapshot(agent, []byte("p3"))
+		} else {
+			err = k.BroadcastDelta(agent, []byte(fmt.Sprintf("p%d", seq)))
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A new node starts at the snapshot and never sees deltas 1 and 2.
+	fresh := resume(t, k, agent, 0)
+	expectSeqs(t, fresh, 3, 4, 5)
+
+	// A node that already applied 4 only gets what follows.
+	behind := resume(t, k, agent, 4)
+	expectSeqs(t, behind, 5)
+
+	// Both stay subscribed to live updates, including from a restarted
+	// publisher, which continues the sequence.
+	k2 := NewKVCacheController(js)
+	if err := k2.BroadcastDelta(agent, []byte("p6")); err != nil {
+		t.Fatal(err)
+	}
+	expectSeqs(t, fresh, 6)
+	expectSeqs(t, behind, 6)
+}
+
+func TestKVCachePeriodicSnapshots(t *testing.T) {
+	k, _ := newKVCache(t)
+	k.ConfigureSnapshots(2, func(agentID string) ([]byte, error) {
+		return []byte("p3"), nil
+	})
+	for _, p := range []string{"p1", "p2", "p4"} {
+		if err := k.BroadcastDelta("coder", []byte(p)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	events := resume(t, k, "coder", 0)
+	snap := next(t, events)
+	if snap.env == nil || !snap.env.Snapshot || snap.env.Sequence != 3 {
+		t.Fatalf("Expected a snapshot after two deltas, got %+v", snap)
+	}
+	ev := next(t, events)
+	if ev.env == nil || ev.env.Sequence != 4 || ev.env.BaseSnapshotId != 3 {
+		t.Errorf("Expected delta 4 on snapshot 3, got %+v", ev)
+	}
+}
+
+func TestKVCacheReportsGaps(t *testing.T) {
+	k, js := newKVCache(t)
+	const agent = "auditor"
+	if err := k.BroadcastDelta(agent, []byte("p1")); err != nil {
+		t.Fatal(err)
+	}
+	events := resume(t, k, agent, 0)
+	expectSeqs(t, events, 1)
+
+	// Sequence 2 never arrives.
+	publishEnvelope(t, js, &pb.KVCacheEnvelope{AgentId: agent, Sequence: 3, Payload: []byte("p3")}, true)
+	ev := next(t, events)
+	var gap *KVGapError
+	if !errors.As(ev.err, &gap) || gap.Expected != 2 || gap.Got != 3 {
+		t.Fatalf("Expected a gap error, got %+v", ev)
+	}
+
+	// Deltas are dropped until a snapshot resynchronizes.
+	publishEnvelope(t, js, &pb.KVCacheEnvelope{AgentId: agent, Sequence: 4, Payload: []byte("p4")}, true)
+	publishEnvelope(t, js, &pb.KVCacheEnvelope{AgentId: agent, Sequence: 5, Snapshot: true, Payload: []byte("p5")}, true)
+	expectSeqs(t, events, 5)
+
+	// A corrupted payload is reported instead of applied.
+	publishEnvelope(t, js, &pb.KVCacheEnvelope{AgentId: agent, Sequence: 6, Payload: []byte("p6")}, false)
+	if ev := next(t, events); !errors.Is(ev.err, ErrKVChecksum) {
+		t.Errorf("Expected a checksum error, got %+v", ev)
+	}
+}
+
+// publishEnvelope publishes env directly, with a valid checksum if asked.
+func publishEnvelope(t *testing.T, js nats.JetStreamContext, env *pb.KVCacheEnvelope, valid bool) {
+	t.Helper()
+	if valid {
+		env.Checksum = kvChecksum(env.Payload)
+	} else {
+		env.Checksum = kvChecksum(env.Payload) + 1
+	}
+	subject := kvDeltaSubject(env.AgentId)
+	if env.Snapshot {
+		subject = kvSnapshotSubject(env.AgentId)
+	}
+	data, err := proto.Marshal(env)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := js.Publish(subject, data); err != nil {
+		t.Fatal(err)
 	}
 }
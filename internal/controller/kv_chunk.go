@@ -0,0 +1,248 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	pb "github.com/groovy-byte/agent-mesh-core/proto"
+	"github.com/klauspost/compress/s2"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Defaults for KV transport. Chunks stay well under the 1MB NATS default
+// max payload once the envelope is added.
+const (
+	DefaultKVMaxChunkBytes     = 512 * 1024
+	DefaultKVReassemblyTimeout = 5 * time.Second
+
+	// maxKVChunks bounds the reassembly buffer one message can claim.
+	maxKVChunks = 4096
+)
+
+var ErrKVIncomplete = errors.New("kv: chunks missing")
+
+// KVTransportPolicy controls how KV payloads go over the wire. Payloads are
+// quantized, compressed, and then split into chunks of at most MaxChunkBytes.
+type KVTransportPolicy struct {
+	// Quantization encodes deltas of little-endian float32s as lossy blocks.
+	// Snapshots always go exact, so the error does not outlive the next one.
+	Quantization  pb.KVQuantization
+	Compression   pb.KVCompression
+	MaxChunkBytes int
+	// ReassemblyTimeout is how long a subscriber waits for the rest of a
+	// chunked message before reporting it incomplete.
+	ReassemblyTimeout time.Duration
+}
+
+func DefaultKVTransportPolicy() KVTransportPolicy {
+	return KVTransportPolicy{
+		Quantization:      pb.KVQuantization_QUANT_NONE,
+		Compression:       pb.KVCompression_COMPRESSION_NONE,
+		MaxChunkBytes:     DefaultKVMaxChunkBytes,
+		ReassemblyTimeout: DefaultKVReassemblyTimeout,
+	}
+}
+
+// KVTransportStats counts the payloads this controller published.
+type KVTransportStats struct {
+	Messages  uint64
+	Chunks    uint64
+	RawBytes  uint64 // Before quantization and compression.
+	WireBytes uint64 // After quantization and compression, excluding envelopes.
+
+	// Quantized deltas: how many float32 values they carried, the bytes
+	// those took before and after quantization, and the error of
+	// dequantizing them, measured when they were published.
+	QuantizedValues   uint64
+	QuantizedRawBytes uint64
+	QuantizedBytes    uint64
+	QuantSquaredError float64
+	QuantMaxError     float64
+}
+
+// CompressionRatio is RawBytes per WireBytes, 1 before anything is sent.
+func (s KVTransportStats) CompressionRatio() float64 {
+	if s.WireBytes == 0 {
+		return 1
+	}
+	return float64(s.RawBytes) / float64(s.WireBytes)
+}
+
+// BandwidthSaved is how many payload bytes quantization and compression
+// kept off the wire.
+func (s KVTransportStats) BandwidthSaved() uint64 {
+	if s.WireBytes >= s.RawBytes {
+		return 0
+	}
+	return s.RawBytes - s.WireBytes
+}
+
+// QuantRMSE is the root mean square reconstruction error of quantized
+// values, 0 before any are sent.
+func (s KVTransportStats) QuantRMSE() float64 {
+	if s.QuantizedValues == 0 {
+		return 0
+	}
+	return math.Sqrt(s.QuantSquaredError / float64(s.QuantizedValues))
+}
+
+var (
+	zstdEncoder = sync.OnceValue(func() *zstd.Encoder {
+		enc, _ := zstd.NewWriter(nil) // Only fails on invalid options.
+		return enc
+	})
+	zstdDecoder = sync.OnceValue(func() *zstd.Decoder {
+		dec, _ := zstd.NewReader(nil)
+		return dec
+	})
+)
+
+// compressKV compresses payload with c, keeping it uncompressed when that
+// does not make it smaller.
+func compressKV(c pb.KVCompression, payload []byte) (pb.KVCompression, []byte) {
+	var out []byte
+	switch c {
+	case pb.KVCompression_COMPRESSION_ZSTD:
+		out = zstdEncoder().EncodeAll(payload, nil)
+	case pb.KVCompression_COMPRESSION_S2:
+		out = s2.Encode(nil, payload)
+	default:
+		return pb.KVCompression_COMPRESSION_NONE, payload
+	}
+	if len(out) >= len(payload) {
+		return pb.KVCompression_COMPRESSION_NONE, payload
+	}
+	return c, out
+}
+
+func decompressKV(c pb.KVCompression, data []byte) ([]byte, error) {
+	switch c {
+	case pb.KVCompression_COMPRESSION_NONE:
+		return data, nil
+	case pb.KVCompression_COMPRESSION_ZSTD:
+		return zstdDecoder().DecodeAll(data, nil)
+	case pb.KVCompression_COMPRESSION_S2:
+		return s2.Decode(nil, data)
+	}
+	return nil, fmt.Errorf("unknown KV compression %v", c)
+}
+
+// splitKV cuts data into chunks of at most size bytes. Empty data is a
+// single empty chunk.
+func splitKV(data []byte, size int) [][]byte {
+	if size <= 0 || len(data) <= size {
+		return [][]byte{data}
+	}
+	chunks := make([][]byte, 0, (len(data)+size-1)/size)
+	for len(data) > size {
+		chunks = append(chunks, data[:size])
+		data = data[size:]
+	}
+	return append(chunks, data)
+}
+
+// kvAssembly collects the chunks of one sequence, in any order.
+type kvAssembly struct {
+	header *pb.KVCacheEnvelope
+	parts  map[uint32][]byte
+	timer  *time.Timer
+}
+
+// abandonLocked gives up on chunked messages before seq: the stream
+// delivers a publisher's chunks in order, so theirs are not coming.
+// Callers must hold r.mu.
+func (r *kvReplay) abandonLocked(seq uint64) {
+	for s, a := range r.pending {
+		if s < seq {
+			r.dropLocked(s, a)
+			r.failLocked(r.incomplete(s, a))
+		}
+	}
+}
+
+// assembleLocked adds a chunk to its sequence's buffer and returns the
+// whole message once every chunk is in, or nil while chunks are missing.
+// Callers must hold r.mu.
+func (r *kvReplay) assembleLocked(env *pb.KVCacheEnvelope) (*pb.KVCacheEnvelope, error) {
+	if env.ChunkCount <= 1 {
+		return r.decode(env)
+	}
+	if env.ChunkCount > maxKVChunks || env.ChunkIndex >= env.ChunkCount {
+		return nil, fmt.Errorf("agent %s seq %d: chunk %d of %d out of range", r.agentID, env.Sequence, env.ChunkIndex, env.ChunkCount)
+	}
+
+	a, ok := r.pending[env.Sequence]
+	if !ok {
+		a = &kvAssembly{header: env, parts: make(map[uint32][]byte, env.ChunkCount)}
+		seq := env.Sequence
+		a.timer = time.AfterFunc(r.timeout, func() { r.expire(seq, a) })
+		r.pending[seq] = a
+	}
+	if env.ChunkCount != a.header.ChunkCount {
+		return nil, fmt.Errorf("agent %s seq %d: chunk count changed from %d to %d", r.agentID, env.Sequence, a.header.ChunkCount, env.ChunkCount)
+	}
+	a.parts[env.ChunkIndex] = env.Payload
+	if len(a.parts) < int(a.header.ChunkCount) {
+		return nil, nil
+	}
+
+	r.dropLocked(env.Sequence, a)
+	idx := make([]uint32, 0, len(a.parts))
+	var size int
+	for i, p := range a.parts {
+		idx = append(idx, i)
+		size += len(p)
+	}
+	sort.Slice(idx, func(i, j int) bool { return idx[i] < idx[j] })
+	payload := make([]byte, 0, size)
+	for _, i := range idx {
+		payload = append(payload, a.parts[i]...)
+	}
+	a.header.Payload = payload
+	return r.decode(a.header)
+}
+
+// decode decompresses a whole message, verifies it and dequantizes it. The
+// result reads as if it had been sent in one uncompressed piece; a quantized
+// one keeps its quantization to mark the payload as approximate.
+func (r *kvReplay) decode(env *pb.KVCacheEnvelope) (*pb.KVCacheEnvelope, error) {
+	payload, err := decompressKV(env.Compression, env.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("agent %s seq %d: %w: %v", r.agentID, env.Sequence, ErrKVChecksum, err)
+	}
+	if kvChecksum(payload) != env.Checksum || (env.PayloadSize > 0 && uint64(len(payload)) != env.PayloadSize) {
+		return nil, fmt.Errorf("agent %s seq %d: %w", r.agentID, env.Sequence, ErrKVChecksum)
+	}
+	if payload, err = dequantizeKV(env.Quantization, payload, env.ValueCount); err != nil {
+		return nil, fmt.Errorf("agent %s seq %d: %w: %v", r.agentID, env.Sequence, ErrKVChecksum, err)
+	}
+	env.Payload = payload
+	env.PayloadSize = uint64(len(payload))
+	env.Compression = pb.KVCompression_COMPRESSION_NONE
+	env.ChunkIndex, env.ChunkCount = 0, 0
+	return env, nil
+}
+
+// expire reports a chunked message still incomplete after the timeout.
+func (r *kvReplay) expire(seq uint64, a *kvAssembly) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.pending[seq] != a {
+		return // Completed or abandoned meanwhile.
+	}
+	r.dropLocked(seq, a)
+	r.failLocked(r.incomplete(seq, a))
+}
+
+func (r *kvReplay) dropLocked(seq uint64, a *kvAssembly) {
+	a.timer.Stop()
+	delete(r.pending, seq)
+}
+
+func (r *kvReplay) incomplete(seq uint64, a *kvAssembly) error {
+	return fmt.Errorf("agent %s seq %d: %d of %d chunks received: %w", r.agentID, seq, len(a.parts), a.header.ChunkCount, ErrKVIncomplete)
+}
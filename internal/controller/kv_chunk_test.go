@@ -0,0 +1,191 @@
+package controller
+
+import (
+	"bytes"
+	"errors"
+	"math/rand"
+	"testing"
+	"time"
+
+	pb "github.com/groovy-byte/agent-mesh-core/proto"
+	"github.com/nats-io/nats.go"
+	"google.golang.org/protobuf/proto"
+)
+
+// kvTensor returns n bytes of 4-bit values laid out in repeating rows, so
+// both entropy coders and LZ-only codecs find something to compress.
+func kvTensor(n int) []byte {
+	rng := rand.New(rand.NewSource(1))
+	rows := make([][]byte, 32)
+	for i := range rows {
+		rows[i] = make([]byte, 128)
+		for j := range rows[i] {
+			rows[i][j] = byte(rng.Intn(16))
+		}
+	}
+	b := make([]byte, 0, n)
+	for len(b) < n {
+		b = append(b, rows[rng.Intn(len(rows))]...)
+	}
+	return b[:n]
+}
+
+func TestKVCacheLargeDeltas(t *testing.T) {
+	delta := kvTensor(3 << 20) // Well over the 1MB NATS max payload.
+	for _, c := range []pb.KVCompression{pb.KVCompression_COMPRESSION_NONE, pb.KVCompression_COMPRESSION_ZSTD, pb.KVCompression_COMPRESSION_S2} {
+		t.Run(c.String(), func(t *testing.T) {
+			k, _ := newKVCache(t)
+			policy := DefaultKVTransportPolicy()
+			policy.Compression = c
+			policy.MaxChunkBytes = 16 * 1024
+			k.ConfigureTransport(policy)
+
+			if err := k.BroadcastDelta("coder", []byte("p1")); err != nil {
+				t.Fatal(err)
+			}
+			if err := k.BroadcastDelta("coder", delta); err != nil {
+				t.Fatal(err)
+			}
+			events := resume(t, k, "coder", 1)
+			ev := next(t, events)
+			if ev.err != nil {
+				t.Fatal(ev.err)
+			}
+			if ev.env.Sequence != 2 || !bytes.Equal(ev.env.Payload, delta) {
+				t.Errorf("Expected the whole delta as seq 2, got seq %d with %d bytes", ev.env.Sequence, len(ev.env.Payload))
+			}
+
+			s := k.TransportStats()
+			if s.Messages != 2 || s.Chunks <= s.Messages {
+				t.Errorf("Expected the delta to be chunked, got %+v", s)
+			}
+			if ratio := s.CompressionRatio(); (c == pb.KVCompression_COMPRESSION_NONE) != (ratio == 1) || ratio < 1 {
+				t.Errorf("Unexpected compression ratio %.2f for %s", ratio, c)
+			}
+		})
+	}
+}
+
+// publishChunks publishes payload as seq split into count chunks, in the
+// given order.
+func publishChunks(t *testing.T, js nats.JetStreamContext, agentID string, seq uint64, payload []byte, count int, order ...int) {
+	t.Helper()
+	chunks := splitKV(payload, (len(payload)+count-1)/count)
+	if len(chunks) != count {
+		t.Fatalf("Expected %d chunks, got %d", count, len(chunks))
+	}
+	for _, i := range order {
+		env := &pb.KVCacheEnvelope{
+			AgentId:     agentID,
+			Sequence:    seq,
+			Checksum:    kvChecksum(payload),
+			Payload:     chunks[i],
+			ChunkIndex:  uint32(i),
+			ChunkCount:  uint32(count),
+			PayloadSize: uint64(len(payload)),
+		}
+		data, err := proto.Marshal(env)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := js.Publish(kvDeltaSubject(agentID), data); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestKVCacheOutOfOrderChunks(t *testing.T) {
+	k, js := newKVCache(t)
+	events := resume(t, k, "editor", 0)
+
+	publishChunks(t, js, "editor", 1, []byte("p1 split into three"), 3, 2, 0, 1)
+	ev := next(t, events)
+	if ev.err != nil || string(ev.env.Payload) != "p1 split into three" {
+		t.Fatalf("Expected the reassembled delta, got %+v", ev)
+	}
+}
+
+func TestKVCacheMissingChunks(t *testing.T) {
+	k, js := newKVCache(t)
+	policy := DefaultKVTransportPolicy()
+	policy.ReassemblyTimeout = 50 * time.Millisecond
+	k.ConfigureTransport(policy)
+	events := resume(t, k, "editor", 0)
+
+	// Chunk 1 never arrives and nothing follows: the timeout reports it.
+	start := time.Now()
+	publishChunks(t, js, "editor", 1, []byte("p1 split into three"), 3, 0, 2)
+	ev := next(t, events)
+	if !errors.Is(ev.err, ErrKVIncomplete) {
+		t.Fatalf("Expected an incomplete error, got %+v", ev)
+	}
+	if waited := time.Since(start); waited < policy.ReassemblyTimeout {
+		t.Errorf("Expected the error after the timeout, got it after %v", waited)
+	}
+
+	// A snapshot resynchronizes.
+	publishEnvelope(t, js, &pb.KVCacheEnvelope{AgentId: "editor", Sequence: 2, Snapshot: true, Payload: []byte("p2")}, true)
+	expectSeqs(t, events, 2)
+
+	// A later sequence arriving first means the missing chunk is lost.
+	publishChunks(t, js, "editor", 3, []byte("p3 in two"), 2, 1)
+	publishEnvelope(t, js, &pb.KVCacheEnvelope{AgentId: "editor", Sequence: 4, Payload: []byte("p4")}, true)
+	if ev := next(t, events); !errors.Is(ev.err, ErrKVIncomplete) {
+		t.Fatalf("Expected an incomplete error, got %+v", ev)
+	}
+	publishEnvelope(t, js, &pb.KVCacheEnvelope{AgentId: "editor", Sequence: 5, Snapshot: true, Payload: []byte("p5")}, true)
+	expectSeqs(t, events, 5)
+}
+
+func TestKVCacheResumeChunkedSnapshot(t *testing.T) {
+	k, _ := newKVCache(t)
+	policy := DefaultKVTransportPolicy()
+	policy.MaxChunkBytes = 16 * 1024
+	k.ConfigureTransport(policy)
+
+	state := kvTensor(100 * 1024)
+	for _, step := range []func() error{
+		func() error { return k.BroadcastDelta("coder", []byte("p1")) },
+		func() error { return k.BroadcastDelta("editor", []byte("p1")) },
+		func() error { return k.Snapshot("coder", state) },
+		func() error { return k.BroadcastDelta("coder", []byte("p3")) },
+	} {
+		if err := step(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	events := resume(t, k, "coder", 0)
+	ev := next(t, events)
+	if ev.err != nil {
+		t.Fatal(ev.err)
+	}
+	if !ev.env.Snapshot || ev.env.Sequence != 2 || !bytes.Equal(ev.env.Payload, state) {
+		t.Fatalf("Expected the whole snapshot as seq 2, got seq %d with %d bytes", ev.env.Sequence, len(ev.env.Payload))
+	}
+	expectSeqs(t, events, 3)
+
+	// The snapshot's last chunk points at its first, stream seq 3.
+	_, last, err := k.lastEnvelope(kvSnapshotSubject("coder"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := last.Header.Get(kvFirstChunkHeader); got != "3" {
+		t.Errorf("Expected the last chunk to point at seq 3, got %q", got)
+	}
+}
+
+func TestKVFirstChunk(t *testing.T) {
+	env := &pb.KVCacheEnvelope{ChunkIndex: 3, ChunkCount: 4}
+	// Other agents' messages came between the chunks.
+	msg := &nats.RawStreamMsg{Sequence: 20, Header: nats.Header{}}
+	msg.Header.Set(kvFirstChunkHeader, "12")
+	if got := firstChunk(env, msg); got != 12 {
+		t.Errorf("Expected chunk 0 at seq 12, got %d", got)
+	}
+	// Without the header it assumes no interleaving.
+	msg.Header = nil
+	if got := firstChunk(env, msg); got != 17 {
+		t.Errorf("Expected chunk 0 at seq 17, got %d", got)
+	}
+}
@@ -0,0 +1,89 @@
+package controller
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/groovy-byte/agent-mesh-core/internal/quantx"
+	pb "github.com/groovy-byte/agent-mesh-core/proto"
+)
+
+// kvQuantError is the reconstruction error of one quantized payload.
+type kvQuantError struct {
+	squared float64 // Sum over all values.
+	max     float64
+}
+
+// quantizeKV encodes payload, read as little-endian float32s, as q blocks
+// and measures what dequantizing them loses. Payloads that are not whole
+// float32s stay as they are.
+func quantizeKV(q pb.KVQuantization, payload []byte) (pb.KVQuantization, []byte, kvQuantError, error) {
+	if q == pb.KVQuantization_QUANT_NONE || len(payload) == 0 || len(payload)%4 != 0 {
+		return pb.KVQuantization_QUANT_NONE, payload, kvQuantError{}, nil
+	}
+	x := make([]float32, len(payload)/4)
+	for i := range x {
+		x[i] = math.Float32frombits(binary.LittleEndian.Uint32(payload[4*i:]))
+	}
+	var out []byte
+	switch q {
+	case pb.KVQuantization_QUANT_Q8_0:
+		out = quantx.MarshalQ8_0(quantx.QuantizeQ8_0(x))
+	case pb.KVQuantization_QUANT_Q2_K:
+		out = quantx.MarshalQ2K(quantx.QuantizeQ2K(x))
+	default:
+		return 0, nil, kvQuantError{}, fmt.Errorf("unknown KV quantization %v", q)
+	}
+
+	y, err := dequantizeKV(q, out, uint64(len(x)))
+	if err != nil {
+		return 0, nil, kvQuantError{}, err
+	}
+	var e kvQuantError
+	for i, v := range x {
+		d := math.Abs(float64(v) - float64(math.Float32frombits(binary.LittleEndian.Uint32(y[4*i:]))))
+		e.squared += d * d
+		e.max = max(e.max, d)
+	}
+	return q, out, e, nil
+}
+
+// dequantizeKV expands q blocks back into their first values float32s,
+// little-endian.
+func dequantizeKV(q pb.KVQuantization, data []byte, values uint64) ([]byte, error) {
+	var y []float32
+	switch q {
+	case pb.KVQuantization_QUANT_NONE:
+		return data, nil
+	case pb.KVQuantization_QUANT_Q8_0:
+		blocks, err := quantx.UnmarshalQ8_0(data)
+		if err != nil {
+			return nil, err
+		}
+		if values > uint64(len(blocks))*quantx.QK8_0 {
+			return nil, fmt.Errorf("%d Q8_0 blocks cannot hold %d values", len(blocks), values)
+		}
+		y = make([]float32, values)
+		quantx.DequantizeQ8_0(blocks, y)
+	case pb.KVQuantization_QUANT_Q2_K:
+		blocks, err := quantx.UnmarshalQ2K(data)
+		if err != nil {
+			return nil, err
+		}
+		if values > uint64(len(blocks))*quantx.QK_K {
+			return nil, fmt.Errorf("%d Q2_K blocks cannot hold %d values", len(blocks), values)
+		}
+		y = make([]float32, len(blocks)*quantx.QK_K)
+		quantx.DequantizeQ2KBlocks(blocks, y)
+		y = y[:values]
+	default:
+		return nil, fmt.Errorf("unknown KV quantization %v", q)
+	}
+
+	out := make([]byte, 0, 4*len(y))
+	for _, v := range y {
+		out = binary.LittleEndian.AppendUint32(out, math.Float32bits(v))
+	}
+	return out, nil
+}
@@ -0,0 +1,136 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+
+	pb "github.com/groovy-byte/agent-mesh-core/proto"
+)
+
+// kvFloats returns n normally distributed float32s as little-endian bytes.
+func kvFloats(n int) []byte {
+	rng := rand.New(rand.NewSource(1))
+	b := make([]byte, 0, 4*n)
+	for i := 0; i < n; i++ {
+		b = binary.LittleEndian.AppendUint32(b, math.Float32bits(float32(rng.NormFloat64())))
+	}
+	return b
+}
+
+// kvError returns the RMS and max difference between two float32 payloads.
+func kvError(t *testing.T, want, got []byte) (rms, worst float64) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("Expected %d bytes, got %d", len(want), len(got))
+	}
+	var sum float64
+	for i := 0; i < len(want); i += 4 {
+		d := math.Abs(float64(math.Float32frombits(binary.LittleEndian.Uint32(want[i:])) - math.Float32frombits(binary.LittleEndian.Uint32(got[i:]))))
+		sum += d * d
+		worst = max(worst, d)
+	}
+	return math.Sqrt(sum / float64(len(want)/4)), worst
+}
+
+func TestKVCacheQuantizedDeltas(t *testing.T) {
+	delta := kvFloats(10000) // Not a whole number of blocks.
+	for _, tc := range []struct {
+		q        pb.KVQuantization
+		minRatio float64
+		maxRMSE  float64
+	}{
+		{pb.KVQuantization_QUANT_Q8_0, 3.5, 0.01},
+		{pb.KVQuantization_QUANT_Q2_K, 13, 0.6}, // At most 1024/72 = 14.2x.
+	} {
+		t.Run(tc.q.String(), func(t *testing.T) {
+			k, _ := newKVCache(t)
+			policy := DefaultKVTransportPolicy()
+			policy.Quantization = tc.q
+			policy.MaxChunkBytes = 4 * 1024
+			k.ConfigureTransport(policy)
+
+			if err := k.BroadcastDelta("coder", delta); err != nil {
+				t.Fatal(err)
+			}
+			ev := next(t, resume(t, k, "coder", 0))
+			if ev.err != nil {
+				t.Fatal(ev.err)
+			}
+			if ev.env.Quantization != tc.q {
+				t.Errorf("Expected the delta tagged %s, got %s", tc.q, ev.env.Quantization)
+			}
+			rms, worst := kvError(t, delta, ev.env.Payload)
+
+			s := k.TransportStats()
+			if ratio := s.CompressionRatio(); ratio < tc.minRatio {
+				t.Errorf("Expected at least %.1fx less bandwidth, got %.2fx", tc.minRatio, ratio)
+			}
+			if s.BandwidthSaved() != uint64(len(delta))-s.WireBytes || s.QuantizedValues != 10000 || s.QuantizedRawBytes != uint64(len(delta)) {
+				t.Errorf("Unexpected stats %+v", s)
+			}
+			if math.Abs(s.QuantRMSE()-rms) > 1e-6 || math.Abs(s.QuantMaxError-worst) > 1e-6 {
+				t.Errorf("Expected reported error %g/%g, got %g/%g", rms, worst, s.QuantRMSE(), s.QuantMaxError)
+			}
+			if rms == 0 || rms > tc.maxRMSE {
+				t.Errorf("Expected an RMSE under %g, got %g", tc.maxRMSE, rms)
+			}
+		})
+	}
+}
+
+func TestKVCacheQuantizationSkips(t *testing.T) {
+	k, _ := newKVCache(t)
+	policy := DefaultKVTransportPolicy()
+	policy.Quantization = pb.KVQuantization_QUANT_Q2_K
+	k.ConfigureTransport(policy)
+	events := resume(t, k, "coder", 0)
+
+	// Snapshots stay exact, and so do payloads that are not float32s.
+	state := kvFloats(512)
+	if err := k.Snapshot("coder", state); err != nil {
+		t.Fatal(err)
+	}
+	if err := k.BroadcastDelta("coder", []byte("p2")); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range [][]byte{state, []byte("p2")} {
+		ev := next(t, events)
+		if ev.err != nil {
+			t.Fatal(ev.err)
+		}
+		if ev.env.Quantization != pb.KVQuantization_QUANT_NONE || !bytes.Equal(ev.env.Payload, want) {
+			t.Errorf("Expected seq %d unquantized, got %s", ev.env.Sequence, ev.env.Quantization)
+		}
+	}
+	if s := k.TransportStats(); s.QuantizedValues != 0 || s.BandwidthSaved() != 0 {
+		t.Errorf("Expected nothing quantized, got %+v", s)
+	}
+}
+
+func TestKVCacheQuantizedWithCompression(t *testing.T) {
+	k, js := newKVCache(t)
+	policy := DefaultKVTransportPolicy()
+	policy.Quantization = pb.KVQuantization_QUANT_Q8_0
+	policy.Compression = pb.KVCompression_COMPRESSION_ZSTD
+	k.ConfigureTransport(policy)
+
+	delta := make([]byte, 4*4096) // Zeros quantize exactly and compress well.
+	if err := k.BroadcastDelta("coder", delta); err != nil {
+		t.Fatal(err)
+	}
+	ev := next(t, resume(t, k, "coder", 0))
+	if ev.err != nil || !bytes.Equal(ev.env.Payload, delta) {
+		t.Fatalf("Expected the zero delta back, got %+v", ev)
+	}
+
+	// A value count the blocks cannot hold is reported as corruption.
+	publishEnvelope(t, js, &pb.KVCacheEnvelope{AgentId: "coder", Sequence: 2, Payload: make([]byte, 36), Quantization: pb.KVQuantization_QUANT_Q8_0, ValueCount: 33}, true)
+	events := resume(t, k, "coder", 1)
+	if ev := next(t, events); !errors.Is(ev.err, ErrKVChecksum) {
+		t.Errorf("Expected a corrupt quantized payload to be reported, got %+v", ev)
+	}
+}
@@ -0,0 +1,158 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+// KVStreamPolicy configures the MESH_STATE stream.
+type KVStreamPolicy struct {
+	Storage  nats.StorageType
+	MaxAge   time.Duration // 0 keeps messages until purged.
+	Replicas int
+	// MaxBytesPerAgent bounds an agent's history. JetStream has no per-subject
+	// byte limit, so the publisher enforces it: once an agent's messages pass
+	// the limit, the next snapshot (taken early when a snapshot source is
+	// configured) purges everything before it. 0 disables compaction.
+	MaxBytesPerAgent int64
+}
+
+// DefaultKVStreamPolicy keeps transient state in memory until purged.
+func DefaultKVStreamPolicy() KVStreamPolicy {
+	return KVStreamPolicy{
+		Storage:  nats.MemoryStorage,
+		Replicas: 1,
+	}
+}
+
+func (p KVStreamPolicy) streamConfig() nats.StreamConfig {
+	return nats.StreamConfig{
+		Name:     KVStreamName,
+		Subjects: []string{KVSubjectPrefix + ".>"},
+		Storage:  p.Storage,
+		MaxAge:   p.MaxAge,
+		Replicas: max(p.Replicas, 1),
+	}
+}
+
+// ensureStream creates MESH_STATE, or reconciles an existing stream's
+// subjects, max age and replicas with the policy. Storage cannot change in
+// place, so a mismatch is an error.
+func (k *KVCacheController) ensureStream(p KVStreamPolicy) error {
+	want := p.streamConfig()
+	info, err := k.js.StreamInfo(KVStreamName)
+	if errors.Is(err, nats.ErrStreamNotFound) {
+		if _, err := k.js.AddStream(&want); err != nil {
+			return fmt.Errorf("failed to create %s stream: %w", KVStreamName, err)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to inspect %s stream: %w", KVStreamName, err)
+	}
+
+	have := info.Config
+	if have.Storage != want.Storage {
+		return fmt.Errorf("%s stream uses %s storage, policy wants %s; delete the stream to change it", KVStreamName, have.Storage, want.Storage)
+	}
+	if len(have.Subjects) == 1 && have.Subjects[0] == want.Subjects[0] && have.MaxAge == want.MaxAge && have.Replicas == want.Replicas {
+		return nil
+	}
+	have.Subjects = want.Subjects
+	have.MaxAge = want.MaxAge
+	have.Replicas = want.Replicas
+	if _, err := k.js.UpdateStream(&have); err != nil {
+		return fmt.Errorf("failed to update %s stream: %w", KVStreamName, err)
+	}
+	log.Printf("[KV] 🔧 Reconciled %s stream (max age %v, %d replicas)", KVStreamName, want.MaxAge, want.Replicas)
+	return nil
+}
+
+// Purge deletes an agent's KV history from the stream, for when the agent
+// is evicted. A later publish for the same ID starts again at sequence 1
+// under a new epoch, which tells live subscribers to start over.
+func (k *KVCacheController) Purge(agentID string) error {
+	a := k.agent(agentID)
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if err := k.js.PurgeStream(KVStreamName, &nats.StreamPurgeRequest{Subject: kvAgentSubjects(agentID)}); err != nil {
+		return fmt.Errorf("failed to purge KV state of agent %s: %w", agentID, err)
+	}
+	a.seq, a.base, a.sinceSnapshot, a.stored = 0, 0, 0, 0
+	a.epoch = newKVEpoch() // JetStream also still remembers the old message IDs.
+	a.loaded = true
+	log.Printf("[KV] 🧹 Purged KV state of agent %s", agentID)
+	return nil
+}
+
+// PurgeOnEvict purges each agent's KV history when r evicts it. Failures
+// are logged, since the eviction itself has already happened.
+func (k *KVCacheController) PurgeOnEvict(r *MeshRegistry) {
+	r.OnEvict(func(agentID string) {
+		if err := k.Purge(agentID); err != nil {
+			log.Printf("[KV] ⚠️ %v", err)
+		}
+	})
+}
+
+// budgetLocked returns MaxBytesPerAgent. Callers must hold a.mu.
+func (k *KVCacheController) budgetLocked() int64 {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.stream.MaxBytesPerAgent
+}
+
+// overBudgetLocked reports whether an agent's history has passed
+// MaxBytesPerAgent. Callers must hold a.mu.
+func (k *KVCacheController) overBudgetLocked(a *kvAgent) bool {
+	limit := k.budgetLocked()
+	return limit > 0 && a.stored > limit
+}
+
+// storedBytes adds up the sizes of an agent's messages in the stream,
+// reading headers only, so a restarted publisher keeps to its budget.
+func (k *KVCacheController) storedBytes(agentID string) (int64, error) {
+	sub, err := k.js.SubscribeSync(kvAgentSubjects(agentID), nats.OrderedConsumer(), nats.DeliverAll(), nats.HeadersOnly())
+	if err != nil {
+		return 0, fmt.Errorf("failed to size KV state of agent %s: %w", agentID, err)
+	}
+	defer sub.Unsubscribe()
+
+	var stored int64
+	for {
+		msg, err := sub.NextMsg(5 * time.Second)
+		if err != nil {
+			return 0, fmt.Errorf("failed to size KV state of agent %s: %w", agentID, err)
+		}
+		size, err := strconv.ParseInt(msg.Header.Get(nats.MsgSize), 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("failed to size KV state of agent %s: %w", agentID, err)
+		}
+		stored += size
+		meta, err := msg.Metadata()
+		if err != nil {
+			return 0, fmt.Errorf("failed to size KV state of agent %s: %w", agentID, err)
+		}
+		if meta.NumPending == 0 {
+			return stored, nil
+		}
+	}
+}
+
+// compactLocked drops an agent's messages before the snapshot at streamSeq.
+// Callers must hold a.mu.
+func (k *KVCacheController) compactLocked(agentID string, a *kvAgent, streamSeq uint64, snapshotBytes int64) {
+	err := k.js.PurgeStream(KVStreamName, &nats.StreamPurgeRequest{Subject: kvAgentSubjects(agentID), Sequence: streamSeq})
+	if err != nil {
+		log.Printf("[KV] ⚠️ Compacting agent %s failed: %v", agentID, err)
+		return
+	}
+	log.Printf("[KV] 🗜️ Compacted agent %s to the snapshot at seq %d (%d bytes over budget)", agentID, a.seq, a.stored-snapshotBytes)
+	a.stored = snapshotBytes
+}
@@ -0,0 +1,205 @@
+package controller
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	pb "github.com/groovy-byte/agent-mesh-core/proto"
+	"github.com/nats-io/nats.go"
+)
+
+func TestKVStreamReconcile(t *testing.T) {
+	js, err := runEmbeddedNATS(t).JetStream()
+	if err != nil {
+		t.Fatal(err)
+	}
+	NewKVCacheController(js)
+
+	// A second controller with a different policy updates the stream in place.
+	policy := DefaultKVStreamPolicy()
+	policy.MaxAge = time.Hour
+	if _, err := NewKVCacheControllerWithPolicy(js, policy); err != nil {
+		t.Fatal(err)
+	}
+	info, err := js.StreamInfo(KVStreamName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Config.MaxAge != time.Hour || info.Config.Storage != nats.MemoryStorage {
+		t.Errorf("Expected the stream to be reconciled, got %+v", info.Config)
+	}
+
+	// Storage cannot change in place.
+	policy.Storage = nats.FileStorage
+	if _, err := NewKVCacheControllerWithPolicy(js, policy); err == nil {
+		t.Error("Expected an error for a storage change")
+	}
+}
+
+func TestKVStreamPurge(t *testing.T) {
+	k, js := newKVCache(t)
+	for _, agent := range []string{"evicted", "kept"} {
+		for i := 1; i <= 3; i++ {
+			if err := k.BroadcastDelta(agent, []byte(fmt.Sprintf("p%d", i))); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := k.Purge("evicted"); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := js.StreamInfo(KVStreamName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.State.Msgs != 3 {
+		t.Errorf("Expected only the kept agent's 3 messages, got %d", info.State.Msgs)
+	}
+	if err := k.BroadcastDelta("evicted", []byte("p1")); err != nil {
+		t.Fatal(err)
+	}
+	expectSeqs(t, resume(t, k, "evicted", 0), 1)
+}
+
+func TestKVStreamPurgeRestartsSubscribers(t *testing.T) {
+	k, _ := newKVCache(t)
+	events := resume(t, k, "evicted", 0)
+	publish := func(n int) {
+		for i := 1; i <= n; i++ {
+			if err := k.BroadcastDelta("evicted", []byte(fmt.Sprintf("p%d", i))); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	publish(3)
+	expectSeqs(t, events, 1, 2, 3)
+
+	// The live subscription takes the new history from seq 1 instead of
+	// dropping it as already applied.
+	if err := k.Purge("evicted"); err != nil {
+		t.Fatal(err)
+	}
+	publish(2)
+	expectSeqs(t, events, 1, 2)
+	select {
+	case ev := <-events:
+		t.Errorf("Expected nothing more, got %+v", ev)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestKVStreamPurgeOnEvict(t *testing.T) {
+	k, js := newKVCache(t)
+	r := NewMeshRegistry()
+	k.PurgeOnEvict(r)
+	for _, agent := range []string{"evicted", "dead"} {
+		r.RegisterAgent(&pb.HandshakeRequest{AgentId: agent})
+		if err := k.BroadcastDelta(agent, []byte("p1")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	msgs := func() uint64 {
+		info, err := js.StreamInfo(KVStreamName)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return info.State.Msgs
+	}
+
+	r.EvictAgent("evicted")
+	if n := msgs(); n != 1 {
+		t.Errorf("Expected only the live agent's message after EvictAgent, got %d", n)
+	}
+	// Heartbeat death evicts through SweepLiveness.
+	if dead := r.SweepLiveness(time.Now().Add(time.Hour), DefaultHeartbeatConfig()); len(dead) != 1 {
+		t.Fatalf("Expected one dead agent, got %v", dead)
+	}
+	if n := msgs(); n != 0 {
+		t.Errorf("Expected the dead agent's history purged, got %d messages", n)
+	}
+}
+
+func TestKVStreamCompactsOverBudget(t *testing.T) {
+	js, err := runEmbeddedNATS(t).JetStream()
+	if err != nil {
+		t.Fatal(err)
+	}
+	policy := DefaultKVStreamPolicy()
+	policy.MaxBytesPerAgent = 4096
+	k, err := NewKVCacheControllerWithPolicy(js, policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	snapshots := 0
+	k.ConfigureSnapshots(0, func(agentID string) ([]byte, error) {
+		snapshots++
+		return make([]byte, 512), nil
+	})
+
+	delta := make([]byte, 1024)
+	for i := 0; i < 12; i++ {
+		if err := k.BroadcastDelta("planner", delta); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if snapshots == 0 {
+		t.Fatal("Expected the byte budget to trigger a snapshot")
+	}
+
+	info, err := js.StreamInfo(KVStreamName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.State.Bytes > 2*uint64(policy.MaxBytesPerAgent) {
+		t.Errorf("Expected history to stay near the %d byte budget, got %d bytes", policy.MaxBytesPerAgent, info.State.Bytes)
+	}
+
+	// Resume still works from the compacted history.
+	events := resume(t, k, "planner", 0)
+	if ev := next(t, events); ev.err != nil || !ev.env.Snapshot {
+		t.Errorf("Expected replay to start at a snapshot, got %+v", ev)
+	}
+}
+
+func TestKVStreamRestartedPublisher(t *testing.T) {
+	js, err := runEmbeddedNATS(t).JetStream()
+	if err != nil {
+		t.Fatal(err)
+	}
+	policy := DefaultKVStreamPolicy()
+	policy.MaxBytesPerAgent = 1 << 20
+	first, err := NewKVCacheControllerWithPolicy(js, policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	events := resume(t, first, "planner", 0)
+	for i := 1; i <= 2; i++ {
+		if err := first.BroadcastDelta("planner", []byte(fmt.Sprintf("p%d", i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expectSeqs(t, events, 1, 2)
+
+	// A new controller continues the sequence and epoch and counts the
+	// history already stored against the budget.
+	restarted, err := NewKVCacheControllerWithPolicy(js, policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := restarted.agent("planner")
+	a.mu.Lock()
+	err = restarted.loadSequence("planner", a)
+	a.mu.Unlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := first.agent("planner").stored; a.stored != want {
+		t.Errorf("Expected %d stored bytes after the restart, got %d", want, a.stored)
+	}
+	if err := restarted.BroadcastDelta("planner", []byte("p3")); err != nil {
+		t.Fatal(err)
+	}
+	expectSeqs(t, events, 3)
+}
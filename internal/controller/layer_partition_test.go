@@ -1,7 +1,11 @@
 package controller
 
 import (
+	"errors"
+	"reflect"
 	"testing"
+
+	"github.com/groovy-byte/agent-mesh-core/internal/topology"
 )
 
 func TestLayerPartitioning(t *testing.T) {
@@ -28,3 +32,96 @@ func TestLayerPartitioning(t *testing.T) {
 		}
 	}
 }
+
+func TestPartitionPlanFitsVRAM(t *testing.T) {
+	s := NewScheInferFromProfile(topology.HardwareProfile{
+		GPUName:       "NVIDIA GeForce RTX 3070 Laptop GPU",
+		GPUComputeCap: 86,
+		GPUVRAMBytes:  4 << 30,
+	}, 16<<20)
+	model := ModelDescription{Name: "llama-7b", Layers: 32, BytesPerLayer: 200 << 20}
+
+	plan, err := s.PlanModel(model, 512<<20)
+	if err != nil {
+		t.Fatalf("PlanModel failed: %v", err)
+	}
+	// (4096MB - 512MB KV reserve) / 200MB per layer = 17 layers.
+	if plan.GPULayers != 17 {
+		t.Errorf("Expected 17 GPU layers, got %d", plan.GPULayers)
+	}
+	if plan.Memory.TotalBytes != 32*200<<20 {
+		t.Errorf("Expected total of %d bytes, got %d", 32*200<<20, plan.Memory.TotalBytes)
+	}
+	// Profile-built schedulers plan onto an indexed device.
+	cuda0 := Placement{Provider: ProviderGPUCUDA, Device: 0}.String()
+	if got := plan.Memory.ByProvider[cuda0]; got != 17*200<<20 {
+		t.Errorf("Expected %d bytes on CUDA, got %d", 17*200<<20, got)
+	}
+	if plan.Memory.VRAMFreeBytes != (4096-512-17*200)<<20 {
+		t.Errorf("Expected %dMB VRAM free, got %d", 4096-512-17*200, plan.Memory.VRAMFreeBytes>>20)
+	}
+
+	for layer, want := range map[int]string{0: cuda0, 16: cuda0, 17: ProviderCPUAVX2, 31: ProviderCPUAVX2} {
+		if got := s.RouteLayer(layer); got != want {
+			t.Errorf("Layer %d: expected %s, got %s", layer, want, got)
+		}
+	}
+	// Layers outside the plan fall back to the fixed split.
+	if got := s.RouteLayer(40); got != ProviderCPUAVX2 {
+		t.Errorf("Expected out-of-plan layer on CPU_AVX2, got %s", got)
+	}
+}
+
+func TestPartitionPlanVulkanAndRoundTrip(t *testing.T) {
+	s := NewScheInferFromProfile(topology.HardwareProfile{
+		GPUName:       "NVIDIA GeForce GTX 1070",
+		GPUComputeCap: 61,
+		GPUVRAMBytes:  8 << 30,
+		HasAVX512:     true,
+	}, 16<<20)
+	plan, err := s.PlanModel(ModelDescription{Name: "tiny", Layers: 4, BytesPerLayer: 100 << 20}, 0)
+	if err != nil {
+		t.Fatalf("PlanModel failed: %v", err)
+	}
+	if plan.GPU != "GPU_VULKAN:0" || plan.CPU != ProviderCPUAVX512 || plan.GPULayers != 4 {
+		t.Errorf("Expected all 4 layers on Vulkan with AVX512 fallback, got %s", plan)
+	}
+	if plan.Memory.KVReserveBytes != DefaultKVReserveBytes {
+		t.Errorf("Expected default KV reserve, got %d", plan.Memory.KVReserveBytes)
+	}
+
+	data, err := plan.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	decoded, err := UnmarshalPartitionPlan(data)
+	if err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if !reflect.DeepEqual(plan, decoded) {
+		t.Errorf("Plan changed across JSON round-trip:\n%+v\n%+v", plan, decoded)
+	}
+
+	if _, err := s.PlanModel(ModelDescription{Name: "empty"}, 0); !errors.Is(err, ErrInvalidModel) {
+		t.Errorf("Expected ErrInvalidModel, got %v", err)
+	}
+}
+
+func TestPartitionPlanWithoutGPU(t *testing.T) {
+	plan, err := PlanPartition(ModelFromTensors("gguf", map[string]uint64{
+		"token_embd.weight":   500,
+		"blk.0.attn_q.weight": 100,
+		"blk.0.ffn_up.weight": 300,
+		"blk.1.attn_q.weight": 50,
+		"output.weight":       500,
+	}), "", ProviderCPUAVX2, 0, 0)
+	if err != nil {
+		t.Fatalf("PlanPartition failed: %v", err)
+	}
+	if len(plan.Layers) != 2 || plan.Layers[0].Bytes != 400 || plan.Layers[1].Bytes != 50 {
+		t.Errorf("Unexpected layers from tensors: %+v", plan.Layers)
+	}
+	if plan.GPULayers != 0 || plan.Memory.ByProvider[ProviderCPUAVX2] != 450 {
+		t.Errorf("Expected every layer on CPU, got %s", plan)
+	}
+}
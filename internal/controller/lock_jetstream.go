@@ -0,0 +1,406 @@
+package controller
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// JetStreamLockConfig configures the KV bucket shared by all controllers.
+type JetStreamLockConfig struct {
+	Bucket string
+	// BucketTTL expires lock keys that are not renewed, even if every
+	// controller that knew about them is gone. It also caps the effective lease.
+	BucketTTL time.Duration
+	Replicas  int
+	// OpTimeout bounds KV calls made by methods that take no context.
+	OpTimeout time.Duration
+}
+
+func DefaultJetStreamLockConfig() JetStreamLockConfig {
+	return JetStreamLockConfig{
+		Bucket:    "MESH_LOCKS",
+		BucketTTL: MaxLockLease,
+		Replicas:  1,
+		OpTimeout: 5 * time.Second,
+	}
+}
+
+// lockRecord is the JSON value stored under a lock key. Token is zero on the
+// write that grants the lease, in which case the entry's revision is the
+// fencing token; renewals carry the original token forward.
+type lockRecord struct {
+	Holder    string `json:"holder"`
+	Token     uint64 `json:"token,omitempty"`
+	ExpiresAt int64  `json:"expires_at"` // Unix nanoseconds.
+}
+
+// JetStreamLockBackend stores lock domains in a JetStream KeyValue bucket so
+// several vextra controllers share one counterbalance. Every transition is a
+// revision-checked compare-and-swap, and fencing tokens are stream sequence
+// numbers, so they increase across all controllers. Expiry compares wall
+// clocks, so controllers are expected to run NTP. Priority is ignored:
+// waiters are served in whatever order their CAS lands.
+type JetStreamLockBackend struct {
+	kv  jetstream.KeyValue
+	cfg JetStreamLockConfig
+
+	mu    sync.Mutex
+	stats map[string]*LockDomainStats // Local view: this controller's requests only.
+}
+
+// NewJetStreamLockBackend creates (or binds to) the lock bucket.
+func NewJetStreamLockBackend(nc *nats.Conn, cfg JetStreamLockConfig) (*JetStreamLockBackend, error) {
+	js, err := jetstream.New(nc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open JetStream: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.OpTimeout)
+	defer cancel()
+
+	kv, err := js.CreateOrUpdateKeyValue(ctx, jetstream.KeyValueConfig{
+		Bucket:      cfg.Bucket,
+		Description: "Adaptive OS Mesh strategic lock leases",
+		History:     1,
+		TTL:         cfg.BucketTTL,
+		Replicas:    cfg.Replicas,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create lock bucket %s: %w", cfg.Bucket, err)
+	}
+	log.Printf("[Arbiter] 🌐 Using JetStream KV bucket %s for strategic locks", cfg.Bucket)
+	return &JetStreamLockBackend{
+		kv:    kv,
+		cfg:   cfg,
+		stats: make(map[string]*LockDomainStats),
+	}, nil
+}
+
+func (b *JetStreamLockBackend) AcquireLock(domain, agentID string, lease time.Duration) (LockGrant, bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), b.cfg.OpTimeout)
+	defer cancel()
+
+	grant, ok, err := b.tryAcquire(ctx, normalizeDomain(domain), agentID, lease)
+	if err != nil {
+		log.Printf("[Arbiter] ⚠️ Lock %q unavailable for %s: %v", normalizeDomain(domain), agentID, err)
+		return LockGrant{}, false
+	}
+	return grant, ok
+}
+
+func (b *JetStreamLockBackend) WaitForLock(ctx context.Context, domain, agentID string, priority int32, lease time.Duration) (LockGrant, error) {
+	domain = normalizeDomain(domain)
+	grant, ok, err := b.tryAcquire(ctx, domain, agentID, lease)
+	if err != nil || ok {
+		return grant, err
+	}
+
+	watcher, err := b.kv.Watch(ctx, lockKey(domain), jetstream.UpdatesOnly())
+	if err != nil {
+		return LockGrant{}, fmt.Errorf("failed to watch lock %q: %w", domain, err)
+	}
+	defer watcher.Stop()
+
+	since := time.Now()
+	b.withStats(domain, func(st *LockDomainStats) {
+		st.QueueDepth++
+		st.MaxQueueDepth = max(st.MaxQueueDepth, st.QueueDepth)
+	})
+	defer b.withStats(domain, func(st *LockDomainStats) { st.QueueDepth-- })
+
+	for {
+		// Retry on every change to the key, or when the holder's lease would lapse.
+		timer := time.NewTimer(max(time.Until(grant.ExpiresAt), time.Millisecond))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			b.withStats(domain, func(st *LockDomainStats) { st.Timeouts++ })
+			return LockGrant{}, ctx.Err()
+		case <-watcher.Updates():
+		case <-timer.C:
+		}
+		timer.Stop()
+
+		grant, ok, err = b.tryAcquire(ctx, domain, agentID, lease)
+		if err != nil && ctx.Err() == nil {
+			return LockGrant{}, err
+		}
+		if ok {
+			b.withStats(domain, func(st *LockDomainStats) { st.TotalWait += time.Since(since) })
+			return grant, nil
+		}
+	}
+}
+
+func (b *JetStreamLockBackend) RenewLock(domain, agentID string, token uint64, lease time.Duration) (LockGrant, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), b.cfg.OpTimeout)
+	defer cancel()
+
+	domain = normalizeDomain(domain)
+	grant, rev, err := b.load(ctx, domain)
+	if err != nil && !errors.Is(err, ErrLockNotHeld) {
+		return LockGrant{}, err
+	}
+	if err := checkGrant(grant, agentID, token); err != nil {
+		return LockGrant{}, err
+	}
+	grant.ExpiresAt = time.Now().Add(b.clamp(lease))
+	if _, err := b.kv.Update(ctx, lockKey(domain), encodeRecord(grant, true), rev); err != nil {
+		if errors.Is(err, jetstream.ErrKeyExists) {
+			return LockGrant{}, ErrStaleToken // Lost a race with a reclaim.
+		}
+		return LockGrant{}, fmt.Errorf("failed to renew lock %q: %w", domain, err)
+	}
+	return grant, nil
+}
+
+func (b *JetStreamLockBackend) ValidateToken(domain, agentID string, token uint64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), b.cfg.OpTimeout)
+	defer cancel()
+
+	grant, _, err := b.load(ctx, normalizeDomain(domain))
+	if err != nil && !errors.Is(err, ErrLockNotHeld) {
+		return err
+	}
+	return checkGrant(grant, agentID, token)
+}
+
+func (b *JetStreamLockBackend) ReleaseLock(agentID string) {
+	ctx, cancel := context.WithTimeout(context.Background(), b.cfg.OpTimeout)
+	defer cancel()
+
+	for _, domain := range b.domains(ctx) {
+		grant, rev, err := b.load(ctx, domain)
+		if err != nil || grant.Holder != agentID {
+			continue
+		}
+		if err := b.kv.Delete(ctx, lockKey(domain), jetstream.LastRevision(rev)); err == nil {
+			log.Printf("[Arbiter] 🔓 Lock %q released by %s", domain, agentID)
+		}
+	}
+}
+
+func (b *JetStreamLockBackend) ReleaseFencedLock(domain, agentID string, token uint64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), b.cfg.OpTimeout)
+	defer cancel()
+
+	domain = normalizeDomain(domain)
+	grant, rev, err := b.load(ctx, domain)
+	if err != nil {
+		if errors.Is(err, ErrLockNotHeld) {
+			return ErrStaleToken
+		}
+		return err
+	}
+	if grant.Holder != agentID || grant.Token != token {
+		return ErrStaleToken
+	}
+	if err := b.kv.Delete(ctx, lockKey(domain), jetstream.LastRevision(rev)); err != nil {
+		return fmt.Errorf("failed to release lock %q: %w", domain, err)
+	}
+	log.Printf("[Arbiter] 🔓 Lock %q released by %s (token %d)", domain, agentID, token)
+	return nil
+}
+
+func (b *JetStreamLockBackend) LockHolder(domain string) (LockGrant, bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), b.cfg.OpTimeout)
+	defer cancel()
+
+	grant, _, err := b.load(ctx, normalizeDomain(domain))
+	if err != nil || time.Now().After(grant.ExpiresAt) {
+		return LockGrant{}, false
+	}
+	return grant, true
+}
+
+func (b *JetStreamLockBackend) HeldDomains(agentID string) []string {
+	ctx, cancel := context.WithTimeout(context.Background(), b.cfg.OpTimeout)
+	defer cancel()
+
+	held := []string{}
+	for _, domain := range b.domains(ctx) {
+		grant, _, err := b.load(ctx, domain)
+		if err == nil && grant.Holder == agentID && !time.Now().After(grant.ExpiresAt) {
+			held = append(held, domain)
+		}
+	}
+	sort.Strings(held)
+	return held
+}
+
+// LockStats reports contention seen by this controller; Holder reflects the shared bucket.
+func (b *JetStreamLockBackend) LockStats() map[string]LockDomainStats {
+	b.mu.Lock()
+	out := make(map[string]LockDomainStats, len(b.stats))
+	for domain, st := range b.stats {
+		out[domain] = *st
+	}
+	b.mu.Unlock()
+
+	for domain, st := range out {
+		if grant, ok := b.LockHolder(domain); ok {
+			st.Holder = grant.Holder
+			out[domain] = st
+		}
+	}
+	return out
+}
+
+// tryAcquire performs one compare-and-swap attempt on the domain key.
+func (b *JetStreamLockBackend) tryAcquire(ctx context.Context, domain, agentID string, lease time.Duration) (LockGrant, bool, error) {
+	key := lockKey(domain)
+	expiresAt := time.Now().Add(b.clamp(lease))
+	fresh := LockGrant{Domain: domain, Holder: agentID, ExpiresAt: expiresAt}
+
+	current, rev, err := b.load(ctx, domain)
+	switch {
+	case errors.Is(err, ErrLockNotHeld):
+		rev, err = b.kv.Create(ctx, key, encodeRecord(fresh, false))
+	case err != nil:
+		return LockGrant{}, false, err
+	case current.Holder == agentID && !time.Now().After(current.ExpiresAt):
+		// Re-acquire by the holder extends the lease and keeps the token.
+		current.ExpiresAt = expiresAt
+		if _, err = b.kv.Update(ctx, key, encodeRecord(current, true), rev); err == nil {
+			return current, true, nil
+		}
+	case time.Now().After(current.ExpiresAt):
+		log.Printf("[Arbiter] ⚠️ Reclaiming stale lock %q from %s (token %d)", domain, current.Holder, current.Token)
+		rev, err = b.kv.Update(ctx, key, encodeRecord(fresh, false), rev)
+		if err == nil {
+			b.withStats(domain, func(st *LockDomainStats) { st.Reclaims++ })
+		}
+	default:
+		b.withStats(domain, func(st *LockDomainStats) { st.Contended++ })
+		log.Printf("[Arbiter] ⚠️ Lock %q denied to %s (Held by %s)", domain, agentID, current.Holder)
+		return current, false, nil
+	}
+
+	if err != nil {
+		if errors.Is(err, jetstream.ErrKeyExists) {
+			// Another controller won the race; report whoever holds it now.
+			b.withStats(domain, func(st *LockDomainStats) { st.Contended++ })
+			holder, _, loadErr := b.load(ctx, domain)
+			if loadErr != nil && !errors.Is(loadErr, ErrLockNotHeld) {
+				return LockGrant{}, false, loadErr
+			}
+			return holder, false, nil
+		}
+		return LockGrant{}, false, fmt.Errorf("failed to acquire lock %q: %w", domain, err)
+	}
+
+	fresh.Token = rev
+	b.withStats(domain, func(st *LockDomainStats) { st.Grants++ })
+	log.Printf("[Arbiter] 🔑 Lock %q granted to %s (token %d, lease %v)", domain, agentID, rev, b.clamp(lease))
+	return fresh, true, nil
+}
+
+// load returns the current grant and key revision, or ErrLockNotHeld when the key is absent.
+func (b *JetStreamLockBackend) load(ctx context.Context, domain string) (LockGrant, uint64, error) {
+	entry, err := b.kv.Get(ctx, lockKey(domain))
+	if errors.Is(err, jetstream.ErrKeyNotFound) || errors.Is(err, jetstream.ErrKeyDeleted) {
+		return LockGrant{}, 0, ErrLockNotHeld
+	}
+	if err != nil {
+		return LockGrant{}, 0, fmt.Errorf("failed to read lock %q: %w", domain, err)
+	}
+
+	var rec lockRecord
+	if err := json.Unmarshal(entry.Value(), &rec); err != nil {
+		return LockGrant{}, 0, fmt.Errorf("corrupt lock record %q: %w", domain, err)
+	}
+	token := rec.Token
+	if token == 0 {
+		token = entry.Revision()
+	}
+	return LockGrant{Domain: domain, Holder: rec.Holder, Token: token, ExpiresAt: time.Unix(0, rec.ExpiresAt)}, entry.Revision(), nil
+}
+
+// domains lists every domain with a live key in the bucket.
+func (b *JetStreamLockBackend) domains(ctx context.Context) []string {
+	lister, err := b.kv.ListKeys(ctx)
+	if err != nil {
+		return nil
+	}
+	defer lister.Stop()
+
+	out := []string{}
+	for key := range lister.Keys() {
+		if domain, ok := domainFromKey(key); ok {
+			out = append(out, domain)
+		}
+	}
+	return out
+}
+
+func (b *JetStreamLockBackend) withStats(domain string, fn func(*LockDomainStats)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	st, ok := b.stats[domain]
+	if !ok {
+		st = &LockDomainStats{}
+		b.stats[domain] = st
+	}
+	fn(st)
+}
+
+func (b *JetStreamLockBackend) clamp(lease time.Duration) time.Duration {
+	lease = clampLease(lease)
+	if b.cfg.BucketTTL > 0 && lease > b.cfg.BucketTTL {
+		return b.cfg.BucketTTL
+	}
+	return lease
+}
+
+func checkGrant(grant LockGrant, agentID string, token uint64) error {
+	if grant.Holder != agentID {
+		if token != 0 {
+			return ErrStaleToken
+		}
+		return ErrLockNotHeld
+	}
+	if grant.Token != token {
+		return ErrStaleToken
+	}
+	if time.Now().After(grant.ExpiresAt) {
+		return ErrLockExpired
+	}
+	return nil
+}
+
+func encodeRecord(grant LockGrant, keepToken bool) []byte {
+	rec := lockRecord{Holder: grant.Holder, ExpiresAt: grant.ExpiresAt.UnixNano()}
+	if keepToken {
+		rec.Token = grant.Token
+	}
+	data, _ := json.Marshal(rec)
+	return data
+}
+
+// KV keys only allow [-/_=.a-zA-Z0-9], so domains such as "goal:compile" are encoded.
+const lockKeyPrefix = "lock."
+
+func lockKey(domain string) string {
+	return lockKeyPrefix + base64.RawURLEncoding.EncodeToString([]byte(domain))
+}
+
+func domainFromKey(key string) (string, bool) {
+	if !strings.HasPrefix(key, lockKeyPrefix) {
+		return "", false
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(key, lockKeyPrefix))
+	if err != nil {
+		return "", false
+	}
+	return string(raw), true
+}
@@ -0,0 +1,197 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+// newJetStreamPair returns two lock backends on separate connections, as two
+// vextra controllers sharing one NATS cluster would have.
+func newJetStreamPair(t *testing.T, cfg JetStreamLockConfig) (*JetStreamLockBackend, *JetStreamLockBackend) {
+	t.Helper()
+	nc := runEmbeddedNATS(t)
+	nc2, err := nats.Connect(nc.ConnectedUrl())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(nc2.Close)
+
+	a, err := NewJetStreamLockBackend(nc, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := NewJetStreamLockBackend(nc2, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a, b
+}
+
+func TestJetStreamLockSplitBrain(t *testing.T) {
+	ctrlA, ctrlB := newJetStreamPair(t, DefaultJetStreamLockConfig())
+
+	// Agents race for the same domain through both controllers at once.
+	var mu sync.Mutex
+	var winners []LockGrant
+	var wg sync.WaitGroup
+	for i, backend := range []*JetStreamLockBackend{ctrlA, ctrlB, ctrlA, ctrlB, ctrlA, ctrlB} {
+		wg.Add(1)
+		go func(id string, backend *JetStreamLockBackend) {
+			defer wg.Done()
+			if grant, ok := backend.AcquireLock("goal:compile", id, LockTTL); ok {
+				mu.Lock()
+				winners = append(winners, grant)
+				mu.Unlock()
+			}
+		}(string(rune('a'+i)), backend)
+	}
+	wg.Wait()
+
+	if len(winners) != 1 {
+		t.Fatalf("Expected exactly one global holder, got %+v", winners)
+	}
+	winner := winners[0]
+
+	// Both controllers agree on the holder and accept its token.
+	for name, backend := range map[string]*JetStreamLockBackend{"A": ctrlA, "B": ctrlB} {
+		holder, ok := backend.LockHolder("goal:compile")
+		if !ok || holder.Holder != winner.Holder || holder.Token != winner.Token {
+			t.Errorf("Controller %s sees holder %+v, want %+v", name, holder, winner)
+		}
+		if err := backend.ValidateToken("goal:compile", winner.Holder, winner.Token); err != nil {
+			t.Errorf("Controller %s rejected the winning token: %v", name, err)
+		}
+	}
+
+	// Unrelated domains are independent across controllers too.
+	if _, ok := ctrlB.AcquireLock("goal:research", "z", LockTTL); !ok {
+		t.Error("Expected goal:research to be free")
+	}
+}
+
+func TestJetStreamLockStaleHolderAcrossControllers(t *testing.T) {
+	ctrlA, ctrlB := newJetStreamPair(t, DefaultJetStreamLockConfig())
+
+	stale, ok := ctrlA.AcquireLock("", "sleepy", 100*time.Millisecond)
+	if !ok {
+		t.Fatal("Expected sleepy to acquire through controller A")
+	}
+	if _, ok := ctrlB.AcquireLock("", "eager", LockTTL); ok {
+		t.Fatal("Expected eager denied while the lease is live")
+	}
+
+	time.Sleep(150 * time.Millisecond)
+	fresh, ok := ctrlB.AcquireLock("", "eager", LockTTL)
+	if !ok || fresh.Token <= stale.Token {
+		t.Fatalf("Expected eager to reclaim with a newer token than %d, got %+v", stale.Token, fresh)
+	}
+
+	// The stalled holder wakes up on controller A.
+	if err := ctrlA.ValidateToken("", "sleepy", stale.Token); !errors.Is(err, ErrStaleToken) {
+		t.Errorf("Expected ErrStaleToken, got %v", err)
+	}
+	if _, err := ctrlA.RenewLock("", "sleepy", stale.Token, LockTTL); !errors.Is(err, ErrStaleToken) {
+		t.Errorf("Expected stale renewal to fail, got %v", err)
+	}
+	if err := ctrlA.ReleaseFencedLock("", "sleepy", stale.Token); !errors.Is(err, ErrStaleToken) {
+		t.Errorf("Expected stale release to fail, got %v", err)
+	}
+
+	renewed, err := ctrlA.RenewLock("", "eager", fresh.Token, LockTTL)
+	if err != nil || renewed.Token != fresh.Token {
+		t.Errorf("Renewal through the other controller should keep the token: %+v (%v)", renewed, err)
+	}
+	if st := ctrlB.LockStats()[DefaultLockDomain]; st.Reclaims != 1 || st.Holder != "eager" {
+		t.Errorf("Unexpected controller B stats: %+v", st)
+	}
+}
+
+func TestJetStreamLockBucketTTLExpiry(t *testing.T) {
+	cfg := DefaultJetStreamLockConfig()
+	cfg.BucketTTL = time.Second
+	ctrlA, ctrlB := newJetStreamPair(t, cfg)
+
+	// The requested lease is capped by the bucket TTL.
+	first, ok := ctrlA.AcquireLock("gpu:0", "crashed", time.Minute)
+	if !ok || time.Until(first.ExpiresAt) > cfg.BucketTTL {
+		t.Fatalf("Expected a lease capped at %v, got %+v", cfg.BucketTTL, first)
+	}
+
+	// The holder's controller never renews; the key ages out of the bucket.
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, held := ctrlB.LockHolder("gpu:0"); !held {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Lock key did not expire from the bucket")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	next, ok := ctrlB.AcquireLock("gpu:0", "survivor", LockTTL)
+	if !ok || next.Token <= first.Token {
+		t.Errorf("Expected survivor granted a newer token than %d, got %+v", first.Token, next)
+	}
+}
+
+func TestJetStreamLockWaitAcrossControllers(t *testing.T) {
+	ctrlA, ctrlB := newJetStreamPair(t, DefaultJetStreamLockConfig())
+
+	held, _ := ctrlA.AcquireLock("", "holder", LockTTL)
+
+	short, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := ctrlB.WaitForLock(short, "", "impatient", 0, LockTTL); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected DeadlineExceeded, got %v", err)
+	}
+
+	done := make(chan LockGrant, 1)
+	go func() {
+		grant, err := ctrlB.WaitForLock(context.Background(), "", "waiter", 0, LockTTL)
+		if err != nil {
+			t.Error(err)
+		}
+		done <- grant
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	if err := ctrlA.ReleaseFencedLock("", "holder", held.Token); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case grant := <-done:
+		if grant.Holder != "waiter" || grant.Token <= held.Token {
+			t.Errorf("Unexpected hand-off grant: %+v", grant)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Waiter on controller B was not woken by the release on controller A")
+	}
+
+	if held := ctrlA.HeldDomains("waiter"); len(held) != 1 || held[0] != DefaultLockDomain {
+		t.Errorf("Expected controller A to see waiter holding %q, got %v", DefaultLockDomain, held)
+	}
+	ctrlA.ReleaseLock("waiter")
+	if _, ok := ctrlB.LockHolder(""); ok {
+		t.Error("ReleaseLock on controller A did not free the shared domain")
+	}
+}
+
+func TestArbiterWithJetStreamBackend(t *testing.T) {
+	ctrlA, ctrlB := newJetStreamPair(t, DefaultJetStreamLockConfig())
+	first, second := NewArbiter(), NewArbiter()
+	first.UseLockBackend(ctrlA)
+	second.UseLockBackend(ctrlB)
+
+	if !first.RequestStrategicLock("boss-a") {
+		t.Fatal("Expected boss-a to become the strategic boss")
+	}
+	if second.RequestStrategicLock("boss-b") {
+		t.Error("Too many bosses: a second controller granted the same global lock")
+	}
+}
@@ -0,0 +1,315 @@
+package controller
+
+import (
+	"context"
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+// lockWaiter is an agent blocked in WaitForLock.
+type lockWaiter struct {
+	agentID  string
+	priority int32
+	lease    time.Duration
+	since    time.Time
+	grant    *LockGrant    // Set under MemoryLockBackend.mu when the lease is handed over.
+	ready    chan struct{} // Closed once grant is set.
+}
+
+// lockDomain is one independently lockable goal or resource.
+type lockDomain struct {
+	name    string
+	grant   LockGrant // Current holder; empty Holder when free.
+	waiters []*lockWaiter
+	stats   LockDomainStats
+}
+
+// MemoryLockBackend keeps lock domains in process memory. It is the default
+// LockBackend and is only correct while a single controller arbitrates the mesh.
+type MemoryLockBackend struct {
+	mu        sync.Mutex
+	domains   map[string]*lockDomain // Domain -> lease and wait queue.
+	lastToken uint64
+	now       func() time.Time
+}
+
+func NewMemoryLockBackend() *MemoryLockBackend {
+	return &MemoryLockBackend{
+		domains: make(map[string]*lockDomain),
+		now:     time.Now,
+	}
+}
+
+// AcquireLock grants the domain lock to agentID for lease if it is free,
+// expired, or already held by agentID (in which case the lease is extended and
+// the token is kept). It never jumps ahead of queued waiters. On denial it
+// returns the current holder's grant.
+func (m *MemoryLockBackend) AcquireLock(domain, agentID string, lease time.Duration) (LockGrant, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	d := m.domainLocked(domain)
+	lease = clampLease(lease)
+	now := m.now()
+	m.promoteLocked(d, now)
+
+	if d.grant.Holder == agentID {
+		d.grant.ExpiresAt = now.Add(lease)
+		return d.grant, true
+	}
+	if d.grant.Holder == "" {
+		return m.grantLocked(d, agentID, lease, now), true
+	}
+
+	d.stats.Contended++
+	log.Printf("[Arbiter] ⚠️ Lock %q denied to %s (Held by %s)", d.name, agentID, d.grant.Holder)
+	return d.grant, false
+}
+
+// WaitForLock queues agentID on domain and blocks until the lease is granted
+// or ctx is done. Higher priority waiters are served first; equal priorities
+// are served in arrival order.
+func (m *MemoryLockBackend) WaitForLock(ctx context.Context, domain, agentID string, priority int32, lease time.Duration) (LockGrant, error) {
+	domain = normalizeDomain(domain)
+	if grant, ok := m.AcquireLock(domain, agentID, lease); ok {
+		return grant, nil
+	}
+
+	m.mu.Lock()
+	d := m.domainLocked(domain)
+	w := &lockWaiter{
+		agentID:  agentID,
+		priority: priority,
+		lease:    clampLease(lease),
+		since:    m.now(),
+		ready:    make(chan struct{}),
+	}
+	d.enqueue(w)
+	log.Printf("[Arbiter] ⏳ %s queued for lock %q (position %d)", agentID, domain, d.position(w)+1)
+	m.mu.Unlock()
+
+	for {
+		// Wake when the current lease would lapse so a stalled holder cannot block the queue.
+		m.mu.Lock()
+		expiry := d.grant.ExpiresAt.Sub(m.now())
+		m.mu.Unlock()
+		timer := time.NewTimer(max(expiry, time.Millisecond))
+
+		select {
+		case <-w.ready:
+			timer.Stop()
+			return *w.grant, nil
+		case <-timer.C:
+			m.mu.Lock()
+			m.promoteLocked(d, m.now())
+			m.mu.Unlock()
+		case <-ctx.Done():
+			timer.Stop()
+			m.mu.Lock()
+			if w.grant != nil {
+				// Granted while giving up: pass the lease straight on.
+				m.releaseLocked(d, w.grant.Token)
+			} else {
+				d.remove(w)
+			}
+			d.stats.Timeouts++
+			m.mu.Unlock()
+			return LockGrant{}, ctx.Err()
+		}
+	}
+}
+
+// RenewLock extends the lease of a live grant. It fails if token is not the
+// current fencing token or the lease has already lapsed.
+func (m *MemoryLockBackend) RenewLock(domain, agentID string, token uint64, lease time.Duration) (LockGrant, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	d := m.domainLocked(domain)
+	if err := m.checkTokenLocked(d, agentID, token); err != nil {
+		return LockGrant{}, err
+	}
+	d.grant.ExpiresAt = m.now().Add(clampLease(lease))
+	return d.grant, nil
+}
+
+// ValidateToken reports whether token is the live fencing token held by agentID on domain.
+func (m *MemoryLockBackend) ValidateToken(domain, agentID string, token uint64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.checkTokenLocked(m.domainLocked(domain), agentID, token)
+}
+
+// ReleaseLock frees every domain held by agentID.
+func (m *MemoryLockBackend) ReleaseLock(agentID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, d := range m.domains {
+		if d.grant.Holder == agentID {
+			log.Printf("[Arbiter] 🔓 Lock %q released by %s", d.name, agentID)
+			m.releaseLocked(d, d.grant.Token)
+		}
+	}
+}
+
+// ReleaseFencedLock frees the lock only if token is still the live fencing token,
+// so a stalled holder cannot release a lock that was reclaimed and re-granted.
+func (m *MemoryLockBackend) ReleaseFencedLock(domain, agentID string, token uint64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	d := m.domainLocked(domain)
+	if d.grant.Holder != agentID || d.grant.Token != token {
+		return ErrStaleToken
+	}
+	log.Printf("[Arbiter] 🔓 Lock %q released by %s (token %d)", d.name, agentID, token)
+	m.releaseLocked(d, token)
+	return nil
+}
+
+// LockHolder returns the current grant on domain, if any.
+func (m *MemoryLockBackend) LockHolder(domain string) (LockGrant, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	d, ok := m.domains[normalizeDomain(domain)]
+	if !ok || d.grant.Holder == "" || m.now().After(d.grant.ExpiresAt) {
+		return LockGrant{}, false
+	}
+	return d.grant, true
+}
+
+// HeldDomains lists the domains on which agentID holds a live lease.
+func (m *MemoryLockBackend) HeldDomains(agentID string) []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := m.now()
+	held := []string{}
+	for name, d := range m.domains {
+		if d.grant.Holder == agentID && !now.After(d.grant.ExpiresAt) {
+			held = append(held, name)
+		}
+	}
+	sort.Strings(held)
+	return held
+}
+
+// LockStats returns contention metrics for every domain that has been used.
+func (m *MemoryLockBackend) LockStats() map[string]LockDomainStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make(map[string]LockDomainStats, len(m.domains))
+	for name, d := range m.domains {
+		st := d.stats
+		st.Holder = d.grant.Holder
+		st.QueueDepth = len(d.waiters)
+		out[name] = st
+	}
+	return out
+}
+
+func (m *MemoryLockBackend) domainLocked(domain string) *lockDomain {
+	domain = normalizeDomain(domain)
+	d, ok := m.domains[domain]
+	if !ok {
+		d = &lockDomain{name: domain}
+		m.domains[domain] = d
+	}
+	return d
+}
+
+// grantLocked hands a fresh lease with a new fencing token to agentID.
+func (m *MemoryLockBackend) grantLocked(d *lockDomain, agentID string, lease time.Duration, now time.Time) LockGrant {
+	m.lastToken++
+	d.grant = LockGrant{Domain: d.name, Holder: agentID, Token: m.lastToken, ExpiresAt: now.Add(lease)}
+	d.stats.Grants++
+	log.Printf("[Arbiter] 🔑 Lock %q granted to %s (token %d, lease %v)", d.name, agentID, d.grant.Token, lease)
+	return d.grant
+}
+
+// promoteLocked reclaims an expired lease and, if the domain is free, hands it
+// to the head of the wait queue.
+func (m *MemoryLockBackend) promoteLocked(d *lockDomain, now time.Time) {
+	if d.grant.Holder != "" && now.After(d.grant.ExpiresAt) {
+		log.Printf("[Arbiter] ⚠️ Reclaiming stale lock %q from %s (token %d expired at %s)",
+			d.name, d.grant.Holder, d.grant.Token, d.grant.ExpiresAt.Format(time.RFC3339))
+		d.grant = LockGrant{}
+		d.stats.Reclaims++
+	}
+	if d.grant.Holder != "" || len(d.waiters) == 0 {
+		return
+	}
+
+	w := d.waiters[0]
+	d.waiters = d.waiters[1:]
+	grant := m.grantLocked(d, w.agentID, w.lease, now)
+	d.stats.TotalWait += now.Sub(w.since)
+	w.grant = &grant
+	close(w.ready)
+}
+
+func (m *MemoryLockBackend) releaseLocked(d *lockDomain, token uint64) {
+	if d.grant.Token != token {
+		return
+	}
+	d.grant = LockGrant{}
+	m.promoteLocked(d, m.now())
+}
+
+func (m *MemoryLockBackend) checkTokenLocked(d *lockDomain, agentID string, token uint64) error {
+	if d.grant.Holder != agentID {
+		if token != 0 && token <= m.lastToken {
+			return ErrStaleToken
+		}
+		return ErrLockNotHeld
+	}
+	if d.grant.Token != token {
+		return ErrStaleToken
+	}
+	if m.now().After(d.grant.ExpiresAt) {
+		return ErrLockExpired
+	}
+	return nil
+}
+
+// enqueue inserts w behind every waiter of equal or higher priority.
+func (d *lockDomain) enqueue(w *lockWaiter) {
+	i := sort.Search(len(d.waiters), func(i int) bool { return d.waiters[i].priority < w.priority })
+	d.waiters = append(d.waiters, nil)
+	copy(d.waiters[i+1:], d.waiters[i:])
+	d.waiters[i] = w
+	d.stats.MaxQueueDepth = max(d.stats.MaxQueueDepth, len(d.waiters))
+}
+
+func (d *lockDomain) remove(w *lockWaiter) {
+	if i := d.position(w); i >= 0 {
+		d.waiters = append(d.waiters[:i], d.waiters[i+1:]...)
+	}
+}
+
+func (d *lockDomain) position(w *lockWaiter) int {
+	for i, x := range d.waiters {
+		if x == w {
+			return i
+		}
+	}
+	return -1
+}
+
+func normalizeDomain(domain string) string {
+	if domain == "" {
+		return DefaultLockDomain
+	}
+	return domain
+}
+
+func clampLease(lease time.Duration) time.Duration {
+	if lease <= 0 {
+		return LockTTL
+	}
+	if lease > MaxLockLease {
+		return MaxLockLease
+	}
+	return lease
+}
@@ -3,11 +3,35 @@ package controller
 import (
 	"fmt"
 	"log"
+	"math"
 	"sync"
+	"time"
 
 	pb "github.com/groovy-byte/agent-mesh-core/proto"
+	"google.golang.org/protobuf/proto"
 )
 
+// Liveness is the heartbeat-derived health of an agent.
+type Liveness int
+
+const (
+	LivenessAlive Liveness = iota
+	LivenessSuspect
+	LivenessDead
+)
+
+func (l Liveness) String() string {
+	switch l {
+	case LivenessAlive:
+		return "ALIVE"
+	case LivenessSuspect:
+		return "SUSPECT"
+	case LivenessDead:
+		return "DEAD"
+	}
+	return fmt.Sprintf("Liveness(%d)", int(l))
+}
+
 // AgentInfo represents an active agent in the mesh.
 type AgentInfo struct {
 	ID            string
@@ -19,8 +43,12 @@ type AgentInfo struct {
 	TotalTokens   uint32
 	RequestCount  uint32
 	ToolCalls     uint32
-	FailedTasks   []string // Stores the last 5 failed task names.
-	MaxThroughput float32  // Peak GB/s throughput observed.
+	FailedTasks   []string        // Stores the last 5 failed task names.
+	MaxThroughput float32         // Peak GB/s throughput observed.
+	Rejected      uint32          // Inference requests refused by admission control.
+	LastSeen      time.Time       // Time of the last handshake, heartbeat or RPC.
+	CurrentLoad   *pb.OSResources // Load reported by the most recent heartbeat.
+	Liveness      Liveness
 }
 
 // MeshRegistry manages the active agents and their communication neighborhoods.
@@ -28,12 +56,66 @@ type MeshRegistry struct {
 	mu                 sync.RWMutex
 	agents             map[string]*AgentInfo
 	contributionMatrix map[string]map[string]float64 // Source -> {Target: Score}
+	store              MeshStore
+	dsbo               DSBOConfig
+	selector           *NeighborSelector
+	onEvict            []func(agentID string)
 }
 
 func NewMeshRegistry() *MeshRegistry {
 	return &MeshRegistry{
 		agents:             make(map[string]*AgentInfo),
 		contributionMatrix: make(map[string]map[string]float64),
+		store:              NewMemoryStore(),
+		dsbo:               DefaultDSBOConfig(),
+		selector:           NewNeighborSelector(DefaultDSBOConfig()),
+	}
+}
+
+// NewMeshRegistryWithStore creates a registry that writes through to store and
+// reloads the agents and contribution matrix it already holds.
+func NewMeshRegistryWithStore(store MeshStore) (*MeshRegistry, error) {
+	agents, err := store.LoadAgents()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load agents: %w", err)
+	}
+	contributions, err := store.LoadContributions()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load contribution matrix: %w", err)
+	}
+
+	r := &MeshRegistry{
+		agents:             make(map[string]*AgentInfo, len(agents)),
+		contributionMatrix: contributions,
+		store:              store,
+		dsbo:               DefaultDSBOConfig(),
+		selector:           NewNeighborSelector(DefaultDSBOConfig()),
+	}
+	now := time.Now()
+	for _, agent := range agents {
+		// Restored agents get a fresh liveness window to resume heartbeats.
+		agent.LastSeen = now
+		agent.Liveness = LivenessAlive
+		r.agents[agent.ID] = agent
+	}
+	if len(agents) > 0 {
+		log.Printf("[Mesh] ♻️ Restored %d agents from persistent store", len(agents))
+	}
+	return r, nil
+}
+
+// ConfigureDSBO replaces the neighbor selection policy and resets its bandit state.
+func (r *MeshRegistry) ConfigureDSBO(cfg DSBOConfig) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.dsbo = cfg
+	r.selector = NewNeighborSelector(cfg)
+}
+
+// persistLocked writes an agent through to the store. Callers must hold r.mu.
+func (r *MeshRegistry) persistLocked(agent *AgentInfo) {
+	if err := r.store.SaveAgent(agent); err != nil {
+		log.Printf("[Mesh] ⚠️ Failed to persist agent %s: %v", agent.ID, err)
 	}
 }
 
@@ -50,13 +132,16 @@ func (r *MeshRegistry) RecordContribution(sourceID, targetID string, score float
 	if r.contributionMatrix[sourceID][targetID] > 1.0 {
 		r.contributionMatrix[sourceID][targetID] = 1.0
 	}
+	if err := r.store.SaveContribution(sourceID, targetID, r.contributionMatrix[sourceID][targetID]); err != nil {
+		log.Printf("[Mesh] ⚠️ Failed to persist contribution %s -> %s: %v", sourceID, targetID, err)
+	}
 }
 
 // GetContributionDetail returns how a specific agent influenced others.
 func (r *MeshRegistry) GetContributionDetail(id string) map[string]float64 {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
-	
+
 	detail := make(map[string]float64)
 	if targets, ok := r.contributionMatrix[id]; ok {
 		for target, score := range targets {
@@ -74,30 +159,9 @@ func (r *MeshRegistry) GetAgent(id string) (AgentInfo, bool) {
 	if !ok {
 		return AgentInfo{}, false
 	}
-	
+
 	// Create a deep copy to prevent race conditions.
-	failedTasks := make([]string, len(info.FailedTasks))
-	copy(failedTasks, info.FailedTasks)
-	
-	caps := make([]string, len(info.Capabilities))
-	copy(caps, info.Capabilities)
-
-	neighbors := make([]string, len(info.Neighbors))
-	copy(neighbors, info.Neighbors)
-
-	return AgentInfo{
-		ID:            info.ID,
-		Role:          info.Role,
-		Capabilities:  caps,
-		Neighbors:     neighbors,
-		UtilityScore:  info.UtilityScore,
-		TotalLatency:  info.TotalLatency,
-		TotalTokens:   info.TotalTokens,
-		RequestCount:  info.RequestCount,
-		ToolCalls:     info.ToolCalls,
-		FailedTasks:   failedTasks,
-		MaxThroughput: info.MaxThroughput,
-	}, true
+	return *cloneAgentInfo(info), true
 }
 
 // RegisterAgent handles the initial "One-Hop" handshake and agent registration.
@@ -107,16 +171,8 @@ func (r *MeshRegistry) RegisterAgent(req *pb.HandshakeRequest) (*pb.HandshakeRes
 
 	log.Printf("[Mesh] Handshake received from Agent: %s", req.AgentId)
 
-	// Initial neighborhood selection via Distributed Slot-based Bayesian Optimization (DSBO) principle.
-	neighbors := []string{}
-	for id := range r.agents {
-		if id != req.AgentId {
-			neighbors = append(neighbors, id)
-			if len(neighbors) >= 2 { // Limit initial neighbors for evaluation.
-				break
-			}
-		}
-	}
+	// Initial neighborhood selection via Distributed Submodular Bandit Optimization (DSBO).
+	neighbors := r.selector.Select(req.AgentId, req.Capabilities, r.candidatesLocked(req.AgentId), r.dsbo.InitialNeighbors)
 
 	agent := &AgentInfo{
 		ID:            req.AgentId,
@@ -130,19 +186,27 @@ func (r *MeshRegistry) RegisterAgent(req *pb.HandshakeRequest) (*pb.HandshakeRes
 		ToolCalls:     0,
 		FailedTasks:   []string{},
 		MaxThroughput: 0,
+		LastSeen:      time.Now(),
+		Liveness:      LivenessAlive,
 	}
 	r.agents[req.AgentId] = agent
+	r.persistLocked(agent)
 
 	return &pb.HandshakeResponse{
-		SessionId: fmt.Sprintf("mesh_sess_%s", req.AgentId),
-		Approved:  true,
-		ResourceLimits: &pb.OSResources{
-			CpuUsagePercent:  75.0,
-			MemoryTotalBytes: 512 * 1024 * 1024,
-		},
+		SessionId:      fmt.Sprintf("mesh_sess_%s", req.AgentId),
+		Approved:       true,
+		ResourceLimits: HandshakeLimits(),
 	}, nil
 }
 
+// HandshakeLimits are the resource limits granted to every agent at handshake.
+func HandshakeLimits() *pb.OSResources {
+	return &pb.OSResources{
+		CpuUsagePercent:  75.0,
+		MemoryTotalBytes: 512 * 1024 * 1024,
+	}
+}
+
 // RecordTaskResult logs the outcome of an agent operation.
 func (r *MeshRegistry) RecordTaskResult(id string, success bool, taskName string, toolCount uint32) {
 	r.mu.Lock()
@@ -161,6 +225,7 @@ func (r *MeshRegistry) RecordTaskResult(id string, success bool, taskName string
 			agent.FailedTasks = agent.FailedTasks[:5]
 		}
 	}
+	r.persistLocked(agent)
 }
 
 // RecordMetrics updates the performance tracking for an agent.
@@ -174,6 +239,17 @@ func (r *MeshRegistry) RecordMetrics(id string, latency float32, tokens uint32,
 		if throughput > agent.MaxThroughput {
 			agent.MaxThroughput = throughput
 		}
+		r.persistLocked(agent)
+	}
+}
+
+// RecordRejection counts an inference request refused by admission control.
+func (r *MeshRegistry) RecordRejection(id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if agent, ok := r.agents[id]; ok {
+		agent.Rejected++
+		r.persistLocked(agent)
 	}
 }
 
@@ -205,7 +281,10 @@ func (r *MeshRegistry) GetStatsSummary() []AgentStats {
 	return stats
 }
 
-// ReevaluateNeighbors implements DSBO and Proactive Healing signals.
+// ReevaluateNeighbors implements DSBO and Proactive Healing signals. Each current
+// neighbor is credited with the round's reward (context novelty plus the VoC it
+// contributed), then the neighborhood is re-selected: grown on novel context,
+// pruned when utility collapses, and otherwise allowed to swap weak neighbors.
 func (r *MeshRegistry) ReevaluateNeighbors(agentID string, novelContext bool, arbiter *Arbiter) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
@@ -216,8 +295,10 @@ func (r *MeshRegistry) ReevaluateNeighbors(agentID string, novelContext bool, ar
 	}
 
 	// Update agent utility score based on context novelty.
+	novelty := 0.0
 	if novelContext {
 		agent.UtilityScore += 0.1
+		novelty = 1.0
 	} else {
 		agent.UtilityScore -= 0.05
 	}
@@ -228,12 +309,74 @@ func (r *MeshRegistry) ReevaluateNeighbors(agentID string, novelContext bool, ar
 		// Signal logic is handled in the main controller loop.
 	}
 
-	// DSBO Pruning Logic: Prune communication path if utility is low.
-	if agent.UtilityScore < 0.5 && len(agent.Neighbors) > 1 {
+	// Bandit feedback for the edges that were active this round.
+	for _, n := range agent.Neighbors {
+		reward := 0.5*novelty + 0.5*r.contributionMatrix[n][agentID]
+		r.selector.Record(agentID, n, reward)
+	}
+
+	k := len(agent.Neighbors)
+	switch {
+	case agent.UtilityScore < 0.5 && k > 1:
+		// DSBO Pruning Logic: shrink the neighborhood if utility is low.
 		log.Printf("[Mesh] Pruning communication path for %s due to low novelty", agentID)
-		agent.Neighbors = agent.Neighbors[:len(agent.Neighbors)-1]
-		agent.UtilityScore = 0.8 
+		k--
+		agent.UtilityScore = 0.8
+	case novelContext && k < r.dsbo.MaxNeighbors:
+		k++
+	}
+
+	neighbors := r.selector.Select(agentID, agent.Capabilities, r.candidatesLocked(agentID), k)
+	if len(neighbors) > len(agent.Neighbors) {
+		log.Printf("[Mesh] Expanding neighborhood for %s: %v", agentID, neighbors)
+	}
+	agent.Neighbors = neighbors
+	r.persistLocked(agent)
+}
+
+// candidatesLocked lists every other agent as a neighbor candidate with its
+// VoC toward agentID (the stronger direction). Callers must hold r.mu.
+func (r *MeshRegistry) candidatesLocked(agentID string) []NeighborCandidate {
+	candidates := make([]NeighborCandidate, 0, len(r.agents))
+	for id, other := range r.agents {
+		if id == agentID {
+			continue
+		}
+		voc := math.Max(r.contributionMatrix[id][agentID], r.contributionMatrix[agentID][id])
+		candidates = append(candidates, NeighborCandidate{ID: id, Capabilities: other.Capabilities, VoC: voc})
+	}
+	return candidates
+}
+
+// NeighborGraph returns the directed neighbor graph with per-edge bandit
+// statistics. An empty agentID returns the whole mesh.
+func (r *MeshRegistry) NeighborGraph(agentID string) map[string][]NeighborEdge {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	graph := make(map[string][]NeighborEdge)
+	for id, agent := range r.agents {
+		if agentID != "" && id != agentID {
+			continue
+		}
+		edges := make([]NeighborEdge, 0, len(agent.Neighbors))
+		for _, n := range agent.Neighbors {
+			target, ok := r.agents[n]
+			if !ok {
+				continue
+			}
+			voc := math.Max(r.contributionMatrix[n][id], r.contributionMatrix[id][n])
+			pulls, mean := r.selector.Stats(id, n)
+			edges = append(edges, NeighborEdge{
+				TargetID:   n,
+				Score:      r.selector.Score(id, agent.Capabilities, NeighborCandidate{ID: n, Capabilities: target.Capabilities, VoC: voc}),
+				Pulls:      pulls,
+				MeanReward: mean,
+			})
+		}
+		graph[id] = edges
 	}
+	return graph
 }
 
 func (r *MeshRegistry) UpdateRole(id string, role pb.AgentRole) {
@@ -241,5 +384,123 @@ func (r *MeshRegistry) UpdateRole(id string, role pb.AgentRole) {
 	defer r.mu.Unlock()
 	if agent, ok := r.agents[id]; ok {
 		agent.Role = role
+		r.persistLocked(agent)
+	}
+}
+
+// RecordHeartbeat refreshes an agent's last-seen time and reported load.
+// It returns false if the agent is not registered.
+func (r *MeshRegistry) RecordHeartbeat(hb *pb.Heartbeat, seenAt time.Time) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	agent, ok := r.agents[hb.AgentId]
+	if !ok {
+		return false
+	}
+	agent.touch(seenAt)
+	if hb.CurrentLoad != nil {
+		agent.CurrentLoad = proto.Clone(hb.CurrentLoad).(*pb.OSResources)
+	}
+	return true
+}
+
+// Touch refreshes an agent's last-seen time for any other sign of life,
+// such as an RPC, so agents that never publish heartbeats are not swept.
+// It returns false if the agent is not registered.
+func (r *MeshRegistry) Touch(id string, seenAt time.Time) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	agent, ok := r.agents[id]
+	if ok {
+		agent.touch(seenAt)
+	}
+	return ok
+}
+
+func (a *AgentInfo) touch(seenAt time.Time) {
+	if a.Liveness != LivenessAlive {
+		log.Printf("[Mesh] 💓 Agent %s recovered (%s -> ALIVE)", a.ID, a.Liveness)
+	}
+	a.LastSeen = seenAt
+	a.Liveness = LivenessAlive
+}
+
+// SweepLiveness marks agents SUSPECT or DEAD based on how many heartbeat
+// intervals they have missed, and evicts the dead ones. It returns the evicted IDs.
+func (r *MeshRegistry) SweepLiveness(now time.Time, cfg HeartbeatConfig) []string {
+	r.mu.Lock()
+
+	suspectAfter := time.Duration(cfg.SuspectAfter) * cfg.Interval
+	deadAfter := time.Duration(cfg.DeadAfter) * cfg.Interval
+
+	var dead []string
+	for id, agent := range r.agents {
+		silence := now.Sub(agent.LastSeen)
+		switch {
+		case silence >= deadAfter:
+			agent.Liveness = LivenessDead
+			dead = append(dead, id)
+		case silence >= suspectAfter:
+			if agent.Liveness != LivenessSuspect {
+				log.Printf("[Mesh] ⚠️ Agent %s is SUSPECT (silent for %v)", id, silence.Round(time.Millisecond))
+			}
+			agent.Liveness = LivenessSuspect
+		}
+	}
+
+	for _, id := range dead {
+		log.Printf("[Mesh] 💀 Evicting DEAD agent %s", id)
+		r.evictLocked(id)
+	}
+	hooks := r.onEvict
+	r.mu.Unlock()
+
+	for _, id := range dead {
+		for _, fn := range hooks {
+			fn(id)
+		}
+	}
+	return dead
+}
+
+// EvictAgent removes an agent from the registry and from every neighbor list.
+func (r *MeshRegistry) EvictAgent(id string) {
+	r.mu.Lock()
+	r.evictLocked(id)
+	hooks := r.onEvict
+	r.mu.Unlock()
+
+	for _, fn := range hooks {
+		fn(id)
+	}
+}
+
+// OnEvict registers fn to run after each eviction, by SweepLiveness or
+// EvictAgent, so state kept outside the registry can be dropped with the
+// agent. Hooks run outside the registry lock, in registration order.
+func (r *MeshRegistry) OnEvict(fn func(agentID string)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.onEvict = append(r.onEvict, fn)
+}
+
+func (r *MeshRegistry) evictLocked(id string) {
+	delete(r.agents, id)
+	r.selector.Forget(id)
+	if err := r.store.DeleteAgent(id); err != nil {
+		log.Printf("[Mesh] ⚠️ Failed to delete agent %s from store: %v", id, err)
+	}
+	for _, agent := range r.agents {
+		kept := agent.Neighbors[:0]
+		for _, n := range agent.Neighbors {
+			if n != id {
+				kept = append(kept, n)
+			}
+		}
+		if len(kept) != len(agent.Neighbors) {
+			agent.Neighbors = kept
+			r.persistLocked(agent)
+		}
 	}
 }
@@ -0,0 +1,263 @@
+package controller
+
+import (
+	"container/list"
+	"context"
+	"log"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/groovy-byte/agent-mesh-core/internal/backend"
+	pb "github.com/groovy-byte/agent-mesh-core/proto"
+	"google.golang.org/protobuf/proto"
+)
+
+// HardwarePathCache is reported for responses served from the cache.
+const HardwarePathCache = "CACHE"
+
+// Defaults for the response cache.
+const (
+	DefaultCacheEntries    = 1024
+	DefaultCacheTTL        = 10 * time.Minute
+	DefaultCacheSimilarity = 0.95
+)
+
+// CachePolicy controls InferenceController's response cache. Requests with
+// a temperature above MaxTemperature are never cached; the default of 0
+// keeps sampled completions out.
+type CachePolicy struct {
+	MaxEntries     int
+	TTL            time.Duration
+	MaxTemperature float32
+	// Similarity is the cosine similarity at which the embedding tier treats
+	// two prompts as the same question.
+	Similarity float64
+}
+
+func DefaultCachePolicy() CachePolicy {
+	return CachePolicy{
+		MaxEntries: DefaultCacheEntries,
+		TTL:        DefaultCacheTTL,
+		Similarity: DefaultCacheSimilarity,
+	}
+}
+
+// CacheStats counts response cache outcomes. Bypassed requests were not
+// cacheable (temperature too high).
+type CacheStats struct {
+	Hits         uint64 // Exact matches.
+	SemanticHits uint64 // Embedding-tier matches.
+	Misses       uint64
+	Bypassed     uint64
+	Evictions    uint64
+	Expirations  uint64
+	Entries      int
+}
+
+// cacheKey identifies a completion: the normalized prompt and the
+// parameters that change the output.
+type cacheKey struct {
+	prompt      string
+	temperature float32
+	maxTokens   uint32
+}
+
+type cacheEntry struct {
+	key       cacheKey
+	resp      *pb.InferenceResponse
+	embedding []float32
+	expires   time.Time
+}
+
+// ResponseCache is an exact-match LRU over completed responses with an
+// optional embedding-similarity tier for near-identical prompts.
+type ResponseCache struct {
+	mu       sync.Mutex
+	policy   CachePolicy
+	embedder backend.Embedder // nil disables the similarity tier.
+	lru      *list.List       // Front is most recently used; values are *cacheEntry.
+	items    map[cacheKey]*list.Element
+	stats    CacheStats
+	now      func() time.Time
+}
+
+// NewResponseCache builds a cache; embedder may be nil.
+func NewResponseCache(policy CachePolicy, embedder backend.Embedder) *ResponseCache {
+	if policy.MaxEntries <= 0 {
+		policy.MaxEntries = DefaultCacheEntries
+	}
+	if policy.Similarity <= 0 {
+		policy.Similarity = DefaultCacheSimilarity
+	}
+	return &ResponseCache{
+		policy:   policy,
+		embedder: embedder,
+		lru:      list.New(),
+		items:    make(map[cacheKey]*list.Element),
+		now:      time.Now,
+	}
+}
+
+// normalizePrompt collapses whitespace so prompts that differ only in
+// spacing or line breaks share an entry.
+func normalizePrompt(prompt string) string {
+	return strings.Join(strings.Fields(prompt), " ")
+}
+
+// Cacheable reports whether req may be served from or stored in the cache.
+func (c *ResponseCache) Cacheable(req *pb.InferenceRequest) bool {
+	return req.Temperature <= c.policy.MaxTemperature
+}
+
+// lookup is a pending cache read. Its embedding is reused by Put.
+type lookup struct {
+	key       cacheKey
+	embedding []float32
+}
+
+// Get returns a cached response for req, trying the exact tier and then
+// the embedding tier. The returned lookup is passed to Put on a miss.
+func (c *ResponseCache) Get(ctx context.Context, req *pb.InferenceRequest) (*pb.InferenceResponse, *lookup) {
+	l := &lookup{key: cacheKey{prompt: normalizePrompt(req.Prompt), temperature: req.Temperature, maxTokens: req.MaxTokens}}
+
+	c.mu.Lock()
+	if e := c.liveLocked(l.key); e != nil {
+		c.stats.Hits++
+		c.mu.Unlock()
+		return proto.Clone(e.resp).(*pb.InferenceResponse), l
+	}
+	c.mu.Unlock()
+
+	if c.embedder == nil {
+		c.recordMiss()
+		return nil, l
+	}
+	vec, err := c.embedder.Embed(ctx, l.key.prompt)
+	if err != nil {
+		log.Printf("[Cache] ⚠️ Embedding failed, skipping similarity tier: %v", err)
+		c.recordMiss()
+		return nil, l
+	}
+	l.embedding = vec
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e := c.similarLocked(l); e != nil {
+		c.stats.SemanticHits++
+		c.lru.MoveToFront(c.items[e.key])
+		return proto.Clone(e.resp).(*pb.InferenceResponse), l
+	}
+	c.stats.Misses++
+	return nil, l
+}
+
+// Bypass counts a request that was not cacheable.
+func (c *ResponseCache) Bypass() {
+	c.mu.Lock()
+	c.stats.Bypassed++
+	c.mu.Unlock()
+}
+
+func (c *ResponseCache) recordMiss() {
+	c.mu.Lock()
+	c.stats.Misses++
+	c.mu.Unlock()
+}
+
+// Put stores resp under the lookup that missed, evicting the least recently
+// used entry when full.
+func (c *ResponseCache) Put(l *lookup, resp *pb.InferenceResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e := &cacheEntry{
+		key:       l.key,
+		resp:      proto.Clone(resp).(*pb.InferenceResponse),
+		embedding: l.embedding,
+		expires:   c.now().Add(c.policy.TTL),
+	}
+	if el, ok := c.items[l.key]; ok {
+		el.Value = e
+		c.lru.MoveToFront(el)
+		return
+	}
+	c.items[l.key] = c.lru.PushFront(e)
+	for c.lru.Len() > c.policy.MaxEntries {
+		c.removeLocked(c.lru.Back())
+		c.stats.Evictions++
+	}
+}
+
+// Stats returns a snapshot of the counters.
+func (c *ResponseCache) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s := c.stats
+	s.Entries = c.lru.Len()
+	return s
+}
+
+// liveLocked returns the unexpired entry for key, dropping it if it expired.
+func (c *ResponseCache) liveLocked(key cacheKey) *cacheEntry {
+	el, ok := c.items[key]
+	if !ok {
+		return nil
+	}
+	e := el.Value.(*cacheEntry)
+	if c.expiredLocked(e) {
+		c.removeLocked(el)
+		c.stats.Expirations++
+		return nil
+	}
+	c.lru.MoveToFront(el)
+	return e
+}
+
+// similarLocked scans entries with the same parameters for the most similar
+// embedded prompt at or above the policy threshold.
+func (c *ResponseCache) similarLocked(l *lookup) *cacheEntry {
+	var best *cacheEntry
+	bestScore := c.policy.Similarity
+	for el := c.lru.Front(); el != nil; {
+		next := el.Next()
+		e := el.Value.(*cacheEntry)
+		if c.expiredLocked(e) {
+			c.removeLocked(el)
+			c.stats.Expirations++
+		} else if e.embedding != nil && e.key.temperature == l.key.temperature && e.key.maxTokens == l.key.maxTokens {
+			if score := cosine(e.embedding, l.embedding); score >= bestScore {
+				best, bestScore = e, score
+			}
+		}
+		el = next
+	}
+	return best
+}
+
+func (c *ResponseCache) expiredLocked(e *cacheEntry) bool {
+	return c.policy.TTL > 0 && c.now().After(e.expires)
+}
+
+func (c *ResponseCache) removeLocked(el *list.Element) {
+	delete(c.items, el.Value.(*cacheEntry).key)
+	c.lru.Remove(el)
+}
+
+// cosine returns the cosine similarity of a and b, or 0 when they cannot be
+// compared.
+func cosine(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / math.Sqrt(na*nb)
+}
@@ -0,0 +1,76 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/groovy-byte/agent-mesh-core/internal/backend"
+	pb "github.com/groovy-byte/agent-mesh-core/proto"
+)
+
+func TestResponseCacheExactTier(t *testing.T) {
+	c := NewResponseCache(CachePolicy{MaxEntries: 2, TTL: time.Minute}, nil)
+	now := time.Unix(1000, 0)
+	c.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	req := &pb.InferenceRequest{Prompt: "what is  the\\nL3 size?", MaxTokens: 16}
+	if hit, l := c.Get(ctx, req); hit != nil {
+		t.Fatal("Expected a miss on an empty cache")
+	} else {
+		c.Put(l, &pb.InferenceResponse{Text: "32MB", TokensUsed: 7})
+	}
+
+	// Whitespace differences share an entry; max_tokens does not.
+	hit, _ := c.Get(ctx, &pb.InferenceRequest{Prompt: " what is the\\nL3   size?", MaxTokens: 16})
+	if hit == nil || hit.Text != "32MB" {
+		t.Errorf("Expected a normalized hit, got %+v", hit)
+	}
+	if hit, _ := c.Get(ctx, &pb.InferenceRequest{Prompt: "what is the\\nL3 size?", MaxTokens: 32}); hit != nil {
+		t.Error("Expected a different max_tokens to miss")
+	}
+
+	if c.Cacheable(&pb.InferenceRequest{Temperature: 0.7}) || !c.Cacheable(&pb.InferenceRequest{}) {
+		t.Error("Expected only temperature-0 requests to be cacheable by default")
+	}
+
+	// Entries expire after the TTL.
+	now = now.Add(2 * time.Minute)
+	if hit, _ := c.Get(ctx, req); hit != nil {
+		t.Error("Expected the entry to expire")
+	}
+
+	// The least recently used entry is evicted when full.
+	for _, p := range []string{"a", "b", "c"} {
+		_, l := c.Get(ctx, &pb.InferenceRequest{Prompt: p})
+		c.Put(l, &pb.InferenceResponse{Text: p})
+	}
+	if hit, _ := c.Get(ctx, &pb.InferenceRequest{Prompt: "a"}); hit != nil {
+		t.Error("Expected the oldest entry to be evicted")
+	}
+
+	s := c.Stats()
+	if s.Hits != 1 || s.Evictions != 1 || s.Expirations != 1 || s.Entries != 2 {
+		t.Errorf("Unexpected stats: %+v", s)
+	}
+}
+
+func TestResponseCacheSemanticTier(t *testing.T) {
+	c := NewResponseCache(CachePolicy{MaxEntries: 8, Similarity: 0.85}, backend.NewFake("embed"))
+	ctx := context.Background()
+
+	_, l := c.Get(ctx, &pb.InferenceRequest{Prompt: "Summarize the debate between the two agents about GPU routing"})
+	c.Put(l, &pb.InferenceResponse{Text: "summary"})
+
+	hit, _ := c.Get(ctx, &pb.InferenceRequest{Prompt: "summarize the debate between the two agents about gpu routing please"})
+	if hit == nil || hit.Text != "summary" {
+		t.Errorf("Expected a near-identical prompt to hit, got %+v", hit)
+	}
+	if hit, _ := c.Get(ctx, &pb.InferenceRequest{Prompt: "list every NUMA node"}); hit != nil {
+		t.Errorf("Expected an unrelated prompt to miss, got %+v", hit)
+	}
+	if s := c.Stats(); s.SemanticHits != 1 || s.Misses != 2 {
+		t.Errorf("Unexpected stats: %+v", s)
+	}
+}
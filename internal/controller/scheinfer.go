@@ -2,9 +2,13 @@ package controller
 
 import "C"
 import (
+	"fmt"
 	"log"
 	"runtime"
-	"fmt"
+	"sync"
+	"time"
+
+	"github.com/groovy-byte/agent-mesh-core/internal/topology"
 )
 
 var globalScheduler *ScheInfer
@@ -14,12 +18,17 @@ func InitializeGlobalScheduler(l3Size uint64, gpuName string, computeCap int, av
 	globalScheduler = NewScheInfer(l3Size, gpuName, computeCap, avx512)
 }
 
+// SetGlobalScheduler shares an existing scheduler with the C backend.
+func SetGlobalScheduler(s *ScheInfer) {
+	globalScheduler = s
+}
+
 //export scheinfer_route_task
 func scheinfer_route_task(dataSizeBytes uint64) *C.char {
 	if globalScheduler == nil {
 		return C.CString("CPU_AVX2")
 	}
-	provider := globalScheduler.RouteTask(dataSizeBytes)
+	provider, _ := globalScheduler.RouteTask(dataSizeBytes)
 	return C.CString(provider)
 }
 
@@ -30,6 +39,16 @@ type ScheInfer struct {
 	hasVulkan   bool
 	hasAvx512   bool
 	gpuName     string
+	cost        *CostModel
+	profile     *topology.HardwareProfile
+	gpuVRAM     uint64
+
+	mu      sync.RWMutex
+	plan    *PartitionPlan
+	gpus    []topology.GPUDevice
+	policy  LoadPolicy
+	load    map[string]*ProviderLoad // Keyed by placement, e.g. "GPU_CUDA:1".
+	network NetworkCost
 }
 
 func NewScheInfer(l3Size uint64, gpuName string, computeCap int, avx512 bool) *ScheInfer {
@@ -38,49 +57,141 @@ func NewScheInfer(l3Size uint64, gpuName string, computeCap int, avx512 bool) *S
 		gpuName:     gpuName,
 		hasAvx512:   avx512,
 		// Ampere (7.0+) or better preferred for CUDA path
-		hasCuda:     gpuName != "" && computeCap >= 70,
+		hasCuda: gpuName != "" && computeCap >= 70,
 		// Pascal/Turing fallback to Vulkan
-		hasVulkan:   gpuName != "" && computeCap >= 60 && computeCap < 70,
+		hasVulkan: gpuName != "" && computeCap >= 60 && computeCap < 70,
+		cost:      NewCostModel(l3Size, DefaultPCIeGBs),
+		load:      make(map[string]*ProviderLoad),
+		network:   NetworkCost{GBs: DefaultNetworkGBs, RTT: DefaultNetworkRTT},
 	}
 }
 
-// RouteTask determines the optimal execution provider for a given tensor size.
-func (s *ScheInfer) RouteTask(dataSizeBytes uint64) string {
-	// If the data fits within the CPU's L3 cache, route to CPU to avoid PCIe transfer overhead.
-	if dataSizeBytes < s.l3CacheSize {
-		log.Printf("[ScheInfer] Cache-Resident Task (%d KB): Routing to CPU AVX2", dataSizeBytes/1024)
-		return "CPU_AVX2"
+// NewScheInferFromProfile builds a scheduler from a probed host topology.
+// When no L3 cache was detected, fallbackL3 is used instead.
+func NewScheInferFromProfile(p topology.HardwareProfile, fallbackL3 uint64) *ScheInfer {
+	l3Size := p.L3CacheBytes
+	if l3Size == 0 {
+		l3Size = fallbackL3
+	}
+	s := NewScheInfer(l3Size, p.GPUName, p.GPUComputeCap, p.HasAVX512)
+	s.profile = &p
+	s.gpuVRAM = p.GPUVRAMBytes
+	switch {
+	case len(p.GPUs) > 0:
+		s.useGPUs(p.GPUs)
+	case p.GPUName != "":
+		s.useGPUs([]topology.GPUDevice{{Name: p.GPUName, VRAMBytes: p.GPUVRAMBytes, FreeBytes: p.GPUVRAMBytes, ComputeCap: p.GPUComputeCap}})
 	}
+	return s
+}
+
+// Providers lists the execution providers available on this node, in tie-break order.
+func (s *ScheInfer) Providers() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.providersLocked()
+}
 
-	// For larger tensors, prefer a GPU if an appropriate one is available.
+func (s *ScheInfer) providersLocked() []string {
+	providers := []string{ProviderCPUAVX2}
 	if s.hasCuda {
-		log.Printf("[ScheInfer] High-Throughput Task (%d MB): Routing to NVIDIA CUDA (Ampere+)", dataSizeBytes/(1024*1024))
-		return "GPU_CUDA"
+		providers = append(providers, ProviderGPUCUDA)
 	}
-
 	if s.hasVulkan {
-		log.Printf("[ScheInfer] Legacy GPU Task (%d MB): Routing to Vulkan", dataSizeBytes/(1024*1024))
-		return "GPU_VULKAN"
+		providers = append(providers, ProviderGPUVulkan)
+	}
+	if s.hasAvx512 {
+		providers = append(providers, ProviderCPUAVX512)
 	}
+	return providers
+}
 
-	// If no suitable GPU is found, use the high-performance AVX-512 CPU path as a fallback.
+// RouteTask picks the provider with the lowest estimated latency for a tensor
+// of dataSizeBytes and returns that estimate alongside it. GPU placements on
+// nodes with an enumerated device list carry the device index, e.g. "GPU_CUDA:1".
+// It does not reserve capacity; wrap execution in AcquireTask for that.
+func (s *ScheInfer) RouteTask(dataSizeBytes uint64) (string, time.Duration) {
+	p, est := s.Place(dataSizeBytes)
+	return p.String(), est
+}
+
+// RecordThroughput feeds a measured throughput (e.g. InferenceResponse.ThroughputGbs) into the cost model.
+func (s *ScheInfer) RecordThroughput(provider string, dataSizeBytes uint64, gbs float64) {
+	s.cost.Observe(ParsePlacement(provider).Provider, dataSizeBytes, gbs)
+}
+
+// LoadBenchmarks seeds the cost model with offline benchmark samples.
+func (s *ScheInfer) LoadBenchmarks(samples []BenchmarkSample) {
+	for _, b := range samples {
+		s.cost.Observe(b.Provider, b.SizeBytes, b.ThroughputGbs)
+	}
+}
+
+// EstimateLatency returns the cost model's estimate for running dataSizeBytes on provider.
+func (s *ScheInfer) EstimateLatency(provider string, dataSizeBytes uint64) (time.Duration, bool) {
+	return s.cost.Estimate(ParsePlacement(provider).Provider, dataSizeBytes)
+}
+
+// PlanModel builds a partition plan for model against this node's GPU and
+// CPU tiers and makes RouteLayer follow it. A zero kvReserve holds back
+// DefaultKVReserveBytes.
+func (s *ScheInfer) PlanModel(model ModelDescription, kvReserve uint64) (*PartitionPlan, error) {
+	if kvReserve == 0 {
+		kvReserve = DefaultKVReserveBytes
+	}
+	gpu := ""
+	if s.hasCuda {
+		gpu = ProviderGPUCUDA
+	} else if s.hasVulkan {
+		gpu = ProviderGPUVulkan
+	}
+	vram := s.gpuVRAM
+	// Layers are planned onto a single device: the largest of the preferred class.
+	if d, ok := s.largestDevice(gpu); ok {
+		gpu, vram = Placement{Provider: gpu, Device: d.Index}.String(), d.VRAMBytes
+	}
+	cpu := ProviderCPUAVX2
 	if s.hasAvx512 {
-		log.Printf("[ScheInfer] AVX-512 Optimized Path (%d MB): Routing to Vector Tier", dataSizeBytes/(1024*1024))
-		return "CPU_AVX512"
+		cpu = ProviderCPUAVX512
+	}
+	plan, err := PlanPartition(model, gpu, cpu, vram, kvReserve)
+	if err != nil {
+		return nil, err
 	}
+	s.UsePartitionPlan(plan)
+	log.Printf("[ScheInfer] Partition plan %s", plan)
+	return plan, nil
+}
 
-	log.Printf("[ScheInfer] DRAM-Bound Task (%d MB): Routing to standard CPU path", dataSizeBytes/(1024*1024))
-	return "CPU_AVX2"
+// UsePartitionPlan installs a precomputed plan; nil restores the default heuristic.
+func (s *ScheInfer) UsePartitionPlan(plan *PartitionPlan) {
+	s.mu.Lock()
+	s.plan = plan
+	s.mu.Unlock()
 }
 
-// RouteLayer implements Smart Layer Partitioning (Task 10.2)
+// PartitionPlan returns the active plan, or nil when none is installed.
+func (s *ScheInfer) PartitionPlan() *PartitionPlan {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.plan
+}
+
+// RouteLayer implements Smart Layer Partitioning (Task 10.2). With a
+// partition plan installed the plan decides; otherwise a fixed split is used.
 func (s *ScheInfer) RouteLayer(layerID int) string {
-	// Heuristic: Pin first half of layers to GPU (Compute-heavy), 
+	if provider, ok := s.PartitionPlan().Provider(layerID); ok {
+		return provider
+	}
+
+	// Heuristic: Pin first half of layers to GPU (Compute-heavy),
 	// second half to CPU (Latency-sensitive reasoning)
 	if layerID < 16 {
-		if s.hasCuda { return "GPU_CUDA" }
+		if s.hasCuda {
+			return "GPU_CUDA"
+		}
 	}
-	
+
 	// Routing to CPU for late-stage reasoning/decoding
 	return "CPU_AVX2"
 }
@@ -88,6 +199,13 @@ func (s *ScheInfer) RouteLayer(layerID int) string {
 // GetMeshCapability returns the node's performance profile for the Global Mesh
 func (s *ScheInfer) GetMeshCapability() string {
 	profile := fmt.Sprintf("CPU:%d cores", runtime.NumCPU())
+	if p := s.profile; p != nil && p.PhysicalCores > 0 {
+		profile = fmt.Sprintf("CPU:%d cores/%d threads | L3:%dMB x%d | NUMA:%d",
+			p.PhysicalCores, p.LogicalCPUs, p.L3CacheBytes>>20, p.L3Instances, max(len(p.NUMANodes), 1))
+	}
+	if n := len(s.GPUs()); n > 1 {
+		profile += fmt.Sprintf(" | GPUs:%d", n)
+	}
 	if s.hasCuda {
 		profile += " | GPU:CUDA(Ampere)"
 	} else if s.hasAvx512 {
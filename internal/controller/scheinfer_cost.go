@@ -0,0 +1,147 @@
+package controller
+
+import (
+	"math/bits"
+	"sync"
+	"time"
+)
+
+// Execution providers understood by ScheInfer.
+const (
+	ProviderCPUAVX2   = "CPU_AVX2"
+	ProviderCPUAVX512 = "CPU_AVX512"
+	ProviderGPUCUDA   = "GPU_CUDA"
+	ProviderGPUVulkan = "GPU_VULKAN"
+)
+
+// DefaultPCIeGBs is the host-to-device bandwidth assumed when none is measured.
+const DefaultPCIeGBs = 12.0
+
+// ProviderCost is the prior cost curve of one execution provider, used until
+// throughput has been observed for a given tensor size.
+type ProviderCost struct {
+	Overhead  time.Duration // Fixed dispatch cost (kernel launch, sync).
+	Transfer  bool          // Data crosses PCIe before compute.
+	CacheGBs  float64       // Compute throughput while the tensor fits in L3.
+	MemoryGBs float64       // Compute throughput for the part that spills to DRAM.
+}
+
+// DefaultProviderCosts are conservative priors matching the original routing
+// cascade: cache-resident work stays on the CPU, on AVX-512 where the host
+// has it and AVX2 otherwise, while large tensors favor the GPU.
+func DefaultProviderCosts() map[string]ProviderCost {
+	return map[string]ProviderCost{
+		ProviderCPUAVX2:   {CacheGBs: 8.2, MemoryGBs: 4.0},
+		ProviderCPUAVX512: {CacheGBs: 12.5, MemoryGBs: 6.0},
+		ProviderGPUCUDA:   {Overhead: 200 * time.Microsecond, Transfer: true, CacheGBs: 25.0, MemoryGBs: 25.0},
+		ProviderGPUVulkan: {Overhead: 300 * time.Microsecond, Transfer: true, CacheGBs: 12.0, MemoryGBs: 12.0},
+	}
+}
+
+// BenchmarkSample is one measured run of a provider on a tensor of SizeBytes.
+type BenchmarkSample struct {
+	Provider      string
+	SizeBytes     uint64
+	ThroughputGbs float64
+}
+
+// CostModel estimates per-provider latency as overhead + PCIe transfer +
+// size / throughput. Throughput comes from an EWMA of observations in the
+// tensor's size bucket (powers of two in MB), or from the prior curve when
+// that bucket has not been measured yet. It is safe for concurrent use.
+type CostModel struct {
+	mu       sync.Mutex
+	l3Size   uint64
+	pcieGBs  float64
+	alpha    float64 // EWMA weight of each new observation.
+	priors   map[string]ProviderCost
+	observed map[string]map[int]float64 // Provider -> size bucket -> GB/s
+}
+
+func NewCostModel(l3Size uint64, pcieGBs float64) *CostModel {
+	if pcieGBs <= 0 {
+		pcieGBs = DefaultPCIeGBs
+	}
+	return &CostModel{
+		l3Size:   l3Size,
+		pcieGBs:  pcieGBs,
+		alpha:    0.3,
+		priors:   DefaultProviderCosts(),
+		observed: make(map[string]map[int]float64),
+	}
+}
+
+// Observe folds a measured throughput into the provider's size bucket.
+// Non-positive throughputs and unknown providers are ignored.
+func (m *CostModel) Observe(provider string, sizeBytes uint64, gbs float64) {
+	if gbs <= 0 {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.priors[provider]; !ok {
+		return
+	}
+	buckets, ok := m.observed[provider]
+	if !ok {
+		buckets = make(map[int]float64)
+		m.observed[provider] = buckets
+	}
+	b := sizeBucket(sizeBytes)
+	if prev, ok := buckets[b]; ok {
+		buckets[b] = prev + m.alpha*(gbs-prev)
+	} else {
+		buckets[b] = gbs
+	}
+}
+
+// Estimate returns the expected latency of running sizeBytes on provider.
+func (m *CostModel) Estimate(provider string, sizeBytes uint64) (time.Duration, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	prior, ok := m.priors[provider]
+	if !ok {
+		return 0, false
+	}
+
+	if gbs, ok := m.observed[provider][sizeBucket(sizeBytes)]; ok {
+		seconds := prior.Overhead.Seconds()
+		if prior.Transfer {
+			seconds += gigabytes(sizeBytes) / m.pcieGBs
+		}
+		seconds += gigabytes(sizeBytes) / gbs
+		return time.Duration(seconds * float64(time.Second)), true
+	}
+	return m.priorEstimate(prior, sizeBytes, m.l3Size), true
+}
+
+// EstimatePrior returns the prior-curve estimate for a host with the given L3
+// size, ignoring local observations. It is used to cost remote nodes.
+func (m *CostModel) EstimatePrior(provider string, sizeBytes, l3Size uint64) (time.Duration, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	prior, ok := m.priors[provider]
+	if !ok {
+		return 0, false
+	}
+	return m.priorEstimate(prior, sizeBytes, l3Size), true
+}
+
+func (m *CostModel) priorEstimate(prior ProviderCost, sizeBytes, l3Size uint64) time.Duration {
+	seconds := prior.Overhead.Seconds()
+	if prior.Transfer {
+		seconds += gigabytes(sizeBytes) / m.pcieGBs
+	}
+	cached := min(sizeBytes, l3Size)
+	seconds += gigabytes(cached)/prior.CacheGBs + gigabytes(sizeBytes-cached)/prior.MemoryGBs
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// sizeBucket groups tensor sizes by powers of two in MB; everything under 1MB is bucket 0.
+func sizeBucket(sizeBytes uint64) int {
+	return bits.Len64(sizeBytes >> 20)
+}
+
+func gigabytes(n uint64) float64 {
+	return float64(n) / 1e9
+}
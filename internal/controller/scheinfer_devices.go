@@ -0,0 +1,101 @@
+package controller
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/groovy-byte/agent-mesh-core/internal/topology"
+)
+
+// Placement is a routing decision. Device is the GPU index on nodes with an
+// enumerated device list, and -1 for CPU providers or a legacy single GPU.
+type Placement struct {
+	Provider string
+	Device   int
+}
+
+// String renders the placement as "GPU_CUDA:1", or just the provider without a device.
+func (p Placement) String() string {
+	if p.Device < 0 {
+		return p.Provider
+	}
+	return fmt.Sprintf("%s:%d", p.Provider, p.Device)
+}
+
+// ParsePlacement is the inverse of Placement.String.
+func ParsePlacement(s string) Placement {
+	if name, idx, ok := strings.Cut(s, ":"); ok {
+		if n, err := strconv.Atoi(idx); err == nil && n >= 0 {
+			return Placement{Provider: name, Device: n}
+		}
+	}
+	return Placement{Provider: s, Device: -1}
+}
+
+// gpuProvider maps a compute capability to the GPU path ScheInfer uses for it:
+// Volta/Ampere (7.0+) run CUDA, Pascal/Turing-era parts (6.x) fall back to Vulkan.
+func gpuProvider(computeCap int) string {
+	switch {
+	case computeCap >= 70:
+		return ProviderGPUCUDA
+	case computeCap >= 60:
+		return ProviderGPUVulkan
+	}
+	return ""
+}
+
+func isGPUProvider(provider string) bool {
+	return provider == ProviderGPUCUDA || provider == ProviderGPUVulkan
+}
+
+// useGPUs installs the device list and derives the available GPU paths from it.
+func (s *ScheInfer) useGPUs(devs []topology.GPUDevice) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.gpus = append([]topology.GPUDevice(nil), devs...)
+	s.hasCuda, s.hasVulkan = false, false
+	for _, d := range s.gpus {
+		switch gpuProvider(d.ComputeCap) {
+		case ProviderGPUCUDA:
+			s.hasCuda = true
+		case ProviderGPUVulkan:
+			s.hasVulkan = true
+		}
+	}
+}
+
+// GPUs returns a snapshot of the enumerated devices.
+func (s *ScheInfer) GPUs() []topology.GPUDevice {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]topology.GPUDevice(nil), s.gpus...)
+}
+
+// UpdateGPUMemory records a fresh free-memory reading for device index,
+// e.g. from a periodic quantx.ListGpuDevices poll.
+func (s *ScheInfer) UpdateGPUMemory(index int, freeBytes uint64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.gpus {
+		if s.gpus[i].Index == index {
+			s.gpus[i].FreeBytes = freeBytes
+			return true
+		}
+	}
+	return false
+}
+
+// largestDevice returns the device of provider with the most total VRAM, for partition planning.
+func (s *ScheInfer) largestDevice(provider string) (topology.GPUDevice, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var best topology.GPUDevice
+	found := false
+	for _, d := range s.gpus {
+		if gpuProvider(d.ComputeCap) == provider && (!found || d.VRAMBytes > best.VRAMBytes) {
+			best, found = d, true
+		}
+	}
+	return best, found
+}
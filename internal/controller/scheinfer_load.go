@@ -0,0 +1,212 @@
+package controller
+
+import (
+	"log"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// LoadPolicy bounds the in-flight work a placement takes before routing
+// spills over to the next-best one. Zero values disable the corresponding check.
+type LoadPolicy struct {
+	MaxQueueDepth  int     // In-flight tasks per provider (per device for indexed GPUs).
+	MaxUtilization float64 // Share of a GPU's free memory held by in-flight tasks, 0-1.
+}
+
+// ProviderLoad is the in-flight work and spillover history of one placement.
+type ProviderLoad struct {
+	InFlight      int
+	InFlightBytes uint64
+	Acquired      uint64
+	SpilledOut    uint64 // Tasks that ranked best here but were sent elsewhere.
+	SpilledIn     uint64 // Tasks received because a better placement was saturated.
+}
+
+// TaskLease is a reservation on a placement, held while the task executes.
+type TaskLease struct {
+	Placement
+	Bytes   uint64
+	Spilled bool // The best-ranked placement was saturated.
+
+	sched *ScheInfer
+	once  sync.Once
+}
+
+// Release returns the lease's capacity. It is safe to call more than once.
+func (l *TaskLease) Release() {
+	l.once.Do(func() {
+		l.sched.mu.Lock()
+		defer l.sched.mu.Unlock()
+		if ld := l.sched.load[l.String()]; ld != nil {
+			ld.InFlight--
+			ld.InFlightBytes -= l.Bytes
+		}
+	})
+}
+
+// ConfigureLoad sets the spillover thresholds.
+func (s *ScheInfer) ConfigureLoad(policy LoadPolicy) {
+	s.mu.Lock()
+	s.policy = policy
+	s.mu.Unlock()
+}
+
+// Place is RouteTask with the provider and device index kept apart.
+func (s *ScheInfer) Place(dataSizeBytes uint64) (Placement, time.Duration) {
+	s.mu.RLock()
+	chosen, from := s.selectLocked(dataSizeBytes)
+	s.mu.RUnlock()
+
+	s.logPlacement(dataSizeBytes, chosen, from)
+	return chosen.Placement, chosen.est
+}
+
+// AcquireTask routes a task like RouteTask and reserves capacity on the chosen
+// placement until the lease is released, so concurrent callers see the load.
+func (s *ScheInfer) AcquireTask(dataSizeBytes uint64) (*TaskLease, time.Duration) {
+	s.mu.Lock()
+	chosen, from := s.selectLocked(dataSizeBytes)
+	ld := s.loadLocked(chosen.String())
+	ld.InFlight++
+	ld.InFlightBytes += dataSizeBytes
+	ld.Acquired++
+	if from != nil {
+		ld.SpilledIn++
+		s.loadLocked(from.String()).SpilledOut++
+	}
+	s.mu.Unlock()
+
+	s.logPlacement(dataSizeBytes, chosen, from)
+	return &TaskLease{Placement: chosen.Placement, Bytes: dataSizeBytes, Spilled: from != nil, sched: s}, chosen.est
+}
+
+// AcquirePlacement reserves capacity on a placement the caller has already
+// chosen, such as the draft path of speculative decoding. It never spills over.
+func (s *ScheInfer) AcquirePlacement(placement string, dataSizeBytes uint64) *TaskLease {
+	p := ParsePlacement(placement)
+	s.mu.Lock()
+	ld := s.loadLocked(p.String())
+	ld.InFlight++
+	ld.InFlightBytes += dataSizeBytes
+	ld.Acquired++
+	s.mu.Unlock()
+	return &TaskLease{Placement: p, Bytes: dataSizeBytes, sched: s}
+}
+
+// LoadStats returns a snapshot of every placement that has seen work.
+func (s *ScheInfer) LoadStats() map[string]ProviderLoad {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make(map[string]ProviderLoad, len(s.load))
+	for name, ld := range s.load {
+		out[name] = *ld
+	}
+	return out
+}
+
+func (s *ScheInfer) logPlacement(size uint64, chosen candidate, from *candidate) {
+	if from != nil {
+		log.Printf("[ScheInfer] ⚠️ %s saturated, spilling %d KB to %s", from, size/1024, chosen)
+	}
+	log.Printf("[ScheInfer] Task (%d KB): Routing to %s (est. %v)", size/1024, chosen, chosen.est)
+}
+
+type candidate struct {
+	Placement
+	est   time.Duration
+	rank  int    // Position in Providers(), the tie-break order.
+	avail uint64 // Unreserved device memory; GPUs with more are preferred.
+}
+
+func (s *ScheInfer) loadLocked(key string) *ProviderLoad {
+	ld, ok := s.load[key]
+	if !ok {
+		ld = &ProviderLoad{}
+		s.load[key] = ld
+	}
+	return ld
+}
+
+// candidatesLocked ranks every placement able to hold the tensor by
+// estimated latency, then provider order, then free device memory.
+func (s *ScheInfer) candidatesLocked(size uint64) []candidate {
+	var out []candidate
+	for rank, p := range s.providersLocked() {
+		est, ok := s.cost.Estimate(p, size)
+		if !ok {
+			continue
+		}
+		if !isGPUProvider(p) || len(s.gpus) == 0 {
+			out = append(out, candidate{Placement: Placement{Provider: p, Device: -1}, est: est, rank: rank})
+			continue
+		}
+		for _, d := range s.gpus {
+			if gpuProvider(d.ComputeCap) != p {
+				continue
+			}
+			pl := Placement{Provider: p, Device: d.Index}
+			var reserved uint64
+			if ld := s.load[pl.String()]; ld != nil {
+				reserved = ld.InFlightBytes
+			}
+			if d.FreeBytes < reserved || d.FreeBytes-reserved < size {
+				continue
+			}
+			out = append(out, candidate{Placement: pl, est: est, rank: rank, avail: d.FreeBytes - reserved})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		if a.est != b.est {
+			return a.est < b.est
+		}
+		if a.rank != b.rank {
+			return a.rank < b.rank
+		}
+		if a.avail != b.avail {
+			return a.avail > b.avail
+		}
+		return a.Device < b.Device
+	})
+	return out
+}
+
+// selectLocked returns the best unsaturated candidate and, when that is not
+// the overall best, the candidate it spilled from. If every candidate is
+// saturated the best one is used anyway.
+func (s *ScheInfer) selectLocked(size uint64) (candidate, *candidate) {
+	cands := s.candidatesLocked(size)
+	if len(cands) == 0 {
+		return candidate{Placement: Placement{Provider: ProviderCPUAVX2, Device: -1}, est: time.Duration(math.MaxInt64)}, nil
+	}
+	for i, c := range cands {
+		if s.saturatedLocked(c, size) {
+			continue
+		}
+		if i == 0 {
+			return c, nil
+		}
+		return c, &cands[0]
+	}
+	return cands[0], nil
+}
+
+func (s *ScheInfer) saturatedLocked(c candidate, size uint64) bool {
+	ld := s.load[c.String()]
+	if ld == nil {
+		return false
+	}
+	if s.policy.MaxQueueDepth > 0 && ld.InFlight >= s.policy.MaxQueueDepth {
+		return true
+	}
+	if s.policy.MaxUtilization > 0 && c.Device >= 0 {
+		for _, d := range s.gpus {
+			if d.Index == c.Device && d.FreeBytes > 0 {
+				return float64(ld.InFlightBytes+size)/float64(d.FreeBytes) > s.policy.MaxUtilization
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,163 @@
+package controller
+
+import (
+	"log"
+	"runtime"
+	"time"
+
+	pb "github.com/groovy-byte/agent-mesh-core/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Defaults for the node-to-node link used when costing remote placements.
+const (
+	DefaultNetworkGBs = 1.25 // 10GbE
+	DefaultNetworkRTT = time.Millisecond
+)
+
+// NetworkCost is the price of shipping a task to another node.
+type NetworkCost struct {
+	GBs float64
+	RTT time.Duration
+}
+
+func (n NetworkCost) estimate(sizeBytes uint64) time.Duration {
+	gbs := n.GBs
+	if gbs <= 0 {
+		gbs = DefaultNetworkGBs
+	}
+	return n.RTT + time.Duration(gigabytes(sizeBytes)/gbs*float64(time.Second))
+}
+
+// GlobalPlacement is a mesh-wide routing decision. Node and Addr are empty
+// when the task should run locally.
+type GlobalPlacement struct {
+	Placement
+	Node     string
+	Addr     string
+	Estimate time.Duration
+}
+
+// Remote reports whether the task should be forwarded.
+func (g GlobalPlacement) Remote() bool {
+	return g.Node != ""
+}
+
+// String renders remote placements as "node-b/GPU_VULKAN:0".
+func (g GlobalPlacement) String() string {
+	if !g.Remote() {
+		return g.Placement.String()
+	}
+	return g.Node + "/" + g.Placement.String()
+}
+
+// ConfigureNetwork sets the link cost used for remote placements.
+func (s *ScheInfer) ConfigureNetwork(n NetworkCost) {
+	s.mu.Lock()
+	s.network = n
+	s.mu.Unlock()
+}
+
+// Capability is the structured profile this node publishes to the mesh.
+func (s *ScheInfer) Capability(nodeID, grpcAddr string) *pb.NodeCapability {
+	c := &pb.NodeCapability{
+		NodeId:       nodeID,
+		GrpcAddr:     grpcAddr,
+		LogicalCpus:  uint32(runtime.NumCPU()),
+		L3CacheBytes: s.l3CacheSize,
+		SimdTier:     "AVX2",
+		Providers:    s.Providers(),
+		Load:         make(map[string]*pb.ProviderLoadMetrics),
+		PublishedAt:  timestamppb.Now(),
+	}
+	if s.hasAvx512 {
+		c.SimdTier = "AVX512"
+	}
+	if p := s.profile; p != nil && p.LogicalCPUs > 0 {
+		c.PhysicalCores = uint32(p.PhysicalCores)
+		c.LogicalCpus = uint32(p.LogicalCPUs)
+		c.NumaNodes = uint32(len(p.NUMANodes))
+	}
+	for _, d := range s.GPUs() {
+		c.Gpus = append(c.Gpus, &pb.GpuDevice{
+			Index:             uint32(d.Index),
+			Name:              d.Name,
+			VramBytes:         d.VRAMBytes,
+			FreeBytes:         d.FreeBytes,
+			ComputeCapability: uint32(d.ComputeCap),
+		})
+	}
+	for placement, ld := range s.LoadStats() {
+		c.Load[placement] = &pb.ProviderLoadMetrics{
+			InFlight:      uint32(ld.InFlight),
+			InFlightBytes: ld.InFlightBytes,
+			Acquired:      ld.Acquired,
+			SpilledOut:    ld.SpilledOut,
+			SpilledIn:     ld.SpilledIn,
+		}
+	}
+	return c
+}
+
+// RouteTaskGlobal compares the best local placement with every placement
+// advertised by peers. Remote estimates use the prior cost curve for the
+// peer's L3 size plus the network cost; peers without a gRPC address, and
+// remote placements at this node's queue-depth limit, are skipped.
+func (s *ScheInfer) RouteTaskGlobal(dataSizeBytes uint64, peers []*pb.NodeCapability) GlobalPlacement {
+	local, est := s.Place(dataSizeBytes)
+	best := GlobalPlacement{Placement: local, Estimate: est}
+
+	s.mu.RLock()
+	network, maxDepth := s.network, s.policy.MaxQueueDepth
+	s.mu.RUnlock()
+	transfer := network.estimate(dataSizeBytes)
+
+	for _, peer := range peers {
+		if peer.GrpcAddr == "" {
+			continue
+		}
+		for _, pl := range remotePlacements(peer, dataSizeBytes) {
+			if ld := peer.Load[pl.String()]; maxDepth > 0 && ld != nil && int(ld.InFlight) >= maxDepth {
+				continue
+			}
+			remote, ok := s.cost.EstimatePrior(pl.Provider, dataSizeBytes, peer.L3CacheBytes)
+			if !ok {
+				continue
+			}
+			if total := remote + transfer; total < best.Estimate {
+				best = GlobalPlacement{Placement: pl, Node: peer.NodeId, Addr: peer.GrpcAddr, Estimate: total}
+			}
+		}
+	}
+	if best.Remote() {
+		log.Printf("[ScheInfer] 🌐 Task (%d KB): %s beats local %s (est. %v vs %v)",
+			dataSizeBytes/1024, best, local, best.Estimate, est)
+	}
+	return best
+}
+
+// remotePlacements lists a peer's providers, expanding GPU providers into
+// the devices with enough unreserved memory for the task.
+func remotePlacements(peer *pb.NodeCapability, sizeBytes uint64) []Placement {
+	var out []Placement
+	for _, provider := range peer.Providers {
+		if !isGPUProvider(provider) || len(peer.Gpus) == 0 {
+			out = append(out, Placement{Provider: provider, Device: -1})
+			continue
+		}
+		for _, d := range peer.Gpus {
+			if gpuProvider(int(d.ComputeCapability)) != provider {
+				continue
+			}
+			pl := Placement{Provider: provider, Device: int(d.Index)}
+			var reserved uint64
+			if ld := peer.Load[pl.String()]; ld != nil {
+				reserved = ld.InFlightBytes
+			}
+			if d.FreeBytes >= reserved && d.FreeBytes-reserved >= sizeBytes {
+				out = append(out, pl)
+			}
+		}
+	}
+	return out
+}
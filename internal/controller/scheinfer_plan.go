@@ -0,0 +1,172 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+// DefaultKVReserveBytes is the VRAM held back for the KV cache when the caller
+// does not size it explicitly.
+const DefaultKVReserveBytes = 512 * 1024 * 1024
+
+var ErrInvalidModel = errors.New("scheinfer: model has no layers")
+
+// ModelDescription is what the planner needs to know about a model. LayerBytes
+// overrides BytesPerLayer for models whose blocks differ in size.
+type ModelDescription struct {
+	Name          string   `json:"name"`
+	Layers        int      `json:"layers"`
+	BytesPerLayer uint64   `json:"bytes_per_layer"`
+	LayerBytes    []uint64 `json:"layer_bytes,omitempty"`
+}
+
+// layerSize returns the weight size of layer i.
+func (m ModelDescription) layerSize(i int) uint64 {
+	if i < len(m.LayerBytes) {
+		return m.LayerBytes[i]
+	}
+	return m.BytesPerLayer
+}
+
+var blockTensorPattern = regexp.MustCompile(`^blk\.([0-9]+)\.`)
+
+// ModelFromTensors builds a ModelDescription from GGUF tensor names and sizes.
+// Tensors named "blk.N.*" are summed into layer N; embeddings and the output
+// head are not part of any repeating layer and are ignored.
+func ModelFromTensors(name string, tensors map[string]uint64) ModelDescription {
+	sizes := make(map[int]uint64)
+	layers := 0
+	for tensor, size := range tensors {
+		m := blockTensorPattern.FindStringSubmatch(tensor)
+		if m == nil {
+			continue
+		}
+		n, _ := strconv.Atoi(m[1])
+		sizes[n] += size
+		layers = max(layers, n+1)
+	}
+	desc := ModelDescription{Name: name, Layers: layers, LayerBytes: make([]uint64, layers)}
+	for n, size := range sizes {
+		desc.LayerBytes[n] = size
+	}
+	return desc
+}
+
+// LayerAssignment places one layer on an execution provider.
+type LayerAssignment struct {
+	Layer    int    `json:"layer"`
+	Provider string `json:"provider"`
+	Bytes    uint64 `json:"bytes"`
+}
+
+// MemoryReport totals the plan's weight memory per provider and against the GPU budget.
+type MemoryReport struct {
+	TotalBytes     uint64            `json:"total_bytes"`
+	ByProvider     map[string]uint64 `json:"by_provider"`
+	VRAMBytes      uint64            `json:"vram_bytes"`
+	KVReserveBytes uint64            `json:"kv_reserve_bytes"`
+	VRAMFreeBytes  uint64            `json:"vram_free_bytes"` // Budget left after weights and KV reserve.
+}
+
+// PartitionPlan maps every layer of a model to a provider. The GPU takes a
+// contiguous prefix of layers that fits in VRAM after the KV reserve; the
+// rest run on the best CPU tier.
+type PartitionPlan struct {
+	Model     string            `json:"model"`
+	GPU       string            `json:"gpu_provider,omitempty"`
+	CPU       string            `json:"cpu_provider"`
+	GPULayers int               `json:"gpu_layers"`
+	Layers    []LayerAssignment `json:"layers"`
+	Memory    MemoryReport      `json:"memory"`
+}
+
+// Provider returns the provider for layerID, or false when the layer is out of range.
+func (p *PartitionPlan) Provider(layerID int) (string, bool) {
+	if p == nil || layerID < 0 || layerID >= len(p.Layers) {
+		return "", false
+	}
+	return p.Layers[layerID].Provider, true
+}
+
+// Marshal encodes the plan as JSON.
+func (p *PartitionPlan) Marshal() ([]byte, error) {
+	return json.Marshal(p)
+}
+
+// UnmarshalPartitionPlan decodes a plan produced by Marshal.
+func UnmarshalPartitionPlan(data []byte) (*PartitionPlan, error) {
+	var p PartitionPlan
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("scheinfer: failed to decode partition plan: %w", err)
+	}
+	return &p, nil
+}
+
+// PlanPartition builds a layer-to-provider plan for model. vramBytes is the
+// GPU's total memory (e.g. from quantx.GetGpuInfo) and kvReserve the part of
+// it kept free for the KV cache.
+func PlanPartition(model ModelDescription, gpuProvider, cpuProvider string, vramBytes, kvReserve uint64) (*PartitionPlan, error) {
+	if model.Layers <= 0 {
+		return nil, ErrInvalidModel
+	}
+	plan := &PartitionPlan{
+		Model:  model.Name,
+		CPU:    cpuProvider,
+		Layers: make([]LayerAssignment, model.Layers),
+		Memory: MemoryReport{
+			ByProvider:     make(map[string]uint64),
+			KVReserveBytes: kvReserve,
+		},
+	}
+
+	var budget uint64
+	if gpuProvider != "" {
+		plan.GPU = gpuProvider
+		plan.Memory.VRAMBytes = vramBytes
+		if vramBytes > kvReserve {
+			budget = vramBytes - kvReserve
+		}
+	}
+
+	onGPU := true
+	for i := range plan.Layers {
+		size := model.layerSize(i)
+		provider := cpuProvider
+		if onGPU && gpuProvider != "" && size <= budget {
+			provider = gpuProvider
+			budget -= size
+			plan.GPULayers++
+		} else {
+			onGPU = false // Keep the GPU share contiguous to avoid extra PCIe hops.
+		}
+		plan.Layers[i] = LayerAssignment{Layer: i, Provider: provider, Bytes: size}
+		plan.Memory.ByProvider[provider] += size
+		plan.Memory.TotalBytes += size
+	}
+	if gpuProvider != "" {
+		plan.Memory.VRAMFreeBytes = budget
+	}
+	return plan, nil
+}
+
+// String summarizes the plan for logs, e.g. "llama-8b: 24/32 layers on GPU_CUDA, CPU_AVX2 1024MB, GPU_CUDA 3072MB".
+func (p *PartitionPlan) String() string {
+	providers := make([]string, 0, len(p.Memory.ByProvider))
+	for name := range p.Memory.ByProvider {
+		providers = append(providers, name)
+	}
+	sort.Strings(providers)
+	gpu := p.GPU
+	if gpu == "" {
+		gpu = "GPU"
+	}
+	out := fmt.Sprintf("%s: %d/%d layers on %s", p.Model, p.GPULayers, len(p.Layers), gpu)
+	for _, name := range providers {
+		out += fmt.Sprintf(", %s %dMB", name, p.Memory.ByProvider[name]>>20)
+	}
+	return out
+}
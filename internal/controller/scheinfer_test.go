@@ -2,12 +2,15 @@ package controller
 
 import (
 	"testing"
+	"time"
+
+	"github.com/groovy-byte/agent-mesh-core/internal/topology"
 )
 
 func TestScheInferRouting(t *testing.T) {
 	// Mock 16MB L3 cache
 	l3Size := uint64(16 * 1024 * 1024)
-	
+
 	tests := []struct {
 		name       string
 		gpuName    string
@@ -44,6 +47,14 @@ func TestScheInferRouting(t *testing.T) {
 			dataSize:   32 * 1024 * 1024, // 32MB
 			expected:   "GPU_VULKAN",
 		},
+		{
+			name:       "Small task on Ampere with AVX-512",
+			gpuName:    "NVIDIA GeForce RTX 3070 Laptop GPU",
+			computeCap: 86,
+			avx512:     true,
+			dataSize:   1024 * 1024, // 1MB
+			expected:   "CPU_AVX512",
+		},
 		{
 			name:       "Large task on AVX-512 (No GPU)",
 			gpuName:    "",
@@ -65,10 +76,199 @@ func TestScheInferRouting(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := NewScheInfer(l3Size, tt.gpuName, tt.computeCap, tt.avx512)
-			got := s.RouteTask(tt.dataSize)
+			got, _ := s.RouteTask(tt.dataSize)
 			if got != tt.expected {
 				t.Errorf("RouteTask() = %v, want %v", got, tt.expected)
 			}
 		})
 	}
 }
+
+func TestScheInferLearnsFromThroughput(t *testing.T) {
+	s := NewScheInfer(16*1024*1024, "NVIDIA GeForce RTX 3070 Laptop GPU", 86, true)
+	size := uint64(64 * 1024 * 1024)
+
+	provider, before := s.RouteTask(size)
+	if provider != ProviderGPUCUDA {
+		t.Fatalf("Expected prior to favor GPU_CUDA for 64MB, got %s", provider)
+	}
+	if before <= 0 {
+		t.Errorf("Expected a positive latency estimate, got %v", before)
+	}
+
+	// Benchmarks show the AVX-512 path is much faster on tensors of this size.
+	s.LoadBenchmarks([]BenchmarkSample{
+		{Provider: ProviderCPUAVX512, SizeBytes: size, ThroughputGbs: 60},
+		{Provider: ProviderCPUAVX512, SizeBytes: 100 * 1024 * 1024, ThroughputGbs: 60},
+	})
+	provider, after := s.RouteTask(size)
+	if provider != ProviderCPUAVX512 {
+		t.Errorf("Expected routing to learn CPU_AVX512, got %s", provider)
+	}
+	if after >= before {
+		t.Errorf("Expected a lower estimate after learning, got %v (was %v)", after, before)
+	}
+
+	// Other size buckets keep using the prior.
+	if provider, _ := s.RouteTask(256 * 1024 * 1024); provider != ProviderGPUCUDA {
+		t.Errorf("Expected unmeasured 256MB bucket to stay on GPU_CUDA, got %s", provider)
+	}
+}
+
+func TestCostModelTransferAndEWMA(t *testing.T) {
+	m := NewCostModel(16*1024*1024, 10)
+	size := uint64(1e9)
+
+	// GPU estimate includes PCIe transfer: 1GB / 10GB/s + 1GB / 25GB/s + overhead.
+	est, ok := m.Estimate(ProviderGPUCUDA, size)
+	want := 200*time.Microsecond + 100*time.Millisecond + 40*time.Millisecond
+	if !ok || (est-want).Abs() > time.Millisecond {
+		t.Errorf("Expected ~%v, got %v", want, est)
+	}
+
+	m.Observe(ProviderGPUCUDA, size, 50)
+	m.Observe(ProviderGPUCUDA, size, 0) // Ignored.
+	m.Observe(ProviderGPUCUDA, size, 100)
+	est, _ = m.Estimate(ProviderGPUCUDA, size)
+	// EWMA: 50 + 0.3*(100-50) = 65 GB/s.
+	want = 200*time.Microsecond + 100*time.Millisecond + time.Second/65
+	if (est - want).Abs() > time.Millisecond {
+		t.Errorf("Expected ~%v after observations, got %v", want, est)
+	}
+
+	if _, ok := m.Estimate("TPU", size); ok {
+		t.Error("Expected no estimate for an unknown provider")
+	}
+}
+
+func TestScheInferFromProfile(t *testing.T) {
+	s := NewScheInferFromProfile(topology.HardwareProfile{
+		LogicalCPUs:   16,
+		PhysicalCores: 8,
+		L3CacheBytes:  32 << 20,
+		L3Instances:   1,
+		GPUName:       "Fake RTX",
+		GPUComputeCap: 86,
+	}, 16<<20)
+	if s.l3CacheSize != 32<<20 || !s.hasCuda || s.hasAvx512 {
+		t.Errorf("Unexpected scheduler from profile: %+v", s)
+	}
+	if got := s.GetMeshCapability(); got != "CPU:8 cores/16 threads | L3:32MB x1 | NUMA:1 | GPU:CUDA(Ampere)" {
+		t.Errorf("Unexpected capability %q", got)
+	}
+
+	// No L3 detected: fall back to the configured size.
+	if s := NewScheInferFromProfile(topology.HardwareProfile{}, 16<<20); s.l3CacheSize != 16<<20 {
+		t.Errorf("Expected fallback L3 of 16MB, got %d", s.l3CacheSize)
+	}
+}
+
+func TestScheInferMultiGPU(t *testing.T) {
+	s := NewScheInferFromProfile(topology.HardwareProfile{
+		GPUs: []topology.GPUDevice{
+			{Index: 0, Name: "RTX 3070", VRAMBytes: 8 << 30, FreeBytes: 2 << 30, ComputeCap: 86},
+			{Index: 1, Name: "RTX 4090", VRAMBytes: 24 << 30, FreeBytes: 6 << 30, ComputeCap: 89},
+			{Index: 2, Name: "GTX 1070", VRAMBytes: 8 << 30, FreeBytes: 8 << 30, ComputeCap: 61},
+		},
+	}, 16<<20)
+
+	// Large tasks go to the CUDA device with the most free memory.
+	if got, _ := s.RouteTask(256 << 20); got != "GPU_CUDA:1" {
+		t.Errorf("Expected GPU_CUDA:1, got %s", got)
+	}
+	if !s.UpdateGPUMemory(1, 1<<30) {
+		t.Fatal("Expected device 1 to be known")
+	}
+	if got, _ := s.RouteTask(256 << 20); got != "GPU_CUDA:0" {
+		t.Errorf("Expected GPU_CUDA:0 after device 1 filled up, got %s", got)
+	}
+	// No CUDA device can hold 3GB; the Pascal card still can via Vulkan.
+	if got, _ := s.RouteTask(3 << 30); got != "GPU_VULKAN:2" {
+		t.Errorf("Expected GPU_VULKAN:2, got %s", got)
+	}
+	if got, _ := s.RouteTask(10 << 30); got != ProviderCPUAVX2 {
+		t.Errorf("Expected CPU_AVX2 when no device fits, got %s", got)
+	}
+
+	// Throughput reported for an indexed device feeds the provider's cost curve.
+	before, _ := s.EstimateLatency("GPU_CUDA:0", 256<<20)
+	s.RecordThroughput("GPU_CUDA:0", 256<<20, 100)
+	if after, _ := s.EstimateLatency(ProviderGPUCUDA, 256<<20); after >= before {
+		t.Errorf("Expected indexed observation to lower the CUDA estimate, got %v -> %v", before, after)
+	}
+
+	if p := ParsePlacement("GPU_CUDA:1"); p.Provider != ProviderGPUCUDA || p.Device != 1 {
+		t.Errorf("Unexpected placement %+v", p)
+	}
+	if p := ParsePlacement(ProviderCPUAVX2); p.Device != -1 || p.String() != ProviderCPUAVX2 {
+		t.Errorf("Unexpected placement %+v", p)
+	}
+}
+
+func TestScheInferSpillsOverWhenSaturated(t *testing.T) {
+	s := NewScheInfer(16*1024*1024, "NVIDIA GeForce RTX 3070 Laptop GPU", 86, true)
+	s.ConfigureLoad(LoadPolicy{MaxQueueDepth: 2})
+	size := uint64(256 << 20)
+
+	a, _ := s.AcquireTask(size)
+	b, _ := s.AcquireTask(size)
+	if a.String() != ProviderGPUCUDA || b.String() != ProviderGPUCUDA {
+		t.Fatalf("Expected the first two tasks on GPU_CUDA, got %s and %s", a, b)
+	}
+	// GPU queue is full: the next-best provider takes the third task.
+	c, _ := s.AcquireTask(size)
+	if c.String() != ProviderCPUAVX512 || !c.Spilled {
+		t.Errorf("Expected spillover to CPU_AVX512, got %s (spilled=%v)", c, c.Spilled)
+	}
+	// RouteTask sees the load without reserving anything.
+	if got, _ := s.RouteTask(size); got != ProviderCPUAVX512 {
+		t.Errorf("Expected RouteTask to avoid the saturated GPU, got %s", got)
+	}
+
+	a.Release()
+	a.Release() // Releasing twice must not free a second slot.
+	d, _ := s.AcquireTask(size)
+	if d.String() != ProviderGPUCUDA || d.Spilled {
+		t.Errorf("Expected GPU_CUDA after a release, got %s", d)
+	}
+	e, _ := s.AcquireTask(size)
+	if e.String() != ProviderCPUAVX512 {
+		t.Errorf("Expected double release to leave the GPU full, got %s", e)
+	}
+
+	stats := s.LoadStats()
+	if g := stats[ProviderGPUCUDA]; g.InFlight != 2 || g.Acquired != 3 || g.SpilledOut != 2 {
+		t.Errorf("Unexpected GPU_CUDA load: %+v", g)
+	}
+	if c := stats[ProviderCPUAVX512]; c.InFlight != 2 || c.SpilledIn != 2 {
+		t.Errorf("Unexpected CPU_AVX512 load: %+v", c)
+	}
+}
+
+func TestScheInferUtilizationThreshold(t *testing.T) {
+	s := NewScheInferFromProfile(topology.HardwareProfile{
+		HasAVX512: true,
+		GPUs: []topology.GPUDevice{
+			{Index: 0, Name: "RTX 3070", VRAMBytes: 8 << 30, FreeBytes: 1 << 30, ComputeCap: 86},
+			{Index: 1, Name: "RTX 3070", VRAMBytes: 8 << 30, FreeBytes: 1 << 30, ComputeCap: 86},
+		},
+	}, 16<<20)
+	s.ConfigureLoad(LoadPolicy{MaxUtilization: 0.5})
+	size := uint64(400 << 20)
+
+	// In-flight bytes count against free memory, so tasks alternate devices.
+	a, _ := s.AcquireTask(size)
+	b, _ := s.AcquireTask(size)
+	if a.String() != "GPU_CUDA:0" || b.String() != "GPU_CUDA:1" {
+		t.Errorf("Expected tasks balanced across both devices, got %s and %s", a, b)
+	}
+	// A second 400MB task would take either device past 50% of its free memory.
+	c, _ := s.AcquireTask(size)
+	if c.String() != ProviderCPUAVX512 || !c.Spilled {
+		t.Errorf("Expected spillover to CPU_AVX512, got %s", c)
+	}
+	b.Release()
+	if got, _ := s.RouteTask(size); got != "GPU_CUDA:1" {
+		t.Errorf("Expected GPU_CUDA:1 after release, got %s", got)
+	}
+}
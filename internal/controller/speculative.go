@@ -0,0 +1,155 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/groovy-byte/agent-mesh-core/internal/backend"
+	pb "github.com/groovy-byte/agent-mesh-core/proto"
+)
+
+// Defaults for speculative decoding.
+const (
+	DefaultDraftTokens = 4
+	// DefaultSpeculativeMaxTokens bounds speculative requests that leave
+	// max_tokens unset, since the loop otherwise runs until the target ends.
+	DefaultSpeculativeMaxTokens = 256
+)
+
+// speculation counts draft tokens over a speculative request.
+type speculation struct {
+	proposed int
+	accepted int
+	rounds   int
+}
+
+// rate is the share of proposed tokens the target accepted.
+func (s speculation) rate() float32 {
+	if s.proposed == 0 {
+		return 0
+	}
+	return float32(s.accepted) / float32(s.proposed)
+}
+
+// draftPath picks where the draft model runs: the requested path, or the
+// fastest CPU provider on this node.
+func (c *InferenceController) draftPath(spec *pb.SpeculativeDecoding) string {
+	if spec.DraftPath != "" {
+		return spec.DraftPath
+	}
+	for _, p := range c.scheduler.Providers() {
+		if p == ProviderCPUAVX512 {
+			return p
+		}
+	}
+	return ProviderCPUAVX2
+}
+
+// speculative runs req with the draft model on the draft path proposing
+// tokens that the backend on the routed hardware path verifies. Accepted
+// text is passed to emit, when non-nil, as each round completes. When the
+// draft path resolves to the target's own backend, speculation cannot save
+// a forward pass and req runs as plain generation instead.
+func (c *InferenceController) speculative(ctx context.Context, req *pb.InferenceRequest, emit backend.TokenFunc) (*pb.InferenceResponse, error) {
+	draftPath := c.draftPath(req.Speculative)
+	draft := c.Backend(draftPath)
+	k := int(req.Speculative.DraftTokens)
+	if k <= 0 {
+		k = DefaultDraftTokens
+	}
+
+	var st speculation
+	speculated := false
+	resp, err := c.run(ctx, req, func(hardwarePath string, target backend.Backend, breq backend.Request) (backend.Result, error) {
+		if draft == target {
+			log.Printf("[Inference] ⚠️ Speculative decode for %s skipped: draft %s runs on the backend of target %s",
+				req.AgentId, draftPath, hardwarePath)
+			if emit == nil {
+				return target.Generate(ctx, breq)
+			}
+			return backend.Stream(ctx, target, breq, emit)
+		}
+
+		// The draft model loads its path as well; hold it so routing sees that.
+		lease := c.scheduler.AcquirePlacement(draftPath, requestBytes(req))
+		defer lease.Release()
+		res, s, err := speculate(ctx, draft, target, breq, k, emit)
+		if err != nil {
+			return backend.Result{}, err
+		}
+		st, speculated = s, true
+		log.Printf("[Inference] 🔮 Speculative decode for %s: draft %s, target %s, accepted %d/%d in %d rounds",
+			req.AgentId, draftPath, hardwarePath, s.accepted, s.proposed, s.rounds)
+		return res, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if speculated {
+		resp.AcceptanceRate = st.rate()
+		resp.DraftPath = draftPath
+	}
+	return resp, nil
+}
+
+// speculate decodes req in rounds: draft proposes up to k tokens after the
+// text so far and target keeps the prefix it agrees with plus its own next
+// token. With greedy decoding the text matches what target alone generates.
+func speculate(ctx context.Context, draft, target backend.Backend, req backend.Request, k int, emit backend.TokenFunc) (backend.Result, speculation, error) {
+	limit := req.MaxTokens
+	if limit <= 0 {
+		limit = DefaultSpeculativeMaxTokens
+	}
+
+	var st speculation
+	var text strings.Builder
+	completion := 0
+	for completion < limit {
+		step := req
+		step.Prompt = req.Prompt + text.String()
+		step.MaxTokens = min(k, limit-completion)
+		proposed, err := backend.Tokens(ctx, draft, step)
+		if err != nil {
+			return backend.Result{}, st, fmt.Errorf("draft: %w", err)
+		}
+		if len(proposed) > step.MaxTokens {
+			proposed = proposed[:step.MaxTokens]
+		}
+
+		accepted, next, err := backend.Verify(ctx, target, step, proposed)
+		if err != nil {
+			return backend.Result{}, st, fmt.Errorf("verify: %w", err)
+		}
+		st.rounds++
+		st.proposed += len(proposed)
+		st.accepted += accepted
+
+		pieces := proposed[:accepted:accepted]
+		if next != "" {
+			pieces = append(pieces, next)
+		}
+		if len(pieces) > limit-completion {
+			pieces = pieces[:limit-completion]
+		}
+		for _, p := range pieces {
+			text.WriteString(p)
+			if emit != nil {
+				if err := emit(p); err != nil {
+					return backend.Result{}, st, err
+				}
+			}
+		}
+		completion += len(pieces)
+		if next == "" {
+			break // The target ended the completion.
+		}
+	}
+
+	res := backend.Result{Text: text.String(), CompletionTokens: completion}
+	if tokens, err := target.Tokenize(ctx, req.Prompt); err == nil {
+		res.PromptTokens = len(tokens)
+	}
+	return res, st, nil
+}
@@ -0,0 +1,113 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/groovy-byte/agent-mesh-core/internal/backend"
+	pb "github.com/groovy-byte/agent-mesh-core/proto"
+)
+
+const foxPrompt = "Tell me about the fox."
+
+// foxTarget answers foxPrompt with nine words; foxDraft agrees except that
+// it guesses "cat" after "brown".
+func foxTarget() *backend.Fake {
+	return &backend.Fake{Name: "target", Script: strings.Fields("a quick brown fox jumps over one lazy dog")}
+}
+
+func foxDraft() *backend.Fake {
+	return &backend.Fake{Name: "draft", Script: strings.Fields("a quick brown cat fox jumps over one lazy dog")}
+}
+
+func TestSpeculativeDecoding(t *testing.T) {
+	c := NewInferenceController(NewScheInfer(16*1024*1024, "NVIDIA GeForce RTX 3070 Laptop GPU", 86, false))
+	c.UseDefaultBackend(foxTarget())
+	c.RegisterBackend(ProviderCPUAVX512, foxDraft())
+	ctx := context.Background()
+
+	plain, err := c.Generate(ctx, &pb.InferenceRequest{AgentId: "a", Prompt: foxPrompt})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plain.AcceptanceRate != 0 || plain.DraftPath != "" {
+		t.Errorf("Expected no speculation without the field, got %+v", plain)
+	}
+
+	var streamed strings.Builder
+	req := &pb.InferenceRequest{
+		AgentId:     "a",
+		Prompt:      foxPrompt,
+		Speculative: &pb.SpeculativeDecoding{DraftPath: ProviderCPUAVX512, DraftTokens: 4},
+	}
+	resp, err := c.GenerateStream(ctx, req, func(text string) error {
+		streamed.WriteString(text)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Round 1 keeps "a quick brown" and corrects "cat" to "fox"; round 2
+	// accepts all four tokens and adds "dog"; round 3 drafts nothing and
+	// the target ends.
+	if resp.Text != plain.Text || streamed.String() != resp.Text {
+		t.Errorf("Expected speculation to match the target, got %q (streamed %q), want %q", resp.Text, streamed.String(), plain.Text)
+	}
+	if resp.AcceptanceRate != 7.0/8 {
+		t.Errorf("Expected acceptance rate 0.875, got %v", resp.AcceptanceRate)
+	}
+	if resp.DraftPath != ProviderCPUAVX512 || resp.HardwarePath != ProviderCPUAVX2 {
+		t.Errorf("Expected draft on %s and target on %s, got %s and %s", ProviderCPUAVX512, ProviderCPUAVX2, resp.DraftPath, resp.HardwarePath)
+	}
+	if resp.CompletionTokens != 9 {
+		t.Errorf("Expected 9 tokens, got %d", resp.CompletionTokens)
+	}
+	if ld := c.scheduler.LoadStats()[ProviderCPUAVX512]; ld.Acquired != 1 || ld.InFlight != 0 {
+		t.Errorf("Expected the draft path leased once and released, got %+v", ld)
+	}
+
+	// max_tokens cuts a round short.
+	req.MaxTokens = 5
+	resp, err = c.Generate(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Text != " a quick brown fox jumps" || resp.CompletionTokens != 5 {
+		t.Errorf("Expected 5 tokens, got %q (%d)", resp.Text, resp.CompletionTokens)
+	}
+}
+
+func TestSpeculativeFallsBackOnSameBackend(t *testing.T) {
+	// Without a draft backend the draft path resolves to the target itself.
+	c := NewInferenceController(NewScheInfer(16*1024*1024, "NVIDIA GeForce RTX 3070 Laptop GPU", 86, false))
+	c.UseDefaultBackend(foxTarget())
+	ctx := context.Background()
+
+	plain, err := c.Generate(ctx, &pb.InferenceRequest{AgentId: "a", Prompt: foxPrompt})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var streamed strings.Builder
+	resp, err := c.GenerateStream(ctx, &pb.InferenceRequest{
+		AgentId:     "a",
+		Prompt:      foxPrompt,
+		Speculative: &pb.SpeculativeDecoding{DraftPath: ProviderCPUAVX512},
+	}, func(text string) error {
+		streamed.WriteString(text)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Text != plain.Text || streamed.String() != resp.Text {
+		t.Errorf("Expected plain generation %q, got %q (streamed %q)", plain.Text, resp.Text, streamed.String())
+	}
+	if resp.AcceptanceRate != 0 || resp.DraftPath != "" {
+		t.Errorf("Expected no speculation, got rate %v on %q", resp.AcceptanceRate, resp.DraftPath)
+	}
+	if ld := c.scheduler.LoadStats()[ProviderCPUAVX512]; ld.Acquired != 0 {
+		t.Errorf("Expected no draft lease, got %+v", ld)
+	}
+}
@@ -0,0 +1,145 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	pb "github.com/groovy-byte/agent-mesh-core/proto"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// DefaultStateHistoryDepth is how many snapshots the Arbiter keeps per agent.
+const DefaultStateHistoryDepth = 32
+
+var ErrVersionNotFound = errors.New("arbiter: state version not retained")
+
+// StateSnapshot is one saved AgentAction. Versions start at 1 and increase by
+// one on every SaveState for the agent, even after older snapshots are dropped.
+type StateSnapshot struct {
+	Version uint64
+	SavedAt time.Time
+	Action  *pb.AgentAction
+}
+
+// StateChange is one AgentAction field that differs between two snapshots.
+// Values are rendered as JSON; an empty string means the field is unset.
+type StateChange struct {
+	Field  string
+	Before string
+	After  string
+}
+
+// stateRing is a fixed-capacity ring buffer of snapshots, oldest first.
+type stateRing struct {
+	buf   []StateSnapshot
+	start int
+	n     int
+}
+
+func newStateRing(depth int) *stateRing {
+	if depth <= 0 {
+		depth = DefaultStateHistoryDepth
+	}
+	return &stateRing{buf: make([]StateSnapshot, depth)}
+}
+
+// push appends snap, overwriting the oldest entry when full.
+func (r *stateRing) push(snap StateSnapshot) {
+	if r.n < len(r.buf) {
+		r.buf[(r.start+r.n)%len(r.buf)] = snap
+		r.n++
+		return
+	}
+	r.buf[r.start] = snap
+	r.start = (r.start + 1) % len(r.buf)
+}
+
+// snapshots returns the retained entries, oldest first.
+func (r *stateRing) snapshots() []StateSnapshot {
+	out := make([]StateSnapshot, r.n)
+	for i := 0; i < r.n; i++ {
+		out[i] = r.buf[(r.start+i)%len(r.buf)]
+	}
+	return out
+}
+
+// resize keeps the newest entries that fit in depth.
+func (r *stateRing) resize(depth int) *stateRing {
+	out := newStateRing(depth)
+	snaps := r.snapshots()
+	if len(snaps) > len(out.buf) {
+		snaps = snaps[len(snaps)-len(out.buf):]
+	}
+	for _, s := range snaps {
+		out.push(s)
+	}
+	return out
+}
+
+// DiffActions lists the fields that differ between before and after, in field-number order.
+func DiffActions(before, after *pb.AgentAction) []StateChange {
+	if before == nil {
+		before = &pb.AgentAction{}
+	}
+	if after == nil {
+		after = &pb.AgentAction{}
+	}
+	b, a := before.ProtoReflect(), after.ProtoReflect()
+
+	changes := []StateChange{}
+	fields := b.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		if fieldEqual(b, a, fd) {
+			continue
+		}
+		changes = append(changes, StateChange{
+			Field:  string(fd.Name()),
+			Before: renderField(b, fd),
+			After:  renderField(a, fd),
+		})
+	}
+	return changes
+}
+
+func fieldEqual(x, y protoreflect.Message, fd protoreflect.FieldDescriptor) bool {
+	if x.Has(fd) != y.Has(fd) {
+		return false
+	}
+	if !x.Has(fd) {
+		return true
+	}
+	if fd.Kind() == protoreflect.MessageKind {
+		return proto.Equal(x.Get(fd).Message().Interface(), y.Get(fd).Message().Interface())
+	}
+	return x.Get(fd).Equal(y.Get(fd))
+}
+
+func renderField(m protoreflect.Message, fd protoreflect.FieldDescriptor) string {
+	if !m.Has(fd) {
+		return ""
+	}
+	v := m.Get(fd)
+	switch fd.Kind() {
+	case protoreflect.MessageKind:
+		data, err := protojson.Marshal(v.Message().Interface())
+		if err != nil {
+			return fmt.Sprintf("<%v>", err)
+		}
+		return string(data)
+	case protoreflect.StringKind:
+		return fmt.Sprintf("%q", v.String())
+	case protoreflect.EnumKind:
+		if ev := fd.Enum().Values().ByNumber(v.Enum()); ev != nil {
+			return string(ev.Name())
+		}
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+func cloneAction(a *pb.AgentAction) *pb.AgentAction {
+	return proto.Clone(a).(*pb.AgentAction)
+}
@@ -0,0 +1,157 @@
+package controller
+
+import (
+	"sync"
+
+	pb "github.com/groovy-byte/agent-mesh-core/proto"
+	"google.golang.org/protobuf/proto"
+)
+
+// MeshStore persists registry and arbiter state so a controller restart can
+// recover agents, the VoC contribution matrix and reconstitution snapshots.
+type MeshStore interface {
+	SaveAgent(info *AgentInfo) error
+	DeleteAgent(id string) error
+	LoadAgents() ([]*AgentInfo, error)
+
+	SaveContribution(sourceID, targetID string, score float64) error
+	LoadContributions() (map[string]map[string]float64, error)
+
+	SaveState(state *pb.AgentAction) error
+	LoadStates() (map[string]*pb.AgentAction, error)
+
+	// AppendStateSnapshot records snap in the agent's history, keeping only the newest keep entries.
+	AppendStateSnapshot(snap StateSnapshot, keep int) error
+	LoadStateHistory() (map[string][]StateSnapshot, error)
+
+	Close() error
+}
+
+// MemoryStore is the default MeshStore; it keeps everything in process memory.
+type MemoryStore struct {
+	mu            sync.Mutex
+	agents        map[string]*AgentInfo
+	contributions map[string]map[string]float64
+	states        map[string]*pb.AgentAction
+	history       map[string][]StateSnapshot
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		agents:        make(map[string]*AgentInfo),
+		contributions: make(map[string]map[string]float64),
+		states:        make(map[string]*pb.AgentAction),
+		history:       make(map[string][]StateSnapshot),
+	}
+}
+
+func (m *MemoryStore) SaveAgent(info *AgentInfo) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.agents[info.ID] = cloneAgentInfo(info)
+	return nil
+}
+
+func (m *MemoryStore) DeleteAgent(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.agents, id)
+	return nil
+}
+
+func (m *MemoryStore) LoadAgents() ([]*AgentInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	agents := make([]*AgentInfo, 0, len(m.agents))
+	for _, info := range m.agents {
+		agents = append(agents, cloneAgentInfo(info))
+	}
+	return agents, nil
+}
+
+func (m *MemoryStore) SaveContribution(sourceID, targetID string, score float64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.contributions[sourceID]; !ok {
+		m.contributions[sourceID] = make(map[string]float64)
+	}
+	m.contributions[sourceID][targetID] = score
+	return nil
+}
+
+func (m *MemoryStore) LoadContributions() (map[string]map[string]float64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make(map[string]map[string]float64, len(m.contributions))
+	for source, targets := range m.contributions {
+		out[source] = make(map[string]float64, len(targets))
+		for target, score := range targets {
+			out[source][target] = score
+		}
+	}
+	return out, nil
+}
+
+func (m *MemoryStore) SaveState(state *pb.AgentAction) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.states[state.AgentId] = proto.Clone(state).(*pb.AgentAction)
+	return nil
+}
+
+func (m *MemoryStore) LoadStates() (map[string]*pb.AgentAction, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make(map[string]*pb.AgentAction, len(m.states))
+	for id, state := range m.states {
+		out[id] = proto.Clone(state).(*pb.AgentAction)
+	}
+	return out, nil
+}
+
+func (m *MemoryStore) AppendStateSnapshot(snap StateSnapshot, keep int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	id := snap.Action.AgentId
+	snap.Action = cloneAction(snap.Action)
+	h := append(m.history[id], snap)
+	if keep > 0 && len(h) > keep {
+		h = h[len(h)-keep:]
+	}
+	m.history[id] = h
+	return nil
+}
+
+func (m *MemoryStore) LoadStateHistory() (map[string][]StateSnapshot, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make(map[string][]StateSnapshot, len(m.history))
+	for id, h := range m.history {
+		snaps := make([]StateSnapshot, len(h))
+		for i, snap := range h {
+			snap.Action = cloneAction(snap.Action)
+			snaps[i] = snap
+		}
+		out[id] = snaps
+	}
+	return out, nil
+}
+
+func (m *MemoryStore) Close() error {
+	return nil
+}
+
+// cloneAgentInfo deep-copies an AgentInfo so stored records never alias live registry state.
+func cloneAgentInfo(info *AgentInfo) *AgentInfo {
+	c := *info
+	c.Capabilities = make([]string, len(info.Capabilities))
+	copy(c.Capabilities, info.Capabilities)
+	c.Neighbors = make([]string, len(info.Neighbors))
+	copy(c.Neighbors, info.Neighbors)
+	c.FailedTasks = make([]string, len(info.FailedTasks))
+	copy(c.FailedTasks, info.FailedTasks)
+	if info.CurrentLoad != nil {
+		c.CurrentLoad = proto.Clone(info.CurrentLoad).(*pb.OSResources)
+	}
+	return &c
+}
@@ -0,0 +1,320 @@
+package controller
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	pb "github.com/groovy-byte/agent-mesh-core/proto"
+	_ "github.com/mattn/go-sqlite3"
+	"google.golang.org/protobuf/proto"
+)
+
+// sqliteMigrations are applied in order; each entry bumps the schema version by one.
+// Never edit an existing entry — append a new one instead.
+var sqliteMigrations = []string{
+	// 1: agents, VoC contribution matrix and reconstitution snapshots.
+	`CREATE TABLE agents (
+		id             TEXT PRIMARY KEY,
+		role           INTEGER NOT NULL,
+		capabilities   TEXT NOT NULL,
+		neighbors      TEXT NOT NULL,
+		utility_score  REAL NOT NULL,
+		total_latency  REAL NOT NULL,
+		total_tokens   INTEGER NOT NULL,
+		request_count  INTEGER NOT NULL,
+		tool_calls     INTEGER NOT NULL,
+		failed_tasks   TEXT NOT NULL,
+		max_throughput REAL NOT NULL
+	);
+	CREATE TABLE contributions (
+		source_id TEXT NOT NULL,
+		target_id TEXT NOT NULL,
+		score     REAL NOT NULL,
+		PRIMARY KEY (source_id, target_id)
+	);
+	CREATE TABLE agent_states (
+		agent_id TEXT PRIMARY KEY,
+		action   BLOB NOT NULL,
+		saved_at INTEGER NOT NULL
+	);`,
+	// 2: versioned reconstitution history; existing snapshots become version 1.
+	`CREATE TABLE agent_state_history (
+		agent_id TEXT NOT NULL,
+		version  INTEGER NOT NULL,
+		action   BLOB NOT NULL,
+		saved_at INTEGER NOT NULL,
+		PRIMARY KEY (agent_id, version)
+	);
+	INSERT INTO agent_state_history (agent_id, version, action, saved_at)
+		SELECT agent_id, 1, action, saved_at FROM agent_states;`,
+	// 3: inference requests refused by admission control.
+	`ALTER TABLE agents ADD COLUMN rejected INTEGER NOT NULL DEFAULT 0;`,
+}
+
+// SQLiteStore is a MeshStore backed by a SQLite database file.
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteStore opens (or creates) the database at path and migrates it to the latest schema.
+func NewSQLiteStore(path string) (*SQLiteStore, error) {
+	db, err := sql.Open("sqlite3", path+"?_busy_timeout=5000&_journal_mode=WAL")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open mesh store: %w", err)
+	}
+	// SQLite serializes writers; a single connection avoids SQLITE_BUSY under write-through.
+	db.SetMaxOpenConns(1)
+
+	s := &SQLiteStore{db: db}
+	if err := s.migrate(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// SchemaVersion reports the number of migrations applied to the database.
+func (s *SQLiteStore) SchemaVersion() (int, error) {
+	var version int
+	err := s.db.QueryRow("SELECT COALESCE(MAX(version), 0) FROM schema_migrations").Scan(&version)
+	return version, err
+}
+
+func (s *SQLiteStore) migrate() error {
+	if _, err := s.db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    INTEGER PRIMARY KEY,
+		applied_at INTEGER NOT NULL
+	)`); err != nil {
+		return fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+
+	current, err := s.SchemaVersion()
+	if err != nil {
+		return fmt.Errorf("failed to read schema version: %w", err)
+	}
+
+	for i := current; i < len(sqliteMigrations); i++ {
+		version := i + 1
+		tx, err := s.db.Begin()
+		if err != nil {
+			return fmt.Errorf("migration %d: %w", version, err)
+		}
+		if _, err := tx.Exec(sqliteMigrations[i]); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %d: %w", version, err)
+		}
+		if _, err := tx.Exec("INSERT INTO schema_migrations (version, applied_at) VALUES (?, ?)", version, time.Now().Unix()); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %d: %w", version, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("migration %d: %w", version, err)
+		}
+		log.Printf("[Store] Applied schema migration %d", version)
+	}
+	return nil
+}
+
+func (s *SQLiteStore) SaveAgent(info *AgentInfo) error {
+	caps, err := json.Marshal(info.Capabilities)
+	if err != nil {
+		return err
+	}
+	neighbors, err := json.Marshal(info.Neighbors)
+	if err != nil {
+		return err
+	}
+	failed, err := json.Marshal(info.FailedTasks)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.Exec(`INSERT INTO agents
+		(id, role, capabilities, neighbors, utility_score, total_latency, total_tokens, request_count, tool_calls, failed_tasks, max_throughput, rejected)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET
+			role = excluded.role,
+			capabilities = excluded.capabilities,
+			neighbors = excluded.neighbors,
+			utility_score = excluded.utility_score,
+			total_latency = excluded.total_latency,
+			total_tokens = excluded.total_tokens,
+			request_count = excluded.request_count,
+			tool_calls = excluded.tool_calls,
+			failed_tasks = excluded.failed_tasks,
+			max_throughput = excluded.max_throughput,
+			rejected = excluded.rejected`,
+		info.ID, int32(info.Role), string(caps), string(neighbors), info.UtilityScore, info.TotalLatency,
+		info.TotalTokens, info.RequestCount, info.ToolCalls, string(failed), info.MaxThroughput, info.Rejected)
+	if err != nil {
+		return fmt.Errorf("failed to save agent %s: %w", info.ID, err)
+	}
+	return nil
+}
+
+func (s *SQLiteStore) DeleteAgent(id string) error {
+	if _, err := s.db.Exec("DELETE FROM agents WHERE id = ?", id); err != nil {
+		return fmt.Errorf("failed to delete agent %s: %w", id, err)
+	}
+	return nil
+}
+
+func (s *SQLiteStore) LoadAgents() ([]*AgentInfo, error) {
+	rows, err := s.db.Query(`SELECT id, role, capabilities, neighbors, utility_score, total_latency,
+		total_tokens, request_count, tool_calls, failed_tasks, max_throughput, rejected FROM agents`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var agents []*AgentInfo
+	for rows.Next() {
+		var (
+			info                    AgentInfo
+			role                    int32
+			caps, neighbors, failed string
+		)
+		if err := rows.Scan(&info.ID, &role, &caps, &neighbors, &info.UtilityScore, &info.TotalLatency,
+			&info.TotalTokens, &info.RequestCount, &info.ToolCalls, &failed, &info.MaxThroughput, &info.Rejected); err != nil {
+			return nil, err
+		}
+		info.Role = pb.AgentRole(role)
+		if err := json.Unmarshal([]byte(caps), &info.Capabilities); err != nil {
+			return nil, fmt.Errorf("agent %s: bad capabilities: %w", info.ID, err)
+		}
+		if err := json.Unmarshal([]byte(neighbors), &info.Neighbors); err != nil {
+			return nil, fmt.Errorf("agent %s: bad neighbors: %w", info.ID, err)
+		}
+		if err := json.Unmarshal([]byte(failed), &info.FailedTasks); err != nil {
+			return nil, fmt.Errorf("agent %s: bad failed tasks: %w", info.ID, err)
+		}
+		agents = append(agents, &info)
+	}
+	return agents, rows.Err()
+}
+
+func (s *SQLiteStore) SaveContribution(sourceID, targetID string, score float64) error {
+	_, err := s.db.Exec(`INSERT INTO contributions (source_id, target_id, score) VALUES (?, ?, ?)
+		ON CONFLICT(source_id, target_id) DO UPDATE SET score = excluded.score`, sourceID, targetID, score)
+	if err != nil {
+		return fmt.Errorf("failed to save contribution: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStore) LoadContributions() (map[string]map[string]float64, error) {
+	rows, err := s.db.Query("SELECT source_id, target_id, score FROM contributions")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make(map[string]map[string]float64)
+	for rows.Next() {
+		var source, target string
+		var score float64
+		if err := rows.Scan(&source, &target, &score); err != nil {
+			return nil, err
+		}
+		if _, ok := out[source]; !ok {
+			out[source] = make(map[string]float64)
+		}
+		out[source][target] = score
+	}
+	return out, rows.Err()
+}
+
+func (s *SQLiteStore) SaveState(state *pb.AgentAction) error {
+	data, err := proto.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("failed to marshal state: %w", err)
+	}
+	_, err = s.db.Exec(`INSERT INTO agent_states (agent_id, action, saved_at) VALUES (?, ?, ?)
+		ON CONFLICT(agent_id) DO UPDATE SET action = excluded.action, saved_at = excluded.saved_at`,
+		state.AgentId, data, time.Now().UnixNano())
+	if err != nil {
+		return fmt.Errorf("failed to save state for %s: %w", state.AgentId, err)
+	}
+	return nil
+}
+
+func (s *SQLiteStore) LoadStates() (map[string]*pb.AgentAction, error) {
+	rows, err := s.db.Query("SELECT agent_id, action FROM agent_states")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make(map[string]*pb.AgentAction)
+	for rows.Next() {
+		var id string
+		var data []byte
+		if err := rows.Scan(&id, &data); err != nil {
+			return nil, err
+		}
+		state := &pb.AgentAction{}
+		if err := proto.Unmarshal(data, state); err != nil {
+			return nil, fmt.Errorf("agent %s: corrupt state: %w", id, err)
+		}
+		out[id] = state
+	}
+	return out, rows.Err()
+}
+
+func (s *SQLiteStore) AppendStateSnapshot(snap StateSnapshot, keep int) error {
+	data, err := proto.Marshal(snap.Action)
+	if err != nil {
+		return fmt.Errorf("failed to marshal state: %w", err)
+	}
+	id := snap.Action.AgentId
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`INSERT OR REPLACE INTO agent_state_history (agent_id, version, action, saved_at) VALUES (?, ?, ?, ?)`,
+		id, snap.Version, data, snap.SavedAt.UnixNano()); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to append state for %s: %w", id, err)
+	}
+	if keep > 0 {
+		if _, err := tx.Exec(`DELETE FROM agent_state_history WHERE agent_id = ? AND version <= ?`,
+			id, int64(snap.Version)-int64(keep)); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to trim state history for %s: %w", id, err)
+		}
+	}
+	return tx.Commit()
+}
+
+func (s *SQLiteStore) LoadStateHistory() (map[string][]StateSnapshot, error) {
+	rows, err := s.db.Query("SELECT agent_id, version, action, saved_at FROM agent_state_history ORDER BY agent_id, version")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make(map[string][]StateSnapshot)
+	for rows.Next() {
+		var id string
+		var version uint64
+		var data []byte
+		var savedAt int64
+		if err := rows.Scan(&id, &version, &data, &savedAt); err != nil {
+			return nil, err
+		}
+		state := &pb.AgentAction{}
+		if err := proto.Unmarshal(data, state); err != nil {
+			return nil, fmt.Errorf("agent %s version %d: corrupt state: %w", id, version, err)
+		}
+		out[id] = append(out[id], StateSnapshot{Version: version, SavedAt: time.Unix(0, savedAt), Action: state})
+	}
+	return out, rows.Err()
+}
+
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,176 @@
+package controller
+
+import (
+	"path/filepath"
+	"testing"
+
+	pb "github.com/groovy-byte/agent-mesh-core/proto"
+)
+
+func TestMeshStoreRestartRecovery(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "mesh_state.db")
+
+	stores := []struct {
+		name string
+		open func(t *testing.T) MeshStore
+	}{
+		{
+			name: "Memory",
+			open: func() func(t *testing.T) MeshStore {
+				shared := NewMemoryStore()
+				return func(t *testing.T) MeshStore { return shared }
+			}(),
+		},
+		{
+			name: "SQLite",
+			open: func(t *testing.T) MeshStore {
+				s, err := NewSQLiteStore(dbPath)
+				if err != nil {
+					t.Fatal(err)
+				}
+				return s
+			},
+		},
+	}
+
+	for _, st := range stores {
+		t.Run(st.name, func(t *testing.T) {
+			// 1. First controller lifetime.
+			store := st.open(t)
+			r, err := NewMeshRegistryWithStore(store)
+			if err != nil {
+				t.Fatal(err)
+			}
+			a, err := NewArbiterWithStore(store, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			r.RegisterAgent(&pb.HandshakeRequest{AgentId: "scout", Capabilities: []string{"SEARCH"}})
+			r.RegisterAgent(&pb.HandshakeRequest{AgentId: "coder", InitialRole: pb.AgentRole_STRATEGIC})
+			r.RecordMetrics("coder", 120.0, 64, 9.5)
+			r.RecordTaskResult("coder", false, "BUILD", 2)
+			r.RecordRejection("coder")
+			r.RecordContribution("scout", "coder", 0.4)
+			a.SaveState(&pb.AgentAction{AgentId: "coder", ActionType: "REASONING", ReasoningChain: "step 2 of 5"})
+			a.SaveState(&pb.AgentAction{AgentId: "coder", ActionType: "REASONING", ReasoningChain: "step 3 of 5"})
+
+			// 2. Crash: drop all in-memory structures and reopen the store.
+			if st.name == "SQLite" {
+				store.Close()
+			}
+			store = st.open(t)
+			defer store.Close()
+
+			r2, err := NewMeshRegistryWithStore(store)
+			if err != nil {
+				t.Fatal(err)
+			}
+			a2, err := NewArbiterWithStore(store, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			coder, ok := r2.GetAgent("coder")
+			if !ok {
+				t.Fatal("Agent coder was not restored")
+			}
+			if coder.Role != pb.AgentRole_STRATEGIC || coder.TotalTokens != 64 || coder.MaxThroughput != 9.5 {
+				t.Errorf("Restored agent metrics mismatch: %+v", coder)
+			}
+			if coder.ToolCalls != 2 || len(coder.FailedTasks) != 1 || coder.FailedTasks[0] != "BUILD" || coder.Rejected != 1 {
+				t.Errorf("Restored task audit mismatch: %+v", coder)
+			}
+			if coder.Liveness != LivenessAlive {
+				t.Errorf("Restored agent should be ALIVE, got %s", coder.Liveness)
+			}
+
+			scout, _ := r2.GetAgent("scout")
+			if len(scout.Capabilities) != 1 || scout.Capabilities[0] != "SEARCH" {
+				t.Errorf("Restored capabilities mismatch: %v", scout.Capabilities)
+			}
+
+			if score := r2.GetContributionDetail("scout")["coder"]; score != 0.4 {
+				t.Errorf("Expected restored contribution 0.4, got %f", score)
+			}
+
+			state, ok := a2.GetState("coder")
+			if !ok || state.ReasoningChain != "step 3 of 5" {
+				t.Errorf("Reconstitution snapshot not restored: %v", state)
+			}
+			if prev, err := a2.GetStateVersion("coder", 1); err != nil || prev.Action.ReasoningChain != "step 2 of 5" {
+				t.Errorf("State history not restored: %+v (%v)", prev, err)
+			}
+			if next := a2.SaveState(&pb.AgentAction{AgentId: "coder"}); next.Version != 3 {
+				t.Errorf("Expected versions to continue at 3 after restart, got %d", next.Version)
+			}
+		})
+	}
+}
+
+func TestSQLiteStoreEviction(t *testing.T) {
+	store, err := NewSQLiteStore(filepath.Join(t.TempDir(), "mesh_state.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	r, err := NewMeshRegistryWithStore(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.RegisterAgent(&pb.HandshakeRequest{AgentId: "a"})
+	r.RegisterAgent(&pb.HandshakeRequest{AgentId: "b"})
+	r.EvictAgent("a")
+
+	agents, err := store.LoadAgents()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(agents) != 1 || agents[0].ID != "b" {
+		t.Fatalf("Expected only b to remain persisted, got %d agents", len(agents))
+	}
+	if len(agents[0].Neighbors) != 0 {
+		t.Errorf("Evicted agent still persisted as neighbor: %v", agents[0].Neighbors)
+	}
+}
+
+func TestSQLiteStoreMigrationsIdempotent(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "mesh_state.db")
+
+	for i := 0; i < 2; i++ {
+		store, err := NewSQLiteStore(dbPath)
+		if err != nil {
+			t.Fatalf("Open %d failed: %v", i, err)
+		}
+		version, err := store.SchemaVersion()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if version != len(sqliteMigrations) {
+			t.Errorf("Expected schema version %d, got %d", len(sqliteMigrations), version)
+		}
+		store.Close()
+	}
+}
+
+func TestArbiterRestoresConfiguredHistoryDepth(t *testing.T) {
+	store := NewMemoryStore()
+	depth := DefaultStateHistoryDepth + 8
+	a, err := NewArbiterWithStore(store, depth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < depth; i++ {
+		a.SaveState(&pb.AgentAction{AgentId: "coder", ActionType: "REASONING"})
+	}
+
+	// The restart must not cut the history back to the default depth.
+	a2, err := NewArbiterWithStore(store, depth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if history := a2.StateHistory("coder"); len(history) != depth || history[0].Version != 1 {
+		t.Errorf("Expected all %d versions restored, got %d", depth, len(history))
+	}
+}
@@ -0,0 +1,365 @@
+// Package openai serves the OpenAI /v1/chat/completions and /v1/completions
+// HTTP API on top of the mesh inference path, so editors and eval harnesses
+// can use the mesh without speaking mesh.proto.
+package openai
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/groovy-byte/agent-mesh-core/internal/backend"
+	"github.com/groovy-byte/agent-mesh-core/internal/controller"
+	pb "github.com/groovy-byte/agent-mesh-core/proto"
+)
+
+// Response headers describing how the mesh served a request. Streaming
+// responses send them as trailers, since routing happens after the headers
+// are flushed.
+const (
+	HeaderHardwarePath = "X-Mesh-Hardware-Path"
+	HeaderLatencyMs    = "X-Mesh-Latency-Ms"
+	HeaderThroughput   = "X-Mesh-Throughput-Gbs"
+
+	// HeaderAgentID names the calling agent when the body has no "user".
+	HeaderAgentID = "X-Agent-Id"
+)
+
+// DefaultAgentID is charged for requests that do not identify an agent.
+const DefaultAgentID = "openai"
+
+// DefaultTemperature is OpenAI's sampling temperature for requests that omit
+// one; only an explicit 0 asks for greedy decoding.
+const DefaultTemperature = 1
+
+// ShutdownTimeout bounds how long Run waits for open requests to finish.
+const ShutdownTimeout = 10 * time.Second
+
+// Inferer runs an inference request, streaming through emit when it is
+// non-nil. server.Server implements it with budgets and metrics.
+type Inferer interface {
+	Infer(ctx context.Context, req *pb.InferenceRequest, emit backend.TokenFunc) (*pb.InferenceResponse, error)
+}
+
+// Handler translates OpenAI requests into mesh inference calls.
+type Handler struct {
+	inf   Inferer
+	model string
+	mux   *http.ServeMux
+}
+
+// NewHandler serves inf under the model name reported to clients. The model
+// field of incoming requests is echoed back but does not select a model.
+func NewHandler(inf Inferer, model string) *Handler {
+	h := &Handler{inf: inf, model: model, mux: http.NewServeMux()}
+	h.mux.HandleFunc("POST /v1/chat/completions", h.chatCompletions)
+	h.mux.HandleFunc("POST /v1/completions", h.completions)
+	h.mux.HandleFunc("GET /v1/models", h.models)
+	return h
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+// Run serves the facade on lis until ctx is cancelled, then waits for open
+// requests before returning.
+func (h *Handler) Run(ctx context.Context, lis net.Listener) error {
+	srv := &http.Server{Handler: h, ReadHeaderTimeout: 10 * time.Second}
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.Serve(lis)
+	}()
+	log.Printf("[OpenAI] 🌐 Facade listening on %s", lis.Addr())
+
+	select {
+	case err := <-errCh:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), ShutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Printf("[OpenAI] ⚠️ Graceful shutdown timed out: %v", err)
+		return srv.Close()
+	}
+	return nil
+}
+
+func (h *Handler) chatCompletions(w http.ResponseWriter, r *http.Request) {
+	var req chatCompletionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request_error", fmt.Sprintf("invalid request body: %v", err))
+		return
+	}
+	if len(req.Messages) == 0 {
+		writeError(w, http.StatusBadRequest, "invalid_request_error", "messages is required")
+		return
+	}
+	h.generate(w, r, req.generationParams, chatPrompt(req.Messages), true)
+}
+
+func (h *Handler) completions(w http.ResponseWriter, r *http.Request) {
+	var req completionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request_error", fmt.Sprintf("invalid request body: %v", err))
+		return
+	}
+	if len(req.Prompt) != 1 {
+		writeError(w, http.StatusBadRequest, "invalid_request_error", "exactly one prompt is supported per request")
+		return
+	}
+	h.generate(w, r, req.generationParams, req.Prompt[0], false)
+}
+
+func (h *Handler) models(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, modelList{
+		Object: "list",
+		Data:   []model{{ID: h.model, Object: "model", OwnedBy: "agent-mesh"}},
+	})
+}
+
+// chatPrompt flattens a conversation into "role: content" lines and cues
+// the assistant's turn.
+func chatPrompt(msgs []chatMessage) string {
+	var b strings.Builder
+	for _, m := range msgs {
+		fmt.Fprintf(&b, "%s: %s\n", m.Role, m.Content)
+	}
+	b.WriteString("assistant:")
+	return b.String()
+}
+
+// completion carries what both endpoints need to render a response.
+type completion struct {
+	id      string
+	created int64
+	model   string
+	chat    bool
+}
+
+func (h *Handler) generate(w http.ResponseWriter, r *http.Request, params generationParams, prompt string, chat bool) {
+	req := &pb.InferenceRequest{
+		AgentId:     agentID(r, params),
+		Prompt:      prompt,
+		MaxTokens:   params.maxTokens(),
+		Temperature: DefaultTemperature,
+	}
+	if params.Temperature != nil {
+		req.Temperature = *params.Temperature
+	}
+	c := completion{id: newID(chat), created: time.Now().Unix(), model: params.Model, chat: chat}
+	if c.model == "" {
+		c.model = h.model
+	}
+
+	if params.Stream {
+		h.stream(w, r, req, c, params.includeUsage())
+		return
+	}
+
+	resp, err := h.inf.Infer(r.Context(), req, nil)
+	if err != nil {
+		writeInferenceError(w, err)
+		return
+	}
+	setMeshHeaders(w.Header(), resp)
+	finish := finishReason(req, resp)
+	out := completionResponse{
+		ID:      c.id,
+		Created: c.created,
+		Model:   c.model,
+		Usage:   usageOf(resp),
+	}
+	if chat {
+		out.Object = "chat.completion"
+		out.Choices = []chatChoice{{
+			Message:      &chatMessage{Role: "assistant", Content: messageContent(resp.Text)},
+			FinishReason: &finish,
+		}}
+	} else {
+		out.Object = "text_completion"
+		out.Choices = []completionChoice{{Text: resp.Text, FinishReason: &finish}}
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// stream answers with server-sent events. The first token commits the 200
+// status, so errors before it get a normal JSON error response and errors
+// after it are sent as a final error event.
+func (h *Handler) stream(w http.ResponseWriter, r *http.Request, req *pb.InferenceRequest, c completion, includeUsage bool) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "server_error", "streaming is not supported by this connection")
+		return
+	}
+
+	started := false
+	start := func() {
+		if started {
+			return
+		}
+		started = true
+		hdr := w.Header()
+		hdr.Set("Content-Type", "text/event-stream")
+		hdr.Set("Cache-Control", "no-cache")
+		hdr.Set("Trailer", strings.Join([]string{HeaderHardwarePath, HeaderLatencyMs, HeaderThroughput}, ", "))
+		w.WriteHeader(http.StatusOK)
+	}
+	send := func(v any) error {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "data: %s\n\n", data); err != nil {
+			return err
+		}
+		flusher.Flush()
+		return nil
+	}
+
+	first := true
+	resp, err := h.inf.Infer(r.Context(), req, func(text string) error {
+		start()
+		ev := c.chunk(text, nil)
+		if first && c.chat {
+			ev.Choices.([]chatChoice)[0].Delta.Role = "assistant"
+		}
+		first = false
+		return send(ev)
+	})
+	if err != nil {
+		if !started {
+			writeInferenceError(w, err)
+			return
+		}
+		if r.Context().Err() == nil {
+			send(errorResponse{Error: apiError{Message: err.Error(), Type: "server_error"}})
+		}
+		return
+	}
+
+	start()
+	finish := finishReason(req, resp)
+	send(c.chunk("", &finish))
+	if includeUsage {
+		send(completionResponse{
+			ID:      c.id,
+			Object:  c.object(),
+			Created: c.created,
+			Model:   c.model,
+			Choices: []any{},
+			Usage:   usageOf(resp),
+		})
+	}
+	fmt.Fprint(w, "data: [DONE]\n\n")
+	setMeshHeaders(w.Header(), resp)
+	flusher.Flush()
+}
+
+func (c completion) object() string {
+	if c.chat {
+		return "chat.completion.chunk"
+	}
+	return "text_completion"
+}
+
+// chunk builds one streamed event; finish is set on the last one.
+func (c completion) chunk(text string, finish *string) completionResponse {
+	out := completionResponse{ID: c.id, Object: c.object(), Created: c.created, Model: c.model}
+	if c.chat {
+		out.Choices = []chatChoice{{Delta: &chatDelta{Content: text}, FinishReason: finish}}
+	} else {
+		out.Choices = []completionChoice{{Text: text, FinishReason: finish}}
+	}
+	return out
+}
+
+// agentID picks the agent charged for a request: the "user" field, then
+// the X-Agent-Id header, then DefaultAgentID.
+func agentID(r *http.Request, params generationParams) string {
+	if params.User != "" {
+		return params.User
+	}
+	if id := r.Header.Get(HeaderAgentID); id != "" {
+		return id
+	}
+	return DefaultAgentID
+}
+
+func finishReason(req *pb.InferenceRequest, resp *pb.InferenceResponse) string {
+	if req.MaxTokens > 0 && resp.CompletionTokens >= req.MaxTokens {
+		return "length"
+	}
+	return "stop"
+}
+
+// usageOf reports TokensUsed, split as the backend counted it.
+func usageOf(resp *pb.InferenceResponse) *usage {
+	return &usage{
+		PromptTokens:     resp.PromptTokens,
+		CompletionTokens: resp.CompletionTokens,
+		TotalTokens:      resp.TokensUsed,
+	}
+}
+
+func setMeshHeaders(hdr http.Header, resp *pb.InferenceResponse) {
+	hdr.Set(HeaderHardwarePath, resp.HardwarePath)
+	hdr.Set(HeaderLatencyMs, strconv.FormatFloat(float64(resp.LatencyMs), 'f', 3, 32))
+	hdr.Set(HeaderThroughput, strconv.FormatFloat(float64(resp.ThroughputGbs), 'f', 6, 32))
+}
+
+func newID(chat bool) string {
+	var b [12]byte
+	rand.Read(b[:])
+	if chat {
+		return "chatcmpl-" + hex.EncodeToString(b[:])
+	}
+	return "cmpl-" + hex.EncodeToString(b[:])
+}
+
+// writeInferenceError maps an Infer error to an OpenAI error response.
+func writeInferenceError(w http.ResponseWriter, err error) {
+	var be *controller.BudgetError
+	switch {
+	case errors.As(err, &be):
+		if be.RetryAfter > 0 {
+			w.Header().Set("Retry-After", strconv.FormatInt(int64(math.Ceil(be.RetryAfter.Seconds())), 10))
+		}
+		writeError(w, http.StatusTooManyRequests, "rate_limit_error", err.Error())
+	case errors.Is(err, backend.ErrEmptyPrompt):
+		writeError(w, http.StatusBadRequest, "invalid_request_error", "prompt is required")
+	case errors.Is(err, context.DeadlineExceeded):
+		writeError(w, http.StatusGatewayTimeout, "timeout", err.Error())
+	case errors.Is(err, context.Canceled):
+		// The client is gone; there is nobody to answer.
+	default:
+		writeError(w, http.StatusInternalServerError, "server_error", fmt.Sprintf("inference failed: %v", err))
+	}
+}
+
+func writeError(w http.ResponseWriter, code int, typ, msg string) {
+	writeJSON(w, code, errorResponse{Error: apiError{Message: msg, Type: typ}})
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("[OpenAI] ⚠️ Failed to write response: %v", err)
+	}
+}
@@ -0,0 +1,185 @@
+package openai
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/groovy-byte/agent-mesh-core/internal/backend"
+	"github.com/groovy-byte/agent-mesh-core/internal/controller"
+	pb "github.com/groovy-byte/agent-mesh-core/proto"
+)
+
+// controllerInferer runs requests straight through an InferenceController
+// and can be told to reject them like an exhausted budget.
+type controllerInferer struct {
+	c      *controller.InferenceController
+	reject error
+	last   *pb.InferenceRequest
+}
+
+func (ci *controllerInferer) Infer(ctx context.Context, req *pb.InferenceRequest, emit backend.TokenFunc) (*pb.InferenceResponse, error) {
+	ci.last = req
+	if ci.reject != nil {
+		return nil, ci.reject
+	}
+	if emit == nil {
+		return ci.c.Generate(ctx, req)
+	}
+	return ci.c.GenerateStream(ctx, req, emit)
+}
+
+func startFacade(t *testing.T) (*httptest.Server, *controllerInferer) {
+	t.Helper()
+	s := controller.NewScheInfer(16*1024*1024, "NVIDIA GeForce RTX 3070 Laptop GPU", 86, false)
+	inf := &controllerInferer{c: controller.NewInferenceController(s)}
+	srv := httptest.NewServer(NewHandler(inf, "vextra"))
+	t.Cleanup(srv.Close)
+	return srv, inf
+}
+
+func post(t *testing.T, url, body string) *http.Response {
+	t.Helper()
+	resp, err := http.Post(url, "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+func TestChatCompletion(t *testing.T) {
+	srv, inf := startFacade(t)
+
+	resp := post(t, srv.URL+"/v1/chat/completions", `{
+		"model": "gpt-4o",
+		"user": "editor",
+		"max_tokens": 3,
+		"messages": [
+			{"role": "system", "content": "be brief"},
+			{"role": "user", "content": [{"type": "text", "text": "hello mesh"}]}
+		]
+	}`)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected 200, got %s", resp.Status)
+	}
+	if got := resp.Header.Get(HeaderHardwarePath); got != "CPU_AVX2" {
+		t.Errorf("Expected the hardware path header, got %q", got)
+	}
+	if inf.last.AgentId != "editor" || inf.last.Prompt != "system: be brief\nuser: hello mesh\nassistant:" {
+		t.Errorf("Unexpected translated request: %+v", inf.last)
+	}
+	if inf.last.Temperature != DefaultTemperature {
+		t.Errorf("Expected an omitted temperature to default to %v, got %v", DefaultTemperature, inf.last.Temperature)
+	}
+
+	// An explicit 0 still asks for greedy decoding.
+	post(t, srv.URL+"/v1/chat/completions", `{"temperature": 0, "messages": [{"role": "user", "content": "hi"}]}`)
+	if inf.last.Temperature != 0 {
+		t.Errorf("Expected temperature 0, got %v", inf.last.Temperature)
+	}
+
+	var out struct {
+		Object  string `json:"object"`
+		Model   string `json:"model"`
+		Choices []struct {
+			Message      chatMessage `json:"message"`
+			FinishReason string      `json:"finish_reason"`
+		} `json:"choices"`
+		Usage usage `json:"usage"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Object != "chat.completion" || out.Model != "gpt-4o" || len(out.Choices) != 1 {
+		t.Fatalf("Unexpected response: %+v", out)
+	}
+	if c := out.Choices[0]; c.Message.Role != "assistant" || c.Message.Content != "[vextra-fake] system: be brief" || c.FinishReason != "length" {
+		t.Errorf("Unexpected choice: %+v", c)
+	}
+	if out.Usage.TotalTokens != out.Usage.PromptTokens+out.Usage.CompletionTokens || out.Usage.CompletionTokens != 3 {
+		t.Errorf("Unexpected usage: %+v", out.Usage)
+	}
+}
+
+func TestCompletionStream(t *testing.T) {
+	srv, _ := startFacade(t)
+
+	resp := post(t, srv.URL+"/v1/completions", `{
+		"prompt": "stream these words",
+		"stream": true,
+		"stream_options": {"include_usage": true}
+	}`)
+	if ct := resp.Header.Get("Content-Type"); resp.StatusCode != http.StatusOK || ct != "text/event-stream" {
+		t.Fatalf("Expected an event stream, got %s (%s)", resp.Status, ct)
+	}
+
+	var text, finish string
+	var total uint32
+	done := false
+	sc := bufio.NewScanner(resp.Body)
+	for sc.Scan() {
+		data, ok := strings.CutPrefix(sc.Text(), "data: ")
+		if !ok {
+			continue
+		}
+		if data == "[DONE]" {
+			done = true
+			continue
+		}
+		var ev struct {
+			Object  string             `json:"object"`
+			Choices []completionChoice `json:"choices"`
+			Usage   *usage             `json:"usage"`
+		}
+		if err := json.Unmarshal([]byte(data), &ev); err != nil {
+			t.Fatalf("Bad event %q: %v", data, err)
+		}
+		if ev.Object != "text_completion" {
+			t.Errorf("Unexpected object %q", ev.Object)
+		}
+		for _, c := range ev.Choices {
+			text += c.Text
+			if c.FinishReason != nil {
+				finish = *c.FinishReason
+			}
+		}
+		if ev.Usage != nil {
+			total = ev.Usage.TotalTokens
+		}
+	}
+	if !done || text != "[vextra-fake] stream these words" || finish != "stop" || total != 6 {
+		t.Errorf("Unexpected stream: done=%v text=%q finish=%q total=%d", done, text, finish, total)
+	}
+	if got := resp.Trailer.Get(HeaderHardwarePath); got != "CPU_AVX2" {
+		t.Errorf("Expected the hardware path trailer, got %q", got)
+	}
+}
+
+func TestFacadeErrors(t *testing.T) {
+	srv, inf := startFacade(t)
+
+	if resp := post(t, srv.URL+"/v1/chat/completions", `{"messages": []}`); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected 400 without messages, got %s", resp.Status)
+	}
+	if resp := post(t, srv.URL+"/v1/completions", `{"prompt": "  "}`); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an empty prompt, got %s", resp.Status)
+	}
+
+	inf.reject = &controller.BudgetError{AgentID: DefaultAgentID, Reason: "token budget exhausted", RetryAfter: 1500 * time.Millisecond}
+	resp := post(t, srv.URL+"/v1/completions", `{"prompt": "hi", "stream": true}`)
+	if resp.StatusCode != http.StatusTooManyRequests || resp.Header.Get("Retry-After") != "2" {
+		t.Errorf("Expected 429 with Retry-After 2, got %s %q", resp.Status, resp.Header.Get("Retry-After"))
+	}
+	var body errorResponse
+	data, _ := io.ReadAll(resp.Body)
+	if err := json.Unmarshal(data, &body); err != nil || body.Error.Type != "rate_limit_error" {
+		t.Errorf("Expected an OpenAI error body, got %s", data)
+	}
+}
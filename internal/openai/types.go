@@ -0,0 +1,151 @@
+package openai
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+// The subset of the OpenAI wire format the facade understands. Unknown
+// fields (n, stop, tools, ...) are accepted and ignored.
+
+type chatMessage struct {
+	Role    string         `json:"role"`
+	Content messageContent `json:"content"`
+}
+
+// messageContent is either a plain string or an array of content parts, of
+// which only the text parts are kept.
+type messageContent string
+
+func (c *messageContent) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*c = messageContent(s)
+		return nil
+	}
+	var parts []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(data, &parts); err != nil {
+		return errors.New("content must be a string or an array of content parts")
+	}
+	var b strings.Builder
+	for _, p := range parts {
+		if p.Type == "text" {
+			b.WriteString(p.Text)
+		}
+	}
+	*c = messageContent(b.String())
+	return nil
+}
+
+// promptList is a completion prompt: a string or an array of strings.
+type promptList []string
+
+func (p *promptList) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*p = promptList{s}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return errors.New("prompt must be a string or an array of strings")
+	}
+	*p = list
+	return nil
+}
+
+type streamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+// generationParams are the fields shared by both completion endpoints.
+type generationParams struct {
+	Model               string         `json:"model"`
+	MaxTokens           uint32         `json:"max_tokens"`
+	MaxCompletionTokens uint32         `json:"max_completion_tokens"`
+	Temperature         *float32       `json:"temperature"`
+	Stream              bool           `json:"stream"`
+	StreamOptions       *streamOptions `json:"stream_options"`
+	User                string         `json:"user"`
+}
+
+func (p generationParams) maxTokens() uint32 {
+	if p.MaxCompletionTokens > 0 {
+		return p.MaxCompletionTokens
+	}
+	return p.MaxTokens
+}
+
+func (p generationParams) includeUsage() bool {
+	return p.StreamOptions != nil && p.StreamOptions.IncludeUsage
+}
+
+type chatCompletionRequest struct {
+	generationParams
+	Messages []chatMessage `json:"messages"`
+}
+
+type completionRequest struct {
+	generationParams
+	Prompt promptList `json:"prompt"`
+}
+
+type usage struct {
+	PromptTokens     uint32 `json:"prompt_tokens"`
+	CompletionTokens uint32 `json:"completion_tokens"`
+	TotalTokens      uint32 `json:"total_tokens"`
+}
+
+type chatChoice struct {
+	Index        int          `json:"index"`
+	Message      *chatMessage `json:"message,omitempty"`
+	Delta        *chatDelta   `json:"delta,omitempty"`
+	FinishReason *string      `json:"finish_reason"`
+}
+
+type chatDelta struct {
+	Role    string `json:"role,omitempty"`
+	Content string `json:"content,omitempty"`
+}
+
+type completionChoice struct {
+	Index        int     `json:"index"`
+	Text         string  `json:"text"`
+	FinishReason *string `json:"finish_reason"`
+}
+
+// completionResponse covers chat.completion, chat.completion.chunk and
+// text_completion objects; Choices holds chatChoice or completionChoice.
+type completionResponse struct {
+	ID      string `json:"id"`
+	Object  string `json:"object"`
+	Created int64  `json:"created"`
+	Model   string `json:"model"`
+	Choices any    `json:"choices"`
+	Usage   *usage `json:"usage,omitempty"`
+}
+
+type model struct {
+	ID      string `json:"id"`
+	Object  string `json:"object"`
+	Created int64  `json:"created"`
+	OwnedBy string `json:"owned_by"`
+}
+
+type modelList struct {
+	Object string  `json:"object"`
+	Data   []model `json:"data"`
+}
+
+type apiError struct {
+	Message string `json:"message"`
+	Type    string `json:"type"`
+}
+
+type errorResponse struct {
+	Error apiError `json:"error"`
+}
@@ -48,6 +48,32 @@ static size_t get_gpu_vram() {
     return prop.totalGlobalMem;
 }
 
+static int get_gpu_count() {
+    int count = 0;
+    if (cudaGetDeviceCount(&count) != cudaSuccess) return 0;
+    return count;
+}
+
+// get_gpu_device fills the properties of device idx; returns 0 on success.
+static int get_gpu_device(int idx, char* name, size_t name_len, size_t* total, size_t* free_mem, int* cc) {
+    struct cudaDeviceProp prop;
+    if (cudaGetDeviceProperties(&prop, idx) != cudaSuccess) return -1;
+    strncpy(name, prop.name, name_len - 1);
+    name[name_len - 1] = '\0';
+    *total = prop.totalGlobalMem;
+    *cc = prop.major * 10 + prop.minor;
+
+    int prev = 0;
+    cudaGetDevice(&prev);
+    size_t total_mem = 0;
+    *free_mem = 0;
+    if (cudaSetDevice(idx) == cudaSuccess) {
+        cudaMemGetInfo(free_mem, &total_mem);
+    }
+    cudaSetDevice(prev);
+    return 0;
+}
+
 static int get_gpu_compute_capability() {
     struct cudaDeviceProp prop;
     cudaGetDeviceProperties(&prop, 0);
@@ -74,6 +100,39 @@ func GetGpuInfo() (string, uint64, error) {
 	return name, vram, nil
 }
 
+/**
+ * ListGpuDevices enumerates every CUDA device with its name, total and
+ * currently free VRAM, and compute capability.
+ */
+func ListGpuDevices() ([]GpuDevice, error) {
+	count := int(C.get_gpu_count())
+	if count == 0 {
+		return nil, errors.New("cuda: no device found")
+	}
+	name := (*C.char)(C.malloc(256))
+	defer C.free(unsafe.Pointer(name))
+
+	devs := make([]GpuDevice, 0, count)
+	for i := 0; i < count; i++ {
+		var total, free C.size_t
+		var cc C.int
+		if C.get_gpu_device(C.int(i), name, 256, &total, &free, &cc) != 0 {
+			continue
+		}
+		devs = append(devs, GpuDevice{
+			Index:             i,
+			Name:              C.GoString(name),
+			TotalVRAM:         uint64(total),
+			FreeVRAM:          uint64(free),
+			ComputeCapability: int(cc),
+		})
+	}
+	if len(devs) == 0 {
+		return nil, errors.New("cuda: failed to get device properties")
+	}
+	return devs, nil
+}
+
 /**
  * GetGpuComputeCapability returns the CUDA compute capability (e.g., 86 for Ampere).
  */
@@ -2,12 +2,52 @@
 
 package quantx
 
-import "errors"
+import (
+	"errors"
+	"sync"
+)
+
+var (
+	fakeMu      sync.Mutex
+	fakeDevices []GpuDevice
+)
+
+/**
+ * SetFakeGpuDevices makes the stubs report devs as if they were real CUDA
+ * devices, so multi-GPU routing can be tested without the cuda build tag.
+ * The returned function restores the previous set.
+ */
+func SetFakeGpuDevices(devs []GpuDevice) (restore func()) {
+	fakeMu.Lock()
+	defer fakeMu.Unlock()
+	prev := fakeDevices
+	fakeDevices = append([]GpuDevice(nil), devs...)
+	return func() {
+		fakeMu.Lock()
+		fakeDevices = prev
+		fakeMu.Unlock()
+	}
+}
+
+/**
+ * ListGpuDevices returns the fake devices, or an error when none are installed.
+ */
+func ListGpuDevices() ([]GpuDevice, error) {
+	fakeMu.Lock()
+	defer fakeMu.Unlock()
+	if len(fakeDevices) == 0 {
+		return nil, errors.New("cuda: build tag not provided")
+	}
+	return append([]GpuDevice(nil), fakeDevices...), nil
+}
 
 /**
  * GetGpuInfo returns stubs when CUDA is not enabled.
  */
 func GetGpuInfo() (string, uint64, error) {
+	if devs, err := ListGpuDevices(); err == nil {
+		return devs[0].Name, devs[0].TotalVRAM, nil
+	}
 	return "No GPU (CUDA disabled)", 0, errors.New("cuda: build tag not provided")
 }
 
@@ -15,6 +55,9 @@ func GetGpuInfo() (string, uint64, error) {
  * GetGpuComputeCapability returns 0 when CUDA is not enabled.
  */
 func GetGpuComputeCapability() int {
+	if devs, err := ListGpuDevices(); err == nil {
+		return devs[0].ComputeCapability
+	}
 	return 0
 }
 
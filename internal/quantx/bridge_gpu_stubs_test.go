@@ -0,0 +1,34 @@
+//go:build !cuda
+
+package quantx
+
+import "testing"
+
+func TestFakeGpuDevices(t *testing.T) {
+	if _, err := ListGpuDevices(); err == nil {
+		t.Fatal("Expected no devices without the cuda build tag")
+	}
+
+	restore := SetFakeGpuDevices([]GpuDevice{
+		{Index: 0, Name: "Fake A", TotalVRAM: 8 << 30, FreeVRAM: 6 << 30, ComputeCapability: 86},
+		{Index: 1, Name: "Fake B", TotalVRAM: 24 << 30, FreeVRAM: 20 << 30, ComputeCapability: 89},
+	})
+	devs, err := ListGpuDevices()
+	if err != nil || len(devs) != 2 {
+		t.Fatalf("Expected 2 fake devices, got %d (%v)", len(devs), err)
+	}
+	if devs[1].Name != "Fake B" || devs[1].FreeVRAM != 20<<30 {
+		t.Errorf("Unexpected device 1: %+v", devs[1])
+	}
+	if name, vram, err := GetGpuInfo(); err != nil || name != "Fake A" || vram != 8<<30 {
+		t.Errorf("Expected GetGpuInfo to report device 0, got %q %d %v", name, vram, err)
+	}
+	if cc := GetGpuComputeCapability(); cc != 86 {
+		t.Errorf("Expected compute capability 86, got %d", cc)
+	}
+
+	restore()
+	if _, _, err := GetGpuInfo(); err == nil {
+		t.Error("Expected restore to remove the fake devices")
+	}
+}
@@ -51,3 +51,15 @@ func (m *GGUFModel) Free() {
 func (m *GGUFModel) GetTensorCount() int {
 	return int(C.gguf_get_n_tensors(m.gctx))
 }
+
+// TensorSizes maps each tensor name to its size in bytes, for sizing layer
+// partitions (see controller.ModelFromTensors).
+func (m *GGUFModel) TensorSizes() map[string]uint64 {
+	n := C.gguf_get_n_tensors(m.gctx)
+	sizes := make(map[string]uint64, int(n))
+	for i := C.int64_t(0); i < C.int64_t(n); i++ {
+		name := C.GoString(C.gguf_get_tensor_name(m.gctx, i))
+		sizes[name] = uint64(C.gguf_get_tensor_size(m.gctx, i))
+	}
+	return sizes
+}
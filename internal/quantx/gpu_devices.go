@@ -0,0 +1,10 @@
+package quantx
+
+// GpuDevice describes one GPU visible to the bridge.
+type GpuDevice struct {
+	Index             int
+	Name              string
+	TotalVRAM         uint64
+	FreeVRAM          uint64
+	ComputeCapability int // e.g. 86 for Ampere.
+}
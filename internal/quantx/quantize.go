@@ -0,0 +1,160 @@
+package quantx
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"unsafe"
+)
+
+// Values per block, and bytes per marshaled block.
+const (
+	QK8_0 = 32
+	QK_K  = 256
+
+	BlockQ8_0Size = 4 + QK8_0
+	BlockQ2KSize  = 8 + QK_K/4
+)
+
+// BlockQ8_0 represents a quantized 8-bit block: value i is Qs[i] * D.
+type BlockQ8_0 struct {
+	D  float32
+	Qs [QK8_0]int8
+}
+
+/**
+ * QuantizeQ8_0 quantizes x into 8-bit blocks with a symmetric scale per block.
+ * Each value is off by at most half its block's scale, max|x| / 254.
+ * A short last block is padded with zeros.
+ * @param x Values to quantize.
+ * @return ceil(len(x) / 32) blocks.
+ */
+func QuantizeQ8_0(x []float32) []BlockQ8_0 {
+	blocks := make([]BlockQ8_0, (len(x)+QK8_0-1)/QK8_0)
+	for i := range blocks {
+		xs := x[i*QK8_0 : min((i+1)*QK8_0, len(x))]
+		var amax float32
+		for _, v := range xs {
+			amax = max(amax, float32(math.Abs(float64(v))))
+		}
+		if amax == 0 {
+			continue
+		}
+		d := amax / 127
+		blocks[i].D = d
+		for j, v := range xs {
+			blocks[i].Qs[j] = int8(max(-127, min(127, math.Round(float64(v/d)))))
+		}
+	}
+	return blocks
+}
+
+/**
+ * DequantizeQ8_0 expands 8-bit blocks back to float32.
+ * @param blocks The quantized blocks.
+ * @param y Output slice; its first min(len(y), 32 * len(blocks)) values are written.
+ */
+func DequantizeQ8_0(blocks []BlockQ8_0, y []float32) {
+	n := min(len(y), len(blocks)*QK8_0)
+	for i := 0; i < n; i++ {
+		b := &blocks[i/QK8_0]
+		y[i] = float32(b.Qs[i%QK8_0]) * b.D
+	}
+}
+
+/**
+ * QuantizeQ2K quantizes x into the 2-bit blocks DequantizeQ2K reads.
+ * Each block spans its values' range in four steps from Dmin, so a value is
+ * off by at most (max - min) / 6 of its block. A short last block is padded
+ * with its final value so the padding does not widen the range.
+ * @param x Values to quantize.
+ * @return ceil(len(x) / 256) blocks.
+ */
+func QuantizeQ2K(x []float32) []BlockQ2K {
+	blocks := make([]BlockQ2K, (len(x)+QK_K-1)/QK_K)
+	for i := range blocks {
+		xs := x[i*QK_K : min((i+1)*QK_K, len(x))]
+		lo, hi := xs[0], xs[0]
+		for _, v := range xs {
+			lo, hi = min(lo, v), max(hi, v)
+		}
+		b := &blocks[i]
+		b.Dmin = lo
+		b.D = (hi - lo) / 3
+		for j := 0; j < QK_K; j++ {
+			var q uint8
+			if b.D > 0 {
+				v := xs[min(j, len(xs)-1)]
+				q = uint8(max(0, min(3, math.Round(float64((v-lo)/b.D)))))
+			}
+			b.Qs[j/4] |= q << (2 * (j % 4))
+		}
+	}
+	return blocks
+}
+
+/**
+ * DequantizeQ2KBlocks runs DequantizeQ2K over a slice of blocks.
+ * @param blocks The quantized blocks.
+ * @param y Output slice of at least 256 * len(blocks) values.
+ */
+func DequantizeQ2KBlocks(blocks []BlockQ2K, y []float32) {
+	if len(blocks) == 0 {
+		return
+	}
+	DequantizeQ2K(unsafe.Pointer(&blocks[0]), y, len(blocks)*QK_K)
+}
+
+// MarshalQ8_0 lays blocks out as BlockQ8_0Size little-endian bytes each.
+func MarshalQ8_0(blocks []BlockQ8_0) []byte {
+	out := make([]byte, 0, len(blocks)*BlockQ8_0Size)
+	for _, b := range blocks {
+		out = binary.LittleEndian.AppendUint32(out, math.Float32bits(b.D))
+		for _, q := range b.Qs {
+			out = append(out, byte(q))
+		}
+	}
+	return out
+}
+
+// UnmarshalQ8_0 parses blocks written by MarshalQ8_0.
+func UnmarshalQ8_0(data []byte) ([]BlockQ8_0, error) {
+	if len(data)%BlockQ8_0Size != 0 {
+		return nil, fmt.Errorf("quantx: %d bytes is not a whole number of Q8_0 blocks", len(data))
+	}
+	blocks := make([]BlockQ8_0, len(data)/BlockQ8_0Size)
+	for i := range blocks {
+		p := data[i*BlockQ8_0Size:]
+		blocks[i].D = math.Float32frombits(binary.LittleEndian.Uint32(p))
+		for j := range blocks[i].Qs {
+			blocks[i].Qs[j] = int8(p[4+j])
+		}
+	}
+	return blocks, nil
+}
+
+// MarshalQ2K lays blocks out as BlockQ2KSize little-endian bytes each.
+func MarshalQ2K(blocks []BlockQ2K) []byte {
+	out := make([]byte, 0, len(blocks)*BlockQ2KSize)
+	for _, b := range blocks {
+		out = binary.LittleEndian.AppendUint32(out, math.Float32bits(b.D))
+		out = binary.LittleEndian.AppendUint32(out, math.Float32bits(b.Dmin))
+		out = append(out, b.Qs[:]...)
+	}
+	return out
+}
+
+// UnmarshalQ2K parses blocks written by MarshalQ2K.
+func UnmarshalQ2K(data []byte) ([]BlockQ2K, error) {
+	if len(data)%BlockQ2KSize != 0 {
+		return nil, fmt.Errorf("quantx: %d bytes is not a whole number of Q2_K blocks", len(data))
+	}
+	blocks := make([]BlockQ2K, len(data)/BlockQ2KSize)
+	for i := range blocks {
+		p := data[i*BlockQ2KSize:]
+		blocks[i].D = math.Float32frombits(binary.LittleEndian.Uint32(p))
+		blocks[i].Dmin = math.Float32frombits(binary.LittleEndian.Uint32(p[4:]))
+		copy(blocks[i].Qs[:], p[8:])
+	}
+	return blocks, nil
+}
@@ -0,0 +1,134 @@
+package quantx
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// testValues returns n values shaped like KV activations: mostly small, with
+// a few outliers.
+func testValues(n int) []float32 {
+	rng := rand.New(rand.NewSource(1))
+	x := make([]float32, n)
+	for i := range x {
+		x[i] = float32(rng.NormFloat64())
+		if rng.Intn(64) == 0 {
+			x[i] *= 8
+		}
+	}
+	return x
+}
+
+func TestQuantizeQ8_0RoundTrip(t *testing.T) {
+	x := testValues(1000) // Not a multiple of the block size.
+	blocks, err := UnmarshalQ8_0(MarshalQ8_0(QuantizeQ8_0(x)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(blocks) != 32 {
+		t.Fatalf("Expected 32 blocks, got %d", len(blocks))
+	}
+	y := make([]float32, len(x))
+	DequantizeQ8_0(blocks, y)
+
+	for i := range x {
+		bound := blocks[i/QK8_0].D/2 + 1e-6
+		if diff := float32(math.Abs(float64(x[i] - y[i]))); diff > bound {
+			t.Fatalf("At index %d: expected %f within %g, got %f", i, x[i], bound, y[i])
+		}
+	}
+	if e := rmse(x, y); e > 0.02 {
+		t.Errorf("Expected an RMSE under 0.02, got %g", e)
+	}
+}
+
+func TestQuantizeQ2KRoundTrip(t *testing.T) {
+	x := testValues(1000)
+	blocks, err := UnmarshalQ2K(MarshalQ2K(QuantizeQ2K(x)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(blocks) != 4 {
+		t.Fatalf("Expected 4 blocks, got %d", len(blocks))
+	}
+	y := make([]float32, len(blocks)*QK_K)
+	DequantizeQ2KBlocks(blocks, y)
+
+	for i := range x {
+		b := blocks[i/QK_K]
+		bound := b.D/2 + 1e-5
+		if diff := float32(math.Abs(float64(x[i] - y[i]))); diff > bound {
+			t.Fatalf("At index %d: expected %f within %g, got %f", i, x[i], bound, y[i])
+		}
+		if y[i] < b.Dmin-1e-5 || y[i] > b.Dmin+3*b.D+1e-5 {
+			t.Fatalf("At index %d: %f is outside the block's range", i, y[i])
+		}
+	}
+}
+
+func TestQuantizeQ2KMatchesPattern(t *testing.T) {
+	// The values of TestDequantizeQ2K quantize back to its 0xE4 pattern.
+	x := make([]float32, QK_K)
+	for i := range x {
+		x[i] = []float32{0.5, 2.5, 4.5, 6.5}[i%4]
+	}
+	b := QuantizeQ2K(x)[0]
+	if b.D != 2 || b.Dmin != 0.5 {
+		t.Fatalf("Expected D 2 and Dmin 0.5, got %f and %f", b.D, b.Dmin)
+	}
+	for i, q := range b.Qs {
+		if q != 0xE4 {
+			t.Fatalf("At byte %d: expected 0xE4, got %#x", i, q)
+		}
+	}
+}
+
+func TestQuantizeConstantBlocks(t *testing.T) {
+	x := make([]float32, 300)
+	for i := range x {
+		x[i] = 1.25
+	}
+	y := make([]float32, 2*QK_K)
+	DequantizeQ2KBlocks(QuantizeQ2K(x), y)
+	for i := range x {
+		if y[i] != 1.25 {
+			t.Fatalf("At index %d: expected 1.25, got %f", i, y[i])
+		}
+	}
+
+	zeros := make([]float32, 40)
+	DequantizeQ8_0(QuantizeQ8_0(zeros), zeros)
+	for i, v := range zeros {
+		if v != 0 {
+			t.Fatalf("At index %d: expected 0, got %f", i, v)
+		}
+	}
+}
+
+func TestUnmarshalRejectsPartialBlocks(t *testing.T) {
+	if _, err := UnmarshalQ8_0(make([]byte, BlockQ8_0Size+1)); err == nil {
+		t.Error("Expected an error for a partial Q8_0 block")
+	}
+	if _, err := UnmarshalQ2K(make([]byte, BlockQ2KSize-1)); err == nil {
+		t.Error("Expected an error for a partial Q2_K block")
+	}
+}
+
+func rmse(x, y []float32) float64 {
+	var sum float64
+	for i := range x {
+		d := float64(x[i] - y[i])
+		sum += d * d
+	}
+	return math.Sqrt(sum / float64(len(x)))
+}
+
+func BenchmarkQuantizeQ2K(b *testing.B) {
+	x := testValues(1 << 20)
+	b.SetBytes(int64(len(x) * 4))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		QuantizeQ2K(x)
+	}
+}
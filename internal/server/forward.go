@@ -0,0 +1,102 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/groovy-byte/agent-mesh-core/internal/controller"
+	pb "github.com/groovy-byte/agent-mesh-core/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/proto"
+)
+
+// ForwardTimeout bounds a forwarded ExecuteStrategicAction before the action
+// falls back to local execution.
+const ForwardTimeout = 5 * time.Second
+
+// PeerDialer opens a StrategicMesh client for another controller's gRPC address.
+type PeerDialer func(addr string) (pb.StrategicMeshClient, error)
+
+// meshRouting is the optional cross-node forwarding state of a Server.
+type meshRouting struct {
+	nodeID string
+	peers  *controller.CapabilityDirectory
+	dial   PeerDialer
+
+	mu      sync.Mutex
+	clients map[string]pb.StrategicMeshClient
+	conns   []*grpc.ClientConn
+}
+
+// UseMeshRouting lets ExecuteStrategicAction forward work to peers whose
+// published capability beats this node's. A nil dial uses plaintext gRPC.
+// It must be called before Run, which closes the peer connections on
+// shutdown.
+func (s *Server) UseMeshRouting(nodeID string, peers *controller.CapabilityDirectory, dial PeerDialer) {
+	m := &meshRouting{
+		nodeID:  nodeID,
+		peers:   peers,
+		dial:    dial,
+		clients: make(map[string]pb.StrategicMeshClient),
+	}
+	if m.dial == nil {
+		m.dial = m.dialInsecure
+	}
+	s.mesh = m
+}
+
+func (m *meshRouting) dialInsecure(addr string) (pb.StrategicMeshClient, error) {
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, err
+	}
+	m.conns = append(m.conns, conn)
+	return pb.NewStrategicMeshClient(conn), nil
+}
+
+func (m *meshRouting) client(addr string) (pb.StrategicMeshClient, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if c, ok := m.clients[addr]; ok {
+		return c, nil
+	}
+	c, err := m.dial(addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial peer %s: %w", addr, err)
+	}
+	m.clients[addr] = c
+	return c, nil
+}
+
+// close releases connections opened by the default dialer.
+func (m *meshRouting) close() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, conn := range m.conns {
+		conn.Close()
+	}
+	m.conns, m.clients = nil, make(map[string]pb.StrategicMeshClient)
+}
+
+// forward runs action on the peer chosen by RouteTaskGlobal.
+func (m *meshRouting) forward(ctx context.Context, target controller.GlobalPlacement, action *pb.AgentAction) (*pb.ActionResponse, error) {
+	client, err := m.client(target.Addr)
+	if err != nil {
+		return nil, err
+	}
+	fwd := proto.Clone(action).(*pb.AgentAction)
+	fwd.ForwardedFrom = m.nodeID
+
+	ctx, cancel := context.WithTimeout(ctx, ForwardTimeout)
+	defer cancel()
+	res, err := client.ExecuteStrategicAction(ctx, fwd)
+	if err != nil {
+		return nil, fmt.Errorf("peer %s rejected forwarded action: %w", target.Node, err)
+	}
+	log.Printf("[Vextra] 🌐 Forwarded %s from %s to %s", action.ActionType, action.AgentId, target)
+	return res, nil
+}
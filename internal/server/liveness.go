@@ -0,0 +1,44 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// agentRequest is implemented by every request message naming its agent.
+type agentRequest interface {
+	GetAgentId() string
+}
+
+// touch counts a request from a registered agent as a sign of life, so agents
+// that only speak gRPC stay ALIVE without publishing NATS heartbeats.
+func (s *Server) touch(req any) {
+	if r, ok := req.(agentRequest); ok && r.GetAgentId() != "" {
+		s.registry.Touch(r.GetAgentId(), time.Now())
+	}
+}
+
+func (s *Server) touchUnary(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	s.touch(req)
+	return handler(ctx, req)
+}
+
+func (s *Server) touchStream(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	return handler(srv, &touchingStream{ServerStream: ss, s: s})
+}
+
+// touchingStream touches the agent named by each message it receives.
+type touchingStream struct {
+	grpc.ServerStream
+	s *Server
+}
+
+func (t *touchingStream) RecvMsg(m any) error {
+	if err := t.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	t.s.touch(m)
+	return nil
+}
@@ -0,0 +1,659 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/groovy-byte/agent-mesh-core/internal/backend"
+	"github.com/groovy-byte/agent-mesh-core/internal/controller"
+	pb "github.com/groovy-byte/agent-mesh-core/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// ShutdownTimeout bounds how long Run waits for in-flight RPCs to drain.
+const ShutdownTimeout = 10 * time.Second
+
+// Server implements the StrategicMesh gRPC service by delegating to the mesh controllers.
+type Server struct {
+	pb.UnimplementedStrategicMeshServer
+
+	registry  *controller.MeshRegistry
+	arbiter   *controller.Arbiter
+	roles     *controller.RoleSwitcher
+	search    *controller.QdrantController
+	synthesis *controller.SynthesisController
+	inference *controller.InferenceController
+	scheduler *controller.ScheInfer
+	admission *controller.AdmissionController
+	mesh      *meshRouting // nil unless UseMeshRouting was called.
+	implicit  implicitLocks
+}
+
+func NewServer(scheduler *controller.ScheInfer) *Server {
+	srv, _ := NewServerWithStore(scheduler, controller.NewMemoryStore(), 0)
+	return srv
+}
+
+// NewServerWithStore builds a Server whose registry and arbiter write through to
+// store, restoring any agents and snapshots it already holds. historyDepth is
+// the arbiter's snapshots per agent (0 for the default).
+func NewServerWithStore(scheduler *controller.ScheInfer, store controller.MeshStore, historyDepth int) (*Server, error) {
+	registry, err := controller.NewMeshRegistryWithStore(store)
+	if err != nil {
+		return nil, err
+	}
+	arbiter, err := controller.NewArbiterWithStore(store, historyDepth)
+	if err != nil {
+		return nil, err
+	}
+	admission := controller.NewAdmissionController(controller.DefaultBudgetPolicy())
+	admission.ForgetOnEvict(registry)
+	return &Server{
+		registry:  registry,
+		arbiter:   arbiter,
+		roles:     controller.NewRoleSwitcher(),
+		search:    controller.NewQdrantController(),
+		synthesis: controller.NewSynthesisController(),
+		inference: controller.NewInferenceController(scheduler),
+		scheduler: scheduler,
+		admission: admission,
+	}, nil
+}
+
+// Registry exposes the agent registry for in-process consumers (heartbeats, TUI).
+func (s *Server) Registry() *controller.MeshRegistry {
+	return s.registry
+}
+
+// Inference exposes the inference controller so callers can register backends.
+func (s *Server) Inference() *controller.InferenceController {
+	return s.inference
+}
+
+// Admission exposes the per-agent inference budgets so callers can tune them.
+func (s *Server) Admission() *controller.AdmissionController {
+	return s.admission
+}
+
+// Arbiter exposes the lock and state arbiter for in-process consumers.
+func (s *Server) Arbiter() *controller.Arbiter {
+	return s.arbiter
+}
+
+// Run serves the StrategicMesh service on lis until ctx is cancelled, then
+// drains in-flight RPCs before returning. Every RPC naming a registered
+// agent refreshes its liveness like a heartbeat.
+func (s *Server) Run(ctx context.Context, lis net.Listener, opts ...grpc.ServerOption) error {
+	if s.mesh != nil {
+		defer s.mesh.close()
+	}
+	opts = append([]grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(s.touchUnary),
+		grpc.ChainStreamInterceptor(s.touchStream),
+	}, opts...)
+	gs := grpc.NewServer(opts...)
+	pb.RegisterStrategicMeshServer(gs, s)
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- gs.Serve(lis)
+	}()
+	log.Printf("[Vextra] 🚀 StrategicMesh listening on %s", lis.Addr())
+
+	select {
+	case err := <-errCh:
+		if errors.Is(err, grpc.ErrServerStopped) {
+			return nil
+		}
+		return err
+	case <-ctx.Done():
+	}
+
+	log.Printf("[Vextra] Shutting down, draining in-flight RPCs...")
+	stopped := make(chan struct{})
+	go func() {
+		gs.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(ShutdownTimeout):
+		log.Printf("[Vextra] ⚠️ Graceful stop timed out after %v, forcing shutdown", ShutdownTimeout)
+		gs.Stop()
+	}
+	return nil
+}
+
+// RegisterAgent admits an agent into the mesh via the One-Hop handshake.
+func (s *Server) RegisterAgent(ctx context.Context, req *pb.HandshakeRequest) (*pb.HandshakeResponse, error) {
+	if req.AgentId == "" {
+		return nil, status.Error(codes.InvalidArgument, "agent_id is required")
+	}
+	resp, err := s.registry.RegisterAgent(req)
+	if err != nil {
+		return nil, err
+	}
+	budget := s.admission.Policy().Budget(req.InitialRole, resp.ResourceLimits)
+	resp.InferenceBudget = &pb.InferenceBudget{
+		TokensPerMinute: budget.TokensPerMinute,
+		MaxConcurrent:   uint32(budget.MaxConcurrent),
+	}
+	return resp, nil
+}
+
+// ExecuteStrategicAction enforces the role counterbalance, routes the task and records its state.
+// With mesh routing enabled the task may be forwarded to a better-suited peer;
+// forwarded actions arriving from a peer only run the routing step, since the
+// originating controller owns the agent's role, locks and state.
+func (s *Server) ExecuteStrategicAction(ctx context.Context, action *pb.AgentAction) (*pb.ActionResponse, error) {
+	if action.ForwardedFrom != "" {
+		return s.executeForwarded(action), nil
+	}
+
+	info, ok := s.registry.GetAgent(action.AgentId)
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "agent %s is not registered", action.AgentId)
+	}
+
+	role := s.roles.EvaluateTransition(&info, action.ResourceImpact, action.TaskIntent)
+
+	// Counterbalance: only one agent may plan strategically per lock domain at a time.
+	promotion := false
+	if action.FencingToken != 0 {
+		// Agents holding a lease across several steps prove ownership with their token.
+		if err := s.arbiter.ValidateToken(action.LockDomain, action.AgentId, action.FencingToken); err != nil {
+			return nil, status.Errorf(codes.FailedPrecondition, "fencing token %d rejected: %v", action.FencingToken, err)
+		}
+		promotion, role = true, pb.AgentRole_STRATEGIC
+	} else if role == pb.AgentRole_STRATEGIC || action.ActionType == "HIGH_COMPLEXITY" {
+		holder, _ := s.arbiter.LockHolder(action.LockDomain)
+		var grant controller.LockGrant
+		grant, promotion = s.arbiter.AcquireLock(action.LockDomain, action.AgentId, controller.LockTTL)
+		if promotion {
+			role = pb.AgentRole_STRATEGIC
+			if holder.Holder != action.AgentId {
+				s.implicit.add(action.AgentId, grant)
+			}
+		} else {
+			role = pb.AgentRole_OPERATIONAL
+		}
+	} else if info.Role == pb.AgentRole_STRATEGIC {
+		// Only drop what tokenless actions took; leases from the AcquireLock
+		// RPC belong to the caller holding their token.
+		for _, grant := range s.implicit.take(action.AgentId) {
+			s.arbiter.ReleaseFencedLock(grant.Domain, action.AgentId, grant.Token)
+		}
+		if len(s.arbiter.HeldDomains(action.AgentId)) > 0 {
+			role = pb.AgentRole_STRATEGIC
+		}
+	}
+
+	if role != info.Role {
+		s.registry.UpdateRole(action.AgentId, role)
+	}
+
+	res := s.route(ctx, action)
+	s.arbiter.SaveState(action)
+	s.registry.RecordTaskResult(action.AgentId, true, action.ActionType, 0)
+
+	res.Success = true
+	res.PromotionSuggested = promotion
+	res.RequiredRole = role
+	return res, nil
+}
+
+// route picks where the action runs, forwarding it to a peer when mesh routing
+// finds a better remote placement. A failed forward falls back to local routing.
+func (s *Server) route(ctx context.Context, action *pb.AgentAction) *pb.ActionResponse {
+	if s.mesh != nil {
+		target := s.scheduler.RouteTaskGlobal(action.DataSizeBytes, s.mesh.peers.Peers(time.Now()))
+		if target.Remote() {
+			res, err := s.mesh.forward(ctx, target, action)
+			if err == nil {
+				return &pb.ActionResponse{
+					RoutingProvider:    res.RoutingProvider,
+					RoutingNode:        target.Node,
+					Forwarded:          true,
+					EstimatedLatencyMs: float32(target.Estimate.Seconds() * 1000),
+				}
+			}
+			log.Printf("[Vextra] ⚠️ %v; running locally", err)
+		}
+	}
+
+	provider, estimate := s.scheduler.RouteTask(action.DataSizeBytes)
+	res := &pb.ActionResponse{
+		RoutingProvider:    provider,
+		EstimatedLatencyMs: float32(estimate.Seconds() * 1000),
+	}
+	if s.mesh != nil {
+		res.RoutingNode = s.mesh.nodeID
+	}
+	return res
+}
+
+// executeForwarded runs the routing step for an action a peer sent here.
+func (s *Server) executeForwarded(action *pb.AgentAction) *pb.ActionResponse {
+	provider, estimate := s.scheduler.RouteTask(action.DataSizeBytes)
+	res := &pb.ActionResponse{
+		Success:            true,
+		RoutingProvider:    provider,
+		EstimatedLatencyMs: float32(estimate.Seconds() * 1000),
+	}
+	if s.mesh != nil {
+		res.RoutingNode = s.mesh.nodeID
+	}
+	log.Printf("[Vextra] 🌐 Executing %s for %s forwarded by %s on %s", action.ActionType, action.AgentId, action.ForwardedFrom, provider)
+	return res
+}
+
+// AcquireLock grants a lease on the requested lock domain. Without wait a
+// denial is not an error and the response names the current holder; with wait
+// the call queues until granted or the RPC deadline passes.
+func (s *Server) AcquireLock(ctx context.Context, req *pb.LockRequest) (*pb.LockResponse, error) {
+	if _, ok := s.registry.GetAgent(req.AgentId); !ok {
+		return nil, status.Errorf(codes.NotFound, "agent %s is not registered", req.AgentId)
+	}
+	lease := leaseFromMillis(req.LeaseMs)
+
+	if req.Wait {
+		grant, err := s.arbiter.WaitForLock(ctx, req.Domain, req.AgentId, req.Priority, lease)
+		if err != nil {
+			return nil, status.FromContextError(err).Err()
+		}
+		s.implicit.forget(req.AgentId, grant.Domain)
+		s.registry.UpdateRole(req.AgentId, pb.AgentRole_STRATEGIC)
+		return lockResponse(grant, true), nil
+	}
+
+	grant, ok := s.arbiter.AcquireLock(req.Domain, req.AgentId, lease)
+	if ok {
+		s.implicit.forget(req.AgentId, grant.Domain)
+		s.registry.UpdateRole(req.AgentId, pb.AgentRole_STRATEGIC)
+		return lockResponse(grant, true), nil
+	}
+	return &pb.LockResponse{Granted: false, HolderId: grant.Holder, Domain: grant.Domain}, nil
+}
+
+// RenewLock extends a live lease. Stale or expired tokens fail with FailedPrecondition.
+func (s *Server) RenewLock(ctx context.Context, req *pb.LockRequest) (*pb.LockResponse, error) {
+	grant, err := s.arbiter.RenewLock(req.Domain, req.AgentId, req.FencingToken, leaseFromMillis(req.LeaseMs))
+	if err != nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "renew rejected: %v", err)
+	}
+	return lockResponse(grant, true), nil
+}
+
+// ReleaseLock frees the lease if the token is still current.
+func (s *Server) ReleaseLock(ctx context.Context, req *pb.LockRequest) (*pb.LockResponse, error) {
+	if err := s.arbiter.ReleaseFencedLock(req.Domain, req.AgentId, req.FencingToken); err != nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "release rejected: %v", err)
+	}
+	// Stay STRATEGIC while any other domain is still held.
+	if _, ok := s.registry.GetAgent(req.AgentId); ok && len(s.arbiter.HeldDomains(req.AgentId)) == 0 {
+		s.registry.UpdateRole(req.AgentId, pb.AgentRole_OPERATIONAL)
+	}
+	return &pb.LockResponse{Granted: false, FencingToken: req.FencingToken, Domain: req.Domain}, nil
+}
+
+// implicitLocks remembers the leases ExecuteStrategicAction took for
+// tokenless strategic actions, so it can release them without touching
+// leases acquired explicitly.
+type implicitLocks struct {
+	mu     sync.Mutex
+	grants map[string]map[string]controller.LockGrant // Agent -> domain -> grant.
+}
+
+func (l *implicitLocks) add(agentID string, grant controller.LockGrant) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.grants == nil {
+		l.grants = make(map[string]map[string]controller.LockGrant)
+	}
+	if l.grants[agentID] == nil {
+		l.grants[agentID] = make(map[string]controller.LockGrant)
+	}
+	l.grants[agentID][grant.Domain] = grant
+}
+
+// forget hands a lease over to an explicit AcquireLock by the same agent.
+func (l *implicitLocks) forget(agentID, domain string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.grants[agentID], domain)
+}
+
+func (l *implicitLocks) take(agentID string) []controller.LockGrant {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var grants []controller.LockGrant
+	for _, g := range l.grants[agentID] {
+		grants = append(grants, g)
+	}
+	delete(l.grants, agentID)
+	return grants
+}
+
+func leaseFromMillis(ms uint32) time.Duration {
+	return time.Duration(ms) * time.Millisecond
+}
+
+func lockResponse(grant controller.LockGrant, granted bool) *pb.LockResponse {
+	return &pb.LockResponse{
+		Granted:      granted,
+		FencingToken: grant.Token,
+		ExpiresAt:    timestamppb.New(grant.ExpiresAt),
+		HolderId:     grant.Holder,
+		Domain:       grant.Domain,
+	}
+}
+
+// SemanticSearch queries the knowledge base through the Soft-Throttle. Whether the
+// query was novel feeds the agent's DSBO neighborhood re-evaluation.
+func (s *Server) SemanticSearch(ctx context.Context, req *pb.SearchRequest) (*pb.SearchResponse, error) {
+	resp, err := s.search.Search(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	novel := !strings.HasPrefix(resp.ReasoningContext, "THROTTLED")
+	s.registry.ReevaluateNeighbors(req.AgentId, novel, s.arbiter)
+	return resp, nil
+}
+
+// GetStateReconstitution returns the last saved action for a failed agent, or
+// the requested version / point in time.
+func (s *Server) GetStateReconstitution(ctx context.Context, req *pb.ReconstitutionRequest) (*pb.AgentAction, error) {
+	var snap controller.StateSnapshot
+	var err error
+	switch {
+	case req.Version != 0:
+		snap, err = s.arbiter.GetStateVersion(req.AgentId, req.Version)
+	case req.AsOf != nil:
+		snap, err = s.arbiter.GetStateAt(req.AgentId, req.AsOf.AsTime())
+	default:
+		state, ok := s.arbiter.GetState(req.AgentId)
+		if !ok {
+			return nil, status.Errorf(codes.NotFound, "no saved state for agent %s", req.AgentId)
+		}
+		return state, nil
+	}
+	if err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	return snap.Action, nil
+}
+
+// GetStateHistory lists the retained snapshots for an agent, oldest first.
+func (s *Server) GetStateHistory(ctx context.Context, req *pb.ReconstitutionRequest) (*pb.StateHistory, error) {
+	snaps := s.arbiter.StateHistory(req.AgentId)
+	if len(snaps) == 0 {
+		return nil, status.Errorf(codes.NotFound, "no saved state for agent %s", req.AgentId)
+	}
+	out := &pb.StateHistory{Snapshots: make([]*pb.StateSnapshot, 0, len(snaps))}
+	for _, snap := range snaps {
+		out.Snapshots = append(out.Snapshots, &pb.StateSnapshot{
+			Version: snap.Version,
+			SavedAt: timestamppb.New(snap.SavedAt),
+			Action:  snap.Action,
+		})
+	}
+	return out, nil
+}
+
+// DiffStates compares two retained versions of an agent's state.
+func (s *Server) DiffStates(ctx context.Context, req *pb.StateDiffRequest) (*pb.StateDiff, error) {
+	changes, err := s.arbiter.DiffStates(req.AgentId, req.FromVersion, req.ToVersion)
+	if err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	out := &pb.StateDiff{Changes: make([]*pb.FieldChange, 0, len(changes))}
+	for _, c := range changes {
+		out.Changes = append(out.Changes, &pb.FieldChange{Field: c.Field, Before: c.Before, After: c.After})
+	}
+	return out, nil
+}
+
+// SynthesizeOutputs merges parallel agent outputs (AdaptOrch).
+func (s *Server) SynthesizeOutputs(ctx context.Context, req *pb.SynthesisRequest) (*pb.SynthesisResponse, error) {
+	return s.synthesis.Synthesize(req)
+}
+
+// GenerateResponse runs hardware-aware inference and records the agent's metrics.
+func (s *Server) GenerateResponse(ctx context.Context, req *pb.InferenceRequest) (*pb.InferenceResponse, error) {
+	resp, err := s.Infer(ctx, req, nil)
+	if err != nil {
+		grpc.SetTrailer(ctx, retryAfter(err))
+		return nil, inferenceError(ctx, err)
+	}
+	return resp, nil
+}
+
+// GenerateStream sends the completion as it is generated, one chunk per
+// backend token, then a final chunk with the summary. A client disconnect
+// cancels the stream context, which aborts generation.
+func (s *Server) GenerateStream(req *pb.InferenceRequest, stream pb.StrategicMesh_GenerateStreamServer) error {
+	ctx := stream.Context()
+	var generated uint32
+	resp, err := s.Infer(ctx, req, func(text string) error {
+		generated++
+		return stream.Send(&pb.InferenceChunk{Text: text, TokensGenerated: generated})
+	})
+	if err != nil {
+		stream.SetTrailer(retryAfter(err))
+		return inferenceError(ctx, err)
+	}
+	return stream.Send(&pb.InferenceChunk{TokensGenerated: generated, Done: true, Summary: resp})
+}
+
+// Infer is the inference path shared by the gRPC and HTTP front ends: it
+// checks the agent's budget, runs req (streaming through emit when it is
+// non-nil) and records the agent's metrics. Errors are returned unmapped;
+// budget rejections wrap controller.ErrBudgetExceeded.
+func (s *Server) Infer(ctx context.Context, req *pb.InferenceRequest, emit backend.TokenFunc) (*pb.InferenceResponse, error) {
+	s.touch(req) // In-process callers like the OpenAI facade skip the interceptors.
+	adm, err := s.admit(req)
+	if err != nil {
+		return nil, err
+	}
+	var resp *pb.InferenceResponse
+	if emit == nil {
+		resp, err = s.inference.Generate(ctx, req)
+	} else {
+		var streamed uint32
+		resp, err = s.inference.GenerateStream(ctx, req, func(text string) error {
+			streamed++
+			return emit(text)
+		})
+		if err != nil {
+			// Tokens already streamed were produced, so they are billed.
+			adm.Done(streamed)
+			return nil, err
+		}
+	}
+	if err != nil {
+		adm.Done(0)
+		return nil, err
+	}
+	// Cached responses cost no generation, so they are neither billed nor
+	// counted as tokens used.
+	billed := resp.TokensUsed
+	if resp.Cached {
+		billed = 0
+	}
+	adm.Done(billed)
+	s.registry.RecordMetrics(req.AgentId, resp.LatencyMs, billed, resp.ThroughputGbs)
+	return resp, nil
+}
+
+// RetryAfterKey is the trailer carrying the seconds to wait after a
+// RESOURCE_EXHAUSTED inference rejection.
+const RetryAfterKey = "retry-after"
+
+// admit checks req against the calling agent's budget, which follows its
+// current role (unregistered callers are treated as operational). Rejections
+// are counted in the agent's metrics.
+func (s *Server) admit(req *pb.InferenceRequest) (*controller.Admission, error) {
+	role := pb.AgentRole_OPERATIONAL
+	if info, ok := s.registry.GetAgent(req.AgentId); ok {
+		role = info.Role
+	}
+	policy := s.admission.Policy()
+	adm, err := s.admission.Admit(req.AgentId, policy.Budget(role, controller.HandshakeLimits()), policy.Charge(req))
+	if err != nil {
+		s.registry.RecordRejection(req.AgentId)
+		log.Printf("[Vextra] 🚦 Rejected inference: %v", err)
+		return nil, err
+	}
+	return adm, nil
+}
+
+// retryAfter builds the trailer for an admission error, rounding up to whole
+// seconds. It is empty when retrying cannot help.
+func retryAfter(err error) metadata.MD {
+	var be *controller.BudgetError
+	if !errors.As(err, &be) || be.RetryAfter <= 0 {
+		return nil
+	}
+	secs := int64(math.Ceil(be.RetryAfter.Seconds()))
+	return metadata.Pairs(RetryAfterKey, strconv.FormatInt(secs, 10))
+}
+
+// inferenceError maps an InferenceController error to a gRPC status.
+func inferenceError(ctx context.Context, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		err = ctxErr
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return status.FromContextError(err).Err()
+	}
+	if errors.Is(err, backend.ErrEmptyPrompt) {
+		return status.Error(codes.InvalidArgument, "prompt is required")
+	}
+	if errors.Is(err, controller.ErrBudgetExceeded) {
+		return status.Error(codes.ResourceExhausted, err.Error())
+	}
+	return status.Errorf(codes.Internal, "inference failed: %v", err)
+}
+
+// GetMeshStats reports per-agent audit metrics and the VoC contribution matrix.
+func (s *Server) GetMeshStats(ctx context.Context, req *pb.StatsRequest) (*pb.MeshStats, error) {
+	summary := s.registry.GetStatsSummary()
+	stats := &pb.MeshStats{
+		AgentsActive:       int32(len(summary)),
+		AgentLogs:          make(map[string]*pb.AgentMetrics, len(summary)),
+		ContributionMatrix: make(map[string]*pb.InfluenceMap),
+	}
+
+	for _, sum := range summary {
+		info, ok := s.registry.GetAgent(sum.ID)
+		if !ok {
+			continue
+		}
+		stats.AgentLogs[sum.ID] = &pb.AgentMetrics{
+			ToolCalls:        info.ToolCalls,
+			FailedTasks:      info.FailedTasks,
+			AvgLatencyMs:     sum.AvgLatency,
+			TotalTokens:      sum.Tokens,
+			RejectedRequests: info.Rejected,
+		}
+		if detail := s.registry.GetContributionDetail(sum.ID); len(detail) > 0 {
+			stats.ContributionMatrix[sum.ID] = &pb.InfluenceMap{Influence: detail}
+		}
+	}
+
+	lockStats := s.arbiter.LockStats()
+	stats.LockDomains = make(map[string]*pb.LockDomainMetrics, len(lockStats))
+	for domain, st := range lockStats {
+		m := &pb.LockDomainMetrics{
+			HolderId:      st.Holder,
+			Grants:        st.Grants,
+			Contended:     st.Contended,
+			Timeouts:      st.Timeouts,
+			Reclaims:      st.Reclaims,
+			QueueDepth:    uint32(st.QueueDepth),
+			MaxQueueDepth: uint32(st.MaxQueueDepth),
+		}
+		if st.Grants > 0 {
+			m.AvgWaitMs = float64(st.TotalWait.Milliseconds()) / float64(st.Grants)
+		}
+		stats.LockDomains[domain] = m
+	}
+
+	loadStats := s.scheduler.LoadStats()
+	stats.ProviderLoad = make(map[string]*pb.ProviderLoadMetrics, len(loadStats))
+	for placement, ld := range loadStats {
+		stats.ProviderLoad[placement] = &pb.ProviderLoadMetrics{
+			InFlight:      uint32(ld.InFlight),
+			InFlightBytes: ld.InFlightBytes,
+			Acquired:      ld.Acquired,
+			SpilledOut:    ld.SpilledOut,
+			SpilledIn:     ld.SpilledIn,
+		}
+	}
+
+	batchStats := s.inference.BatchStats()
+	stats.Batching = make(map[string]*pb.BatchMetrics, len(batchStats))
+	for path, h := range batchStats {
+		m := &pb.BatchMetrics{
+			Batches:    h.Batches,
+			Requests:   h.Requests,
+			Expired:    h.Expired,
+			SizeCounts: make(map[uint32]uint64, len(h.Sizes)),
+		}
+		for size, n := range h.Sizes {
+			m.SizeCounts[uint32(size)] = n
+		}
+		stats.Batching[path] = m
+	}
+
+	if cs, ok := s.inference.CacheStats(); ok {
+		stats.ResponseCache = &pb.ResponseCacheMetrics{
+			Hits:         cs.Hits,
+			SemanticHits: cs.SemanticHits,
+			Misses:       cs.Misses,
+			Bypassed:     cs.Bypassed,
+			Evictions:    cs.Evictions,
+			Expirations:  cs.Expirations,
+			Entries:      uint32(cs.Entries),
+		}
+	}
+	return stats, nil
+}
+
+// GetNeighborGraph exposes the DSBO neighbor graph and its bandit statistics.
+func (s *Server) GetNeighborGraph(ctx context.Context, req *pb.NeighborGraphRequest) (*pb.NeighborGraph, error) {
+	graph := s.registry.NeighborGraph(req.AgentId)
+	if req.AgentId != "" && len(graph) == 0 {
+		return nil, status.Errorf(codes.NotFound, "agent %s is not registered", req.AgentId)
+	}
+
+	out := &pb.NeighborGraph{Adjacency: make(map[string]*pb.NeighborList, len(graph))}
+	for id, edges := range graph {
+		list := &pb.NeighborList{Edges: make([]*pb.NeighborEdge, 0, len(edges))}
+		if info, ok := s.registry.GetAgent(id); ok {
+			list.UtilityScore = info.UtilityScore
+		}
+		for _, e := range edges {
+			list.Edges = append(list.Edges, &pb.NeighborEdge{
+				TargetId:   e.TargetID,
+				Score:      e.Score,
+				Pulls:      e.Pulls,
+				MeanReward: e.MeanReward,
+			})
+		}
+		out.Adjacency[id] = list
+	}
+	return out, nil
+}
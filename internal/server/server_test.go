@@ -0,0 +1,798 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/groovy-byte/agent-mesh-core/internal/backend"
+	"github.com/groovy-byte/agent-mesh-core/internal/controller"
+	pb "github.com/groovy-byte/agent-mesh-core/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// startTestServer runs a Server on an in-memory listener and returns a connected client.
+func startTestServer(t *testing.T) (pb.StrategicMeshClient, *Server) {
+	t.Helper()
+	return startTestServerWith(t, controller.NewScheInfer(16*1024*1024, "NVIDIA GeForce RTX 3070 Laptop GPU", 86, false))
+}
+
+// startTestServerWith serves a Server on bufconn, applying configure before
+// Run starts.
+func startTestServerWith(t *testing.T, scheduler *controller.ScheInfer, configure ...func(*Server)) (pb.StrategicMeshClient, *Server) {
+	t.Helper()
+
+	lis := bufconn.Listen(1024 * 1024)
+	srv := NewServer(scheduler)
+	for _, f := range configure {
+		f(srv)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- srv.Run(ctx, lis)
+	}()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to dial bufnet: %v", err)
+	}
+
+	t.Cleanup(func() {
+		conn.Close()
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("Run returned error: %v", err)
+		}
+	})
+	return pb.NewStrategicMeshClient(conn), srv
+}
+
+func TestServerRegisterAndStats(t *testing.T) {
+	c, _ := startTestServer(t)
+	ctx := context.Background()
+
+	res, err := c.RegisterAgent(ctx, &pb.HandshakeRequest{AgentId: "scout", InitialRole: pb.AgentRole_OPERATIONAL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Approved || res.SessionId != "mesh_sess_scout" {
+		t.Errorf("Unexpected handshake response: %+v", res)
+	}
+
+	if _, err := c.RegisterAgent(ctx, &pb.HandshakeRequest{}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for empty agent_id, got %v", err)
+	}
+
+	stats, err := c.GetMeshStats(ctx, &pb.StatsRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.AgentsActive != 1 {
+		t.Errorf("Expected 1 active agent, got %d", stats.AgentsActive)
+	}
+	if _, ok := stats.AgentLogs["scout"]; !ok {
+		t.Errorf("Expected audit log for scout")
+	}
+}
+
+func TestServerStrategicCounterbalance(t *testing.T) {
+	c, _ := startTestServer(t)
+	ctx := context.Background()
+
+	for _, id := range []string{"boss-a", "boss-b"} {
+		if _, err := c.RegisterAgent(ctx, &pb.HandshakeRequest{AgentId: id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	resA, err := c.ExecuteStrategicAction(ctx, &pb.AgentAction{AgentId: "boss-a", ActionType: "HIGH_COMPLEXITY"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resA.PromotionSuggested || resA.RequiredRole != pb.AgentRole_STRATEGIC {
+		t.Errorf("Expected boss-a to acquire the strategic lock, got %+v", resA)
+	}
+
+	resB, err := c.ExecuteStrategicAction(ctx, &pb.AgentAction{AgentId: "boss-b", ActionType: "HIGH_COMPLEXITY"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resB.PromotionSuggested || resB.RequiredRole != pb.AgentRole_OPERATIONAL {
+		t.Errorf("Expected boss-b to be denied the strategic lock, got %+v", resB)
+	}
+
+	_, err = c.ExecuteStrategicAction(ctx, &pb.AgentAction{AgentId: "ghost"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("Expected NotFound for unregistered agent, got %v", err)
+	}
+}
+
+func TestServerRPCsKeepAgentsAlive(t *testing.T) {
+	c, srv := startTestServer(t)
+	ctx := context.Background()
+	for _, id := range []string{"grpc-only", "silent"} {
+		if _, err := c.RegisterAgent(ctx, &pb.HandshakeRequest{AgentId: id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cfg := controller.HeartbeatConfig{Interval: 20 * time.Millisecond, SuspectAfter: 2, DeadAfter: 5}
+	time.Sleep(150 * time.Millisecond)
+
+	// Neither agent publishes NATS heartbeats; only one keeps calling in.
+	if _, err := c.GenerateResponse(ctx, &pb.InferenceRequest{AgentId: "grpc-only", Prompt: "still here"}); err != nil {
+		t.Fatal(err)
+	}
+	stream, err := c.GenerateStream(ctx, &pb.InferenceRequest{AgentId: "grpc-only", Prompt: "and streaming"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for {
+		if _, err := stream.Recv(); err != nil {
+			break
+		}
+	}
+
+	evicted := srv.Registry().SweepLiveness(time.Now(), cfg)
+	if len(evicted) != 1 || evicted[0] != "silent" {
+		t.Errorf("Expected only the silent agent evicted, got %v", evicted)
+	}
+	if info, ok := srv.Registry().GetAgent("grpc-only"); !ok || info.Liveness != controller.LivenessAlive {
+		t.Errorf("Expected the gRPC-active agent to stay ALIVE, got %+v", info)
+	}
+}
+
+func TestServerRoutingAndReconstitution(t *testing.T) {
+	c, _ := startTestServer(t)
+	ctx := context.Background()
+
+	if _, err := c.RegisterAgent(ctx, &pb.HandshakeRequest{AgentId: "hanging-agent"}); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := c.ExecuteStrategicAction(ctx, &pb.AgentAction{
+		AgentId:        "hanging-agent",
+		ActionType:     "OS_TASK",
+		ReasoningChain: "Initial step before crash",
+		DataSizeBytes:  32 * 1024 * 1024,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.RoutingProvider != "GPU_CUDA" {
+		t.Errorf("Expected GPU_CUDA routing for 32MB task, got %s", res.RoutingProvider)
+	}
+	if res.EstimatedLatencyMs <= 0 {
+		t.Errorf("Expected a latency estimate alongside the provider, got %f", res.EstimatedLatencyMs)
+	}
+
+	state, err := c.GetStateReconstitution(ctx, &pb.ReconstitutionRequest{AgentId: "hanging-agent"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.ReasoningChain != "Initial step before crash" {
+		t.Errorf("Reconstituted wrong state: %s", state.ReasoningChain)
+	}
+
+	_, err = c.GetStateReconstitution(ctx, &pb.ReconstitutionRequest{AgentId: "unknown"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("Expected NotFound for agent without state, got %v", err)
+	}
+}
+
+func TestServerGenerateRecordsMetrics(t *testing.T) {
+	c, srv := startTestServer(t)
+	ctx := context.Background()
+	srv.Inference().ConfigureBatching(controller.BatchPolicy{Window: time.Millisecond})
+
+	if _, err := c.RegisterAgent(ctx, &pb.HandshakeRequest{AgentId: "coder"}); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := c.GenerateResponse(ctx, &pb.InferenceRequest{AgentId: "coder", Prompt: "hello mesh"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.HardwarePath != "CPU_AVX2" {
+		t.Errorf("Expected CPU_AVX2 for a small prompt, got %s", res.HardwarePath)
+	}
+
+	agent, _ := srv.Registry().GetAgent("coder")
+	if agent.RequestCount != 1 || agent.TotalTokens != res.TokensUsed {
+		t.Errorf("Metrics not recorded: requests=%d tokens=%d", agent.RequestCount, agent.TotalTokens)
+	}
+
+	if _, err := c.GenerateResponse(ctx, &pb.InferenceRequest{AgentId: "coder"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for an empty prompt, got %v", err)
+	}
+
+	// The request held and released a CPU_AVX2 lease.
+	stats, err := c.GetMeshStats(ctx, &pb.StatsRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ld := stats.ProviderLoad["CPU_AVX2"]; ld == nil || ld.Acquired != 1 || ld.InFlight != 0 {
+		t.Errorf("Expected one completed CPU_AVX2 task, got %+v", ld)
+	}
+	if b := stats.Batching["CPU_AVX2"]; b == nil || b.Batches != 1 || b.SizeCounts[1] != 1 {
+		t.Errorf("Expected one batch of size 1 on CPU_AVX2, got %+v", b)
+	}
+}
+
+func TestServerGenerateStream(t *testing.T) {
+	c, srv := startTestServer(t)
+	ctx := context.Background()
+	if _, err := c.RegisterAgent(ctx, &pb.HandshakeRequest{AgentId: "coder"}); err != nil {
+		t.Fatal(err)
+	}
+
+	stream, err := c.GenerateStream(ctx, &pb.InferenceRequest{AgentId: "coder", Prompt: "stream me please"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var text string
+	var last *pb.InferenceChunk
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		text += chunk.Text
+		last = chunk
+	}
+	if last == nil || !last.Done || last.Summary == nil {
+		t.Fatalf("Expected a final summary chunk, got %+v", last)
+	}
+	if text != "[vextra-fake] stream me please" || last.TokensGenerated != 4 {
+		t.Errorf("Unexpected stream: %q in %d tokens", text, last.TokensGenerated)
+	}
+	if last.Summary.HardwarePath != "CPU_AVX2" || last.Summary.Text != text {
+		t.Errorf("Unexpected summary: %+v", last.Summary)
+	}
+	if agent, _ := srv.Registry().GetAgent("coder"); agent.RequestCount != 1 {
+		t.Errorf("Expected the stream to record metrics, got %d requests", agent.RequestCount)
+	}
+
+	stream, err = c.GenerateStream(ctx, &pb.InferenceRequest{AgentId: "coder"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stream.Recv(); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for an empty prompt, got %v", err)
+	}
+}
+
+func TestServerGenerateStreamCancel(t *testing.T) {
+	c, srv := startTestServer(t)
+	srv.Inference().UseDefaultBackend(&backend.Fake{Name: "slow", TokenDelay: 20 * time.Millisecond})
+	if _, err := c.RegisterAgent(context.Background(), &pb.HandshakeRequest{AgentId: "coder"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// 200 tokens at 20ms would take 4s; cancelling after the first must abort it.
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := c.GenerateStream(ctx, &pb.InferenceRequest{
+		AgentId:   "coder",
+		Prompt:    strings.Repeat("tok ", 200),
+		MaxTokens: 200,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stream.Recv(); err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		ld := srv.scheduler.LoadStats()["CPU_AVX2"]
+		if ld.Acquired == 1 && ld.InFlight == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected the cancelled generation to release its lease, got %+v", ld)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if agent, _ := srv.Registry().GetAgent("coder"); agent.RequestCount != 0 {
+		t.Errorf("Expected no metrics for an aborted stream, got %d requests", agent.RequestCount)
+	}
+}
+
+func TestServerEnforcesInferenceBudget(t *testing.T) {
+	c, srv := startTestServer(t)
+	ctx := context.Background()
+	policy := controller.DefaultBudgetPolicy()
+	policy.TokensPerMinute = 400 // 300 at the 75% handshake CPU limit.
+	srv.Admission().Configure(policy)
+
+	hs, err := c.RegisterAgent(ctx, &pb.HandshakeRequest{AgentId: "flooder"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := hs.InferenceBudget; b == nil || b.TokensPerMinute != 300 || b.MaxConcurrent != 2 {
+		t.Errorf("Expected the handshake to advertise the budget, got %+v", b)
+	}
+
+	// 100 prompt words plus 100 completion tokens, billed in full by the fake backend.
+	req := &pb.InferenceRequest{AgentId: "flooder", Prompt: strings.Repeat("tok ", 100), MaxTokens: 100}
+	if _, err := c.GenerateResponse(ctx, req); err != nil {
+		t.Fatal(err)
+	}
+	var trailer metadata.MD
+	_, err = c.GenerateResponse(ctx, req, grpc.Trailer(&trailer))
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("Expected RESOURCE_EXHAUSTED, got %v", err)
+	}
+	// 100 tokens short at 300 per minute.
+	if got := trailer.Get(RetryAfterKey); len(got) != 1 || got[0] != "20" {
+		t.Errorf("Expected retry-after 20, got %v", got)
+	}
+
+	stream, err := c.GenerateStream(ctx, &pb.InferenceRequest{AgentId: "flooder", Prompt: "hi", MaxTokens: 100000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stream.Recv(); status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("Expected RESOURCE_EXHAUSTED for an oversized stream, got %v", err)
+	}
+	if got := stream.Trailer().Get(RetryAfterKey); len(got) != 0 {
+		t.Errorf("Expected no retry-after for a request that can never fit, got %v", got)
+	}
+
+	stats, err := c.GetMeshStats(ctx, &pb.StatsRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m := stats.AgentLogs["flooder"]; m == nil || m.RejectedRequests != 2 {
+		t.Errorf("Expected two recorded rejections, got %+v", m)
+	}
+}
+
+func TestServerReportsSpillover(t *testing.T) {
+	c, srv := startTestServer(t)
+	ctx := context.Background()
+	srv.scheduler.ConfigureLoad(controller.LoadPolicy{MaxQueueDepth: 1})
+
+	held, _ := srv.scheduler.AcquireTask(256 << 20)
+	defer held.Release()
+	spilled, _ := srv.scheduler.AcquireTask(256 << 20)
+	defer spilled.Release()
+	if held.String() != "GPU_CUDA" || !spilled.Spilled {
+		t.Fatalf("Expected the second task to spill off GPU_CUDA, got %s then %s", held, spilled)
+	}
+
+	stats, err := c.GetMeshStats(ctx, &pb.StatsRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ld := stats.ProviderLoad["GPU_CUDA"]; ld == nil || ld.SpilledOut != 1 || ld.InFlight != 1 {
+		t.Errorf("Expected GPU_CUDA to report one spillover, got %+v", ld)
+	}
+	if ld := stats.ProviderLoad[spilled.String()]; ld == nil || ld.SpilledIn != 1 {
+		t.Errorf("Expected %s to report one spilled-in task, got %+v", spilled, ld)
+	}
+}
+
+func TestServerSynthesizeOutputs(t *testing.T) {
+	c, _ := startTestServer(t)
+
+	res, err := c.SynthesizeOutputs(context.Background(), &pb.SynthesisRequest{
+		AgentIds:   []string{"a", "b"},
+		TargetGoal: "merge",
+		ActionsToMerge: []*pb.AgentAction{
+			{AgentId: "a", ReasoningChain: "step-a"},
+			{AgentId: "b", ReasoningChain: "step-b"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.SynthesizedState != "Merged State: [a: step-a] [b: step-b] " {
+		t.Errorf("Unexpected synthesized state: %q", res.SynthesizedState)
+	}
+}
+
+func TestServerGracefulShutdown(t *testing.T) {
+	lis := bufconn.Listen(1024 * 1024)
+	srv := NewServer(controller.NewScheInfer(16*1024*1024, "", 0, false))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- srv.Run(ctx, lis)
+	}()
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Expected clean shutdown, got %v", err)
+		}
+	case <-time.After(ShutdownTimeout):
+		t.Fatal("Run did not return after context cancellation")
+	}
+}
+
+func TestServerStateSurvivesRestart(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "mesh_state.db")
+	scheduler := controller.NewScheInfer(16*1024*1024, "", 0, false)
+
+	store, err := controller.NewSQLiteStore(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv, err := NewServerWithStore(scheduler, store, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	srv.RegisterAgent(ctx, &pb.HandshakeRequest{AgentId: "hanging-agent"})
+	if _, err := srv.ExecuteStrategicAction(ctx, &pb.AgentAction{
+		AgentId: "hanging-agent", ActionType: "OS_TASK", ReasoningChain: "Initial step before crash",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	store.Close()
+
+	// Controller restarts against the same database.
+	store, err = controller.NewSQLiteStore(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	srv, err = NewServerWithStore(scheduler, store, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	state, err := srv.GetStateReconstitution(ctx, &pb.ReconstitutionRequest{AgentId: "hanging-agent"})
+	if err != nil {
+		t.Fatalf("Reconstitution failed after restart: %v", err)
+	}
+	if state.ReasoningChain != "Initial step before crash" {
+		t.Errorf("Unexpected reconstituted state: %s", state.ReasoningChain)
+	}
+	if _, ok := srv.Registry().GetAgent("hanging-agent"); !ok {
+		t.Errorf("Agent registration lost across restart")
+	}
+}
+
+func TestServerNeighborGraph(t *testing.T) {
+	c, _ := startTestServer(t)
+	ctx := context.Background()
+
+	for _, id := range []string{"scout", "coder", "reviewer"} {
+		if _, err := c.RegisterAgent(ctx, &pb.HandshakeRequest{AgentId: id, Capabilities: []string{"CODE"}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	graph, err := c.GetNeighborGraph(ctx, &pb.NeighborGraphRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(graph.Adjacency) != 3 {
+		t.Fatalf("Expected 3 agents in graph, got %d", len(graph.Adjacency))
+	}
+	if edges := graph.Adjacency["reviewer"].Edges; len(edges) != 2 {
+		t.Errorf("Expected reviewer to have 2 neighbors, got %d", len(edges))
+	}
+
+	single, err := c.GetNeighborGraph(ctx, &pb.NeighborGraphRequest{AgentId: "coder"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(single.Adjacency) != 1 || single.Adjacency["coder"].UtilityScore != 1.0 {
+		t.Errorf("Unexpected single-agent graph: %v", single.Adjacency)
+	}
+
+	if _, err := c.GetNeighborGraph(ctx, &pb.NeighborGraphRequest{AgentId: "ghost"}); status.Code(err) != codes.NotFound {
+		t.Errorf("Expected NotFound for unknown agent, got %v", err)
+	}
+}
+
+func TestServerFencedLockLifecycle(t *testing.T) {
+	c, _ := startTestServer(t)
+	ctx := context.Background()
+
+	for _, id := range []string{"planner", "rival"} {
+		if _, err := c.RegisterAgent(ctx, &pb.HandshakeRequest{AgentId: id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	grant, err := c.AcquireLock(ctx, &pb.LockRequest{AgentId: "planner", LeaseMs: 60000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !grant.Granted || grant.FencingToken == 0 || grant.ExpiresAt == nil {
+		t.Fatalf("Expected a fenced grant, got %+v", grant)
+	}
+
+	denied, err := c.AcquireLock(ctx, &pb.LockRequest{AgentId: "rival"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if denied.Granted || denied.HolderId != "planner" {
+		t.Errorf("Expected rival denied with holder planner, got %+v", denied)
+	}
+
+	// Several steps carrying the token keep the agent strategic.
+	for i := 0; i < 2; i++ {
+		res, err := c.ExecuteStrategicAction(ctx, &pb.AgentAction{
+			AgentId: "planner", ActionType: "REASONING", FencingToken: grant.FencingToken,
+		})
+		if err != nil {
+			t.Fatalf("Step %d with valid token failed: %v", i, err)
+		}
+		if res.RequiredRole != pb.AgentRole_STRATEGIC {
+			t.Errorf("Expected STRATEGIC role while holding the lease, got %v", res.RequiredRole)
+		}
+	}
+
+	if _, err := c.RenewLock(ctx, &pb.LockRequest{AgentId: "planner", FencingToken: grant.FencingToken}); err != nil {
+		t.Errorf("Renew failed: %v", err)
+	}
+	if _, err := c.ReleaseLock(ctx, &pb.LockRequest{AgentId: "planner", FencingToken: grant.FencingToken}); err != nil {
+		t.Fatal(err)
+	}
+
+	next, err := c.AcquireLock(ctx, &pb.LockRequest{AgentId: "rival"})
+	if err != nil || !next.Granted || next.FencingToken <= grant.FencingToken {
+		t.Fatalf("Expected rival to get a newer token, got %+v (%v)", next, err)
+	}
+
+	// The old holder's token is now stale.
+	_, err = c.ExecuteStrategicAction(ctx, &pb.AgentAction{AgentId: "planner", FencingToken: grant.FencingToken})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("Expected FailedPrecondition for stale token, got %v", err)
+	}
+	if _, err := c.RenewLock(ctx, &pb.LockRequest{AgentId: "planner", FencingToken: grant.FencingToken}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("Expected FailedPrecondition for stale renewal, got %v", err)
+	}
+}
+
+func TestServerLockDomainsAndQueue(t *testing.T) {
+	c, _ := startTestServer(t)
+	ctx := context.Background()
+
+	for _, id := range []string{"compiler", "researcher", "waiter"} {
+		if _, err := c.RegisterAgent(ctx, &pb.HandshakeRequest{AgentId: id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Unrelated goals both get the counterbalance.
+	for _, a := range []*pb.AgentAction{
+		{AgentId: "compiler", ActionType: "HIGH_COMPLEXITY", LockDomain: "goal:compile"},
+		{AgentId: "researcher", ActionType: "HIGH_COMPLEXITY", LockDomain: "goal:research"},
+	} {
+		res, err := c.ExecuteStrategicAction(ctx, a)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !res.PromotionSuggested {
+			t.Errorf("Expected %s promoted on %s", a.AgentId, a.LockDomain)
+		}
+	}
+
+	// A blocking acquire on a held domain honors the RPC deadline.
+	short, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	_, err := c.AcquireLock(short, &pb.LockRequest{AgentId: "waiter", Domain: "goal:compile", Wait: true})
+	if status.Code(err) != codes.DeadlineExceeded {
+		t.Errorf("Expected DeadlineExceeded for queued acquire, got %v", err)
+	}
+
+	stats, err := c.GetMeshStats(ctx, &pb.StatsRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	compile := stats.LockDomains["goal:compile"]
+	if compile == nil || compile.HolderId != "compiler" || compile.Contended != 1 || compile.Timeouts != 1 {
+		t.Errorf("Unexpected goal:compile metrics: %+v", compile)
+	}
+	if research := stats.LockDomains["goal:research"]; research == nil || research.Contended != 0 {
+		t.Errorf("Unexpected goal:research metrics: %+v", research)
+	}
+}
+
+func TestServerPointInTimeReconstitution(t *testing.T) {
+	c, _ := startTestServer(t)
+	ctx := context.Background()
+
+	if _, err := c.RegisterAgent(ctx, &pb.HandshakeRequest{AgentId: "recovering"}); err != nil {
+		t.Fatal(err)
+	}
+	for _, step := range []string{"load dataset", "train", "corrupt output"} {
+		if _, err := c.ExecuteStrategicAction(ctx, &pb.AgentAction{AgentId: "recovering", ActionType: "OS_TASK", ReasoningChain: step}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	history, err := c.GetStateHistory(ctx, &pb.ReconstitutionRequest{AgentId: "recovering"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history.Snapshots) != 3 || history.Snapshots[2].Version != 3 {
+		t.Fatalf("Unexpected history: %v", history.Snapshots)
+	}
+
+	prev, err := c.GetStateReconstitution(ctx, &pb.ReconstitutionRequest{AgentId: "recovering", Version: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if prev.ReasoningChain != "train" {
+		t.Errorf("Expected rollback to version 2, got %q", prev.ReasoningChain)
+	}
+
+	asOf, err := c.GetStateReconstitution(ctx, &pb.ReconstitutionRequest{
+		AgentId: "recovering", AsOf: history.Snapshots[0].SavedAt,
+	})
+	if err != nil || asOf.ReasoningChain != "load dataset" {
+		t.Errorf("Expected version 1 as of its save time, got %v (%v)", asOf, err)
+	}
+
+	diff, err := c.DiffStates(ctx, &pb.StateDiffRequest{AgentId: "recovering", FromVersion: 2, ToVersion: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diff.Changes) != 1 || diff.Changes[0].Field != "reasoning_chain" {
+		t.Errorf("Unexpected diff: %v", diff.Changes)
+	}
+
+	if _, err := c.GetStateReconstitution(ctx, &pb.ReconstitutionRequest{AgentId: "recovering", Version: 42}); status.Code(err) != codes.NotFound {
+		t.Errorf("Expected NotFound for unknown version, got %v", err)
+	}
+}
+
+func TestServerKeepsExplicitLeases(t *testing.T) {
+	c, srv := startTestServer(t)
+	ctx := context.Background()
+	if _, err := c.RegisterAgent(ctx, &pb.HandshakeRequest{AgentId: "planner"}); err != nil {
+		t.Fatal(err)
+	}
+
+	lease, err := c.AcquireLock(ctx, &pb.LockRequest{AgentId: "planner", Domain: "deploy", LeaseMs: 60000})
+	if err != nil || !lease.Granted {
+		t.Fatalf("Expected the deploy lease, got %+v (%v)", lease, err)
+	}
+	res, err := c.ExecuteStrategicAction(ctx, &pb.AgentAction{AgentId: "planner", ActionType: "HIGH_COMPLEXITY", LockDomain: "plan"})
+	if err != nil || !res.PromotionSuggested {
+		t.Fatalf("Expected an implicit lock on plan, got %+v (%v)", res, err)
+	}
+
+	// Stepping down releases the implicit lock but not the explicit lease.
+	res, err = c.ExecuteStrategicAction(ctx, &pb.AgentAction{AgentId: "planner", ActionType: "OS_TASK", TaskIntent: "LONG_HORIZON"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, held := srv.Arbiter().LockHolder("plan"); held {
+		t.Error("Expected the implicit plan lock to be released")
+	}
+	if g, held := srv.Arbiter().LockHolder("deploy"); !held || g.Token != lease.FencingToken {
+		t.Errorf("Expected the deploy lease to survive, got %+v", g)
+	}
+	if res.RequiredRole != pb.AgentRole_STRATEGIC {
+		t.Errorf("Expected STRATEGIC while the deploy lease is held, got %v", res.RequiredRole)
+	}
+	if _, err := c.ReleaseLock(ctx, &pb.LockRequest{AgentId: "planner", Domain: "deploy", FencingToken: lease.FencingToken}); err != nil {
+		t.Errorf("Expected the explicit lease to still be releasable, got %v", err)
+	}
+}
+
+func TestServerForwardsToBetterPeer(t *testing.T) {
+	// Node A is AVX2-only; node B has AVX-512 and is reachable over a fast link.
+	nodeA := controller.NewScheInfer(16*1024*1024, "", 0, false)
+	nodeA.ConfigureNetwork(controller.NetworkCost{GBs: 100, RTT: 100 * time.Microsecond})
+	nodeB := controller.NewScheInfer(16*1024*1024, "", 0, true)
+
+	clientB, srvB := startTestServerWith(t, nodeB, func(s *Server) {
+		s.UseMeshRouting("node-b", controller.NewCapabilityDirectory(time.Minute), nil)
+	})
+
+	peers := controller.NewCapabilityDirectory(time.Minute)
+	peers.Update(nodeB.Capability("node-b", "bufnet-b"), time.Now())
+	dialed := 0
+	c, srvA := startTestServerWith(t, nodeA, func(s *Server) {
+		s.UseMeshRouting("node-a", peers, func(addr string) (pb.StrategicMeshClient, error) {
+			dialed++
+			if addr != "bufnet-b" {
+				return nil, errors.New("unknown peer")
+			}
+			return clientB, nil
+		})
+	})
+
+	ctx := context.Background()
+	if _, err := c.RegisterAgent(ctx, &pb.HandshakeRequest{AgentId: "builder"}); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := c.ExecuteStrategicAction(ctx, &pb.AgentAction{AgentId: "builder", ActionType: "OS_COMPILATION", DataSizeBytes: 1 << 30})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Forwarded || res.RoutingNode != "node-b" || res.RoutingProvider != "CPU_AVX512" {
+		t.Errorf("Expected forwarding to node-b/CPU_AVX512, got node=%q provider=%q forwarded=%v", res.RoutingNode, res.RoutingProvider, res.Forwarded)
+	}
+	// The originating controller still owns the agent's state.
+	if snap, ok := srvA.Arbiter().GetState("builder"); !ok || snap.DataSizeBytes != 1<<30 {
+		t.Errorf("Expected node-a to record the action state")
+	}
+	if _, ok := srvB.Arbiter().GetState("builder"); ok {
+		t.Errorf("Expected node-b to leave state to the originator")
+	}
+
+	// Small tasks are not worth the round trip.
+	res, err = c.ExecuteStrategicAction(ctx, &pb.AgentAction{AgentId: "builder", ActionType: "REASONING", DataSizeBytes: 64 * 1024})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Forwarded || res.RoutingNode != "node-a" || res.RoutingProvider != "CPU_AVX2" {
+		t.Errorf("Expected local CPU_AVX2, got node=%q provider=%q", res.RoutingNode, res.RoutingProvider)
+	}
+
+	// An unreachable peer falls back to local execution.
+	peers.Update(nodeB.Capability("node-b", "unreachable"), time.Now())
+	res, err = c.ExecuteStrategicAction(ctx, &pb.AgentAction{AgentId: "builder", ActionType: "OS_COMPILATION", DataSizeBytes: 1 << 30})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Forwarded || res.RoutingProvider != "CPU_AVX2" {
+		t.Errorf("Expected local fallback, got node=%q provider=%q", res.RoutingNode, res.RoutingProvider)
+	}
+	if dialed != 2 {
+		t.Errorf("Expected one dial per peer address, got %d", dialed)
+	}
+}
+
+func TestServerResponseCache(t *testing.T) {
+	c, srv := startTestServer(t)
+	ctx := context.Background()
+	srv.Inference().ConfigureCache(controller.DefaultCachePolicy(), nil)
+	if _, err := c.RegisterAgent(ctx, &pb.HandshakeRequest{AgentId: "reader"}); err != nil {
+		t.Fatal(err)
+	}
+
+	req := &pb.InferenceRequest{AgentId: "reader", Prompt: "describe the mesh", MaxTokens: 8}
+	if _, err := c.GenerateResponse(ctx, req); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := c.GenerateResponse(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resp.Cached || resp.HardwarePath != controller.HardwarePathCache {
+		t.Errorf("Expected a cached response, got %+v", resp)
+	}
+
+	stats, err := c.GetMeshStats(ctx, &pb.StatsRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rc := stats.ResponseCache; rc == nil || rc.Hits != 1 || rc.Misses != 1 || rc.Entries != 1 {
+		t.Errorf("Expected cache stats in GetMeshStats, got %+v", rc)
+	}
+}
@@ -0,0 +1,351 @@
+// Package topology discovers the host's cache, core, NUMA and accelerator
+// layout so ScheInfer can be built without hand-entered hardware figures.
+package topology
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/groovy-byte/agent-mesh-core/internal/quantx"
+	"golang.org/x/sys/cpu"
+)
+
+// CacheInfo describes one cache instance shared by a set of logical CPUs.
+type CacheInfo struct {
+	Level      int
+	Type       string // "Data", "Instruction" or "Unified".
+	SizeBytes  uint64
+	SharedCPUs []int
+}
+
+// NUMANode is one memory node and the CPUs local to it.
+type NUMANode struct {
+	ID            int
+	CPUs          []int
+	MemTotalBytes uint64
+}
+
+// GPUDevice is one accelerator as reported by the GPU bridge.
+type GPUDevice struct {
+	Index      int
+	Name       string
+	VRAMBytes  uint64
+	FreeBytes  uint64
+	ComputeCap int
+}
+
+// HardwareProfile is everything ScheInfer needs to know about a node.
+type HardwareProfile struct {
+	LogicalCPUs   int
+	PhysicalCores int
+	L3CacheBytes  uint64 // Size of one L3 instance (per CCX on chiplet parts).
+	L3Instances   int
+	Caches        []CacheInfo
+	NUMANodes     []NUMANode
+
+	HasAVX2   bool
+	HasFMA    bool
+	HasAVX512 bool
+
+	// The primary (first) GPU; GPUName is empty when no usable GPU was found.
+	GPUName       string
+	GPUVRAMBytes  uint64
+	GPUComputeCap int
+	GPUs          []GPUDevice // Every enumerated device, including the primary.
+}
+
+// Options controls where Probe looks. Tests point SysfsRoot at a fake tree
+// and replace the accelerator hooks.
+type Options struct {
+	SysfsRoot string
+
+	HasAVX2   func() bool
+	HasFMA    func() bool
+	HasAVX512 func() bool
+
+	// GPUDevices enumerates all devices. When unset or failing, Probe falls
+	// back to GPUInfo and GPUComputeCapability for a single device.
+	GPUDevices           func() ([]GPUDevice, error)
+	GPUInfo              func() (string, uint64, error)
+	GPUComputeCapability func() int
+}
+
+// DefaultOptions probes the live host: /sys, x/sys/cpu flags and the quantx bridge.
+func DefaultOptions() Options {
+	return Options{
+		SysfsRoot:            "/sys",
+		HasAVX2:              func() bool { return cpu.X86.HasAVX2 },
+		HasFMA:               func() bool { return cpu.X86.HasFMA },
+		HasAVX512:            quantx.HasAVX512,
+		GPUDevices:           listQuantxDevices,
+		GPUInfo:              quantx.GetGpuInfo,
+		GPUComputeCapability: quantx.GetGpuComputeCapability,
+	}
+}
+
+func listQuantxDevices() ([]GPUDevice, error) {
+	devs, err := quantx.ListGpuDevices()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]GPUDevice, len(devs))
+	for i, d := range devs {
+		out[i] = GPUDevice{
+			Index:      d.Index,
+			Name:       d.Name,
+			VRAMBytes:  d.TotalVRAM,
+			FreeBytes:  d.FreeVRAM,
+			ComputeCap: d.ComputeCapability,
+		}
+	}
+	return out, nil
+}
+
+// Detect probes the live host.
+func Detect() (HardwareProfile, error) {
+	return Probe(DefaultOptions())
+}
+
+// Probe builds a HardwareProfile. A missing sysfs tree (e.g. on non-Linux
+// hosts) is not an error; the affected fields are left zero.
+func Probe(opts Options) (HardwareProfile, error) {
+	var p HardwareProfile
+
+	if err := probeCPUs(filepath.Join(opts.SysfsRoot, "devices/system/cpu"), &p); err != nil {
+		return p, err
+	}
+	nodes, err := probeNUMA(filepath.Join(opts.SysfsRoot, "devices/system/node"))
+	if err != nil {
+		return p, err
+	}
+	p.NUMANodes = nodes
+
+	if opts.HasAVX2 != nil {
+		p.HasAVX2 = opts.HasAVX2()
+	}
+	if opts.HasFMA != nil {
+		p.HasFMA = opts.HasFMA()
+	}
+	if opts.HasAVX512 != nil {
+		p.HasAVX512 = opts.HasAVX512()
+	}
+	probeGPUs(opts, &p)
+	return p, nil
+}
+
+func probeGPUs(opts Options, p *HardwareProfile) {
+	if opts.GPUDevices != nil {
+		if devs, err := opts.GPUDevices(); err == nil && len(devs) > 0 {
+			p.GPUs = devs
+			p.GPUName, p.GPUVRAMBytes, p.GPUComputeCap = devs[0].Name, devs[0].VRAMBytes, devs[0].ComputeCap
+			return
+		}
+	}
+	if opts.GPUInfo == nil {
+		return
+	}
+	name, vram, err := opts.GPUInfo()
+	if err != nil {
+		return
+	}
+	p.GPUName, p.GPUVRAMBytes = name, vram
+	if opts.GPUComputeCapability != nil {
+		p.GPUComputeCap = opts.GPUComputeCapability()
+	}
+	p.GPUs = []GPUDevice{{Name: name, VRAMBytes: vram, FreeBytes: vram, ComputeCap: p.GPUComputeCap}}
+}
+
+var cpuDirPattern = regexp.MustCompile(`^cpu[0-9]+$`)
+
+func probeCPUs(dir string, p *HardwareProfile) error {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("topology: failed to list %s: %w", dir, err)
+	}
+
+	cores := make(map[string]bool)
+	caches := make(map[string]CacheInfo) // Keyed by level/type/shared CPUs.
+	for _, e := range entries {
+		if !cpuDirPattern.MatchString(e.Name()) {
+			continue
+		}
+		p.LogicalCPUs++
+		cpuDir := filepath.Join(dir, e.Name())
+
+		pkg := readString(filepath.Join(cpuDir, "topology/physical_package_id"))
+		core := readString(filepath.Join(cpuDir, "topology/core_id"))
+		if core == "" {
+			core = e.Name() // No topology info: count each logical CPU as a core.
+		}
+		cores[pkg+"/"+core] = true
+
+		indexes, _ := filepath.Glob(filepath.Join(cpuDir, "cache/index[0-9]*"))
+		for _, idx := range indexes {
+			c, ok := readCache(idx)
+			if !ok {
+				continue
+			}
+			key := fmt.Sprintf("%d/%s/%v", c.Level, c.Type, c.SharedCPUs)
+			caches[key] = c
+		}
+	}
+	p.PhysicalCores = len(cores)
+
+	for _, c := range caches {
+		p.Caches = append(p.Caches, c)
+		if c.Level == 3 && c.Type != "Instruction" {
+			p.L3Instances++
+			p.L3CacheBytes = max(p.L3CacheBytes, c.SizeBytes)
+		}
+	}
+	sort.Slice(p.Caches, func(i, j int) bool {
+		a, b := p.Caches[i], p.Caches[j]
+		if a.Level != b.Level {
+			return a.Level < b.Level
+		}
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+		return firstCPU(a.SharedCPUs) < firstCPU(b.SharedCPUs)
+	})
+	return nil
+}
+
+func readCache(dir string) (CacheInfo, bool) {
+	level, err := strconv.Atoi(readString(filepath.Join(dir, "level")))
+	if err != nil {
+		return CacheInfo{}, false
+	}
+	size, err := parseSize(readString(filepath.Join(dir, "size")))
+	if err != nil {
+		return CacheInfo{}, false
+	}
+	shared, _ := ParseCPUList(readString(filepath.Join(dir, "shared_cpu_list")))
+	return CacheInfo{
+		Level:      level,
+		Type:       readString(filepath.Join(dir, "type")),
+		SizeBytes:  size,
+		SharedCPUs: shared,
+	}, true
+}
+
+var nodeDirPattern = regexp.MustCompile(`^node([0-9]+)$`)
+
+func probeNUMA(dir string) ([]NUMANode, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("topology: failed to list %s: %w", dir, err)
+	}
+
+	var nodes []NUMANode
+	for _, e := range entries {
+		m := nodeDirPattern.FindStringSubmatch(e.Name())
+		if m == nil {
+			continue
+		}
+		id, _ := strconv.Atoi(m[1])
+		nodeDir := filepath.Join(dir, e.Name())
+		cpus, err := ParseCPUList(readString(filepath.Join(nodeDir, "cpulist")))
+		if err != nil {
+			return nil, fmt.Errorf("topology: node%d: %w", id, err)
+		}
+		nodes = append(nodes, NUMANode{
+			ID:            id,
+			CPUs:          cpus,
+			MemTotalBytes: readNodeMemTotal(filepath.Join(nodeDir, "meminfo")),
+		})
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].ID < nodes[j].ID })
+	return nodes, nil
+}
+
+// readNodeMemTotal parses "Node 0 MemTotal:   32768000 kB" from a node meminfo file.
+func readNodeMemTotal(path string) uint64 {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) >= 4 && fields[2] == "MemTotal:" {
+			kb, err := strconv.ParseUint(fields[3], 10, 64)
+			if err == nil {
+				return kb * 1024
+			}
+		}
+	}
+	return 0
+}
+
+// ParseCPUList parses the kernel's list format, e.g. "0-3,8,10-11".
+func ParseCPUList(s string) ([]int, error) {
+	cpus := []int{}
+	if s == "" {
+		return cpus, nil
+	}
+	for _, part := range strings.Split(s, ",") {
+		lo, hi, isRange := strings.Cut(part, "-")
+		start, err := strconv.Atoi(lo)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cpu list %q", s)
+		}
+		end := start
+		if isRange {
+			if end, err = strconv.Atoi(hi); err != nil || end < start {
+				return nil, fmt.Errorf("invalid cpu list %q", s)
+			}
+		}
+		for c := start; c <= end; c++ {
+			cpus = append(cpus, c)
+		}
+	}
+	return cpus, nil
+}
+
+// parseSize parses sysfs cache sizes such as "32K", "16384K" or "1M".
+func parseSize(s string) (uint64, error) {
+	mult := uint64(1)
+	switch {
+	case strings.HasSuffix(s, "K"):
+		mult, s = 1024, strings.TrimSuffix(s, "K")
+	case strings.HasSuffix(s, "M"):
+		mult, s = 1024*1024, strings.TrimSuffix(s, "M")
+	case strings.HasSuffix(s, "G"):
+		mult, s = 1024*1024*1024, strings.TrimSuffix(s, "G")
+	}
+	n, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid cache size %q", s)
+	}
+	return n * mult, nil
+}
+
+func readString(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+func firstCPU(cpus []int) int {
+	if len(cpus) == 0 {
+		return -1
+	}
+	return cpus[0]
+}
@@ -0,0 +1,155 @@
+package topology
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// fakeSysfs lays out a chiplet-style host: 8 logical CPUs as 4 SMT cores,
+// two 16MB L3 instances (one per CCX) and two NUMA nodes.
+func fakeSysfs(t *testing.T) string {
+	root := t.TempDir()
+	cpuDir := filepath.Join(root, "devices/system/cpu")
+	for n := 0; n < 8; n++ {
+		dir := filepath.Join(cpuDir, fmt.Sprintf("cpu%d", n))
+		writeFile(t, filepath.Join(dir, "topology/physical_package_id"), "0")
+		writeFile(t, filepath.Join(dir, "topology/core_id"), fmt.Sprint(n/2))
+
+		sibling := fmt.Sprintf("%d-%d", n/2*2, n/2*2+1)
+		writeFile(t, filepath.Join(dir, "cache/index0/level"), "1")
+		writeFile(t, filepath.Join(dir, "cache/index0/type"), "Data")
+		writeFile(t, filepath.Join(dir, "cache/index0/size"), "32K")
+		writeFile(t, filepath.Join(dir, "cache/index0/shared_cpu_list"), sibling)
+
+		ccx := "0-3"
+		if n >= 4 {
+			ccx = "4-7"
+		}
+		writeFile(t, filepath.Join(dir, "cache/index3/level"), "3")
+		writeFile(t, filepath.Join(dir, "cache/index3/type"), "Unified")
+		writeFile(t, filepath.Join(dir, "cache/index3/size"), "16384K")
+		writeFile(t, filepath.Join(dir, "cache/index3/shared_cpu_list"), ccx)
+	}
+	// Non-CPU entries that live alongside cpuN must be ignored.
+	writeFile(t, filepath.Join(cpuDir, "cpufreq/boost"), "1")
+	writeFile(t, filepath.Join(cpuDir, "online"), "0-7")
+
+	nodeDir := filepath.Join(root, "devices/system/node")
+	writeFile(t, filepath.Join(nodeDir, "node0/cpulist"), "0-3")
+	writeFile(t, filepath.Join(nodeDir, "node0/meminfo"), "Node 0 MemTotal:       16384000 kB\nNode 0 MemFree:         8000000 kB")
+	writeFile(t, filepath.Join(nodeDir, "node1/cpulist"), "4-7")
+	writeFile(t, filepath.Join(nodeDir, "node1/meminfo"), "Node 1 MemTotal:       16384000 kB")
+	return root
+}
+
+func TestProbeFakeSysfs(t *testing.T) {
+	p, err := Probe(Options{
+		SysfsRoot:            fakeSysfs(t),
+		HasAVX2:              func() bool { return true },
+		HasAVX512:            func() bool { return true },
+		GPUInfo:              func() (string, uint64, error) { return "Fake RTX", 8 << 30, nil },
+		GPUComputeCapability: func() int { return 86 },
+	})
+	if err != nil {
+		t.Fatalf("Probe failed: %v", err)
+	}
+
+	if p.LogicalCPUs != 8 || p.PhysicalCores != 4 {
+		t.Errorf("Expected 8 logical CPUs on 4 cores, got %d on %d", p.LogicalCPUs, p.PhysicalCores)
+	}
+	if p.L3CacheBytes != 16<<20 || p.L3Instances != 2 {
+		t.Errorf("Expected 2 x 16MB L3, got %d x %d", p.L3Instances, p.L3CacheBytes)
+	}
+	// 4 per-core L1D instances plus 2 L3 instances.
+	if len(p.Caches) != 6 {
+		t.Errorf("Expected 6 cache instances, got %d", len(p.Caches))
+	}
+	if len(p.NUMANodes) != 2 {
+		t.Fatalf("Expected 2 NUMA nodes, got %d", len(p.NUMANodes))
+	}
+	if n := p.NUMANodes[1]; n.ID != 1 || !reflect.DeepEqual(n.CPUs, []int{4, 5, 6, 7}) || n.MemTotalBytes != 16384000*1024 {
+		t.Errorf("Unexpected node1: %+v", n)
+	}
+	if !p.HasAVX2 || !p.HasAVX512 || p.HasFMA {
+		t.Errorf("Expected AVX2+AVX512 without FMA, got %+v", p)
+	}
+	if p.GPUName != "Fake RTX" || p.GPUVRAMBytes != 8<<30 || p.GPUComputeCap != 86 {
+		t.Errorf("Unexpected GPU fields: %q %d %d", p.GPUName, p.GPUVRAMBytes, p.GPUComputeCap)
+	}
+}
+
+func TestProbeWithoutSysfsOrGPU(t *testing.T) {
+	p, err := Probe(Options{
+		SysfsRoot:            filepath.Join(t.TempDir(), "missing"),
+		GPUInfo:              func() (string, uint64, error) { return "", 0, errors.New("no device") },
+		GPUComputeCapability: func() int { return 86 },
+	})
+	if err != nil {
+		t.Fatalf("Expected a missing sysfs tree to be tolerated, got %v", err)
+	}
+	if p.LogicalCPUs != 0 || p.L3CacheBytes != 0 || p.NUMANodes != nil {
+		t.Errorf("Expected an empty CPU profile, got %+v", p)
+	}
+	if p.GPUName != "" || p.GPUComputeCap != 0 {
+		t.Errorf("Expected no GPU when GPUInfo fails, got %q cc=%d", p.GPUName, p.GPUComputeCap)
+	}
+}
+
+func TestProbeEnumeratesGPUs(t *testing.T) {
+	devs := []GPUDevice{
+		{Index: 0, Name: "RTX 3070", VRAMBytes: 8 << 30, FreeBytes: 7 << 30, ComputeCap: 86},
+		{Index: 1, Name: "RTX 4090", VRAMBytes: 24 << 30, FreeBytes: 20 << 30, ComputeCap: 89},
+	}
+	p, err := Probe(Options{
+		SysfsRoot:  t.TempDir(),
+		GPUDevices: func() ([]GPUDevice, error) { return devs, nil },
+		GPUInfo:    func() (string, uint64, error) { return "unused", 1, nil },
+	})
+	if err != nil {
+		t.Fatalf("Probe failed: %v", err)
+	}
+	if !reflect.DeepEqual(p.GPUs, devs) {
+		t.Errorf("Expected enumerated devices, got %+v", p.GPUs)
+	}
+	if p.GPUName != "RTX 3070" || p.GPUVRAMBytes != 8<<30 || p.GPUComputeCap != 86 {
+		t.Errorf("Expected device 0 as the primary GPU, got %q %d %d", p.GPUName, p.GPUVRAMBytes, p.GPUComputeCap)
+	}
+}
+
+func TestParseCPUList(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []int
+		ok   bool
+	}{
+		{"", []int{}, true},
+		{"3", []int{3}, true},
+		{"0-3,8,10-11", []int{0, 1, 2, 3, 8, 10, 11}, true},
+		{"4-2", nil, false},
+		{"a-b", nil, false},
+	}
+	for _, tt := range tests {
+		got, err := ParseCPUList(tt.in)
+		if (err == nil) != tt.ok {
+			t.Errorf("ParseCPUList(%q): expected ok=%v, got err=%v", tt.in, tt.ok, err)
+			continue
+		}
+		if tt.ok && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseCPUList(%q): expected %v, got %v", tt.in, tt.want, got)
+		}
+	}
+}
@@ -26,8 +26,8 @@ const (
 type AgentRole int32
 
 const (
-	AgentRole_OPERATIONAL AgentRole = 0 // NATS-based fast path
-	AgentRole_STRATEGIC   AgentRole = 1 // gRPC-based reasoning path
+	AgentRole_OPERATIONAL AgentRole = 0 // Low-latency, high-throughput tasks via NATS.
+	AgentRole_STRATEGIC   AgentRole = 1 // Complex, reasoning-heavy tasks via gRPC.
 )
 
 // Enum value maps for AgentRole.
@@ -69,6 +69,105 @@ func (AgentRole) EnumDescriptor() ([]byte, []int) {
 	return file_proto_mesh_proto_rawDescGZIP(), []int{0}
 }
 
+type KVCompression int32
+
+const (
+	KVCompression_COMPRESSION_NONE KVCompression = 0
+	KVCompression_COMPRESSION_ZSTD KVCompression = 1
+	KVCompression_COMPRESSION_S2   KVCompression = 2
+)
+
+// Enum value maps for KVCompression.
+var (
+	KVCompression_name = map[int32]string{
+		0: "COMPRESSION_NONE",
+		1: "COMPRESSION_ZSTD",
+		2: "COMPRESSION_S2",
+	}
+	KVCompression_value = map[string]int32{
+		"COMPRESSION_NONE": 0,
+		"COMPRESSION_ZSTD": 1,
+		"COMPRESSION_S2":   2,
+	}
+)
+
+func (x KVCompression) Enum() *KVCompression {
+	p := new(KVCompression)
+	*p = x
+	return p
+}
+
+func (x KVCompression) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (KVCompression) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_mesh_proto_enumTypes[1].Descriptor()
+}
+
+func (KVCompression) Type() protoreflect.EnumType {
+	return &file_proto_mesh_proto_enumTypes[1]
+}
+
+func (x KVCompression) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use KVCompression.Descriptor instead.
+func (KVCompression) EnumDescriptor() ([]byte, []int) {
+	return file_proto_mesh_proto_rawDescGZIP(), []int{1}
+}
+
+// Lossy block encodings for payloads of little-endian float32 values.
+type KVQuantization int32
+
+const (
+	KVQuantization_QUANT_NONE KVQuantization = 0
+	KVQuantization_QUANT_Q8_0 KVQuantization = 1 // 32 values per block: a float32 scale and 32 int8s.
+	KVQuantization_QUANT_Q2_K KVQuantization = 2 // 256 values per block: float32 scale and minimum, 2 bits each.
+)
+
+// Enum value maps for KVQuantization.
+var (
+	KVQuantization_name = map[int32]string{
+		0: "QUANT_NONE",
+		1: "QUANT_Q8_0",
+		2: "QUANT_Q2_K",
+	}
+	KVQuantization_value = map[string]int32{
+		"QUANT_NONE": 0,
+		"QUANT_Q8_0": 1,
+		"QUANT_Q2_K": 2,
+	}
+)
+
+func (x KVQuantization) Enum() *KVQuantization {
+	p := new(KVQuantization)
+	*p = x
+	return p
+}
+
+func (x KVQuantization) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (KVQuantization) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_mesh_proto_enumTypes[2].Descriptor()
+}
+
+func (KVQuantization) Type() protoreflect.EnumType {
+	return &file_proto_mesh_proto_enumTypes[2]
+}
+
+func (x KVQuantization) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use KVQuantization.Descriptor instead.
+func (KVQuantization) EnumDescriptor() ([]byte, []int) {
+	return file_proto_mesh_proto_rawDescGZIP(), []int{2}
+}
+
 type OSResources struct {
 	state            protoimpl.MessageState `protogen:"open.v1"`
 	CpuUsagePercent  float64                `protobuf:"fixed64,1,opt,name=cpu_usage_percent,json=cpuUsagePercent,proto3" json:"cpu_usage_percent,omitempty"`
@@ -198,13 +297,14 @@ func (x *HandshakeRequest) GetInitialRole() AgentRole {
 }
 
 type HandshakeResponse struct {
-	state          protoimpl.MessageState `protogen:"open.v1"`
-	SessionId      string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
-	Approved       bool                   `protobuf:"varint,2,opt,name=approved,proto3" json:"approved,omitempty"`
-	ErrorMessage   string                 `protobuf:"bytes,3,opt,name=error_message,json=errorMessage,proto3" json:"error_message,omitempty"`
-	ResourceLimits *OSResources           `protobuf:"bytes,4,opt,name=resource_limits,json=resourceLimits,proto3" json:"resource_limits,omitempty"`
-	unknownFields  protoimpl.UnknownFields
-	sizeCache      protoimpl.SizeCache
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	SessionId       string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	Approved        bool                   `protobuf:"varint,2,opt,name=approved,proto3" json:"approved,omitempty"`
+	ErrorMessage    string                 `protobuf:"bytes,3,opt,name=error_message,json=errorMessage,proto3" json:"error_message,omitempty"`
+	ResourceLimits  *OSResources           `protobuf:"bytes,4,opt,name=resource_limits,json=resourceLimits,proto3" json:"resource_limits,omitempty"`
+	InferenceBudget *InferenceBudget       `protobuf:"bytes,5,opt,name=inference_budget,json=inferenceBudget,proto3" json:"inference_budget,omitempty"` // Derived from resource_limits and the initial role.
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
 }
 
 func (x *HandshakeResponse) Reset() {
@@ -265,6 +365,66 @@ func (x *HandshakeResponse) GetResourceLimits() *OSResources {
 	return nil
 }
 
+func (x *HandshakeResponse) GetInferenceBudget() *InferenceBudget {
+	if x != nil {
+		return x.InferenceBudget
+	}
+	return nil
+}
+
+// InferenceBudget is enforced on GenerateResponse and GenerateStream.
+type InferenceBudget struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	TokensPerMinute uint32                 `protobuf:"varint,1,opt,name=tokens_per_minute,json=tokensPerMinute,proto3" json:"tokens_per_minute,omitempty"`
+	MaxConcurrent   uint32                 `protobuf:"varint,2,opt,name=max_concurrent,json=maxConcurrent,proto3" json:"max_concurrent,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *InferenceBudget) Reset() {
+	*x = InferenceBudget{}
+	mi := &file_proto_mesh_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InferenceBudget) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InferenceBudget) ProtoMessage() {}
+
+func (x *InferenceBudget) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mesh_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InferenceBudget.ProtoReflect.Descriptor instead.
+func (*InferenceBudget) Descriptor() ([]byte, []int) {
+	return file_proto_mesh_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *InferenceBudget) GetTokensPerMinute() uint32 {
+	if x != nil {
+		return x.TokensPerMinute
+	}
+	return 0
+}
+
+func (x *InferenceBudget) GetMaxConcurrent() uint32 {
+	if x != nil {
+		return x.MaxConcurrent
+	}
+	return 0
+}
+
 type Heartbeat struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
@@ -277,7 +437,7 @@ type Heartbeat struct {
 
 func (x *Heartbeat) Reset() {
 	*x = Heartbeat{}
-	mi := &file_proto_mesh_proto_msgTypes[3]
+	mi := &file_proto_mesh_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -289,7 +449,7 @@ func (x *Heartbeat) String() string {
 func (*Heartbeat) ProtoMessage() {}
 
 func (x *Heartbeat) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_mesh_proto_msgTypes[3]
+	mi := &file_proto_mesh_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -302,7 +462,7 @@ func (x *Heartbeat) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Heartbeat.ProtoReflect.Descriptor instead.
 func (*Heartbeat) Descriptor() ([]byte, []int) {
-	return file_proto_mesh_proto_rawDescGZIP(), []int{3}
+	return file_proto_mesh_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *Heartbeat) GetAgentId() string {
@@ -334,26 +494,24 @@ func (x *Heartbeat) GetCurrentRole() AgentRole {
 }
 
 type AgentAction struct {
-	state      protoimpl.MessageState `protogen:"open.v1"`
-	AgentId    string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
-	ActionType string                 `protobuf:"bytes,2,opt,name=action_type,json=actionType,proto3" json:"action_type,omitempty"` // e.g. "OS_COMPILATION", "REASONING", "SEARCH"
-	// Strict parameters for known OS operations
-	ResourceImpact *OSResources `protobuf:"bytes,3,opt,name=resource_impact,json=resourceImpact,proto3" json:"resource_impact,omitempty"`
-	// The JSON Flex-Layer: for unmodeled task data/self-programming
-	Payload *structpb.Struct `protobuf:"bytes,4,opt,name=payload,proto3" json:"payload,omitempty"`
-	// Strategic reasoning context (populated when in Strategic role)
-	ReasoningChain string `protobuf:"bytes,5,opt,name=reasoning_chain,json=reasoningChain,proto3" json:"reasoning_chain,omitempty"`
-	// Intent declaration for predictive throttling (AgentCgroup)
-	TaskIntent string `protobuf:"bytes,6,opt,name=task_intent,json=taskIntent,proto3" json:"task_intent,omitempty"`
-	// --- Phase 7: ScheInfer Hints ---
-	DataSizeBytes uint64 `protobuf:"varint,7,opt,name=data_size_bytes,json=dataSizeBytes,proto3" json:"data_size_bytes,omitempty"` // Size of the tensor/data to be processed
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	AgentId        string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	ActionType     string                 `protobuf:"bytes,2,opt,name=action_type,json=actionType,proto3" json:"action_type,omitempty"` // e.g., "OS_COMPILATION", "REASONING", "SEARCH".
+	ResourceImpact *OSResources           `protobuf:"bytes,3,opt,name=resource_impact,json=resourceImpact,proto3" json:"resource_impact,omitempty"`
+	Payload        *structpb.Struct       `protobuf:"bytes,4,opt,name=payload,proto3" json:"payload,omitempty"`                                     // Flexible payload for arbitrary task data.
+	ReasoningChain string                 `protobuf:"bytes,5,opt,name=reasoning_chain,json=reasoningChain,proto3" json:"reasoning_chain,omitempty"` // Context for strategic tasks.
+	TaskIntent     string                 `protobuf:"bytes,6,opt,name=task_intent,json=taskIntent,proto3" json:"task_intent,omitempty"`             // Intent declaration for predictive resource management.
+	DataSizeBytes  uint64                 `protobuf:"varint,7,opt,name=data_size_bytes,json=dataSizeBytes,proto3" json:"data_size_bytes,omitempty"` // Size hint for hardware-aware scheduling.
+	FencingToken   uint64                 `protobuf:"varint,8,opt,name=fencing_token,json=fencingToken,proto3" json:"fencing_token,omitempty"`      // Strategic lock token from AcquireLock; stale tokens are rejected.
+	LockDomain     string                 `protobuf:"bytes,9,opt,name=lock_domain,json=lockDomain,proto3" json:"lock_domain,omitempty"`             // Goal or resource the strategic lock covers; empty uses the global domain.
+	ForwardedFrom  string                 `protobuf:"bytes,10,opt,name=forwarded_from,json=forwardedFrom,proto3" json:"forwarded_from,omitempty"`   // Set by the controller that forwarded this action; it is executed locally and never re-forwarded.
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
 }
 
 func (x *AgentAction) Reset() {
 	*x = AgentAction{}
-	mi := &file_proto_mesh_proto_msgTypes[4]
+	mi := &file_proto_mesh_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -365,7 +523,7 @@ func (x *AgentAction) String() string {
 func (*AgentAction) ProtoMessage() {}
 
 func (x *AgentAction) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_mesh_proto_msgTypes[4]
+	mi := &file_proto_mesh_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -378,7 +536,7 @@ func (x *AgentAction) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use AgentAction.ProtoReflect.Descriptor instead.
 func (*AgentAction) Descriptor() ([]byte, []int) {
-	return file_proto_mesh_proto_rawDescGZIP(), []int{4}
+	return file_proto_mesh_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *AgentAction) GetAgentId() string {
@@ -430,24 +588,45 @@ func (x *AgentAction) GetDataSizeBytes() uint64 {
 	return 0
 }
 
+func (x *AgentAction) GetFencingToken() uint64 {
+	if x != nil {
+		return x.FencingToken
+	}
+	return 0
+}
+
+func (x *AgentAction) GetLockDomain() string {
+	if x != nil {
+		return x.LockDomain
+	}
+	return ""
+}
+
+func (x *AgentAction) GetForwardedFrom() string {
+	if x != nil {
+		return x.ForwardedFrom
+	}
+	return ""
+}
+
 type ActionResponse struct {
-	state   protoimpl.MessageState `protogen:"open.v1"`
-	Success bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
-	Result  *structpb.Struct       `protobuf:"bytes,2,opt,name=result,proto3" json:"result,omitempty"`
-	Error   string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
-	// Signal for role evolution (e.g. promote to Boss)
-	PromotionSuggested bool `protobuf:"varint,4,opt,name=promotion_suggested,json=promotionSuggested,proto3" json:"promotion_suggested,omitempty"`
-	// --- Phase 7: ScheInfer Decision ---
-	RoutingProvider string `protobuf:"bytes,5,opt,name=routing_provider,json=routingProvider,proto3" json:"routing_provider,omitempty"` // e.g. "CPU_AVX2", "GPU_CUDA", "GPU_VULKAN"
-	// Explicit role enforcement from controller (Task 5.1 cleanup)
-	RequiredRole  AgentRole `protobuf:"varint,6,opt,name=required_role,json=requiredRole,proto3,enum=mesh.AgentRole" json:"required_role,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	Success            bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Result             *structpb.Struct       `protobuf:"bytes,2,opt,name=result,proto3" json:"result,omitempty"`
+	Error              string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	PromotionSuggested bool                   `protobuf:"varint,4,opt,name=promotion_suggested,json=promotionSuggested,proto3" json:"promotion_suggested,omitempty"`    // Suggests a role change to STRATEGIC.
+	RoutingProvider    string                 `protobuf:"bytes,5,opt,name=routing_provider,json=routingProvider,proto3" json:"routing_provider,omitempty"`              // The hardware path chosen by the scheduler (e.g., "CPU_AVX2", "GPU_CUDA").
+	RequiredRole       AgentRole              `protobuf:"varint,6,opt,name=required_role,json=requiredRole,proto3,enum=mesh.AgentRole" json:"required_role,omitempty"`  // Enforced role from the controller.
+	EstimatedLatencyMs float32                `protobuf:"fixed32,7,opt,name=estimated_latency_ms,json=estimatedLatencyMs,proto3" json:"estimated_latency_ms,omitempty"` // ScheInfer cost-model estimate for routing_provider.
+	RoutingNode        string                 `protobuf:"bytes,8,opt,name=routing_node,json=routingNode,proto3" json:"routing_node,omitempty"`                          // Node that executed the action; empty when routing is node-local only.
+	Forwarded          bool                   `protobuf:"varint,9,opt,name=forwarded,proto3" json:"forwarded,omitempty"`                                                // The action ran on a remote node.
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
 }
 
 func (x *ActionResponse) Reset() {
 	*x = ActionResponse{}
-	mi := &file_proto_mesh_proto_msgTypes[5]
+	mi := &file_proto_mesh_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -459,7 +638,7 @@ func (x *ActionResponse) String() string {
 func (*ActionResponse) ProtoMessage() {}
 
 func (x *ActionResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_mesh_proto_msgTypes[5]
+	mi := &file_proto_mesh_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -472,7 +651,7 @@ func (x *ActionResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ActionResponse.ProtoReflect.Descriptor instead.
 func (*ActionResponse) Descriptor() ([]byte, []int) {
-	return file_proto_mesh_proto_rawDescGZIP(), []int{5}
+	return file_proto_mesh_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *ActionResponse) GetSuccess() bool {
@@ -517,21 +696,42 @@ func (x *ActionResponse) GetRequiredRole() AgentRole {
 	return AgentRole_OPERATIONAL
 }
 
+func (x *ActionResponse) GetEstimatedLatencyMs() float32 {
+	if x != nil {
+		return x.EstimatedLatencyMs
+	}
+	return 0
+}
+
+func (x *ActionResponse) GetRoutingNode() string {
+	if x != nil {
+		return x.RoutingNode
+	}
+	return ""
+}
+
+func (x *ActionResponse) GetForwarded() bool {
+	if x != nil {
+		return x.Forwarded
+	}
+	return false
+}
+
 type InferenceRequest struct {
-	state       protoimpl.MessageState `protogen:"open.v1"`
-	AgentId     string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
-	Prompt      string                 `protobuf:"bytes,2,opt,name=prompt,proto3" json:"prompt,omitempty"`
-	MaxTokens   uint32                 `protobuf:"varint,3,opt,name=max_tokens,json=maxTokens,proto3" json:"max_tokens,omitempty"`
-	Temperature float32                `protobuf:"fixed32,4,opt,name=temperature,proto3" json:"temperature,omitempty"`
-	// Adaptive Hint: Expected KV Cache size in bytes
-	ExpectedKvCacheBytes uint64 `protobuf:"varint,5,opt,name=expected_kv_cache_bytes,json=expectedKvCacheBytes,proto3" json:"expected_kv_cache_bytes,omitempty"`
+	state                protoimpl.MessageState `protogen:"open.v1"`
+	AgentId              string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Prompt               string                 `protobuf:"bytes,2,opt,name=prompt,proto3" json:"prompt,omitempty"`
+	MaxTokens            uint32                 `protobuf:"varint,3,opt,name=max_tokens,json=maxTokens,proto3" json:"max_tokens,omitempty"`
+	Temperature          float32                `protobuf:"fixed32,4,opt,name=temperature,proto3" json:"temperature,omitempty"`
+	ExpectedKvCacheBytes uint64                 `protobuf:"varint,5,opt,name=expected_kv_cache_bytes,json=expectedKvCacheBytes,proto3" json:"expected_kv_cache_bytes,omitempty"` // Hint for memory-intensive inference tasks.
+	Speculative          *SpeculativeDecoding   `protobuf:"bytes,6,opt,name=speculative,proto3" json:"speculative,omitempty"`                                                    // Unset decodes on the routed hardware path alone.
 	unknownFields        protoimpl.UnknownFields
 	sizeCache            protoimpl.SizeCache
 }
 
 func (x *InferenceRequest) Reset() {
 	*x = InferenceRequest{}
-	mi := &file_proto_mesh_proto_msgTypes[6]
+	mi := &file_proto_mesh_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -543,7 +743,7 @@ func (x *InferenceRequest) String() string {
 func (*InferenceRequest) ProtoMessage() {}
 
 func (x *InferenceRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_mesh_proto_msgTypes[6]
+	mi := &file_proto_mesh_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -556,7 +756,7 @@ func (x *InferenceRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use InferenceRequest.ProtoReflect.Descriptor instead.
 func (*InferenceRequest) Descriptor() ([]byte, []int) {
-	return file_proto_mesh_proto_rawDescGZIP(), []int{6}
+	return file_proto_mesh_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *InferenceRequest) GetAgentId() string {
@@ -594,21 +794,87 @@ func (x *InferenceRequest) GetExpectedKvCacheBytes() uint64 {
 	return 0
 }
 
-type InferenceResponse struct {
+func (x *InferenceRequest) GetSpeculative() *SpeculativeDecoding {
+	if x != nil {
+		return x.Speculative
+	}
+	return nil
+}
+
+// Speculative decoding: a small draft model on draft_path proposes tokens
+// and the target model on the routed hardware path verifies them.
+type SpeculativeDecoding struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Text          string                 `protobuf:"bytes,1,opt,name=text,proto3" json:"text,omitempty"`
-	TokensUsed    uint32                 `protobuf:"varint,2,opt,name=tokens_used,json=tokensUsed,proto3" json:"tokens_used,omitempty"`
-	HardwarePath  string                 `protobuf:"bytes,3,opt,name=hardware_path,json=hardwarePath,proto3" json:"hardware_path,omitempty"` // The path chosen by ScheInfer
-	LatencyMs     float32                `protobuf:"fixed32,4,opt,name=latency_ms,json=latencyMs,proto3" json:"latency_ms,omitempty"`
-	ThroughputGbs float32                `protobuf:"fixed32,5,opt,name=throughput_gbs,json=throughputGbs,proto3" json:"throughput_gbs,omitempty"`
-	Avx512Usage   bool                   `protobuf:"varint,6,opt,name=avx512_usage,json=avx512Usage,proto3" json:"avx512_usage,omitempty"`
+	DraftPath     string                 `protobuf:"bytes,1,opt,name=draft_path,json=draftPath,proto3" json:"draft_path,omitempty"`        // e.g. "CPU_AVX512"; empty picks the fastest CPU path.
+	DraftTokens   uint32                 `protobuf:"varint,2,opt,name=draft_tokens,json=draftTokens,proto3" json:"draft_tokens,omitempty"` // Tokens proposed per round; 0 uses 4.
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
+func (x *SpeculativeDecoding) Reset() {
+	*x = SpeculativeDecoding{}
+	mi := &file_proto_mesh_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SpeculativeDecoding) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SpeculativeDecoding) ProtoMessage() {}
+
+func (x *SpeculativeDecoding) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mesh_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SpeculativeDecoding.ProtoReflect.Descriptor instead.
+func (*SpeculativeDecoding) Descriptor() ([]byte, []int) {
+	return file_proto_mesh_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *SpeculativeDecoding) GetDraftPath() string {
+	if x != nil {
+		return x.DraftPath
+	}
+	return ""
+}
+
+func (x *SpeculativeDecoding) GetDraftTokens() uint32 {
+	if x != nil {
+		return x.DraftTokens
+	}
+	return 0
+}
+
+type InferenceResponse struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Text             string                 `protobuf:"bytes,1,opt,name=text,proto3" json:"text,omitempty"`
+	TokensUsed       uint32                 `protobuf:"varint,2,opt,name=tokens_used,json=tokensUsed,proto3" json:"tokens_used,omitempty"`
+	HardwarePath     string                 `protobuf:"bytes,3,opt,name=hardware_path,json=hardwarePath,proto3" json:"hardware_path,omitempty"`
+	LatencyMs        float32                `protobuf:"fixed32,4,opt,name=latency_ms,json=latencyMs,proto3" json:"latency_ms,omitempty"`
+	ThroughputGbs    float32                `protobuf:"fixed32,5,opt,name=throughput_gbs,json=throughputGbs,proto3" json:"throughput_gbs,omitempty"`
+	Avx512Usage      bool                   `protobuf:"varint,6,opt,name=avx512_usage,json=avx512Usage,proto3" json:"avx512_usage,omitempty"`
+	PromptTokens     uint32                 `protobuf:"varint,7,opt,name=prompt_tokens,json=promptTokens,proto3" json:"prompt_tokens,omitempty"` // tokens_used split as reported by the backend.
+	CompletionTokens uint32                 `protobuf:"varint,8,opt,name=completion_tokens,json=completionTokens,proto3" json:"completion_tokens,omitempty"`
+	Cached           bool                   `protobuf:"varint,9,opt,name=cached,proto3" json:"cached,omitempty"`                                         // Served from the response cache; hardware_path is "CACHE".
+	AcceptanceRate   float32                `protobuf:"fixed32,10,opt,name=acceptance_rate,json=acceptanceRate,proto3" json:"acceptance_rate,omitempty"` // Share of draft tokens the target accepted (speculative requests).
+	DraftPath        string                 `protobuf:"bytes,11,opt,name=draft_path,json=draftPath,proto3" json:"draft_path,omitempty"`                  // Where the draft model ran; empty when speculation fell back to plain decoding.
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
 func (x *InferenceResponse) Reset() {
 	*x = InferenceResponse{}
-	mi := &file_proto_mesh_proto_msgTypes[7]
+	mi := &file_proto_mesh_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -620,7 +886,7 @@ func (x *InferenceResponse) String() string {
 func (*InferenceResponse) ProtoMessage() {}
 
 func (x *InferenceResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_mesh_proto_msgTypes[7]
+	mi := &file_proto_mesh_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -633,7 +899,7 @@ func (x *InferenceResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use InferenceResponse.ProtoReflect.Descriptor instead.
 func (*InferenceResponse) Descriptor() ([]byte, []int) {
-	return file_proto_mesh_proto_rawDescGZIP(), []int{7}
+	return file_proto_mesh_proto_rawDescGZIP(), []int{9}
 }
 
 func (x *InferenceResponse) GetText() string {
@@ -678,30 +944,68 @@ func (x *InferenceResponse) GetAvx512Usage() bool {
 	return false
 }
 
-type SynthesisRequest struct {
-	state          protoimpl.MessageState `protogen:"open.v1"`
-	AgentIds       []string               `protobuf:"bytes,1,rep,name=agent_ids,json=agentIds,proto3" json:"agent_ids,omitempty"`
-	TargetGoal     string                 `protobuf:"bytes,2,opt,name=target_goal,json=targetGoal,proto3" json:"target_goal,omitempty"`
-	ActionsToMerge []*AgentAction         `protobuf:"bytes,3,rep,name=actions_to_merge,json=actionsToMerge,proto3" json:"actions_to_merge,omitempty"`
-	unknownFields  protoimpl.UnknownFields
-	sizeCache      protoimpl.SizeCache
+func (x *InferenceResponse) GetPromptTokens() uint32 {
+	if x != nil {
+		return x.PromptTokens
+	}
+	return 0
 }
 
-func (x *SynthesisRequest) Reset() {
-	*x = SynthesisRequest{}
-	mi := &file_proto_mesh_proto_msgTypes[8]
+func (x *InferenceResponse) GetCompletionTokens() uint32 {
+	if x != nil {
+		return x.CompletionTokens
+	}
+	return 0
+}
+
+func (x *InferenceResponse) GetCached() bool {
+	if x != nil {
+		return x.Cached
+	}
+	return false
+}
+
+func (x *InferenceResponse) GetAcceptanceRate() float32 {
+	if x != nil {
+		return x.AcceptanceRate
+	}
+	return 0
+}
+
+func (x *InferenceResponse) GetDraftPath() string {
+	if x != nil {
+		return x.DraftPath
+	}
+	return ""
+}
+
+// One piece of a streamed completion. The last message has done set and
+// summary filled in; its text is empty.
+type InferenceChunk struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Text            string                 `protobuf:"bytes,1,opt,name=text,proto3" json:"text,omitempty"`
+	TokensGenerated uint32                 `protobuf:"varint,2,opt,name=tokens_generated,json=tokensGenerated,proto3" json:"tokens_generated,omitempty"` // Running count, including this chunk.
+	Done            bool                   `protobuf:"varint,3,opt,name=done,proto3" json:"done,omitempty"`
+	Summary         *InferenceResponse     `protobuf:"bytes,4,opt,name=summary,proto3" json:"summary,omitempty"` // Hardware path, latency and throughput.
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *InferenceChunk) Reset() {
+	*x = InferenceChunk{}
+	mi := &file_proto_mesh_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *SynthesisRequest) String() string {
+func (x *InferenceChunk) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*SynthesisRequest) ProtoMessage() {}
+func (*InferenceChunk) ProtoMessage() {}
 
-func (x *SynthesisRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_mesh_proto_msgTypes[8]
+func (x *InferenceChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mesh_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -712,55 +1016,1396 @@ func (x *SynthesisRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use SynthesisRequest.ProtoReflect.Descriptor instead.
-func (*SynthesisRequest) Descriptor() ([]byte, []int) {
-	return file_proto_mesh_proto_rawDescGZIP(), []int{8}
+// Deprecated: Use InferenceChunk.ProtoReflect.Descriptor instead.
+func (*InferenceChunk) Descriptor() ([]byte, []int) {
+	return file_proto_mesh_proto_rawDescGZIP(), []int{10}
 }
 
-func (x *SynthesisRequest) GetAgentIds() []string {
+func (x *InferenceChunk) GetText() string {
 	if x != nil {
-		return x.AgentIds
+		return x.Text
+	}
+	return ""
+}
+
+func (x *InferenceChunk) GetTokensGenerated() uint32 {
+	if x != nil {
+		return x.TokensGenerated
+	}
+	return 0
+}
+
+func (x *InferenceChunk) GetDone() bool {
+	if x != nil {
+		return x.Done
+	}
+	return false
+}
+
+func (x *InferenceChunk) GetSummary() *InferenceResponse {
+	if x != nil {
+		return x.Summary
+	}
+	return nil
+}
+
+type SynthesisRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	AgentIds       []string               `protobuf:"bytes,1,rep,name=agent_ids,json=agentIds,proto3" json:"agent_ids,omitempty"`
+	TargetGoal     string                 `protobuf:"bytes,2,opt,name=target_goal,json=targetGoal,proto3" json:"target_goal,omitempty"`
+	ActionsToMerge []*AgentAction         `protobuf:"bytes,3,rep,name=actions_to_merge,json=actionsToMerge,proto3" json:"actions_to_merge,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *SynthesisRequest) Reset() {
+	*x = SynthesisRequest{}
+	mi := &file_proto_mesh_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SynthesisRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SynthesisRequest) ProtoMessage() {}
+
+func (x *SynthesisRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mesh_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SynthesisRequest.ProtoReflect.Descriptor instead.
+func (*SynthesisRequest) Descriptor() ([]byte, []int) {
+	return file_proto_mesh_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *SynthesisRequest) GetAgentIds() []string {
+	if x != nil {
+		return x.AgentIds
+	}
+	return nil
+}
+
+func (x *SynthesisRequest) GetTargetGoal() string {
+	if x != nil {
+		return x.TargetGoal
+	}
+	return ""
+}
+
+func (x *SynthesisRequest) GetActionsToMerge() []*AgentAction {
+	if x != nil {
+		return x.ActionsToMerge
+	}
+	return nil
+}
+
+type SynthesisResponse struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	SynthesizedState string                 `protobuf:"bytes,1,opt,name=synthesized_state,json=synthesizedState,proto3" json:"synthesized_state,omitempty"`
+	ConfidenceScore  float32                `protobuf:"fixed32,2,opt,name=confidence_score,json=confidenceScore,proto3" json:"confidence_score,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *SynthesisResponse) Reset() {
+	*x = SynthesisResponse{}
+	mi := &file_proto_mesh_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SynthesisResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SynthesisResponse) ProtoMessage() {}
+
+func (x *SynthesisResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mesh_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SynthesisResponse.ProtoReflect.Descriptor instead.
+func (*SynthesisResponse) Descriptor() ([]byte, []int) {
+	return file_proto_mesh_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *SynthesisResponse) GetSynthesizedState() string {
+	if x != nil {
+		return x.SynthesizedState
+	}
+	return ""
+}
+
+func (x *SynthesisResponse) GetConfidenceScore() float32 {
+	if x != nil {
+		return x.ConfidenceScore
+	}
+	return 0
+}
+
+type StatsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StatsRequest) Reset() {
+	*x = StatsRequest{}
+	mi := &file_proto_mesh_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StatsRequest) ProtoMessage() {}
+
+func (x *StatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mesh_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StatsRequest.ProtoReflect.Descriptor instead.
+func (*StatsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_mesh_proto_rawDescGZIP(), []int{13}
+}
+
+type MeshStats struct {
+	state              protoimpl.MessageState          `protogen:"open.v1"`
+	AgentsActive       int32                           `protobuf:"varint,1,opt,name=agents_active,json=agentsActive,proto3" json:"agents_active,omitempty"`
+	AgentLogs          map[string]*AgentMetrics        `protobuf:"bytes,2,rep,name=agent_logs,json=agentLogs,proto3" json:"agent_logs,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	ContributionMatrix map[string]*InfluenceMap        `protobuf:"bytes,3,rep,name=contribution_matrix,json=contributionMatrix,proto3" json:"contribution_matrix,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	LockDomains        map[string]*LockDomainMetrics   `protobuf:"bytes,4,rep,name=lock_domains,json=lockDomains,proto3" json:"lock_domains,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	ProviderLoad       map[string]*ProviderLoadMetrics `protobuf:"bytes,5,rep,name=provider_load,json=providerLoad,proto3" json:"provider_load,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // Keyed by placement, e.g. "GPU_CUDA:1".
+	Batching           map[string]*BatchMetrics        `protobuf:"bytes,6,rep,name=batching,proto3" json:"batching,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`                             // Keyed by hardware path.
+	ResponseCache      *ResponseCacheMetrics           `protobuf:"bytes,7,opt,name=response_cache,json=responseCache,proto3" json:"response_cache,omitempty"`                                                                        // Unset when caching is off.
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *MeshStats) Reset() {
+	*x = MeshStats{}
+	mi := &file_proto_mesh_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MeshStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MeshStats) ProtoMessage() {}
+
+func (x *MeshStats) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mesh_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MeshStats.ProtoReflect.Descriptor instead.
+func (*MeshStats) Descriptor() ([]byte, []int) {
+	return file_proto_mesh_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *MeshStats) GetAgentsActive() int32 {
+	if x != nil {
+		return x.AgentsActive
+	}
+	return 0
+}
+
+func (x *MeshStats) GetAgentLogs() map[string]*AgentMetrics {
+	if x != nil {
+		return x.AgentLogs
+	}
+	return nil
+}
+
+func (x *MeshStats) GetContributionMatrix() map[string]*InfluenceMap {
+	if x != nil {
+		return x.ContributionMatrix
+	}
+	return nil
+}
+
+func (x *MeshStats) GetLockDomains() map[string]*LockDomainMetrics {
+	if x != nil {
+		return x.LockDomains
+	}
+	return nil
+}
+
+func (x *MeshStats) GetProviderLoad() map[string]*ProviderLoadMetrics {
+	if x != nil {
+		return x.ProviderLoad
+	}
+	return nil
+}
+
+func (x *MeshStats) GetBatching() map[string]*BatchMetrics {
+	if x != nil {
+		return x.Batching
+	}
+	return nil
+}
+
+func (x *MeshStats) GetResponseCache() *ResponseCacheMetrics {
+	if x != nil {
+		return x.ResponseCache
+	}
+	return nil
+}
+
+// ResponseCacheMetrics counts GenerateResponse cache outcomes.
+type ResponseCacheMetrics struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Hits          uint64                 `protobuf:"varint,1,opt,name=hits,proto3" json:"hits,omitempty"`                                     // Exact prompt matches.
+	SemanticHits  uint64                 `protobuf:"varint,2,opt,name=semantic_hits,json=semanticHits,proto3" json:"semantic_hits,omitempty"` // Embedding-similarity matches.
+	Misses        uint64                 `protobuf:"varint,3,opt,name=misses,proto3" json:"misses,omitempty"`
+	Bypassed      uint64                 `protobuf:"varint,4,opt,name=bypassed,proto3" json:"bypassed,omitempty"` // Not cacheable, e.g. temperature above the limit.
+	Evictions     uint64                 `protobuf:"varint,5,opt,name=evictions,proto3" json:"evictions,omitempty"`
+	Expirations   uint64                 `protobuf:"varint,6,opt,name=expirations,proto3" json:"expirations,omitempty"`
+	Entries       uint32                 `protobuf:"varint,7,opt,name=entries,proto3" json:"entries,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResponseCacheMetrics) Reset() {
+	*x = ResponseCacheMetrics{}
+	mi := &file_proto_mesh_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResponseCacheMetrics) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResponseCacheMetrics) ProtoMessage() {}
+
+func (x *ResponseCacheMetrics) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mesh_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResponseCacheMetrics.ProtoReflect.Descriptor instead.
+func (*ResponseCacheMetrics) Descriptor() ([]byte, []int) {
+	return file_proto_mesh_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ResponseCacheMetrics) GetHits() uint64 {
+	if x != nil {
+		return x.Hits
+	}
+	return 0
+}
+
+func (x *ResponseCacheMetrics) GetSemanticHits() uint64 {
+	if x != nil {
+		return x.SemanticHits
+	}
+	return 0
+}
+
+func (x *ResponseCacheMetrics) GetMisses() uint64 {
+	if x != nil {
+		return x.Misses
+	}
+	return 0
+}
+
+func (x *ResponseCacheMetrics) GetBypassed() uint64 {
+	if x != nil {
+		return x.Bypassed
+	}
+	return 0
+}
+
+func (x *ResponseCacheMetrics) GetEvictions() uint64 {
+	if x != nil {
+		return x.Evictions
+	}
+	return 0
+}
+
+func (x *ResponseCacheMetrics) GetExpirations() uint64 {
+	if x != nil {
+		return x.Expirations
+	}
+	return 0
+}
+
+func (x *ResponseCacheMetrics) GetEntries() uint32 {
+	if x != nil {
+		return x.Entries
+	}
+	return 0
+}
+
+// BatchMetrics is the batch-size histogram of one hardware path.
+type BatchMetrics struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Batches       uint64                 `protobuf:"varint,1,opt,name=batches,proto3" json:"batches,omitempty"`
+	Requests      uint64                 `protobuf:"varint,2,opt,name=requests,proto3" json:"requests,omitempty"`
+	Expired       uint64                 `protobuf:"varint,3,opt,name=expired,proto3" json:"expired,omitempty"`                                                                                                    // Requests that gave up before dispatch.
+	SizeCounts    map[uint32]uint64      `protobuf:"bytes,4,rep,name=size_counts,json=sizeCounts,proto3" json:"size_counts,omitempty" protobuf_key:"varint,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"` // Batch size -> number of batches.
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchMetrics) Reset() {
+	*x = BatchMetrics{}
+	mi := &file_proto_mesh_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchMetrics) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchMetrics) ProtoMessage() {}
+
+func (x *BatchMetrics) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mesh_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchMetrics.ProtoReflect.Descriptor instead.
+func (*BatchMetrics) Descriptor() ([]byte, []int) {
+	return file_proto_mesh_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *BatchMetrics) GetBatches() uint64 {
+	if x != nil {
+		return x.Batches
+	}
+	return 0
+}
+
+func (x *BatchMetrics) GetRequests() uint64 {
+	if x != nil {
+		return x.Requests
+	}
+	return 0
+}
+
+func (x *BatchMetrics) GetExpired() uint64 {
+	if x != nil {
+		return x.Expired
+	}
+	return 0
+}
+
+func (x *BatchMetrics) GetSizeCounts() map[uint32]uint64 {
+	if x != nil {
+		return x.SizeCounts
+	}
+	return nil
+}
+
+// NodeCapability is published by every controller on mesh.capability.<node_id>.
+type NodeCapability struct {
+	state         protoimpl.MessageState          `protogen:"open.v1"`
+	NodeId        string                          `protobuf:"bytes,1,opt,name=node_id,json=nodeId,proto3" json:"node_id,omitempty"`
+	GrpcAddr      string                          `protobuf:"bytes,2,opt,name=grpc_addr,json=grpcAddr,proto3" json:"grpc_addr,omitempty"` // Where forwarded actions are sent; empty disables forwarding to this node.
+	PhysicalCores uint32                          `protobuf:"varint,3,opt,name=physical_cores,json=physicalCores,proto3" json:"physical_cores,omitempty"`
+	LogicalCpus   uint32                          `protobuf:"varint,4,opt,name=logical_cpus,json=logicalCpus,proto3" json:"logical_cpus,omitempty"`
+	L3CacheBytes  uint64                          `protobuf:"varint,5,opt,name=l3_cache_bytes,json=l3CacheBytes,proto3" json:"l3_cache_bytes,omitempty"`
+	NumaNodes     uint32                          `protobuf:"varint,6,opt,name=numa_nodes,json=numaNodes,proto3" json:"numa_nodes,omitempty"`
+	SimdTier      string                          `protobuf:"bytes,7,opt,name=simd_tier,json=simdTier,proto3" json:"simd_tier,omitempty"` // "AVX512" or "AVX2".
+	Providers     []string                        `protobuf:"bytes,8,rep,name=providers,proto3" json:"providers,omitempty"`               // Execution providers, e.g. "CPU_AVX2", "GPU_VULKAN".
+	Gpus          []*GpuDevice                    `protobuf:"bytes,9,rep,name=gpus,proto3" json:"gpus,omitempty"`
+	Load          map[string]*ProviderLoadMetrics `protobuf:"bytes,10,rep,name=load,proto3" json:"load,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // Keyed by placement.
+	PublishedAt   *timestamppb.Timestamp          `protobuf:"bytes,11,opt,name=published_at,json=publishedAt,proto3" json:"published_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NodeCapability) Reset() {
+	*x = NodeCapability{}
+	mi := &file_proto_mesh_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NodeCapability) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NodeCapability) ProtoMessage() {}
+
+func (x *NodeCapability) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mesh_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NodeCapability.ProtoReflect.Descriptor instead.
+func (*NodeCapability) Descriptor() ([]byte, []int) {
+	return file_proto_mesh_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *NodeCapability) GetNodeId() string {
+	if x != nil {
+		return x.NodeId
+	}
+	return ""
+}
+
+func (x *NodeCapability) GetGrpcAddr() string {
+	if x != nil {
+		return x.GrpcAddr
+	}
+	return ""
+}
+
+func (x *NodeCapability) GetPhysicalCores() uint32 {
+	if x != nil {
+		return x.PhysicalCores
+	}
+	return 0
+}
+
+func (x *NodeCapability) GetLogicalCpus() uint32 {
+	if x != nil {
+		return x.LogicalCpus
+	}
+	return 0
+}
+
+func (x *NodeCapability) GetL3CacheBytes() uint64 {
+	if x != nil {
+		return x.L3CacheBytes
+	}
+	return 0
+}
+
+func (x *NodeCapability) GetNumaNodes() uint32 {
+	if x != nil {
+		return x.NumaNodes
+	}
+	return 0
+}
+
+func (x *NodeCapability) GetSimdTier() string {
+	if x != nil {
+		return x.SimdTier
+	}
+	return ""
+}
+
+func (x *NodeCapability) GetProviders() []string {
+	if x != nil {
+		return x.Providers
+	}
+	return nil
+}
+
+func (x *NodeCapability) GetGpus() []*GpuDevice {
+	if x != nil {
+		return x.Gpus
+	}
+	return nil
+}
+
+func (x *NodeCapability) GetLoad() map[string]*ProviderLoadMetrics {
+	if x != nil {
+		return x.Load
+	}
+	return nil
+}
+
+func (x *NodeCapability) GetPublishedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.PublishedAt
+	}
+	return nil
+}
+
+type GpuDevice struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Index             uint32                 `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	Name              string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	VramBytes         uint64                 `protobuf:"varint,3,opt,name=vram_bytes,json=vramBytes,proto3" json:"vram_bytes,omitempty"`
+	FreeBytes         uint64                 `protobuf:"varint,4,opt,name=free_bytes,json=freeBytes,proto3" json:"free_bytes,omitempty"`
+	ComputeCapability uint32                 `protobuf:"varint,5,opt,name=compute_capability,json=computeCapability,proto3" json:"compute_capability,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *GpuDevice) Reset() {
+	*x = GpuDevice{}
+	mi := &file_proto_mesh_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GpuDevice) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GpuDevice) ProtoMessage() {}
+
+func (x *GpuDevice) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mesh_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GpuDevice.ProtoReflect.Descriptor instead.
+func (*GpuDevice) Descriptor() ([]byte, []int) {
+	return file_proto_mesh_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *GpuDevice) GetIndex() uint32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *GpuDevice) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *GpuDevice) GetVramBytes() uint64 {
+	if x != nil {
+		return x.VramBytes
+	}
+	return 0
+}
+
+func (x *GpuDevice) GetFreeBytes() uint64 {
+	if x != nil {
+		return x.FreeBytes
+	}
+	return 0
+}
+
+func (x *GpuDevice) GetComputeCapability() uint32 {
+	if x != nil {
+		return x.ComputeCapability
+	}
+	return 0
+}
+
+type ProviderLoadMetrics struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	InFlight      uint32                 `protobuf:"varint,1,opt,name=in_flight,json=inFlight,proto3" json:"in_flight,omitempty"`
+	InFlightBytes uint64                 `protobuf:"varint,2,opt,name=in_flight_bytes,json=inFlightBytes,proto3" json:"in_flight_bytes,omitempty"`
+	Acquired      uint64                 `protobuf:"varint,3,opt,name=acquired,proto3" json:"acquired,omitempty"`
+	SpilledOut    uint64                 `protobuf:"varint,4,opt,name=spilled_out,json=spilledOut,proto3" json:"spilled_out,omitempty"` // Tasks that ranked best here but went elsewhere.
+	SpilledIn     uint64                 `protobuf:"varint,5,opt,name=spilled_in,json=spilledIn,proto3" json:"spilled_in,omitempty"`    // Tasks received from a saturated placement.
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProviderLoadMetrics) Reset() {
+	*x = ProviderLoadMetrics{}
+	mi := &file_proto_mesh_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProviderLoadMetrics) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProviderLoadMetrics) ProtoMessage() {}
+
+func (x *ProviderLoadMetrics) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mesh_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProviderLoadMetrics.ProtoReflect.Descriptor instead.
+func (*ProviderLoadMetrics) Descriptor() ([]byte, []int) {
+	return file_proto_mesh_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *ProviderLoadMetrics) GetInFlight() uint32 {
+	if x != nil {
+		return x.InFlight
+	}
+	return 0
+}
+
+func (x *ProviderLoadMetrics) GetInFlightBytes() uint64 {
+	if x != nil {
+		return x.InFlightBytes
+	}
+	return 0
+}
+
+func (x *ProviderLoadMetrics) GetAcquired() uint64 {
+	if x != nil {
+		return x.Acquired
+	}
+	return 0
+}
+
+func (x *ProviderLoadMetrics) GetSpilledOut() uint64 {
+	if x != nil {
+		return x.SpilledOut
+	}
+	return 0
+}
+
+func (x *ProviderLoadMetrics) GetSpilledIn() uint64 {
+	if x != nil {
+		return x.SpilledIn
+	}
+	return 0
+}
+
+type LockDomainMetrics struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	HolderId      string                 `protobuf:"bytes,1,opt,name=holder_id,json=holderId,proto3" json:"holder_id,omitempty"`
+	Grants        uint64                 `protobuf:"varint,2,opt,name=grants,proto3" json:"grants,omitempty"`
+	Contended     uint64                 `protobuf:"varint,3,opt,name=contended,proto3" json:"contended,omitempty"` // Requests that found the domain held.
+	Timeouts      uint64                 `protobuf:"varint,4,opt,name=timeouts,proto3" json:"timeouts,omitempty"`   // Waiters that hit their deadline.
+	Reclaims      uint64                 `protobuf:"varint,5,opt,name=reclaims,proto3" json:"reclaims,omitempty"`   // Expired leases reclaimed from stalled holders.
+	QueueDepth    uint32                 `protobuf:"varint,6,opt,name=queue_depth,json=queueDepth,proto3" json:"queue_depth,omitempty"`
+	MaxQueueDepth uint32                 `protobuf:"varint,7,opt,name=max_queue_depth,json=maxQueueDepth,proto3" json:"max_queue_depth,omitempty"`
+	AvgWaitMs     float64                `protobuf:"fixed64,8,opt,name=avg_wait_ms,json=avgWaitMs,proto3" json:"avg_wait_ms,omitempty"` // Queue wait averaged over all grants.
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LockDomainMetrics) Reset() {
+	*x = LockDomainMetrics{}
+	mi := &file_proto_mesh_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LockDomainMetrics) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LockDomainMetrics) ProtoMessage() {}
+
+func (x *LockDomainMetrics) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mesh_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LockDomainMetrics.ProtoReflect.Descriptor instead.
+func (*LockDomainMetrics) Descriptor() ([]byte, []int) {
+	return file_proto_mesh_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *LockDomainMetrics) GetHolderId() string {
+	if x != nil {
+		return x.HolderId
+	}
+	return ""
+}
+
+func (x *LockDomainMetrics) GetGrants() uint64 {
+	if x != nil {
+		return x.Grants
+	}
+	return 0
+}
+
+func (x *LockDomainMetrics) GetContended() uint64 {
+	if x != nil {
+		return x.Contended
+	}
+	return 0
+}
+
+func (x *LockDomainMetrics) GetTimeouts() uint64 {
+	if x != nil {
+		return x.Timeouts
+	}
+	return 0
+}
+
+func (x *LockDomainMetrics) GetReclaims() uint64 {
+	if x != nil {
+		return x.Reclaims
+	}
+	return 0
+}
+
+func (x *LockDomainMetrics) GetQueueDepth() uint32 {
+	if x != nil {
+		return x.QueueDepth
+	}
+	return 0
+}
+
+func (x *LockDomainMetrics) GetMaxQueueDepth() uint32 {
+	if x != nil {
+		return x.MaxQueueDepth
+	}
+	return 0
+}
+
+func (x *LockDomainMetrics) GetAvgWaitMs() float64 {
+	if x != nil {
+		return x.AvgWaitMs
+	}
+	return 0
+}
+
+type AgentMetrics struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	ToolCalls        uint32                 `protobuf:"varint,1,opt,name=tool_calls,json=toolCalls,proto3" json:"tool_calls,omitempty"`
+	FailedTasks      []string               `protobuf:"bytes,2,rep,name=failed_tasks,json=failedTasks,proto3" json:"failed_tasks,omitempty"`
+	AvgLatencyMs     float32                `protobuf:"fixed32,3,opt,name=avg_latency_ms,json=avgLatencyMs,proto3" json:"avg_latency_ms,omitempty"`
+	TotalTokens      uint32                 `protobuf:"varint,4,opt,name=total_tokens,json=totalTokens,proto3" json:"total_tokens,omitempty"`
+	RejectedRequests uint32                 `protobuf:"varint,5,opt,name=rejected_requests,json=rejectedRequests,proto3" json:"rejected_requests,omitempty"` // Refused by the agent's inference budget.
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *AgentMetrics) Reset() {
+	*x = AgentMetrics{}
+	mi := &file_proto_mesh_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AgentMetrics) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AgentMetrics) ProtoMessage() {}
+
+func (x *AgentMetrics) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mesh_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AgentMetrics.ProtoReflect.Descriptor instead.
+func (*AgentMetrics) Descriptor() ([]byte, []int) {
+	return file_proto_mesh_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *AgentMetrics) GetToolCalls() uint32 {
+	if x != nil {
+		return x.ToolCalls
+	}
+	return 0
+}
+
+func (x *AgentMetrics) GetFailedTasks() []string {
+	if x != nil {
+		return x.FailedTasks
+	}
+	return nil
+}
+
+func (x *AgentMetrics) GetAvgLatencyMs() float32 {
+	if x != nil {
+		return x.AvgLatencyMs
+	}
+	return 0
+}
+
+func (x *AgentMetrics) GetTotalTokens() uint32 {
+	if x != nil {
+		return x.TotalTokens
+	}
+	return 0
+}
+
+func (x *AgentMetrics) GetRejectedRequests() uint32 {
+	if x != nil {
+		return x.RejectedRequests
+	}
+	return 0
+}
+
+type InfluenceMap struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Influence     map[string]float64     `protobuf:"bytes,1,rep,name=influence,proto3" json:"influence,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"fixed64,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InfluenceMap) Reset() {
+	*x = InfluenceMap{}
+	mi := &file_proto_mesh_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InfluenceMap) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InfluenceMap) ProtoMessage() {}
+
+func (x *InfluenceMap) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mesh_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InfluenceMap.ProtoReflect.Descriptor instead.
+func (*InfluenceMap) Descriptor() ([]byte, []int) {
+	return file_proto_mesh_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *InfluenceMap) GetInfluence() map[string]float64 {
+	if x != nil {
+		return x.Influence
+	}
+	return nil
+}
+
+type NeighborGraphRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"` // Restricts the graph to one agent; empty returns the whole mesh.
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NeighborGraphRequest) Reset() {
+	*x = NeighborGraphRequest{}
+	mi := &file_proto_mesh_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NeighborGraphRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NeighborGraphRequest) ProtoMessage() {}
+
+func (x *NeighborGraphRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mesh_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NeighborGraphRequest.ProtoReflect.Descriptor instead.
+func (*NeighborGraphRequest) Descriptor() ([]byte, []int) {
+	return file_proto_mesh_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *NeighborGraphRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+type NeighborEdge struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TargetId      string                 `protobuf:"bytes,1,opt,name=target_id,json=targetId,proto3" json:"target_id,omitempty"`
+	Score         float64                `protobuf:"fixed64,2,opt,name=score,proto3" json:"score,omitempty"` // Current UCB estimate for the edge.
+	Pulls         uint32                 `protobuf:"varint,3,opt,name=pulls,proto3" json:"pulls,omitempty"`  // Evaluation rounds observed on the edge.
+	MeanReward    float64                `protobuf:"fixed64,4,opt,name=mean_reward,json=meanReward,proto3" json:"mean_reward,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NeighborEdge) Reset() {
+	*x = NeighborEdge{}
+	mi := &file_proto_mesh_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NeighborEdge) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NeighborEdge) ProtoMessage() {}
+
+func (x *NeighborEdge) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mesh_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NeighborEdge.ProtoReflect.Descriptor instead.
+func (*NeighborEdge) Descriptor() ([]byte, []int) {
+	return file_proto_mesh_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *NeighborEdge) GetTargetId() string {
+	if x != nil {
+		return x.TargetId
+	}
+	return ""
+}
+
+func (x *NeighborEdge) GetScore() float64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *NeighborEdge) GetPulls() uint32 {
+	if x != nil {
+		return x.Pulls
+	}
+	return 0
+}
+
+func (x *NeighborEdge) GetMeanReward() float64 {
+	if x != nil {
+		return x.MeanReward
+	}
+	return 0
+}
+
+type NeighborList struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Edges         []*NeighborEdge        `protobuf:"bytes,1,rep,name=edges,proto3" json:"edges,omitempty"`
+	UtilityScore  float64                `protobuf:"fixed64,2,opt,name=utility_score,json=utilityScore,proto3" json:"utility_score,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NeighborList) Reset() {
+	*x = NeighborList{}
+	mi := &file_proto_mesh_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NeighborList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NeighborList) ProtoMessage() {}
+
+func (x *NeighborList) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mesh_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NeighborList.ProtoReflect.Descriptor instead.
+func (*NeighborList) Descriptor() ([]byte, []int) {
+	return file_proto_mesh_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *NeighborList) GetEdges() []*NeighborEdge {
+	if x != nil {
+		return x.Edges
+	}
+	return nil
+}
+
+func (x *NeighborList) GetUtilityScore() float64 {
+	if x != nil {
+		return x.UtilityScore
+	}
+	return 0
+}
+
+type NeighborGraph struct {
+	state         protoimpl.MessageState   `protogen:"open.v1"`
+	Adjacency     map[string]*NeighborList `protobuf:"bytes,1,rep,name=adjacency,proto3" json:"adjacency,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NeighborGraph) Reset() {
+	*x = NeighborGraph{}
+	mi := &file_proto_mesh_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NeighborGraph) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NeighborGraph) ProtoMessage() {}
+
+func (x *NeighborGraph) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mesh_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NeighborGraph.ProtoReflect.Descriptor instead.
+func (*NeighborGraph) Descriptor() ([]byte, []int) {
+	return file_proto_mesh_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *NeighborGraph) GetAdjacency() map[string]*NeighborList {
+	if x != nil {
+		return x.Adjacency
+	}
+	return nil
+}
+
+// Field numbers 2 and 3 are skipped so a HandshakeRequest from older clients
+// still decodes as a request for the latest state.
+type ReconstitutionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Version       uint64                 `protobuf:"varint,4,opt,name=version,proto3" json:"version,omitempty"`      // Exact version; 0 means latest unless as_of is set.
+	AsOf          *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=as_of,json=asOf,proto3" json:"as_of,omitempty"` // Newest snapshot saved at or before this time.
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReconstitutionRequest) Reset() {
+	*x = ReconstitutionRequest{}
+	mi := &file_proto_mesh_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReconstitutionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReconstitutionRequest) ProtoMessage() {}
+
+func (x *ReconstitutionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mesh_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReconstitutionRequest.ProtoReflect.Descriptor instead.
+func (*ReconstitutionRequest) Descriptor() ([]byte, []int) {
+	return file_proto_mesh_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *ReconstitutionRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *ReconstitutionRequest) GetVersion() uint64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *ReconstitutionRequest) GetAsOf() *timestamppb.Timestamp {
+	if x != nil {
+		return x.AsOf
+	}
+	return nil
+}
+
+type StateSnapshot struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Version       uint64                 `protobuf:"varint,1,opt,name=version,proto3" json:"version,omitempty"`
+	SavedAt       *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=saved_at,json=savedAt,proto3" json:"saved_at,omitempty"`
+	Action        *AgentAction           `protobuf:"bytes,3,opt,name=action,proto3" json:"action,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StateSnapshot) Reset() {
+	*x = StateSnapshot{}
+	mi := &file_proto_mesh_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StateSnapshot) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StateSnapshot) ProtoMessage() {}
+
+func (x *StateSnapshot) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mesh_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StateSnapshot.ProtoReflect.Descriptor instead.
+func (*StateSnapshot) Descriptor() ([]byte, []int) {
+	return file_proto_mesh_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *StateSnapshot) GetVersion() uint64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *StateSnapshot) GetSavedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.SavedAt
+	}
+	return nil
+}
+
+func (x *StateSnapshot) GetAction() *AgentAction {
+	if x != nil {
+		return x.Action
+	}
+	return nil
+}
+
+type StateHistory struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Snapshots     []*StateSnapshot       `protobuf:"bytes,1,rep,name=snapshots,proto3" json:"snapshots,omitempty"` // Oldest first.
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StateHistory) Reset() {
+	*x = StateHistory{}
+	mi := &file_proto_mesh_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StateHistory) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StateHistory) ProtoMessage() {}
+
+func (x *StateHistory) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mesh_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StateHistory.ProtoReflect.Descriptor instead.
+func (*StateHistory) Descriptor() ([]byte, []int) {
+	return file_proto_mesh_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *StateHistory) GetSnapshots() []*StateSnapshot {
+	if x != nil {
+		return x.Snapshots
+	}
+	return nil
+}
+
+type StateDiffRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	FromVersion   uint64                 `protobuf:"varint,2,opt,name=from_version,json=fromVersion,proto3" json:"from_version,omitempty"`
+	ToVersion     uint64                 `protobuf:"varint,3,opt,name=to_version,json=toVersion,proto3" json:"to_version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StateDiffRequest) Reset() {
+	*x = StateDiffRequest{}
+	mi := &file_proto_mesh_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StateDiffRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StateDiffRequest) ProtoMessage() {}
+
+func (x *StateDiffRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mesh_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return nil
+	return mi.MessageOf(x)
 }
 
-func (x *SynthesisRequest) GetTargetGoal() string {
+// Deprecated: Use StateDiffRequest.ProtoReflect.Descriptor instead.
+func (*StateDiffRequest) Descriptor() ([]byte, []int) {
+	return file_proto_mesh_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *StateDiffRequest) GetAgentId() string {
 	if x != nil {
-		return x.TargetGoal
+		return x.AgentId
 	}
 	return ""
 }
 
-func (x *SynthesisRequest) GetActionsToMerge() []*AgentAction {
+func (x *StateDiffRequest) GetFromVersion() uint64 {
 	if x != nil {
-		return x.ActionsToMerge
+		return x.FromVersion
 	}
-	return nil
+	return 0
 }
 
-type SynthesisResponse struct {
-	state            protoimpl.MessageState `protogen:"open.v1"`
-	SynthesizedState string                 `protobuf:"bytes,1,opt,name=synthesized_state,json=synthesizedState,proto3" json:"synthesized_state,omitempty"`
-	ConfidenceScore  float32                `protobuf:"fixed32,2,opt,name=confidence_score,json=confidenceScore,proto3" json:"confidence_score,omitempty"`
-	unknownFields    protoimpl.UnknownFields
-	sizeCache        protoimpl.SizeCache
+func (x *StateDiffRequest) GetToVersion() uint64 {
+	if x != nil {
+		return x.ToVersion
+	}
+	return 0
 }
 
-func (x *SynthesisResponse) Reset() {
-	*x = SynthesisResponse{}
-	mi := &file_proto_mesh_proto_msgTypes[9]
+type FieldChange struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Field         string                 `protobuf:"bytes,1,opt,name=field,proto3" json:"field,omitempty"`
+	Before        string                 `protobuf:"bytes,2,opt,name=before,proto3" json:"before,omitempty"` // JSON rendering; empty when unset.
+	After         string                 `protobuf:"bytes,3,opt,name=after,proto3" json:"after,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FieldChange) Reset() {
+	*x = FieldChange{}
+	mi := &file_proto_mesh_proto_msgTypes[31]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *SynthesisResponse) String() string {
+func (x *FieldChange) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*SynthesisResponse) ProtoMessage() {}
+func (*FieldChange) ProtoMessage() {}
 
-func (x *SynthesisResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_mesh_proto_msgTypes[9]
+func (x *FieldChange) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mesh_proto_msgTypes[31]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -771,46 +2416,54 @@ func (x *SynthesisResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use SynthesisResponse.ProtoReflect.Descriptor instead.
-func (*SynthesisResponse) Descriptor() ([]byte, []int) {
-	return file_proto_mesh_proto_rawDescGZIP(), []int{9}
+// Deprecated: Use FieldChange.ProtoReflect.Descriptor instead.
+func (*FieldChange) Descriptor() ([]byte, []int) {
+	return file_proto_mesh_proto_rawDescGZIP(), []int{31}
 }
 
-func (x *SynthesisResponse) GetSynthesizedState() string {
+func (x *FieldChange) GetField() string {
 	if x != nil {
-		return x.SynthesizedState
+		return x.Field
 	}
 	return ""
 }
 
-func (x *SynthesisResponse) GetConfidenceScore() float32 {
+func (x *FieldChange) GetBefore() string {
 	if x != nil {
-		return x.ConfidenceScore
+		return x.Before
 	}
-	return 0
+	return ""
 }
 
-type StatsRequest struct {
+func (x *FieldChange) GetAfter() string {
+	if x != nil {
+		return x.After
+	}
+	return ""
+}
+
+type StateDiff struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
+	Changes       []*FieldChange         `protobuf:"bytes,1,rep,name=changes,proto3" json:"changes,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *StatsRequest) Reset() {
-	*x = StatsRequest{}
-	mi := &file_proto_mesh_proto_msgTypes[10]
+func (x *StateDiff) Reset() {
+	*x = StateDiff{}
+	mi := &file_proto_mesh_proto_msgTypes[32]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *StatsRequest) String() string {
+func (x *StateDiff) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*StatsRequest) ProtoMessage() {}
+func (*StateDiff) ProtoMessage() {}
 
-func (x *StatsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_mesh_proto_msgTypes[10]
+func (x *StateDiff) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mesh_proto_msgTypes[32]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -821,35 +2474,45 @@ func (x *StatsRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use StatsRequest.ProtoReflect.Descriptor instead.
-func (*StatsRequest) Descriptor() ([]byte, []int) {
-	return file_proto_mesh_proto_rawDescGZIP(), []int{10}
+// Deprecated: Use StateDiff.ProtoReflect.Descriptor instead.
+func (*StateDiff) Descriptor() ([]byte, []int) {
+	return file_proto_mesh_proto_rawDescGZIP(), []int{32}
 }
 
-type MeshStats struct {
-	state              protoimpl.MessageState   `protogen:"open.v1"`
-	AgentsActive       int32                    `protobuf:"varint,1,opt,name=agents_active,json=agentsActive,proto3" json:"agents_active,omitempty"`
-	AgentLogs          map[string]*AgentMetrics `protobuf:"bytes,2,rep,name=agent_logs,json=agentLogs,proto3" json:"agent_logs,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
-	ContributionMatrix map[string]*InfluenceMap `protobuf:"bytes,3,rep,name=contribution_matrix,json=contributionMatrix,proto3" json:"contribution_matrix,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
-	unknownFields      protoimpl.UnknownFields
-	sizeCache          protoimpl.SizeCache
+func (x *StateDiff) GetChanges() []*FieldChange {
+	if x != nil {
+		return x.Changes
+	}
+	return nil
 }
 
-func (x *MeshStats) Reset() {
-	*x = MeshStats{}
-	mi := &file_proto_mesh_proto_msgTypes[11]
+type LockRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	FencingToken  uint64                 `protobuf:"varint,2,opt,name=fencing_token,json=fencingToken,proto3" json:"fencing_token,omitempty"` // Required for RenewLock and ReleaseLock.
+	LeaseMs       uint32                 `protobuf:"varint,3,opt,name=lease_ms,json=leaseMs,proto3" json:"lease_ms,omitempty"`                // Requested lease; 0 uses the controller default.
+	Domain        string                 `protobuf:"bytes,4,opt,name=domain,proto3" json:"domain,omitempty"`                                  // Goal or resource to lock; empty uses the global strategic domain.
+	Priority      int32                  `protobuf:"varint,5,opt,name=priority,proto3" json:"priority,omitempty"`                             // Higher priority waiters are served first.
+	Wait          bool                   `protobuf:"varint,6,opt,name=wait,proto3" json:"wait,omitempty"`                                     // Queue and block until granted or the RPC deadline passes.
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LockRequest) Reset() {
+	*x = LockRequest{}
+	mi := &file_proto_mesh_proto_msgTypes[33]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *MeshStats) String() string {
+func (x *LockRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*MeshStats) ProtoMessage() {}
+func (*LockRequest) ProtoMessage() {}
 
-func (x *MeshStats) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_mesh_proto_msgTypes[11]
+func (x *LockRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mesh_proto_msgTypes[33]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -860,57 +2523,79 @@ func (x *MeshStats) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use MeshStats.ProtoReflect.Descriptor instead.
-func (*MeshStats) Descriptor() ([]byte, []int) {
-	return file_proto_mesh_proto_rawDescGZIP(), []int{11}
+// Deprecated: Use LockRequest.ProtoReflect.Descriptor instead.
+func (*LockRequest) Descriptor() ([]byte, []int) {
+	return file_proto_mesh_proto_rawDescGZIP(), []int{33}
 }
 
-func (x *MeshStats) GetAgentsActive() int32 {
+func (x *LockRequest) GetAgentId() string {
 	if x != nil {
-		return x.AgentsActive
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *LockRequest) GetFencingToken() uint64 {
+	if x != nil {
+		return x.FencingToken
 	}
 	return 0
 }
 
-func (x *MeshStats) GetAgentLogs() map[string]*AgentMetrics {
+func (x *LockRequest) GetLeaseMs() uint32 {
 	if x != nil {
-		return x.AgentLogs
+		return x.LeaseMs
 	}
-	return nil
+	return 0
 }
 
-func (x *MeshStats) GetContributionMatrix() map[string]*InfluenceMap {
+func (x *LockRequest) GetDomain() string {
 	if x != nil {
-		return x.ContributionMatrix
+		return x.Domain
 	}
-	return nil
+	return ""
 }
 
-type AgentMetrics struct {
+func (x *LockRequest) GetPriority() int32 {
+	if x != nil {
+		return x.Priority
+	}
+	return 0
+}
+
+func (x *LockRequest) GetWait() bool {
+	if x != nil {
+		return x.Wait
+	}
+	return false
+}
+
+type LockResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	ToolCalls     uint32                 `protobuf:"varint,1,opt,name=tool_calls,json=toolCalls,proto3" json:"tool_calls,omitempty"`
-	FailedTasks   []string               `protobuf:"bytes,2,rep,name=failed_tasks,json=failedTasks,proto3" json:"failed_tasks,omitempty"`
-	AvgLatencyMs  float32                `protobuf:"fixed32,3,opt,name=avg_latency_ms,json=avgLatencyMs,proto3" json:"avg_latency_ms,omitempty"`
-	TotalTokens   uint32                 `protobuf:"varint,4,opt,name=total_tokens,json=totalTokens,proto3" json:"total_tokens,omitempty"`
+	Granted       bool                   `protobuf:"varint,1,opt,name=granted,proto3" json:"granted,omitempty"`
+	FencingToken  uint64                 `protobuf:"varint,2,opt,name=fencing_token,json=fencingToken,proto3" json:"fencing_token,omitempty"`
+	ExpiresAt     *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	HolderId      string                 `protobuf:"bytes,4,opt,name=holder_id,json=holderId,proto3" json:"holder_id,omitempty"` // Current holder, also set when the request is denied.
+	Domain        string                 `protobuf:"bytes,5,opt,name=domain,proto3" json:"domain,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *AgentMetrics) Reset() {
-	*x = AgentMetrics{}
-	mi := &file_proto_mesh_proto_msgTypes[12]
+func (x *LockResponse) Reset() {
+	*x = LockResponse{}
+	mi := &file_proto_mesh_proto_msgTypes[34]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *AgentMetrics) String() string {
+func (x *LockResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*AgentMetrics) ProtoMessage() {}
+func (*LockResponse) ProtoMessage() {}
 
-func (x *AgentMetrics) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_mesh_proto_msgTypes[12]
+func (x *LockResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mesh_proto_msgTypes[34]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -921,61 +2606,90 @@ func (x *AgentMetrics) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use AgentMetrics.ProtoReflect.Descriptor instead.
-func (*AgentMetrics) Descriptor() ([]byte, []int) {
-	return file_proto_mesh_proto_rawDescGZIP(), []int{12}
+// Deprecated: Use LockResponse.ProtoReflect.Descriptor instead.
+func (*LockResponse) Descriptor() ([]byte, []int) {
+	return file_proto_mesh_proto_rawDescGZIP(), []int{34}
 }
 
-func (x *AgentMetrics) GetToolCalls() uint32 {
+func (x *LockResponse) GetGranted() bool {
 	if x != nil {
-		return x.ToolCalls
+		return x.Granted
+	}
+	return false
+}
+
+func (x *LockResponse) GetFencingToken() uint64 {
+	if x != nil {
+		return x.FencingToken
 	}
 	return 0
 }
 
-func (x *AgentMetrics) GetFailedTasks() []string {
+func (x *LockResponse) GetExpiresAt() *timestamppb.Timestamp {
 	if x != nil {
-		return x.FailedTasks
+		return x.ExpiresAt
 	}
 	return nil
 }
 
-func (x *AgentMetrics) GetAvgLatencyMs() float32 {
+func (x *LockResponse) GetHolderId() string {
 	if x != nil {
-		return x.AvgLatencyMs
+		return x.HolderId
 	}
-	return 0
+	return ""
 }
 
-func (x *AgentMetrics) GetTotalTokens() uint32 {
+func (x *LockResponse) GetDomain() string {
 	if x != nil {
-		return x.TotalTokens
+		return x.Domain
 	}
-	return 0
+	return ""
 }
 
-type InfluenceMap struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Influence     map[string]float64     `protobuf:"bytes,1,rep,name=influence,proto3" json:"influence,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"fixed64,2,opt,name=value"`
+// One message on the MESH_STATE stream. Sequences are per agent and shared
+// by deltas and snapshots; a delta applies on top of every earlier message
+// back to its base snapshot. Payloads are quantized, compressed, and then
+// split across chunk_count messages that share a sequence when over the
+// chunk size.
+type KVCacheEnvelope struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	AgentId        string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Sequence       uint64                 `protobuf:"varint,2,opt,name=sequence,proto3" json:"sequence,omitempty"`                                     // Starts at 1 and increases by one per message.
+	BaseSnapshotId uint64                 `protobuf:"varint,3,opt,name=base_snapshot_id,json=baseSnapshotId,proto3" json:"base_snapshot_id,omitempty"` // Sequence of the latest snapshot; 0 before the first.
+	Checksum       uint32                 `protobuf:"varint,4,opt,name=checksum,proto3" json:"checksum,omitempty"`                                     // CRC-32C (Castagnoli) of the whole uncompressed payload.
+	Snapshot       bool                   `protobuf:"varint,5,opt,name=snapshot,proto3" json:"snapshot,omitempty"`                                     // payload is the full state, not a delta.
+	Payload        []byte                 `protobuf:"bytes,6,opt,name=payload,proto3" json:"payload,omitempty"`                                        // This chunk of the compressed payload.
+	PublishedAt    *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=published_at,json=publishedAt,proto3" json:"published_at,omitempty"`
+	Compression    KVCompression          `protobuf:"varint,8,opt,name=compression,proto3,enum=mesh.KVCompression" json:"compression,omitempty"`
+	ChunkIndex     uint32                 `protobuf:"varint,9,opt,name=chunk_index,json=chunkIndex,proto3" json:"chunk_index,omitempty"`
+	ChunkCount     uint32                 `protobuf:"varint,10,opt,name=chunk_count,json=chunkCount,proto3" json:"chunk_count,omitempty"`    // 0 or 1 when the payload fits in one message.
+	PayloadSize    uint64                 `protobuf:"varint,11,opt,name=payload_size,json=payloadSize,proto3" json:"payload_size,omitempty"` // Uncompressed size of the whole payload.
+	// When set, the payload (and so checksum and payload_size) holds blocks
+	// that dequantize to value_count float32s.
+	Quantization KVQuantization `protobuf:"varint,12,opt,name=quantization,proto3,enum=mesh.KVQuantization" json:"quantization,omitempty"`
+	ValueCount   uint64         `protobuf:"varint,13,opt,name=value_count,json=valueCount,proto3" json:"value_count,omitempty"`
+	// Set by the publisher for the agent's current history. A new epoch means
+	// the history was purged and sequences started again at 1.
+	Epoch         string `protobuf:"bytes,14,opt,name=epoch,proto3" json:"epoch,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *InfluenceMap) Reset() {
-	*x = InfluenceMap{}
-	mi := &file_proto_mesh_proto_msgTypes[13]
+func (x *KVCacheEnvelope) Reset() {
+	*x = KVCacheEnvelope{}
+	mi := &file_proto_mesh_proto_msgTypes[35]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *InfluenceMap) String() string {
+func (x *KVCacheEnvelope) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*InfluenceMap) ProtoMessage() {}
+func (*KVCacheEnvelope) ProtoMessage() {}
 
-func (x *InfluenceMap) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_mesh_proto_msgTypes[13]
+func (x *KVCacheEnvelope) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mesh_proto_msgTypes[35]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -986,18 +2700,109 @@ func (x *InfluenceMap) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use InfluenceMap.ProtoReflect.Descriptor instead.
-func (*InfluenceMap) Descriptor() ([]byte, []int) {
-	return file_proto_mesh_proto_rawDescGZIP(), []int{13}
+// Deprecated: Use KVCacheEnvelope.ProtoReflect.Descriptor instead.
+func (*KVCacheEnvelope) Descriptor() ([]byte, []int) {
+	return file_proto_mesh_proto_rawDescGZIP(), []int{35}
 }
 
-func (x *InfluenceMap) GetInfluence() map[string]float64 {
+func (x *KVCacheEnvelope) GetAgentId() string {
 	if x != nil {
-		return x.Influence
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *KVCacheEnvelope) GetSequence() uint64 {
+	if x != nil {
+		return x.Sequence
+	}
+	return 0
+}
+
+func (x *KVCacheEnvelope) GetBaseSnapshotId() uint64 {
+	if x != nil {
+		return x.BaseSnapshotId
+	}
+	return 0
+}
+
+func (x *KVCacheEnvelope) GetChecksum() uint32 {
+	if x != nil {
+		return x.Checksum
+	}
+	return 0
+}
+
+func (x *KVCacheEnvelope) GetSnapshot() bool {
+	if x != nil {
+		return x.Snapshot
+	}
+	return false
+}
+
+func (x *KVCacheEnvelope) GetPayload() []byte {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *KVCacheEnvelope) GetPublishedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.PublishedAt
 	}
 	return nil
 }
 
+func (x *KVCacheEnvelope) GetCompression() KVCompression {
+	if x != nil {
+		return x.Compression
+	}
+	return KVCompression_COMPRESSION_NONE
+}
+
+func (x *KVCacheEnvelope) GetChunkIndex() uint32 {
+	if x != nil {
+		return x.ChunkIndex
+	}
+	return 0
+}
+
+func (x *KVCacheEnvelope) GetChunkCount() uint32 {
+	if x != nil {
+		return x.ChunkCount
+	}
+	return 0
+}
+
+func (x *KVCacheEnvelope) GetPayloadSize() uint64 {
+	if x != nil {
+		return x.PayloadSize
+	}
+	return 0
+}
+
+func (x *KVCacheEnvelope) GetQuantization() KVQuantization {
+	if x != nil {
+		return x.Quantization
+	}
+	return KVQuantization_QUANT_NONE
+}
+
+func (x *KVCacheEnvelope) GetValueCount() uint64 {
+	if x != nil {
+		return x.ValueCount
+	}
+	return 0
+}
+
+func (x *KVCacheEnvelope) GetEpoch() string {
+	if x != nil {
+		return x.Epoch
+	}
+	return ""
+}
+
 type SearchRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
@@ -1009,7 +2814,7 @@ type SearchRequest struct {
 
 func (x *SearchRequest) Reset() {
 	*x = SearchRequest{}
-	mi := &file_proto_mesh_proto_msgTypes[14]
+	mi := &file_proto_mesh_proto_msgTypes[36]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1021,7 +2826,7 @@ func (x *SearchRequest) String() string {
 func (*SearchRequest) ProtoMessage() {}
 
 func (x *SearchRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_mesh_proto_msgTypes[14]
+	mi := &file_proto_mesh_proto_msgTypes[36]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1034,7 +2839,7 @@ func (x *SearchRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SearchRequest.ProtoReflect.Descriptor instead.
 func (*SearchRequest) Descriptor() ([]byte, []int) {
-	return file_proto_mesh_proto_rawDescGZIP(), []int{14}
+	return file_proto_mesh_proto_rawDescGZIP(), []int{36}
 }
 
 func (x *SearchRequest) GetAgentId() string {
@@ -1061,14 +2866,14 @@ func (x *SearchRequest) GetMaxResults() int32 {
 type SearchResponse struct {
 	state            protoimpl.MessageState `protogen:"open.v1"`
 	Results          []*SearchResult        `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
-	ReasoningContext string                 `protobuf:"bytes,2,opt,name=reasoning_context,json=reasoningContext,proto3" json:"reasoning_context,omitempty"` // Contextual relevance from research
+	ReasoningContext string                 `protobuf:"bytes,2,opt,name=reasoning_context,json=reasoningContext,proto3" json:"reasoning_context,omitempty"`
 	unknownFields    protoimpl.UnknownFields
 	sizeCache        protoimpl.SizeCache
 }
 
 func (x *SearchResponse) Reset() {
 	*x = SearchResponse{}
-	mi := &file_proto_mesh_proto_msgTypes[15]
+	mi := &file_proto_mesh_proto_msgTypes[37]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1080,7 +2885,7 @@ func (x *SearchResponse) String() string {
 func (*SearchResponse) ProtoMessage() {}
 
 func (x *SearchResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_mesh_proto_msgTypes[15]
+	mi := &file_proto_mesh_proto_msgTypes[37]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1093,7 +2898,7 @@ func (x *SearchResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SearchResponse.ProtoReflect.Descriptor instead.
 func (*SearchResponse) Descriptor() ([]byte, []int) {
-	return file_proto_mesh_proto_rawDescGZIP(), []int{15}
+	return file_proto_mesh_proto_rawDescGZIP(), []int{37}
 }
 
 func (x *SearchResponse) GetResults() []*SearchResult {
@@ -1112,7 +2917,7 @@ func (x *SearchResponse) GetReasoningContext() string {
 
 type SearchResult struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Source        string                 `protobuf:"bytes,3,opt,name=source,proto3" json:"source,omitempty"` // e.g. "Research Paper", "Student-A Log"
+	Source        string                 `protobuf:"bytes,3,opt,name=source,proto3" json:"source,omitempty"` // The origin of the search result (e.g., a paper title).
 	Content       string                 `protobuf:"bytes,4,opt,name=content,proto3" json:"content,omitempty"`
 	Score         float32                `protobuf:"fixed32,5,opt,name=score,proto3" json:"score,omitempty"`
 	unknownFields protoimpl.UnknownFields
@@ -1121,7 +2926,7 @@ type SearchResult struct {
 
 func (x *SearchResult) Reset() {
 	*x = SearchResult{}
-	mi := &file_proto_mesh_proto_msgTypes[16]
+	mi := &file_proto_mesh_proto_msgTypes[38]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1133,7 +2938,7 @@ func (x *SearchResult) String() string {
 func (*SearchResult) ProtoMessage() {}
 
 func (x *SearchResult) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_mesh_proto_msgTypes[16]
+	mi := &file_proto_mesh_proto_msgTypes[38]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1146,7 +2951,7 @@ func (x *SearchResult) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SearchResult.ProtoReflect.Descriptor instead.
 func (*SearchResult) Descriptor() ([]byte, []int) {
-	return file_proto_mesh_proto_rawDescGZIP(), []int{16}
+	return file_proto_mesh_proto_rawDescGZIP(), []int{38}
 }
 
 func (x *SearchResult) GetSource() string {
@@ -1184,18 +2989,22 @@ const file_proto_mesh_proto_rawDesc = "" +
 	"\x10HandshakeRequest\x12\x19\n" +
 	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\"\n" +
 	"\fcapabilities\x18\x02 \x03(\tR\fcapabilities\x122\n" +
-	"\finitial_role\x18\x03 \x01(\x0e2\x0f.mesh.AgentRoleR\vinitialRole\"\xaf\x01\n" +
+	"\finitial_role\x18\x03 \x01(\x0e2\x0f.mesh.AgentRoleR\vinitialRole\"\xf1\x01\n" +
 	"\x11HandshakeResponse\x12\x1d\n" +
 	"\n" +
 	"session_id\x18\x01 \x01(\tR\tsessionId\x12\x1a\n" +
 	"\bapproved\x18\x02 \x01(\bR\bapproved\x12#\n" +
 	"\rerror_message\x18\x03 \x01(\tR\ferrorMessage\x12:\n" +
-	"\x0fresource_limits\x18\x04 \x01(\v2\x11.mesh.OSResourcesR\x0eresourceLimits\"\xca\x01\n" +
+	"\x0fresource_limits\x18\x04 \x01(\v2\x11.mesh.OSResourcesR\x0eresourceLimits\x12@\n" +
+	"\x10inference_budget\x18\x05 \x01(\v2\x15.mesh.InferenceBudgetR\x0finferenceBudget\"d\n" +
+	"\x0fInferenceBudget\x12*\n" +
+	"\x11tokens_per_minute\x18\x01 \x01(\rR\x0ftokensPerMinute\x12%\n" +
+	"\x0emax_concurrent\x18\x02 \x01(\rR\rmaxConcurrent\"\xca\x01\n" +
 	"\tHeartbeat\x12\x19\n" +
 	"\bagent_id\x18\x01 \x01(\tR\aagentId\x128\n" +
 	"\ttimestamp\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\x124\n" +
 	"\fcurrent_load\x18\x03 \x01(\v2\x11.mesh.OSResourcesR\vcurrentLoad\x122\n" +
-	"\fcurrent_role\x18\x04 \x01(\x0e2\x0f.mesh.AgentRoleR\vcurrentRole\"\xaa\x02\n" +
+	"\fcurrent_role\x18\x04 \x01(\x0e2\x0f.mesh.AgentRoleR\vcurrentRole\"\x97\x03\n" +
 	"\vAgentAction\x12\x19\n" +
 	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x1f\n" +
 	"\vaction_type\x18\x02 \x01(\tR\n" +
@@ -1205,21 +3014,34 @@ const file_proto_mesh_proto_rawDesc = "" +
 	"\x0freasoning_chain\x18\x05 \x01(\tR\x0ereasoningChain\x12\x1f\n" +
 	"\vtask_intent\x18\x06 \x01(\tR\n" +
 	"taskIntent\x12&\n" +
-	"\x0fdata_size_bytes\x18\a \x01(\x04R\rdataSizeBytes\"\x83\x02\n" +
+	"\x0fdata_size_bytes\x18\a \x01(\x04R\rdataSizeBytes\x12#\n" +
+	"\rfencing_token\x18\b \x01(\x04R\ffencingToken\x12\x1f\n" +
+	"\vlock_domain\x18\t \x01(\tR\n" +
+	"lockDomain\x12%\n" +
+	"\x0eforwarded_from\x18\n" +
+	" \x01(\tR\rforwardedFrom\"\xf6\x02\n" +
 	"\x0eActionResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12/\n" +
 	"\x06result\x18\x02 \x01(\v2\x17.google.protobuf.StructR\x06result\x12\x14\n" +
 	"\x05error\x18\x03 \x01(\tR\x05error\x12/\n" +
 	"\x13promotion_suggested\x18\x04 \x01(\bR\x12promotionSuggested\x12)\n" +
 	"\x10routing_provider\x18\x05 \x01(\tR\x0froutingProvider\x124\n" +
-	"\rrequired_role\x18\x06 \x01(\x0e2\x0f.mesh.AgentRoleR\frequiredRole\"\xbd\x01\n" +
+	"\rrequired_role\x18\x06 \x01(\x0e2\x0f.mesh.AgentRoleR\frequiredRole\x120\n" +
+	"\x14estimated_latency_ms\x18\a \x01(\x02R\x12estimatedLatencyMs\x12!\n" +
+	"\frouting_node\x18\b \x01(\tR\vroutingNode\x12\x1c\n" +
+	"\tforwarded\x18\t \x01(\bR\tforwarded\"\xfa\x01\n" +
 	"\x10InferenceRequest\x12\x19\n" +
 	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x16\n" +
 	"\x06prompt\x18\x02 \x01(\tR\x06prompt\x12\x1d\n" +
 	"\n" +
 	"max_tokens\x18\x03 \x01(\rR\tmaxTokens\x12 \n" +
 	"\vtemperature\x18\x04 \x01(\x02R\vtemperature\x125\n" +
-	"\x17expected_kv_cache_bytes\x18\x05 \x01(\x04R\x14expectedKvCacheBytes\"\xd6\x01\n" +
+	"\x17expected_kv_cache_bytes\x18\x05 \x01(\x04R\x14expectedKvCacheBytes\x12;\n" +
+	"\vspeculative\x18\x06 \x01(\v2\x19.mesh.SpeculativeDecodingR\vspeculative\"W\n" +
+	"\x13SpeculativeDecoding\x12\x1d\n" +
+	"\n" +
+	"draft_path\x18\x01 \x01(\tR\tdraftPath\x12!\n" +
+	"\fdraft_tokens\x18\x02 \x01(\rR\vdraftTokens\"\x88\x03\n" +
 	"\x11InferenceResponse\x12\x12\n" +
 	"\x04text\x18\x01 \x01(\tR\x04text\x12\x1f\n" +
 	"\vtokens_used\x18\x02 \x01(\rR\n" +
@@ -1228,7 +3050,19 @@ const file_proto_mesh_proto_rawDesc = "" +
 	"\n" +
 	"latency_ms\x18\x04 \x01(\x02R\tlatencyMs\x12%\n" +
 	"\x0ethroughput_gbs\x18\x05 \x01(\x02R\rthroughputGbs\x12!\n" +
-	"\favx512_usage\x18\x06 \x01(\bR\vavx512Usage\"\x8d\x01\n" +
+	"\favx512_usage\x18\x06 \x01(\bR\vavx512Usage\x12#\n" +
+	"\rprompt_tokens\x18\a \x01(\rR\fpromptTokens\x12+\n" +
+	"\x11completion_tokens\x18\b \x01(\rR\x10completionTokens\x12\x16\n" +
+	"\x06cached\x18\t \x01(\bR\x06cached\x12'\n" +
+	"\x0facceptance_rate\x18\n" +
+	" \x01(\x02R\x0eacceptanceRate\x12\x1d\n" +
+	"\n" +
+	"draft_path\x18\v \x01(\tR\tdraftPath\"\x96\x01\n" +
+	"\x0eInferenceChunk\x12\x12\n" +
+	"\x04text\x18\x01 \x01(\tR\x04text\x12)\n" +
+	"\x10tokens_generated\x18\x02 \x01(\rR\x0ftokensGenerated\x12\x12\n" +
+	"\x04done\x18\x03 \x01(\bR\x04done\x121\n" +
+	"\asummary\x18\x04 \x01(\v2\x17.mesh.InferenceResponseR\asummary\"\x8d\x01\n" +
 	"\x10SynthesisRequest\x12\x1b\n" +
 	"\tagent_ids\x18\x01 \x03(\tR\bagentIds\x12\x1f\n" +
 	"\vtarget_goal\x18\x02 \x01(\tR\n" +
@@ -1237,29 +3071,173 @@ const file_proto_mesh_proto_rawDesc = "" +
 	"\x11SynthesisResponse\x12+\n" +
 	"\x11synthesized_state\x18\x01 \x01(\tR\x10synthesizedState\x12)\n" +
 	"\x10confidence_score\x18\x02 \x01(\x02R\x0fconfidenceScore\"\x0e\n" +
-	"\fStatsRequest\"\xf6\x02\n" +
+	"\fStatsRequest\"\x87\a\n" +
 	"\tMeshStats\x12#\n" +
 	"\ragents_active\x18\x01 \x01(\x05R\fagentsActive\x12=\n" +
 	"\n" +
 	"agent_logs\x18\x02 \x03(\v2\x1e.mesh.MeshStats.AgentLogsEntryR\tagentLogs\x12X\n" +
-	"\x13contribution_matrix\x18\x03 \x03(\v2'.mesh.MeshStats.ContributionMatrixEntryR\x12contributionMatrix\x1aP\n" +
+	"\x13contribution_matrix\x18\x03 \x03(\v2'.mesh.MeshStats.ContributionMatrixEntryR\x12contributionMatrix\x12C\n" +
+	"\flock_domains\x18\x04 \x03(\v2 .mesh.MeshStats.LockDomainsEntryR\vlockDomains\x12F\n" +
+	"\rprovider_load\x18\x05 \x03(\v2!.mesh.MeshStats.ProviderLoadEntryR\fproviderLoad\x129\n" +
+	"\bbatching\x18\x06 \x03(\v2\x1d.mesh.MeshStats.BatchingEntryR\bbatching\x12A\n" +
+	"\x0eresponse_cache\x18\a \x01(\v2\x1a.mesh.ResponseCacheMetricsR\rresponseCache\x1aP\n" +
 	"\x0eAgentLogsEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12(\n" +
 	"\x05value\x18\x02 \x01(\v2\x12.mesh.AgentMetricsR\x05value:\x028\x01\x1aY\n" +
 	"\x17ContributionMatrixEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12(\n" +
-	"\x05value\x18\x02 \x01(\v2\x12.mesh.InfluenceMapR\x05value:\x028\x01\"\x99\x01\n" +
+	"\x05value\x18\x02 \x01(\v2\x12.mesh.InfluenceMapR\x05value:\x028\x01\x1aW\n" +
+	"\x10LockDomainsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12-\n" +
+	"\x05value\x18\x02 \x01(\v2\x17.mesh.LockDomainMetricsR\x05value:\x028\x01\x1aZ\n" +
+	"\x11ProviderLoadEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12/\n" +
+	"\x05value\x18\x02 \x01(\v2\x19.mesh.ProviderLoadMetricsR\x05value:\x028\x01\x1aO\n" +
+	"\rBatchingEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12(\n" +
+	"\x05value\x18\x02 \x01(\v2\x12.mesh.BatchMetricsR\x05value:\x028\x01\"\xdd\x01\n" +
+	"\x14ResponseCacheMetrics\x12\x12\n" +
+	"\x04hits\x18\x01 \x01(\x04R\x04hits\x12#\n" +
+	"\rsemantic_hits\x18\x02 \x01(\x04R\fsemanticHits\x12\x16\n" +
+	"\x06misses\x18\x03 \x01(\x04R\x06misses\x12\x1a\n" +
+	"\bbypassed\x18\x04 \x01(\x04R\bbypassed\x12\x1c\n" +
+	"\tevictions\x18\x05 \x01(\x04R\tevictions\x12 \n" +
+	"\vexpirations\x18\x06 \x01(\x04R\vexpirations\x12\x18\n" +
+	"\aentries\x18\a \x01(\rR\aentries\"\xe2\x01\n" +
+	"\fBatchMetrics\x12\x18\n" +
+	"\abatches\x18\x01 \x01(\x04R\abatches\x12\x1a\n" +
+	"\brequests\x18\x02 \x01(\x04R\brequests\x12\x18\n" +
+	"\aexpired\x18\x03 \x01(\x04R\aexpired\x12C\n" +
+	"\vsize_counts\x18\x04 \x03(\v2\".mesh.BatchMetrics.SizeCountsEntryR\n" +
+	"sizeCounts\x1a=\n" +
+	"\x0fSizeCountsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\rR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x04R\x05value:\x028\x01\"\xfc\x03\n" +
+	"\x0eNodeCapability\x12\x17\n" +
+	"\anode_id\x18\x01 \x01(\tR\x06nodeId\x12\x1b\n" +
+	"\tgrpc_addr\x18\x02 \x01(\tR\bgrpcAddr\x12%\n" +
+	"\x0ephysical_cores\x18\x03 \x01(\rR\rphysicalCores\x12!\n" +
+	"\flogical_cpus\x18\x04 \x01(\rR\vlogicalCpus\x12$\n" +
+	"\x0el3_cache_bytes\x18\x05 \x01(\x04R\fl3CacheBytes\x12\x1d\n" +
+	"\n" +
+	"numa_nodes\x18\x06 \x01(\rR\tnumaNodes\x12\x1b\n" +
+	"\tsimd_tier\x18\a \x01(\tR\bsimdTier\x12\x1c\n" +
+	"\tproviders\x18\b \x03(\tR\tproviders\x12#\n" +
+	"\x04gpus\x18\t \x03(\v2\x0f.mesh.GpuDeviceR\x04gpus\x122\n" +
+	"\x04load\x18\n" +
+	" \x03(\v2\x1e.mesh.NodeCapability.LoadEntryR\x04load\x12=\n" +
+	"\fpublished_at\x18\v \x01(\v2\x1a.google.protobuf.TimestampR\vpublishedAt\x1aR\n" +
+	"\tLoadEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12/\n" +
+	"\x05value\x18\x02 \x01(\v2\x19.mesh.ProviderLoadMetricsR\x05value:\x028\x01\"\xa2\x01\n" +
+	"\tGpuDevice\x12\x14\n" +
+	"\x05index\x18\x01 \x01(\rR\x05index\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x1d\n" +
+	"\n" +
+	"vram_bytes\x18\x03 \x01(\x04R\tvramBytes\x12\x1d\n" +
+	"\n" +
+	"free_bytes\x18\x04 \x01(\x04R\tfreeBytes\x12-\n" +
+	"\x12compute_capability\x18\x05 \x01(\rR\x11computeCapability\"\xb6\x01\n" +
+	"\x13ProviderLoadMetrics\x12\x1b\n" +
+	"\tin_flight\x18\x01 \x01(\rR\binFlight\x12&\n" +
+	"\x0fin_flight_bytes\x18\x02 \x01(\x04R\rinFlightBytes\x12\x1a\n" +
+	"\bacquired\x18\x03 \x01(\x04R\bacquired\x12\x1f\n" +
+	"\vspilled_out\x18\x04 \x01(\x04R\n" +
+	"spilledOut\x12\x1d\n" +
+	"\n" +
+	"spilled_in\x18\x05 \x01(\x04R\tspilledIn\"\x87\x02\n" +
+	"\x11LockDomainMetrics\x12\x1b\n" +
+	"\tholder_id\x18\x01 \x01(\tR\bholderId\x12\x16\n" +
+	"\x06grants\x18\x02 \x01(\x04R\x06grants\x12\x1c\n" +
+	"\tcontended\x18\x03 \x01(\x04R\tcontended\x12\x1a\n" +
+	"\btimeouts\x18\x04 \x01(\x04R\btimeouts\x12\x1a\n" +
+	"\breclaims\x18\x05 \x01(\x04R\breclaims\x12\x1f\n" +
+	"\vqueue_depth\x18\x06 \x01(\rR\n" +
+	"queueDepth\x12&\n" +
+	"\x0fmax_queue_depth\x18\a \x01(\rR\rmaxQueueDepth\x12\x1e\n" +
+	"\vavg_wait_ms\x18\b \x01(\x01R\tavgWaitMs\"\xc6\x01\n" +
 	"\fAgentMetrics\x12\x1d\n" +
 	"\n" +
 	"tool_calls\x18\x01 \x01(\rR\ttoolCalls\x12!\n" +
 	"\ffailed_tasks\x18\x02 \x03(\tR\vfailedTasks\x12$\n" +
 	"\x0eavg_latency_ms\x18\x03 \x01(\x02R\favgLatencyMs\x12!\n" +
-	"\ftotal_tokens\x18\x04 \x01(\rR\vtotalTokens\"\x8d\x01\n" +
+	"\ftotal_tokens\x18\x04 \x01(\rR\vtotalTokens\x12+\n" +
+	"\x11rejected_requests\x18\x05 \x01(\rR\x10rejectedRequests\"\x8d\x01\n" +
 	"\fInfluenceMap\x12?\n" +
 	"\tinfluence\x18\x01 \x03(\v2!.mesh.InfluenceMap.InfluenceEntryR\tinfluence\x1a<\n" +
 	"\x0eInfluenceEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\x01R\x05value:\x028\x01\"a\n" +
+	"\x05value\x18\x02 \x01(\x01R\x05value:\x028\x01\"1\n" +
+	"\x14NeighborGraphRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\"x\n" +
+	"\fNeighborEdge\x12\x1b\n" +
+	"\ttarget_id\x18\x01 \x01(\tR\btargetId\x12\x14\n" +
+	"\x05score\x18\x02 \x01(\x01R\x05score\x12\x14\n" +
+	"\x05pulls\x18\x03 \x01(\rR\x05pulls\x12\x1f\n" +
+	"\vmean_reward\x18\x04 \x01(\x01R\n" +
+	"meanReward\"]\n" +
+	"\fNeighborList\x12(\n" +
+	"\x05edges\x18\x01 \x03(\v2\x12.mesh.NeighborEdgeR\x05edges\x12#\n" +
+	"\rutility_score\x18\x02 \x01(\x01R\futilityScore\"\xa3\x01\n" +
+	"\rNeighborGraph\x12@\n" +
+	"\tadjacency\x18\x01 \x03(\v2\".mesh.NeighborGraph.AdjacencyEntryR\tadjacency\x1aP\n" +
+	"\x0eAdjacencyEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12(\n" +
+	"\x05value\x18\x02 \x01(\v2\x12.mesh.NeighborListR\x05value:\x028\x01\"\x89\x01\n" +
+	"\x15ReconstitutionRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x18\n" +
+	"\aversion\x18\x04 \x01(\x04R\aversion\x12/\n" +
+	"\x05as_of\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\x04asOfJ\x04\b\x02\x10\x03J\x04\b\x03\x10\x04\"\x8b\x01\n" +
+	"\rStateSnapshot\x12\x18\n" +
+	"\aversion\x18\x01 \x01(\x04R\aversion\x125\n" +
+	"\bsaved_at\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\asavedAt\x12)\n" +
+	"\x06action\x18\x03 \x01(\v2\x11.mesh.AgentActionR\x06action\"A\n" +
+	"\fStateHistory\x121\n" +
+	"\tsnapshots\x18\x01 \x03(\v2\x13.mesh.StateSnapshotR\tsnapshots\"o\n" +
+	"\x10StateDiffRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12!\n" +
+	"\ffrom_version\x18\x02 \x01(\x04R\vfromVersion\x12\x1d\n" +
+	"\n" +
+	"to_version\x18\x03 \x01(\x04R\ttoVersion\"Q\n" +
+	"\vFieldChange\x12\x14\n" +
+	"\x05field\x18\x01 \x01(\tR\x05field\x12\x16\n" +
+	"\x06before\x18\x02 \x01(\tR\x06before\x12\x14\n" +
+	"\x05after\x18\x03 \x01(\tR\x05after\"8\n" +
+	"\tStateDiff\x12+\n" +
+	"\achanges\x18\x01 \x03(\v2\x11.mesh.FieldChangeR\achanges\"\xb0\x01\n" +
+	"\vLockRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12#\n" +
+	"\rfencing_token\x18\x02 \x01(\x04R\ffencingToken\x12\x19\n" +
+	"\blease_ms\x18\x03 \x01(\rR\aleaseMs\x12\x16\n" +
+	"\x06domain\x18\x04 \x01(\tR\x06domain\x12\x1a\n" +
+	"\bpriority\x18\x05 \x01(\x05R\bpriority\x12\x12\n" +
+	"\x04wait\x18\x06 \x01(\bR\x04wait\"\xbd\x01\n" +
+	"\fLockResponse\x12\x18\n" +
+	"\agranted\x18\x01 \x01(\bR\agranted\x12#\n" +
+	"\rfencing_token\x18\x02 \x01(\x04R\ffencingToken\x129\n" +
+	"\n" +
+	"expires_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\x12\x1b\n" +
+	"\tholder_id\x18\x04 \x01(\tR\bholderId\x12\x16\n" +
+	"\x06domain\x18\x05 \x01(\tR\x06domain\"\x90\x04\n" +
+	"\x0fKVCacheEnvelope\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x1a\n" +
+	"\bsequence\x18\x02 \x01(\x04R\bsequence\x12(\n" +
+	"\x10base_snapshot_id\x18\x03 \x01(\x04R\x0ebaseSnapshotId\x12\x1a\n" +
+	"\bchecksum\x18\x04 \x01(\rR\bchecksum\x12\x1a\n" +
+	"\bsnapshot\x18\x05 \x01(\bR\bsnapshot\x12\x18\n" +
+	"\apayload\x18\x06 \x01(\fR\apayload\x12=\n" +
+	"\fpublished_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\vpublishedAt\x125\n" +
+	"\vcompression\x18\b \x01(\x0e2\x13.mesh.KVCompressionR\vcompression\x12\x1f\n" +
+	"\vchunk_index\x18\t \x01(\rR\n" +
+	"chunkIndex\x12\x1f\n" +
+	"\vchunk_count\x18\n" +
+	" \x01(\rR\n" +
+	"chunkCount\x12!\n" +
+	"\fpayload_size\x18\v \x01(\x04R\vpayloadSize\x128\n" +
+	"\fquantization\x18\f \x01(\x0e2\x14.mesh.KVQuantizationR\fquantization\x12\x1f\n" +
+	"\vvalue_count\x18\r \x01(\x04R\n" +
+	"valueCount\x12\x14\n" +
+	"\x05epoch\x18\x0e \x01(\tR\x05epoch\"a\n" +
 	"\rSearchRequest\x12\x19\n" +
 	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x14\n" +
 	"\x05query\x18\x02 \x01(\tR\x05query\x12\x1f\n" +
@@ -1274,15 +3252,34 @@ const file_proto_mesh_proto_rawDesc = "" +
 	"\x05score\x18\x05 \x01(\x02R\x05score*+\n" +
 	"\tAgentRole\x12\x0f\n" +
 	"\vOPERATIONAL\x10\x00\x12\r\n" +
-	"\tSTRATEGIC\x10\x012\xd6\x03\n" +
+	"\tSTRATEGIC\x10\x01*O\n" +
+	"\rKVCompression\x12\x14\n" +
+	"\x10COMPRESSION_NONE\x10\x00\x12\x14\n" +
+	"\x10COMPRESSION_ZSTD\x10\x01\x12\x12\n" +
+	"\x0eCOMPRESSION_S2\x10\x02*@\n" +
+	"\x0eKVQuantization\x12\x0e\n" +
+	"\n" +
+	"QUANT_NONE\x10\x00\x12\x0e\n" +
+	"\n" +
+	"QUANT_Q8_0\x10\x01\x12\x0e\n" +
+	"\n" +
+	"QUANT_Q2_K\x10\x022\xfd\x06\n" +
 	"\rStrategicMesh\x12@\n" +
 	"\rRegisterAgent\x12\x16.mesh.HandshakeRequest\x1a\x17.mesh.HandshakeResponse\x12A\n" +
 	"\x16ExecuteStrategicAction\x12\x11.mesh.AgentAction\x1a\x14.mesh.ActionResponse\x12;\n" +
-	"\x0eSemanticSearch\x12\x13.mesh.SearchRequest\x1a\x14.mesh.SearchResponse\x12C\n" +
-	"\x16GetStateReconstitution\x12\x16.mesh.HandshakeRequest\x1a\x11.mesh.AgentAction\x12D\n" +
+	"\x0eSemanticSearch\x12\x13.mesh.SearchRequest\x1a\x14.mesh.SearchResponse\x12H\n" +
+	"\x16GetStateReconstitution\x12\x1b.mesh.ReconstitutionRequest\x1a\x11.mesh.AgentAction\x12B\n" +
+	"\x0fGetStateHistory\x12\x1b.mesh.ReconstitutionRequest\x1a\x12.mesh.StateHistory\x125\n" +
+	"\n" +
+	"DiffStates\x12\x16.mesh.StateDiffRequest\x1a\x0f.mesh.StateDiff\x12D\n" +
 	"\x11SynthesizeOutputs\x12\x16.mesh.SynthesisRequest\x1a\x17.mesh.SynthesisResponse\x12C\n" +
-	"\x10GenerateResponse\x12\x16.mesh.InferenceRequest\x1a\x17.mesh.InferenceResponse\x123\n" +
-	"\fGetMeshStats\x12\x12.mesh.StatsRequest\x1a\x0f.mesh.MeshStatsB.Z,github.com/groovy-byte/agent-mesh-core/protob\x06proto3"
+	"\x10GenerateResponse\x12\x16.mesh.InferenceRequest\x1a\x17.mesh.InferenceResponse\x12@\n" +
+	"\x0eGenerateStream\x12\x16.mesh.InferenceRequest\x1a\x14.mesh.InferenceChunk0\x01\x123\n" +
+	"\fGetMeshStats\x12\x12.mesh.StatsRequest\x1a\x0f.mesh.MeshStats\x12C\n" +
+	"\x10GetNeighborGraph\x12\x1a.mesh.NeighborGraphRequest\x1a\x13.mesh.NeighborGraph\x124\n" +
+	"\vAcquireLock\x12\x11.mesh.LockRequest\x1a\x12.mesh.LockResponse\x122\n" +
+	"\tRenewLock\x12\x11.mesh.LockRequest\x1a\x12.mesh.LockResponse\x124\n" +
+	"\vReleaseLock\x12\x11.mesh.LockRequest\x1a\x12.mesh.LockResponseB.Z,github.com/groovy-byte/agent-mesh-core/protob\x06proto3"
 
 var (
 	file_proto_mesh_proto_rawDescOnce sync.Once
@@ -1296,69 +3293,140 @@ func file_proto_mesh_proto_rawDescGZIP() []byte {
 	return file_proto_mesh_proto_rawDescData
 }
 
-var file_proto_mesh_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_proto_mesh_proto_msgTypes = make([]protoimpl.MessageInfo, 20)
+var file_proto_mesh_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
+var file_proto_mesh_proto_msgTypes = make([]protoimpl.MessageInfo, 48)
 var file_proto_mesh_proto_goTypes = []any{
 	(AgentRole)(0),                // 0: mesh.AgentRole
-	(*OSResources)(nil),           // 1: mesh.OSResources
-	(*HandshakeRequest)(nil),      // 2: mesh.HandshakeRequest
-	(*HandshakeResponse)(nil),     // 3: mesh.HandshakeResponse
-	(*Heartbeat)(nil),             // 4: mesh.Heartbeat
-	(*AgentAction)(nil),           // 5: mesh.AgentAction
-	(*ActionResponse)(nil),        // 6: mesh.ActionResponse
-	(*InferenceRequest)(nil),      // 7: mesh.InferenceRequest
-	(*InferenceResponse)(nil),     // 8: mesh.InferenceResponse
-	(*SynthesisRequest)(nil),      // 9: mesh.SynthesisRequest
-	(*SynthesisResponse)(nil),     // 10: mesh.SynthesisResponse
-	(*StatsRequest)(nil),          // 11: mesh.StatsRequest
-	(*MeshStats)(nil),             // 12: mesh.MeshStats
-	(*AgentMetrics)(nil),          // 13: mesh.AgentMetrics
-	(*InfluenceMap)(nil),          // 14: mesh.InfluenceMap
-	(*SearchRequest)(nil),         // 15: mesh.SearchRequest
-	(*SearchResponse)(nil),        // 16: mesh.SearchResponse
-	(*SearchResult)(nil),          // 17: mesh.SearchResult
-	nil,                           // 18: mesh.MeshStats.AgentLogsEntry
-	nil,                           // 19: mesh.MeshStats.ContributionMatrixEntry
-	nil,                           // 20: mesh.InfluenceMap.InfluenceEntry
-	(*timestamppb.Timestamp)(nil), // 21: google.protobuf.Timestamp
-	(*structpb.Struct)(nil),       // 22: google.protobuf.Struct
+	(KVCompression)(0),            // 1: mesh.KVCompression
+	(KVQuantization)(0),           // 2: mesh.KVQuantization
+	(*OSResources)(nil),           // 3: mesh.OSResources
+	(*HandshakeRequest)(nil),      // 4: mesh.HandshakeRequest
+	(*HandshakeResponse)(nil),     // 5: mesh.HandshakeResponse
+	(*InferenceBudget)(nil),       // 6: mesh.InferenceBudget
+	(*Heartbeat)(nil),             // 7: mesh.Heartbeat
+	(*AgentAction)(nil),           // 8: mesh.AgentAction
+	(*ActionResponse)(nil),        // 9: mesh.ActionResponse
+	(*InferenceRequest)(nil),      // 10: mesh.InferenceRequest
+	(*SpeculativeDecoding)(nil),   // 11: mesh.SpeculativeDecoding
+	(*InferenceResponse)(nil),     // 12: mesh.InferenceResponse
+	(*InferenceChunk)(nil),        // 13: mesh.InferenceChunk
+	(*SynthesisRequest)(nil),      // 14: mesh.SynthesisRequest
+	(*SynthesisResponse)(nil),     // 15: mesh.SynthesisResponse
+	(*StatsRequest)(nil),          // 16: mesh.StatsRequest
+	(*MeshStats)(nil),             // 17: mesh.MeshStats
+	(*ResponseCacheMetrics)(nil),  // 18: mesh.ResponseCacheMetrics
+	(*BatchMetrics)(nil),          // 19: mesh.BatchMetrics
+	(*NodeCapability)(nil),        // 20: mesh.NodeCapability
+	(*GpuDevice)(nil),             // 21: mesh.GpuDevice
+	(*ProviderLoadMetrics)(nil),   // 22: mesh.ProviderLoadMetrics
+	(*LockDomainMetrics)(nil),     // 23: mesh.LockDomainMetrics
+	(*AgentMetrics)(nil),          // 24: mesh.AgentMetrics
+	(*InfluenceMap)(nil),          // 25: mesh.InfluenceMap
+	(*NeighborGraphRequest)(nil),  // 26: mesh.NeighborGraphRequest
+	(*NeighborEdge)(nil),          // 27: mesh.NeighborEdge
+	(*NeighborList)(nil),          // 28: mesh.NeighborList
+	(*NeighborGraph)(nil),         // 29: mesh.NeighborGraph
+	(*ReconstitutionRequest)(nil), // 30: mesh.ReconstitutionRequest
+	(*StateSnapshot)(nil),         // 31: mesh.StateSnapshot
+	(*StateHistory)(nil),          // 32: mesh.StateHistory
+	(*StateDiffRequest)(nil),      // 33: mesh.StateDiffRequest
+	(*FieldChange)(nil),           // 34: mesh.FieldChange
+	(*StateDiff)(nil),             // 35: mesh.StateDiff
+	(*LockRequest)(nil),           // 36: mesh.LockRequest
+	(*LockResponse)(nil),          // 37: mesh.LockResponse
+	(*KVCacheEnvelope)(nil),       // 38: mesh.KVCacheEnvelope
+	(*SearchRequest)(nil),         // 39: mesh.SearchRequest
+	(*SearchResponse)(nil),        // 40: mesh.SearchResponse
+	(*SearchResult)(nil),          // 41: mesh.SearchResult
+	nil,                           // 42: mesh.MeshStats.AgentLogsEntry
+	nil,                           // 43: mesh.MeshStats.ContributionMatrixEntry
+	nil,                           // 44: mesh.MeshStats.LockDomainsEntry
+	nil,                           // 45: mesh.MeshStats.ProviderLoadEntry
+	nil,                           // 46: mesh.MeshStats.BatchingEntry
+	nil,                           // 47: mesh.BatchMetrics.SizeCountsEntry
+	nil,                           // 48: mesh.NodeCapability.LoadEntry
+	nil,                           // 49: mesh.InfluenceMap.InfluenceEntry
+	nil,                           // 50: mesh.NeighborGraph.AdjacencyEntry
+	(*timestamppb.Timestamp)(nil), // 51: google.protobuf.Timestamp
+	(*structpb.Struct)(nil),       // 52: google.protobuf.Struct
 }
 var file_proto_mesh_proto_depIdxs = []int32{
 	0,  // 0: mesh.HandshakeRequest.initial_role:type_name -> mesh.AgentRole
-	1,  // 1: mesh.HandshakeResponse.resource_limits:type_name -> mesh.OSResources
-	21, // 2: mesh.Heartbeat.timestamp:type_name -> google.protobuf.Timestamp
-	1,  // 3: mesh.Heartbeat.current_load:type_name -> mesh.OSResources
-	0,  // 4: mesh.Heartbeat.current_role:type_name -> mesh.AgentRole
-	1,  // 5: mesh.AgentAction.resource_impact:type_name -> mesh.OSResources
-	22, // 6: mesh.AgentAction.payload:type_name -> google.protobuf.Struct
-	22, // 7: mesh.ActionResponse.result:type_name -> google.protobuf.Struct
-	0,  // 8: mesh.ActionResponse.required_role:type_name -> mesh.AgentRole
-	5,  // 9: mesh.SynthesisRequest.actions_to_merge:type_name -> mesh.AgentAction
-	18, // 10: mesh.MeshStats.agent_logs:type_name -> mesh.MeshStats.AgentLogsEntry
-	19, // 11: mesh.MeshStats.contribution_matrix:type_name -> mesh.MeshStats.ContributionMatrixEntry
-	20, // 12: mesh.InfluenceMap.influence:type_name -> mesh.InfluenceMap.InfluenceEntry
-	17, // 13: mesh.SearchResponse.results:type_name -> mesh.SearchResult
-	13, // 14: mesh.MeshStats.AgentLogsEntry.value:type_name -> mesh.AgentMetrics
-	14, // 15: mesh.MeshStats.ContributionMatrixEntry.value:type_name -> mesh.InfluenceMap
-	2,  // 16: mesh.StrategicMesh.RegisterAgent:input_type -> mesh.HandshakeRequest
-	5,  // 17: mesh.StrategicMesh.ExecuteStrategicAction:input_type -> mesh.AgentAction
-	15, // 18: mesh.StrategicMesh.SemanticSearch:input_type -> mesh.SearchRequest
-	2,  // 19: mesh.StrategicMesh.GetStateReconstitution:input_type -> mesh.HandshakeRequest
-	9,  // 20: mesh.StrategicMesh.SynthesizeOutputs:input_type -> mesh.SynthesisRequest
-	7,  // 21: mesh.StrategicMesh.GenerateResponse:input_type -> mesh.InferenceRequest
-	11, // 22: mesh.StrategicMesh.GetMeshStats:input_type -> mesh.StatsRequest
-	3,  // 23: mesh.StrategicMesh.RegisterAgent:output_type -> mesh.HandshakeResponse
-	6,  // 24: mesh.StrategicMesh.ExecuteStrategicAction:output_type -> mesh.ActionResponse
-	16, // 25: mesh.StrategicMesh.SemanticSearch:output_type -> mesh.SearchResponse
-	5,  // 26: mesh.StrategicMesh.GetStateReconstitution:output_type -> mesh.AgentAction
-	10, // 27: mesh.StrategicMesh.SynthesizeOutputs:output_type -> mesh.SynthesisResponse
-	8,  // 28: mesh.StrategicMesh.GenerateResponse:output_type -> mesh.InferenceResponse
-	12, // 29: mesh.StrategicMesh.GetMeshStats:output_type -> mesh.MeshStats
-	23, // [23:30] is the sub-list for method output_type
-	16, // [16:23] is the sub-list for method input_type
-	16, // [16:16] is the sub-list for extension type_name
-	16, // [16:16] is the sub-list for extension extendee
-	0,  // [0:16] is the sub-list for field type_name
+	3,  // 1: mesh.HandshakeResponse.resource_limits:type_name -> mesh.OSResources
+	6,  // 2: mesh.HandshakeResponse.inference_budget:type_name -> mesh.InferenceBudget
+	51, // 3: mesh.Heartbeat.timestamp:type_name -> google.protobuf.Timestamp
+	3,  // 4: mesh.Heartbeat.current_load:type_name -> mesh.OSResources
+	0,  // 5: mesh.Heartbeat.current_role:type_name -> mesh.AgentRole
+	3,  // 6: mesh.AgentAction.resource_impact:type_name -> mesh.OSResources
+	52, // 7: mesh.AgentAction.payload:type_name -> google.protobuf.Struct
+	52, // 8: mesh.ActionResponse.result:type_name -> google.protobuf.Struct
+	0,  // 9: mesh.ActionResponse.required_role:type_name -> mesh.AgentRole
+	11, // 10: mesh.InferenceRequest.speculative:type_name -> mesh.SpeculativeDecoding
+	12, // 11: mesh.InferenceChunk.summary:type_name -> mesh.InferenceResponse
+	8,  // 12: mesh.SynthesisRequest.actions_to_merge:type_name -> mesh.AgentAction
+	42, // 13: mesh.MeshStats.agent_logs:type_name -> mesh.MeshStats.AgentLogsEntry
+	43, // 14: mesh.MeshStats.contribution_matrix:type_name -> mesh.MeshStats.ContributionMatrixEntry
+	44, // 15: mesh.MeshStats.lock_domains:type_name -> mesh.MeshStats.LockDomainsEntry
+	45, // 16: mesh.MeshStats.provider_load:type_name -> mesh.MeshStats.ProviderLoadEntry
+	46, // 17: mesh.MeshStats.batching:type_name -> mesh.MeshStats.BatchingEntry
+	18, // 18: mesh.MeshStats.response_cache:type_name -> mesh.ResponseCacheMetrics
+	47, // 19: mesh.BatchMetrics.size_counts:type_name -> mesh.BatchMetrics.SizeCountsEntry
+	21, // 20: mesh.NodeCapability.gpus:type_name -> mesh.GpuDevice
+	48, // 21: mesh.NodeCapability.load:type_name -> mesh.NodeCapability.LoadEntry
+	51, // 22: mesh.NodeCapability.published_at:type_name -> google.protobuf.Timestamp
+	49, // 23: mesh.InfluenceMap.influence:type_name -> mesh.InfluenceMap.InfluenceEntry
+	27, // 24: mesh.NeighborList.edges:type_name -> mesh.NeighborEdge
+	50, // 25: mesh.NeighborGraph.adjacency:type_name -> mesh.NeighborGraph.AdjacencyEntry
+	51, // 26: mesh.ReconstitutionRequest.as_of:type_name -> google.protobuf.Timestamp
+	51, // 27: mesh.StateSnapshot.saved_at:type_name -> google.protobuf.Timestamp
+	8,  // 28: mesh.StateSnapshot.action:type_name -> mesh.AgentAction
+	31, // 29: mesh.StateHistory.snapshots:type_name -> mesh.StateSnapshot
+	34, // 30: mesh.StateDiff.changes:type_name -> mesh.FieldChange
+	51, // 31: mesh.LockResponse.expires_at:type_name -> google.protobuf.Timestamp
+	51, // 32: mesh.KVCacheEnvelope.published_at:type_name -> google.protobuf.Timestamp
+	1,  // 33: mesh.KVCacheEnvelope.compression:type_name -> mesh.KVCompression
+	2,  // 34: mesh.KVCacheEnvelope.quantization:type_name -> mesh.KVQuantization
+	41, // 35: mesh.SearchResponse.results:type_name -> mesh.SearchResult
+	24, // 36: mesh.MeshStats.AgentLogsEntry.value:type_name -> mesh.AgentMetrics
+	25, // 37: mesh.MeshStats.ContributionMatrixEntry.value:type_name -> mesh.InfluenceMap
+	23, // 38: mesh.MeshStats.LockDomainsEntry.value:type_name -> mesh.LockDomainMetrics
+	22, // 39: mesh.MeshStats.ProviderLoadEntry.value:type_name -> mesh.ProviderLoadMetrics
+	19, // 40: mesh.MeshStats.BatchingEntry.value:type_name -> mesh.BatchMetrics
+	22, // 41: mesh.NodeCapability.LoadEntry.value:type_name -> mesh.ProviderLoadMetrics
+	28, // 42: mesh.NeighborGraph.AdjacencyEntry.value:type_name -> mesh.NeighborList
+	4,  // 43: mesh.StrategicMesh.RegisterAgent:input_type -> mesh.HandshakeRequest
+	8,  // 44: mesh.StrategicMesh.ExecuteStrategicAction:input_type -> mesh.AgentAction
+	39, // 45: mesh.StrategicMesh.SemanticSearch:input_type -> mesh.SearchRequest
+	30, // 46: mesh.StrategicMesh.GetStateReconstitution:input_type -> mesh.ReconstitutionRequest
+	30, // 47: mesh.StrategicMesh.GetStateHistory:input_type -> mesh.ReconstitutionRequest
+	33, // 48: mesh.StrategicMesh.DiffStates:input_type -> mesh.StateDiffRequest
+	14, // 49: mesh.StrategicMesh.SynthesizeOutputs:input_type -> mesh.SynthesisRequest
+	10, // 50: mesh.StrategicMesh.GenerateResponse:input_type -> mesh.InferenceRequest
+	10, // 51: mesh.StrategicMesh.GenerateStream:input_type -> mesh.InferenceRequest
+	16, // 52: mesh.StrategicMesh.GetMeshStats:input_type -> mesh.StatsRequest
+	26, // 53: mesh.StrategicMesh.GetNeighborGraph:input_type -> mesh.NeighborGraphRequest
+	36, // 54: mesh.StrategicMesh.AcquireLock:input_type -> mesh.LockRequest
+	36, // 55: mesh.StrategicMesh.RenewLock:input_type -> mesh.LockRequest
+	36, // 56: mesh.StrategicMesh.ReleaseLock:input_type -> mesh.LockRequest
+	5,  // 57: mesh.StrategicMesh.RegisterAgent:output_type -> mesh.HandshakeResponse
+	9,  // 58: mesh.StrategicMesh.ExecuteStrategicAction:output_type -> mesh.ActionResponse
+	40, // 59: mesh.StrategicMesh.SemanticSearch:output_type -> mesh.SearchResponse
+	8,  // 60: mesh.StrategicMesh.GetStateReconstitution:output_type -> mesh.AgentAction
+	32, // 61: mesh.StrategicMesh.GetStateHistory:output_type -> mesh.StateHistory
+	35, // 62: mesh.StrategicMesh.DiffStates:output_type -> mesh.StateDiff
+	15, // 63: mesh.StrategicMesh.SynthesizeOutputs:output_type -> mesh.SynthesisResponse
+	12, // 64: mesh.StrategicMesh.GenerateResponse:output_type -> mesh.InferenceResponse
+	13, // 65: mesh.StrategicMesh.GenerateStream:output_type -> mesh.InferenceChunk
+	17, // 66: mesh.StrategicMesh.GetMeshStats:output_type -> mesh.MeshStats
+	29, // 67: mesh.StrategicMesh.GetNeighborGraph:output_type -> mesh.NeighborGraph
+	37, // 68: mesh.StrategicMesh.AcquireLock:output_type -> mesh.LockResponse
+	37, // 69: mesh.StrategicMesh.RenewLock:output_type -> mesh.LockResponse
+	37, // 70: mesh.StrategicMesh.ReleaseLock:output_type -> mesh.LockResponse
+	57, // [57:71] is the sub-list for method output_type
+	43, // [43:57] is the sub-list for method input_type
+	43, // [43:43] is the sub-list for extension type_name
+	43, // [43:43] is the sub-list for extension extendee
+	0,  // [0:43] is the sub-list for field type_name
 }
 
 func init() { file_proto_mesh_proto_init() }
@@ -1371,8 +3439,8 @@ func file_proto_mesh_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_mesh_proto_rawDesc), len(file_proto_mesh_proto_rawDesc)),
-			NumEnums:      1,
-			NumMessages:   20,
+			NumEnums:      3,
+			NumMessages:   48,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -23,29 +23,50 @@ const (
 	StrategicMesh_ExecuteStrategicAction_FullMethodName = "/mesh.StrategicMesh/ExecuteStrategicAction"
 	StrategicMesh_SemanticSearch_FullMethodName         = "/mesh.StrategicMesh/SemanticSearch"
 	StrategicMesh_GetStateReconstitution_FullMethodName = "/mesh.StrategicMesh/GetStateReconstitution"
+	StrategicMesh_GetStateHistory_FullMethodName        = "/mesh.StrategicMesh/GetStateHistory"
+	StrategicMesh_DiffStates_FullMethodName             = "/mesh.StrategicMesh/DiffStates"
 	StrategicMesh_SynthesizeOutputs_FullMethodName      = "/mesh.StrategicMesh/SynthesizeOutputs"
 	StrategicMesh_GenerateResponse_FullMethodName       = "/mesh.StrategicMesh/GenerateResponse"
+	StrategicMesh_GenerateStream_FullMethodName         = "/mesh.StrategicMesh/GenerateStream"
 	StrategicMesh_GetMeshStats_FullMethodName           = "/mesh.StrategicMesh/GetMeshStats"
+	StrategicMesh_GetNeighborGraph_FullMethodName       = "/mesh.StrategicMesh/GetNeighborGraph"
+	StrategicMesh_AcquireLock_FullMethodName            = "/mesh.StrategicMesh/AcquireLock"
+	StrategicMesh_RenewLock_FullMethodName              = "/mesh.StrategicMesh/RenewLock"
+	StrategicMesh_ReleaseLock_FullMethodName            = "/mesh.StrategicMesh/ReleaseLock"
 )
 
 // StrategicMeshClient is the client API for StrategicMesh service.
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type StrategicMeshClient interface {
-	// Handshake to enter the mesh
+	// Registers an agent with the mesh.
 	RegisterAgent(ctx context.Context, in *HandshakeRequest, opts ...grpc.CallOption) (*HandshakeResponse, error)
-	// Execute high-complexity planning or reasoning tasks
+	// Executes a high-complexity task requiring strategic planning.
 	ExecuteStrategicAction(ctx context.Context, in *AgentAction, opts ...grpc.CallOption) (*ActionResponse, error)
-	// Semantic search across shared context and research papers
+	// Performs a semantic search over the knowledge base.
 	SemanticSearch(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResponse, error)
-	// Synchronize state for failed node recovery
-	GetStateReconstitution(ctx context.Context, in *HandshakeRequest, opts ...grpc.CallOption) (*AgentAction, error)
-	// Synthesize parallel agent outputs (AdaptOrch)
+	// Retrieves the last known state for a failed agent to allow for recovery,
+	// or an earlier version so the agent can roll back past a corrupt step.
+	GetStateReconstitution(ctx context.Context, in *ReconstitutionRequest, opts ...grpc.CallOption) (*AgentAction, error)
+	// Lists the retained state versions for an agent.
+	GetStateHistory(ctx context.Context, in *ReconstitutionRequest, opts ...grpc.CallOption) (*StateHistory, error)
+	// Compares two retained state versions field by field.
+	DiffStates(ctx context.Context, in *StateDiffRequest, opts ...grpc.CallOption) (*StateDiff, error)
+	// Merges and synthesizes outputs from multiple agents.
 	SynthesizeOutputs(ctx context.Context, in *SynthesisRequest, opts ...grpc.CallOption) (*SynthesisResponse, error)
-	// Phase 8: Hardware-Aware Inference
+	// Executes a hardware-aware inference request.
 	GenerateResponse(ctx context.Context, in *InferenceRequest, opts ...grpc.CallOption) (*InferenceResponse, error)
-	// Audit & Performance Stats
+	// Streams a hardware-aware inference request token by token. Cancelling
+	// the call aborts generation.
+	GenerateStream(ctx context.Context, in *InferenceRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[InferenceChunk], error)
+	// Retrieves performance and audit statistics for the mesh.
 	GetMeshStats(ctx context.Context, in *StatsRequest, opts ...grpc.CallOption) (*MeshStats, error)
+	// Retrieves the DSBO neighbor graph with per-edge bandit statistics.
+	GetNeighborGraph(ctx context.Context, in *NeighborGraphRequest, opts ...grpc.CallOption) (*NeighborGraph, error)
+	// Strategic lock leases guarded by monotonically increasing fencing tokens.
+	AcquireLock(ctx context.Context, in *LockRequest, opts ...grpc.CallOption) (*LockResponse, error)
+	RenewLock(ctx context.Context, in *LockRequest, opts ...grpc.CallOption) (*LockResponse, error)
+	ReleaseLock(ctx context.Context, in *LockRequest, opts ...grpc.CallOption) (*LockResponse, error)
 }
 
 type strategicMeshClient struct {
@@ -86,7 +107,7 @@ func (c *strategicMeshClient) SemanticSearch(ctx context.Context, in *SearchRequ
 	return out, nil
 }
 
-func (c *strategicMeshClient) GetStateReconstitution(ctx context.Context, in *HandshakeRequest, opts ...grpc.CallOption) (*AgentAction, error) {
+func (c *strategicMeshClient) GetStateReconstitution(ctx context.Context, in *ReconstitutionRequest, opts ...grpc.CallOption) (*AgentAction, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(AgentAction)
 	err := c.cc.Invoke(ctx, StrategicMesh_GetStateReconstitution_FullMethodName, in, out, cOpts...)
@@ -96,6 +117,26 @@ func (c *strategicMeshClient) GetStateReconstitution(ctx context.Context, in *Ha
 	return out, nil
 }
 
+func (c *strategicMeshClient) GetStateHistory(ctx context.Context, in *ReconstitutionRequest, opts ...grpc.CallOption) (*StateHistory, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StateHistory)
+	err := c.cc.Invoke(ctx, StrategicMesh_GetStateHistory_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *strategicMeshClient) DiffStates(ctx context.Context, in *StateDiffRequest, opts ...grpc.CallOption) (*StateDiff, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StateDiff)
+	err := c.cc.Invoke(ctx, StrategicMesh_DiffStates_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *strategicMeshClient) SynthesizeOutputs(ctx context.Context, in *SynthesisRequest, opts ...grpc.CallOption) (*SynthesisResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(SynthesisResponse)
@@ -116,6 +157,25 @@ func (c *strategicMeshClient) GenerateResponse(ctx context.Context, in *Inferenc
 	return out, nil
 }
 
+func (c *strategicMeshClient) GenerateStream(ctx context.Context, in *InferenceRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[InferenceChunk], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &StrategicMesh_ServiceDesc.Streams[0], StrategicMesh_GenerateStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[InferenceRequest, InferenceChunk]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type StrategicMesh_GenerateStreamClient = grpc.ServerStreamingClient[InferenceChunk]
+
 func (c *strategicMeshClient) GetMeshStats(ctx context.Context, in *StatsRequest, opts ...grpc.CallOption) (*MeshStats, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(MeshStats)
@@ -126,24 +186,78 @@ func (c *strategicMeshClient) GetMeshStats(ctx context.Context, in *StatsRequest
 	return out, nil
 }
 
+func (c *strategicMeshClient) GetNeighborGraph(ctx context.Context, in *NeighborGraphRequest, opts ...grpc.CallOption) (*NeighborGraph, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(NeighborGraph)
+	err := c.cc.Invoke(ctx, StrategicMesh_GetNeighborGraph_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *strategicMeshClient) AcquireLock(ctx context.Context, in *LockRequest, opts ...grpc.CallOption) (*LockResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LockResponse)
+	err := c.cc.Invoke(ctx, StrategicMesh_AcquireLock_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *strategicMeshClient) RenewLock(ctx context.Context, in *LockRequest, opts ...grpc.CallOption) (*LockResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LockResponse)
+	err := c.cc.Invoke(ctx, StrategicMesh_RenewLock_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *strategicMeshClient) ReleaseLock(ctx context.Context, in *LockRequest, opts ...grpc.CallOption) (*LockResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LockResponse)
+	err := c.cc.Invoke(ctx, StrategicMesh_ReleaseLock_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // StrategicMeshServer is the server API for StrategicMesh service.
 // All implementations must embed UnimplementedStrategicMeshServer
 // for forward compatibility.
 type StrategicMeshServer interface {
-	// Handshake to enter the mesh
+	// Registers an agent with the mesh.
 	RegisterAgent(context.Context, *HandshakeRequest) (*HandshakeResponse, error)
-	// Execute high-complexity planning or reasoning tasks
+	// Executes a high-complexity task requiring strategic planning.
 	ExecuteStrategicAction(context.Context, *AgentAction) (*ActionResponse, error)
-	// Semantic search across shared context and research papers
+	// Performs a semantic search over the knowledge base.
 	SemanticSearch(context.Context, *SearchRequest) (*SearchResponse, error)
-	// Synchronize state for failed node recovery
-	GetStateReconstitution(context.Context, *HandshakeRequest) (*AgentAction, error)
-	// Synthesize parallel agent outputs (AdaptOrch)
+	// Retrieves the last known state for a failed agent to allow for recovery,
+	// or an earlier version so the agent can roll back past a corrupt step.
+	GetStateReconstitution(context.Context, *ReconstitutionRequest) (*AgentAction, error)
+	// Lists the retained state versions for an agent.
+	GetStateHistory(context.Context, *ReconstitutionRequest) (*StateHistory, error)
+	// Compares two retained state versions field by field.
+	DiffStates(context.Context, *StateDiffRequest) (*StateDiff, error)
+	// Merges and synthesizes outputs from multiple agents.
 	SynthesizeOutputs(context.Context, *SynthesisRequest) (*SynthesisResponse, error)
-	// Phase 8: Hardware-Aware Inference
+	// Executes a hardware-aware inference request.
 	GenerateResponse(context.Context, *InferenceRequest) (*InferenceResponse, error)
-	// Audit & Performance Stats
+	// Streams a hardware-aware inference request token by token. Cancelling
+	// the call aborts generation.
+	GenerateStream(*InferenceRequest, grpc.ServerStreamingServer[InferenceChunk]) error
+	// Retrieves performance and audit statistics for the mesh.
 	GetMeshStats(context.Context, *StatsRequest) (*MeshStats, error)
+	// Retrieves the DSBO neighbor graph with per-edge bandit statistics.
+	GetNeighborGraph(context.Context, *NeighborGraphRequest) (*NeighborGraph, error)
+	// Strategic lock leases guarded by monotonically increasing fencing tokens.
+	AcquireLock(context.Context, *LockRequest) (*LockResponse, error)
+	RenewLock(context.Context, *LockRequest) (*LockResponse, error)
+	ReleaseLock(context.Context, *LockRequest) (*LockResponse, error)
 	mustEmbedUnimplementedStrategicMeshServer()
 }
 
@@ -163,18 +277,39 @@ func (UnimplementedStrategicMeshServer) ExecuteStrategicAction(context.Context,
 func (UnimplementedStrategicMeshServer) SemanticSearch(context.Context, *SearchRequest) (*SearchResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method SemanticSearch not implemented")
 }
-func (UnimplementedStrategicMeshServer) GetStateReconstitution(context.Context, *HandshakeRequest) (*AgentAction, error) {
+func (UnimplementedStrategicMeshServer) GetStateReconstitution(context.Context, *ReconstitutionRequest) (*AgentAction, error) {
 	return nil, status.Error(codes.Unimplemented, "method GetStateReconstitution not implemented")
 }
+func (UnimplementedStrategicMeshServer) GetStateHistory(context.Context, *ReconstitutionRequest) (*StateHistory, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetStateHistory not implemented")
+}
+func (UnimplementedStrategicMeshServer) DiffStates(context.Context, *StateDiffRequest) (*StateDiff, error) {
+	return nil, status.Error(codes.Unimplemented, "method DiffStates not implemented")
+}
 func (UnimplementedStrategicMeshServer) SynthesizeOutputs(context.Context, *SynthesisRequest) (*SynthesisResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method SynthesizeOutputs not implemented")
 }
 func (UnimplementedStrategicMeshServer) GenerateResponse(context.Context, *InferenceRequest) (*InferenceResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method GenerateResponse not implemented")
 }
+func (UnimplementedStrategicMeshServer) GenerateStream(*InferenceRequest, grpc.ServerStreamingServer[InferenceChunk]) error {
+	return status.Error(codes.Unimplemented, "method GenerateStream not implemented")
+}
 func (UnimplementedStrategicMeshServer) GetMeshStats(context.Context, *StatsRequest) (*MeshStats, error) {
 	return nil, status.Error(codes.Unimplemented, "method GetMeshStats not implemented")
 }
+func (UnimplementedStrategicMeshServer) GetNeighborGraph(context.Context, *NeighborGraphRequest) (*NeighborGraph, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetNeighborGraph not implemented")
+}
+func (UnimplementedStrategicMeshServer) AcquireLock(context.Context, *LockRequest) (*LockResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method AcquireLock not implemented")
+}
+func (UnimplementedStrategicMeshServer) RenewLock(context.Context, *LockRequest) (*LockResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method RenewLock not implemented")
+}
+func (UnimplementedStrategicMeshServer) ReleaseLock(context.Context, *LockRequest) (*LockResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ReleaseLock not implemented")
+}
 func (UnimplementedStrategicMeshServer) mustEmbedUnimplementedStrategicMeshServer() {}
 func (UnimplementedStrategicMeshServer) testEmbeddedByValue()                       {}
 
@@ -251,7 +386,7 @@ func _StrategicMesh_SemanticSearch_Handler(srv interface{}, ctx context.Context,
 }
 
 func _StrategicMesh_GetStateReconstitution_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(HandshakeRequest)
+	in := new(ReconstitutionRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
@@ -263,7 +398,43 @@ func _StrategicMesh_GetStateReconstitution_Handler(srv interface{}, ctx context.
 		FullMethod: StrategicMesh_GetStateReconstitution_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(StrategicMeshServer).GetStateReconstitution(ctx, req.(*HandshakeRequest))
+		return srv.(StrategicMeshServer).GetStateReconstitution(ctx, req.(*ReconstitutionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StrategicMesh_GetStateHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReconstitutionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StrategicMeshServer).GetStateHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StrategicMesh_GetStateHistory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StrategicMeshServer).GetStateHistory(ctx, req.(*ReconstitutionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StrategicMesh_DiffStates_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StateDiffRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StrategicMeshServer).DiffStates(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StrategicMesh_DiffStates_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StrategicMeshServer).DiffStates(ctx, req.(*StateDiffRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
@@ -304,6 +475,17 @@ func _StrategicMesh_GenerateResponse_Handler(srv interface{}, ctx context.Contex
 	return interceptor(ctx, in, info, handler)
 }
 
+func _StrategicMesh_GenerateStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(InferenceRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(StrategicMeshServer).GenerateStream(m, &grpc.GenericServerStream[InferenceRequest, InferenceChunk]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type StrategicMesh_GenerateStreamServer = grpc.ServerStreamingServer[InferenceChunk]
+
 func _StrategicMesh_GetMeshStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(StatsRequest)
 	if err := dec(in); err != nil {
@@ -322,6 +504,78 @@ func _StrategicMesh_GetMeshStats_Handler(srv interface{}, ctx context.Context, d
 	return interceptor(ctx, in, info, handler)
 }
 
+func _StrategicMesh_GetNeighborGraph_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NeighborGraphRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StrategicMeshServer).GetNeighborGraph(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StrategicMesh_GetNeighborGraph_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StrategicMeshServer).GetNeighborGraph(ctx, req.(*NeighborGraphRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StrategicMesh_AcquireLock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StrategicMeshServer).AcquireLock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StrategicMesh_AcquireLock_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StrategicMeshServer).AcquireLock(ctx, req.(*LockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StrategicMesh_RenewLock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StrategicMeshServer).RenewLock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StrategicMesh_RenewLock_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StrategicMeshServer).RenewLock(ctx, req.(*LockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StrategicMesh_ReleaseLock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StrategicMeshServer).ReleaseLock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StrategicMesh_ReleaseLock_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StrategicMeshServer).ReleaseLock(ctx, req.(*LockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // StrategicMesh_ServiceDesc is the grpc.ServiceDesc for StrategicMesh service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -345,6 +599,14 @@ var StrategicMesh_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetStateReconstitution",
 			Handler:    _StrategicMesh_GetStateReconstitution_Handler,
 		},
+		{
+			MethodName: "GetStateHistory",
+			Handler:    _StrategicMesh_GetStateHistory_Handler,
+		},
+		{
+			MethodName: "DiffStates",
+			Handler:    _StrategicMesh_DiffStates_Handler,
+		},
 		{
 			MethodName: "SynthesizeOutputs",
 			Handler:    _StrategicMesh_SynthesizeOutputs_Handler,
@@ -357,7 +619,29 @@ var StrategicMesh_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetMeshStats",
 			Handler:    _StrategicMesh_GetMeshStats_Handler,
 		},
+		{
+			MethodName: "GetNeighborGraph",
+			Handler:    _StrategicMesh_GetNeighborGraph_Handler,
+		},
+		{
+			MethodName: "AcquireLock",
+			Handler:    _StrategicMesh_AcquireLock_Handler,
+		},
+		{
+			MethodName: "RenewLock",
+			Handler:    _StrategicMesh_RenewLock_Handler,
+		},
+		{
+			MethodName: "ReleaseLock",
+			Handler:    _StrategicMesh_ReleaseLock_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "GenerateStream",
+			Handler:       _StrategicMesh_GenerateStream_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/mesh.proto",
 }
@@ -26,7 +26,7 @@ from google.protobuf import struct_pb2 as google_dot_protobuf_dot_struct__pb2
 from google.protobuf import timestamp_pb2 as google_dot_protobuf_dot_timestamp__pb2
 
 
-DESCRIPTOR = _descriptor_pool.Default().AddSerializedFile(b'\n\x10proto/mesh.proto\x12\x04mesh\x1a\x1cgoogle/protobuf/struct.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"u\n\x0bOSResources\x12\x19\n\x11\x63pu_usage_percent\x18\x01 \x01(\x01\x12\x19\n\x11memory_used_bytes\x18\x02 \x01(\x04\x12\x1a\n\x12memory_total_bytes\x18\x03 \x01(\x04\x12\x14\n\x0c\x64isk_io_wait\x18\x04 \x01(\x01\"a\n\x10HandshakeRequest\x12\x10\n\x08\x61gent_id\x18\x01 \x01(\t\x12\x14\n\x0c\x63\x61pabilities\x18\x02 \x03(\t\x12%\n\x0cinitial_role\x18\x03 \x01(\x0e\x32\x0f.mesh.AgentRole\"|\n\x11HandshakeResponse\x12\x12\n\nsession_id\x18\x01 \x01(\t\x12\x10\n\x08\x61pproved\x18\x02 \x01(\x08\x12\x15\n\rerror_message\x18\x03 \x01(\t\x12*\n\x0fresource_limits\x18\x04 \x01(\x0b\x32\x11.mesh.OSResources\"\x9c\x01\n\tHeartbeat\x12\x10\n\x08\x61gent_id\x18\x01 \x01(\t\x12-\n\ttimestamp\x18\x02 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x12\'\n\x0c\x63urrent_load\x18\x03 \x01(\x0b\x32\x11.mesh.OSResources\x12%\n\x0c\x63urrent_role\x18\x04 \x01(\x0e\x32\x0f.mesh.AgentRole\"\xd1\x01\n\x0b\x41gentAction\x12\x10\n\x08\x61gent_id\x18\x01 \x01(\t\x12\x13\n\x0b\x61\x63tion_type\x18\x02 \x01(\t\x12*\n\x0fresource_impact\x18\x03 \x01(\x0b\x32\x11.mesh.OSResources\x12(\n\x07payload\x18\x04 \x01(\x0b\x32\x17.google.protobuf.Struct\x12\x17\n\x0freasoning_chain\x18\x05 \x01(\t\x12\x13\n\x0btask_intent\x18\x06 \x01(\t\x12\x17\n\x0f\x64\x61ta_size_bytes\x18\x07 \x01(\x04\"\xb8\x01\n\x0e\x41\x63tionResponse\x12\x0f\n\x07success\x18\x01 \x01(\x08\x12\'\n\x06result\x18\x02 \x01(\x0b\x32\x17.google.protobuf.Struct\x12\r\n\x05\x65rror\x18\x03 \x01(\t\x12\x1b\n\x13promotion_suggested\x18\x04 \x01(\x08\x12\x18\n\x10routing_provider\x18\x05 \x01(\t\x12&\n\rrequired_role\x18\x06 \x01(\x0e\x32\x0f.mesh.AgentRole\"~\n\x10InferenceRequest\x12\x10\n\x08\x61gent_id\x18\x01 \x01(\t\x12\x0e\n\x06prompt\x18\x02 \x01(\t\x12\x12\n\nmax_tokens\x18\x03 \x01(\r\x12\x13\n\x0btemperature\x18\x04 \x01(\x02\x12\x1f\n\x17\x65xpected_kv_cache_bytes\x18\x05 \x01(\x04\"a\n\x11InferenceResponse\x12\x0c\n\x04text\x18\x01 \x01(\t\x12\x13\n\x0btokens_used\x18\x02 \x01(\r\x12\x15\n\rhardware_path\x18\x03 \x01(\t\x12\x12\n\nlatency_ms\x18\x04 \x01(\x02\"g\n\x10SynthesisRequest\x12\x11\n\tagent_ids\x18\x01 \x03(\t\x12\x13\n\x0btarget_goal\x18\x02 \x01(\t\x12+\n\x10\x61\x63tions_to_merge\x18\x03 \x03(\x0b\x32\x11.mesh.AgentAction\"H\n\x11SynthesisResponse\x12\x19\n\x11synthesized_state\x18\x01 \x01(\t\x12\x18\n\x10\x63onfidence_score\x18\x02 \x01(\x02\"E\n\rSearchRequest\x12\x10\n\x08\x61gent_id\x18\x01 \x01(\t\x12\r\n\x05query\x18\x02 \x01(\t\x12\x13\n\x0bmax_results\x18\x03 \x01(\x05\"P\n\x0eSearchResponse\x12#\n\x07results\x18\x01 \x03(\x0b\x32\x12.mesh.SearchResult\x12\x19\n\x11reasoning_context\x18\x02 \x01(\t\">\n\x0cSearchResult\x12\x0e\n\x06source\x18\x03 \x01(\t\x12\x0f\n\x07\x63ontent\x18\x04 \x01(\t\x12\r\n\x05score\x18\x05 \x01(\x02*+\n\tAgentRole\x12\x0f\n\x0bOPERATIONAL\x10\x00\x12\r\n\tSTRATEGIC\x10\x01\x32\xa1\x03\n\rStrategicMesh\x12@\n\rRegisterAgent\x12\x16.mesh.HandshakeRequest\x1a\x17.mesh.HandshakeResponse\x12\x41\n\x16\x45xecuteStrategicAction\x12\x11.mesh.AgentAction\x1a\x14.mesh.ActionResponse\x12;\n\x0eSemanticSearch\x12\x13.mesh.SearchRequest\x1a\x14.mesh.SearchResponse\x12\x43\n\x16GetStateReconstitution\x12\x16.mesh.HandshakeRequest\x1a\x11.mesh.AgentAction\x12\x44\n\x11SynthesizeOutputs\x12\x16.mesh.SynthesisRequest\x1a\x17.mesh.SynthesisResponse\x12\x43\n\x10GenerateResponse\x12\x16.mesh.InferenceRequest\x1a\x17.mesh.InferenceResponseB.Z,github.com/groovy-byte/agent-mesh-core/protob\x06proto3')
+DESCRIPTOR = _descriptor_pool.Default().AddSerializedFile(b'\n\x10proto/mesh.proto\x12\x04mesh\x1a\x1cgoogle/protobuf/struct.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"u\n\x0bOSResources\x12\x19\n\x11\x63pu_usage_percent\x18\x01 \x01(\x01\x12\x19\n\x11memory_used_bytes\x18\x02 \x01(\x04\x12\x1a\n\x12memory_total_bytes\x18\x03 \x01(\x04\x12\x14\n\x0c\x64isk_io_wait\x18\x04 \x01(\x01\"a\n\x10HandshakeRequest\x12\x10\n\x08\x61gent_id\x18\x01 \x01(\t\x12\x14\n\x0c\x63\x61pabilities\x18\x02 \x03(\t\x12%\n\x0cinitial_role\x18\x03 \x01(\x0e\x32\x0f.mesh.AgentRole\"\xad\x01\n\x11HandshakeResponse\x12\x12\n\nsession_id\x18\x01 \x01(\t\x12\x10\n\x08\x61pproved\x18\x02 \x01(\x08\x12\x15\n\rerror_message\x18\x03 \x01(\t\x12*\n\x0fresource_limits\x18\x04 \x01(\x0b\x32\x11.mesh.OSResources\x12/\n\x10inference_budget\x18\x05 \x01(\x0b\x32\x15.mesh.InferenceBudget\"D\n\x0fInferenceBudget\x12\x19\n\x11tokens_per_minute\x18\x01 \x01(\r\x12\x16\n\x0emax_concurrent\x18\x02 \x01(\r\"\x9c\x01\n\tHeartbeat\x12\x10\n\x08\x61gent_id\x18\x01 \x01(\t\x12-\n\ttimestamp\x18\x02 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x12\'\n\x0c\x63urrent_load\x18\x03 \x01(\x0b\x32\x11.mesh.OSResources\x12%\n\x0c\x63urrent_role\x18\x04 \x01(\x0e\x32\x0f.mesh.AgentRole\"\x95\x02\n\x0b\x41gentAction\x12\x10\n\x08\x61gent_id\x18\x01 \x01(\t\x12\x13\n\x0b\x61\x63tion_type\x18\x02 \x01(\t\x12*\n\x0fresource_impact\x18\x03 \x01(\x0b\x32\x11.mesh.OSResources\x12(\n\x07payload\x18\x04 \x01(\x0b\x32\x17.google.protobuf.Struct\x12\x17\n\x0freasoning_chain\x18\x05 \x01(\t\x12\x13\n\x0btask_intent\x18\x06 \x01(\t\x12\x17\n\x0f\x64\x61ta_size_bytes\x18\x07 \x01(\x04\x12\x15\n\rfencing_token\x18\x08 \x01(\x04\x12\x13\n\x0block_domain\x18\t \x01(\t\x12\x16\n\x0e\x66orwarded_from\x18\n \x01(\t\"\xff\x01\n\x0e\x41\x63tionResponse\x12\x0f\n\x07success\x18\x01 \x01(\x08\x12\'\n\x06result\x18\x02 \x01(\x0b\x32\x17.google.protobuf.Struct\x12\r\n\x05\x65rror\x18\x03 \x01(\t\x12\x1b\n\x13promotion_suggested\x18\x04 \x01(\x08\x12\x18\n\x10routing_provider\x18\x05 \x01(\t\x12&\n\rrequired_role\x18\x06 \x01(\x0e\x32\x0f.mesh.AgentRole\x12\x1c\n\x14\x65stimated_latency_ms\x18\x07 \x01(\x02\x12\x14\n\x0crouting_node\x18\x08 \x01(\t\x12\x11\n\tforwarded\x18\t \x01(\x08\"\xae\x01\n\x10InferenceRequest\x12\x10\n\x08\x61gent_id\x18\x01 \x01(\t\x12\x0e\n\x06prompt\x18\x02 \x01(\t\x12\x12\n\nmax_tokens\x18\x03 \x01(\r\x12\x13\n\x0btemperature\x18\x04 \x01(\x02\x12\x1f\n\x17\x65xpected_kv_cache_bytes\x18\x05 \x01(\x04\x12.\n\x0bspeculative\x18\x06 \x01(\x0b\x32\x19.mesh.SpeculativeDecoding\"?\n\x13SpeculativeDecoding\x12\x12\n\ndraft_path\x18\x01 \x01(\t\x12\x14\n\x0c\x64raft_tokens\x18\x02 \x01(\r\"\xfe\x01\n\x11InferenceResponse\x12\x0c\n\x04text\x18\x01 \x01(\t\x12\x13\n\x0btokens_used\x18\x02 \x01(\r\x12\x15\n\rhardware_path\x18\x03 \x01(\t\x12\x12\n\nlatency_ms\x18\x04 \x01(\x02\x12\x16\n\x0ethroughput_gbs\x18\x05 \x01(\x02\x12\x14\n\x0c\x61vx512_usage\x18\x06 \x01(\x08\x12\x15\n\rprompt_tokens\x18\x07 \x01(\r\x12\x19\n\x11\x63ompletion_tokens\x18\x08 \x01(\r\x12\x0e\n\x06\x63\x61\x63hed\x18\t \x01(\x08\x12\x17\n\x0f\x61\x63\x63\x65ptance_rate\x18\n \x01(\x02\x12\x12\n\ndraft_path\x18\x0b \x01(\t\"p\n\x0eInferenceChunk\x12\x0c\n\x04text\x18\x01 \x01(\t\x12\x18\n\x10tokens_generated\x18\x02 \x01(\r\x12\x0c\n\x04\x64one\x18\x03 \x01(\x08\x12(\n\x07summary\x18\x04 \x01(\x0b\x32\x17.mesh.InferenceResponse\"g\n\x10SynthesisRequest\x12\x11\n\tagent_ids\x18\x01 \x03(\t\x12\x13\n\x0btarget_goal\x18\x02 \x01(\t\x12+\n\x10\x61\x63tions_to_merge\x18\x03 \x03(\x0b\x32\x11.mesh.AgentAction\"H\n\x11SynthesisResponse\x12\x19\n\x11synthesized_state\x18\x01 \x01(\t\x12\x18\n\x10\x63onfidence_score\x18\x02 \x01(\x02\"\x0e\n\x0cStatsRequest\"\xea\x05\n\tMeshStats\x12\x15\n\ragents_active\x18\x01 \x01(\x05\x12\x32\n\nagent_logs\x18\x02 \x03(\x0b\x32\x1e.mesh.MeshStats.AgentLogsEntry\x12\x44\n\x13\x63ontribution_matrix\x18\x03 \x03(\x0b\x32\'.mesh.MeshStats.ContributionMatrixEntry\x12\x36\n\x0clock_domains\x18\x04 \x03(\x0b\x32 .mesh.MeshStats.LockDomainsEntry\x12\x38\n\rprovider_load\x18\x05 \x03(\x0b\x32!.mesh.MeshStats.ProviderLoadEntry\x12/\n\x08\x62\x61tching\x18\x06 \x03(\x0b\x32\x1d.mesh.MeshStats.BatchingEntry\x12\x32\n\x0eresponse_cache\x18\x07 \x01(\x0b\x32\x1a.mesh.ResponseCacheMetrics\x1a\x44\n\x0e\x41gentLogsEntry\x12\x0b\n\x03key\x18\x01 \x01(\t\x12!\n\x05value\x18\x02 \x01(\x0b\x32\x12.mesh.AgentMetrics:\x02\x38\x01\x1aM\n\x17\x43ontributionMatrixEntry\x12\x0b\n\x03key\x18\x01 \x01(\t\x12!\n\x05value\x18\x02 \x01(\x0b\x32\x12.mesh.InfluenceMap:\x02\x38\x01\x1aK\n\x10LockDomainsEntry\x12\x0b\n\x03key\x18\x01 \x01(\t\x12&\n\x05value\x18\x02 \x01(\x0b\x32\x17.mesh.LockDomainMetrics:\x02\x38\x01\x1aN\n\x11ProviderLoadEntry\x12\x0b\n\x03key\x18\x01 \x01(\t\x12(\n\x05value\x18\x02 \x01(\x0b\x32\x19.mesh.ProviderLoadMetrics:\x02\x38\x01\x1a\x43\n\rBatchingEntry\x12\x0b\n\x03key\x18\x01 \x01(\t\x12!\n\x05value\x18\x02 \x01(\x0b\x32\x12.mesh.BatchMetrics:\x02\x38\x01\"\x96\x01\n\x14ResponseCacheMetrics\x12\x0c\n\x04hits\x18\x01 \x01(\x04\x12\x15\n\rsemantic_hits\x18\x02 \x01(\x04\x12\x0e\n\x06misses\x18\x03 \x01(\x04\x12\x10\n\x08\x62ypassed\x18\x04 \x01(\x04\x12\x11\n\tevictions\x18\x05 \x01(\x04\x12\x13\n\x0b\x65xpirations\x18\x06 \x01(\x04\x12\x0f\n\x07\x65ntries\x18\x07 \x01(\r\"\xae\x01\n\x0c\x42\x61tchMetrics\x12\x0f\n\x07\x62\x61tches\x18\x01 \x01(\x04\x12\x10\n\x08requests\x18\x02 \x01(\x04\x12\x0f\n\x07\x65xpired\x18\x03 \x01(\x04\x12\x37\n\x0bsize_counts\x18\x04 \x03(\x0b\x32\".mesh.BatchMetrics.SizeCountsEntry\x1a\x31\n\x0fSizeCountsEntry\x12\x0b\n\x03key\x18\x01 \x01(\r\x12\r\n\x05value\x18\x02 \x01(\x04:\x02\x38\x01\"\xfb\x02\n\x0eNodeCapability\x12\x0f\n\x07node_id\x18\x01 \x01(\t\x12\x11\n\tgrpc_addr\x18\x02 \x01(\t\x12\x16\n\x0ephysical_cores\x18\x03 \x01(\r\x12\x14\n\x0clogical_cpus\x18\x04 \x01(\r\x12\x16\n\x0el3_cache_bytes\x18\x05 \x01(\x04\x12\x12\n\nnuma_nodes\x18\x06 \x01(\r\x12\x11\n\tsimd_tier\x18\x07 \x01(\t\x12\x11\n\tproviders\x18\x08 \x03(\t\x12\x1d\n\x04gpus\x18\t \x03(\x0b\x32\x0f.mesh.GpuDevice\x12,\n\x04load\x18\n \x03(\x0b\x32\x1e.mesh.NodeCapability.LoadEntry\x12\x30\n\x0cpublished_at\x18\x0b \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x1a\x46\n\tLoadEntry\x12\x0b\n\x03key\x18\x01 \x01(\t\x12(\n\x05value\x18\x02 \x01(\x0b\x32\x19.mesh.ProviderLoadMetrics:\x02\x38\x01\"l\n\tGpuDevice\x12\r\n\x05index\x18\x01 \x01(\r\x12\x0c\n\x04name\x18\x02 \x01(\t\x12\x12\n\nvram_bytes\x18\x03 \x01(\x04\x12\x12\n\nfree_bytes\x18\x04 \x01(\x04\x12\x1a\n\x12\x63ompute_capability\x18\x05 \x01(\r\"|\n\x13ProviderLoadMetrics\x12\x11\n\tin_flight\x18\x01 \x01(\r\x12\x17\n\x0fin_flight_bytes\x18\x02 \x01(\x04\x12\x10\n\x08\x61\x63quired\x18\x03 \x01(\x04\x12\x13\n\x0bspilled_out\x18\x04 \x01(\x04\x12\x12\n\nspilled_in\x18\x05 \x01(\x04\"\xb0\x01\n\x11LockDomainMetrics\x12\x11\n\tholder_id\x18\x01 \x01(\t\x12\x0e\n\x06grants\x18\x02 \x01(\x04\x12\x11\n\tcontended\x18\x03 \x01(\x04\x12\x10\n\x08timeouts\x18\x04 \x01(\x04\x12\x10\n\x08reclaims\x18\x05 \x01(\x04\x12\x13\n\x0bqueue_depth\x18\x06 \x01(\r\x12\x17\n\x0fmax_queue_depth\x18\x07 \x01(\r\x12\x13\n\x0b\x61vg_wait_ms\x18\x08 \x01(\x01\"\x81\x01\n\x0c\x41gentMetrics\x12\x12\n\ntool_calls\x18\x01 \x01(\r\x12\x14\n\x0c\x66\x61iled_tasks\x18\x02 \x03(\t\x12\x16\n\x0e\x61vg_latency_ms\x18\x03 \x01(\x02\x12\x14\n\x0ctotal_tokens\x18\x04 \x01(\r\x12\x19\n\x11rejected_requests\x18\x05 \x01(\r\"v\n\x0cInfluenceMap\x12\x34\n\tinfluence\x18\x01 \x03(\x0b\x32!.mesh.InfluenceMap.InfluenceEntry\x1a\x30\n\x0eInfluenceEntry\x12\x0b\n\x03key\x18\x01 \x01(\t\x12\r\n\x05value\x18\x02 \x01(\x01:\x02\x38\x01\"(\n\x14NeighborGraphRequest\x12\x10\n\x08\x61gent_id\x18\x01 \x01(\t\"T\n\x0cNeighborEdge\x12\x11\n\ttarget_id\x18\x01 \x01(\t\x12\r\n\x05score\x18\x02 \x01(\x01\x12\r\n\x05pulls\x18\x03 \x01(\r\x12\x13\n\x0bmean_reward\x18\x04 \x01(\x01\"H\n\x0cNeighborList\x12!\n\x05\x65\x64ges\x18\x01 \x03(\x0b\x32\x12.mesh.NeighborEdge\x12\x15\n\rutility_score\x18\x02 \x01(\x01\"\x8c\x01\n\rNeighborGraph\x12\x35\n\tadjacency\x18\x01 \x03(\x0b\x32\".mesh.NeighborGraph.AdjacencyEntry\x1a\x44\n\x0e\x41\x64jacencyEntry\x12\x0b\n\x03key\x18\x01 \x01(\t\x12!\n\x05value\x18\x02 \x01(\x0b\x32\x12.mesh.NeighborList:\x02\x38\x01\"q\n\x15ReconstitutionRequest\x12\x10\n\x08\x61gent_id\x18\x01 \x01(\t\x12\x0f\n\x07version\x18\x04 \x01(\x04\x12)\n\x05\x61s_of\x18\x05 \x01(\x0b\x32\x1a.google.protobuf.TimestampJ\x04\x08\x02\x10\x03J\x04\x08\x03\x10\x04\"q\n\rStateSnapshot\x12\x0f\n\x07version\x18\x01 \x01(\x04\x12,\n\x08saved_at\x18\x02 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x12!\n\x06\x61\x63tion\x18\x03 \x01(\x0b\x32\x11.mesh.AgentAction\"6\n\x0cStateHistory\x12&\n\tsnapshots\x18\x01 \x03(\x0b\x32\x13.mesh.StateSnapshot\"N\n\x10StateDiffRequest\x12\x10\n\x08\x61gent_id\x18\x01 \x01(\t\x12\x14\n\x0c\x66rom_version\x18\x02 \x01(\x04\x12\x12\n\nto_version\x18\x03 \x01(\x04\";\n\x0b\x46ieldChange\x12\r\n\x05\x66ield\x18\x01 \x01(\t\x12\x0e\n\x06\x62\x65\x66ore\x18\x02 \x01(\t\x12\r\n\x05\x61\x66ter\x18\x03 \x01(\t\"/\n\tStateDiff\x12\"\n\x07\x63hanges\x18\x01 \x03(\x0b\x32\x11.mesh.FieldChange\"x\n\x0bLockRequest\x12\x10\n\x08\x61gent_id\x18\x01 \x01(\t\x12\x15\n\rfencing_token\x18\x02 \x01(\x04\x12\x10\n\x08lease_ms\x18\x03 \x01(\r\x12\x0e\n\x06\x64omain\x18\x04 \x01(\t\x12\x10\n\x08priority\x18\x05 \x01(\x05\x12\x0c\n\x04wait\x18\x06 \x01(\x08\"\x89\x01\n\x0cLockResponse\x12\x0f\n\x07granted\x18\x01 \x01(\x08\x12\x15\n\rfencing_token\x18\x02 \x01(\x04\x12.\n\nexpires_at\x18\x03 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x12\x11\n\tholder_id\x18\x04 \x01(\t\x12\x0e\n\x06\x64omain\x18\x05 \x01(\t\"\xf0\x02\n\x0fKVCacheEnvelope\x12\x10\n\x08\x61gent_id\x18\x01 \x01(\t\x12\x10\n\x08sequence\x18\x02 \x01(\x04\x12\x18\n\x10\x62\x61se_snapshot_id\x18\x03 \x01(\x04\x12\x10\n\x08\x63hecksum\x18\x04 \x01(\r\x12\x10\n\x08snapshot\x18\x05 \x01(\x08\x12\x0f\n\x07payload\x18\x06 \x01(\x0c\x12\x30\n\x0cpublished_at\x18\x07 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x12(\n\x0b\x63ompression\x18\x08 \x01(\x0e\x32\x13.mesh.KVCompression\x12\x13\n\x0b\x63hunk_index\x18\t \x01(\r\x12\x13\n\x0b\x63hunk_count\x18\n \x01(\r\x12\x14\n\x0cpayload_size\x18\x0b \x01(\x04\x12*\n\x0cquantization\x18\x0c \x01(\x0e\x32\x14.mesh.KVQuantization\x12\x13\n\x0bvalue_count\x18\r \x01(\x04\x12\r\n\x05\x65poch\x18\x0e \x01(\t\"E\n\rSearchRequest\x12\x10\n\x08\x61gent_id\x18\x01 \x01(\t\x12\r\n\x05query\x18\x02 \x01(\t\x12\x13\n\x0bmax_results\x18\x03 \x01(\x05\"P\n\x0eSearchResponse\x12#\n\x07results\x18\x01 \x03(\x0b\x32\x12.mesh.SearchResult\x12\x19\n\x11reasoning_context\x18\x02 \x01(\t\">\n\x0cSearchResult\x12\x0e\n\x06source\x18\x03 \x01(\t\x12\x0f\n\x07\x63ontent\x18\x04 \x01(\t\x12\r\n\x05score\x18\x05 \x01(\x02*+\n\tAgentRole\x12\x0f\n\x0bOPERATIONAL\x10\x00\x12\r\n\tSTRATEGIC\x10\x01*O\n\rKVCompression\x12\x14\n\x10\x43OMPRESSION_NONE\x10\x00\x12\x14\n\x10\x43OMPRESSION_ZSTD\x10\x01\x12\x12\n\x0e\x43OMPRESSION_S2\x10\x02*@\n\x0eKVQuantization\x12\x0e\n\nQUANT_NONE\x10\x00\x12\x0e\n\nQUANT_Q8_0\x10\x01\x12\x0e\n\nQUANT_Q2_K\x10\x02\x32\xfd\x06\n\rStrategicMesh\x12@\n\rRegisterAgent\x12\x16.mesh.HandshakeRequest\x1a\x17.mesh.HandshakeResponse\x12\x41\n\x16\x45xecuteStrategicAction\x12\x11.mesh.AgentAction\x1a\x14.mesh.ActionResponse\x12;\n\x0eSemanticSearch\x12\x13.mesh.SearchRequest\x1a\x14.mesh.SearchResponse\x12H\n\x16GetStateReconstitution\x12\x1b.mesh.ReconstitutionRequest\x1a\x11.mesh.AgentAction\x12\x42\n\x0fGetStateHistory\x12\x1b.mesh.ReconstitutionRequest\x1a\x12.mesh.StateHistory\x12\x35\n\nDiffStates\x12\x16.mesh.StateDiffRequest\x1a\x0f.mesh.StateDiff\x12\x44\n\x11SynthesizeOutputs\x12\x16.mesh.SynthesisRequest\x1a\x17.mesh.SynthesisResponse\x12\x43\n\x10GenerateResponse\x12\x16.mesh.InferenceRequest\x1a\x17.mesh.InferenceResponse\x12@\n\x0eGenerateStream\x12\x16.mesh.InferenceRequest\x1a\x14.mesh.InferenceChunk0\x01\x12\x33\n\x0cGetMeshStats\x12\x12.mesh.StatsRequest\x1a\x0f.mesh.MeshStats\x12\x43\n\x10GetNeighborGraph\x12\x1a.mesh.NeighborGraphRequest\x1a\x13.mesh.NeighborGraph\x12\x34\n\x0b\x41\x63quireLock\x12\x11.mesh.LockRequest\x1a\x12.mesh.LockResponse\x12\x32\n\tRenewLock\x12\x11.mesh.LockRequest\x1a\x12.mesh.LockResponse\x12\x34\n\x0bReleaseLock\x12\x11.mesh.LockRequest\x1a\x12.mesh.LockResponseB.Z,github.com/groovy-byte/agent-mesh-core/protob\x06proto3')
 
 _globals = globals()
 _builder.BuildMessageAndEnumDescriptors(DESCRIPTOR, _globals)
@@ -34,34 +34,126 @@ _builder.BuildTopDescriptorsAndMessages(DESCRIPTOR, 'proto.mesh_pb2', _globals)
 if not _descriptor._USE_C_DESCRIPTORS:
   _globals['DESCRIPTOR']._loaded_options = None
   _globals['DESCRIPTOR']._serialized_options = b'Z,github.com/groovy-byte/agent-mesh-core/proto'
-  _globals['_AGENTROLE']._serialized_start=1614
-  _globals['_AGENTROLE']._serialized_end=1657
+  _globals['_MESHSTATS_AGENTLOGSENTRY']._loaded_options = None
+  _globals['_MESHSTATS_AGENTLOGSENTRY']._serialized_options = b'8\001'
+  _globals['_MESHSTATS_CONTRIBUTIONMATRIXENTRY']._loaded_options = None
+  _globals['_MESHSTATS_CONTRIBUTIONMATRIXENTRY']._serialized_options = b'8\001'
+  _globals['_MESHSTATS_LOCKDOMAINSENTRY']._loaded_options = None
+  _globals['_MESHSTATS_LOCKDOMAINSENTRY']._serialized_options = b'8\001'
+  _globals['_MESHSTATS_PROVIDERLOADENTRY']._loaded_options = None
+  _globals['_MESHSTATS_PROVIDERLOADENTRY']._serialized_options = b'8\001'
+  _globals['_MESHSTATS_BATCHINGENTRY']._loaded_options = None
+  _globals['_MESHSTATS_BATCHINGENTRY']._serialized_options = b'8\001'
+  _globals['_BATCHMETRICS_SIZECOUNTSENTRY']._loaded_options = None
+  _globals['_BATCHMETRICS_SIZECOUNTSENTRY']._serialized_options = b'8\001'
+  _globals['_NODECAPABILITY_LOADENTRY']._loaded_options = None
+  _globals['_NODECAPABILITY_LOADENTRY']._serialized_options = b'8\001'
+  _globals['_INFLUENCEMAP_INFLUENCEENTRY']._loaded_options = None
+  _globals['_INFLUENCEMAP_INFLUENCEENTRY']._serialized_options = b'8\001'
+  _globals['_NEIGHBORGRAPH_ADJACENCYENTRY']._loaded_options = None
+  _globals['_NEIGHBORGRAPH_ADJACENCYENTRY']._serialized_options = b'8\001'
+  _globals['_AGENTROLE']._serialized_start=5857
+  _globals['_AGENTROLE']._serialized_end=5900
+  _globals['_KVCOMPRESSION']._serialized_start=5902
+  _globals['_KVCOMPRESSION']._serialized_end=5981
+  _globals['_KVQUANTIZATION']._serialized_start=5983
+  _globals['_KVQUANTIZATION']._serialized_end=6047
   _globals['_OSRESOURCES']._serialized_start=89
   _globals['_OSRESOURCES']._serialized_end=206
   _globals['_HANDSHAKEREQUEST']._serialized_start=208
   _globals['_HANDSHAKEREQUEST']._serialized_end=305
-  _globals['_HANDSHAKERESPONSE']._serialized_start=307
-  _globals['_HANDSHAKERESPONSE']._serialized_end=431
-  _globals['_HEARTBEAT']._serialized_start=434
-  _globals['_HEARTBEAT']._serialized_end=590
-  _globals['_AGENTACTION']._serialized_start=593
-  _globals['_AGENTACTION']._serialized_end=802
-  _globals['_ACTIONRESPONSE']._serialized_start=805
-  _globals['_ACTIONRESPONSE']._serialized_end=989
-  _globals['_INFERENCEREQUEST']._serialized_start=991
-  _globals['_INFERENCEREQUEST']._serialized_end=1117
-  _globals['_INFERENCERESPONSE']._serialized_start=1119
-  _globals['_INFERENCERESPONSE']._serialized_end=1216
-  _globals['_SYNTHESISREQUEST']._serialized_start=1218
-  _globals['_SYNTHESISREQUEST']._serialized_end=1321
-  _globals['_SYNTHESISRESPONSE']._serialized_start=1323
-  _globals['_SYNTHESISRESPONSE']._serialized_end=1395
-  _globals['_SEARCHREQUEST']._serialized_start=1397
-  _globals['_SEARCHREQUEST']._serialized_end=1466
-  _globals['_SEARCHRESPONSE']._serialized_start=1468
-  _globals['_SEARCHRESPONSE']._serialized_end=1548
-  _globals['_SEARCHRESULT']._serialized_start=1550
-  _globals['_SEARCHRESULT']._serialized_end=1612
-  _globals['_STRATEGICMESH']._serialized_start=1660
-  _globals['_STRATEGICMESH']._serialized_end=2077
+  _globals['_HANDSHAKERESPONSE']._serialized_start=308
+  _globals['_HANDSHAKERESPONSE']._serialized_end=481
+  _globals['_INFERENCEBUDGET']._serialized_start=483
+  _globals['_INFERENCEBUDGET']._serialized_end=551
+  _globals['_HEARTBEAT']._serialized_start=554
+  _globals['_HEARTBEAT']._serialized_end=710
+  _globals['_AGENTACTION']._serialized_start=713
+  _globals['_AGENTACTION']._serialized_end=990
+  _globals['_ACTIONRESPONSE']._serialized_start=993
+  _globals['_ACTIONRESPONSE']._serialized_end=1248
+  _globals['_INFERENCEREQUEST']._serialized_start=1251
+  _globals['_INFERENCEREQUEST']._serialized_end=1425
+  _globals['_SPECULATIVEDECODING']._serialized_start=1427
+  _globals['_SPECULATIVEDECODING']._serialized_end=1490
+  _globals['_INFERENCERESPONSE']._serialized_start=1493
+  _globals['_INFERENCERESPONSE']._serialized_end=1747
+  _globals['_INFERENCECHUNK']._serialized_start=1749
+  _globals['_INFERENCECHUNK']._serialized_end=1861
+  _globals['_SYNTHESISREQUEST']._serialized_start=1863
+  _globals['_SYNTHESISREQUEST']._serialized_end=1966
+  _globals['_SYNTHESISRESPONSE']._serialized_start=1968
+  _globals['_SYNTHESISRESPONSE']._serialized_end=2040
+  _globals['_STATSREQUEST']._serialized_start=2042
+  _globals['_STATSREQUEST']._serialized_end=2056
+  _globals['_MESHSTATS']._serialized_start=2059
+  _globals['_MESHSTATS']._serialized_end=2805
+  _globals['_MESHSTATS_AGENTLOGSENTRY']._serialized_start=2432
+  _globals['_MESHSTATS_AGENTLOGSENTRY']._serialized_end=2500
+  _globals['_MESHSTATS_CONTRIBUTIONMATRIXENTRY']._serialized_start=2502
+  _globals['_MESHSTATS_CONTRIBUTIONMATRIXENTRY']._serialized_end=2579
+  _globals['_MESHSTATS_LOCKDOMAINSENTRY']._serialized_start=2581
+  _globals['_MESHSTATS_LOCKDOMAINSENTRY']._serialized_end=2656
+  _globals['_MESHSTATS_PROVIDERLOADENTRY']._serialized_start=2658
+  _globals['_MESHSTATS_PROVIDERLOADENTRY']._serialized_end=2736
+  _globals['_MESHSTATS_BATCHINGENTRY']._serialized_start=2738
+  _globals['_MESHSTATS_BATCHINGENTRY']._serialized_end=2805
+  _globals['_RESPONSECACHEMETRICS']._serialized_start=2808
+  _globals['_RESPONSECACHEMETRICS']._serialized_end=2958
+  _globals['_BATCHMETRICS']._serialized_start=2961
+  _globals['_BATCHMETRICS']._serialized_end=3135
+  _globals['_BATCHMETRICS_SIZECOUNTSENTRY']._serialized_start=3086
+  _globals['_BATCHMETRICS_SIZECOUNTSENTRY']._serialized_end=3135
+  _globals['_NODECAPABILITY']._serialized_start=3138
+  _globals['_NODECAPABILITY']._serialized_end=3517
+  _globals['_NODECAPABILITY_LOADENTRY']._serialized_start=3447
+  _globals['_NODECAPABILITY_LOADENTRY']._serialized_end=3517
+  _globals['_GPUDEVICE']._serialized_start=3519
+  _globals['_GPUDEVICE']._serialized_end=3627
+  _globals['_PROVIDERLOADMETRICS']._serialized_start=3629
+  _globals['_PROVIDERLOADMETRICS']._serialized_end=3753
+  _globals['_LOCKDOMAINMETRICS']._serialized_start=3756
+  _globals['_LOCKDOMAINMETRICS']._serialized_end=3932
+  _globals['_AGENTMETRICS']._serialized_start=3935
+  _globals['_AGENTMETRICS']._serialized_end=4064
+  _globals['_INFLUENCEMAP']._serialized_start=4066
+  _globals['_INFLUENCEMAP']._serialized_end=4184
+  _globals['_INFLUENCEMAP_INFLUENCEENTRY']._serialized_start=4136
+  _globals['_INFLUENCEMAP_INFLUENCEENTRY']._serialized_end=4184
+  _globals['_NEIGHBORGRAPHREQUEST']._serialized_start=4186
+  _globals['_NEIGHBORGRAPHREQUEST']._serialized_end=4226
+  _globals['_NEIGHBOREDGE']._serialized_start=4228
+  _globals['_NEIGHBOREDGE']._serialized_end=4312
+  _globals['_NEIGHBORLIST']._serialized_start=4314
+  _globals['_NEIGHBORLIST']._serialized_end=4386
+  _globals['_NEIGHBORGRAPH']._serialized_start=4389
+  _globals['_NEIGHBORGRAPH']._serialized_end=4529
+  _globals['_NEIGHBORGRAPH_ADJACENCYENTRY']._serialized_start=4461
+  _globals['_NEIGHBORGRAPH_ADJACENCYENTRY']._serialized_end=4529
+  _globals['_RECONSTITUTIONREQUEST']._serialized_start=4531
+  _globals['_RECONSTITUTIONREQUEST']._serialized_end=4644
+  _globals['_STATESNAPSHOT']._serialized_start=4646
+  _globals['_STATESNAPSHOT']._serialized_end=4759
+  _globals['_STATEHISTORY']._serialized_start=4761
+  _globals['_STATEHISTORY']._serialized_end=4815
+  _globals['_STATEDIFFREQUEST']._serialized_start=4817
+  _globals['_STATEDIFFREQUEST']._serialized_end=4895
+  _globals['_FIELDCHANGE']._serialized_start=4897
+  _globals['_FIELDCHANGE']._serialized_end=4956
+  _globals['_STATEDIFF']._serialized_start=4958
+  _globals['_STATEDIFF']._serialized_end=5005
+  _globals['_LOCKREQUEST']._serialized_start=5007
+  _globals['_LOCKREQUEST']._serialized_end=5127
+  _globals['_LOCKRESPONSE']._serialized_start=5130
+  _globals['_LOCKRESPONSE']._serialized_end=5267
+  _globals['_KVCACHEENVELOPE']._serialized_start=5270
+  _globals['_KVCACHEENVELOPE']._serialized_end=5638
+  _globals['_SEARCHREQUEST']._serialized_start=5640
+  _globals['_SEARCHREQUEST']._serialized_end=5709
+  _globals['_SEARCHRESPONSE']._serialized_start=5711
+  _globals['_SEARCHRESPONSE']._serialized_end=5791
+  _globals['_SEARCHRESULT']._serialized_start=5793
+  _globals['_SEARCHRESULT']._serialized_end=5855
+  _globals['_STRATEGICMESH']._serialized_start=6050
+  _globals['_STRATEGICMESH']._serialized_end=6943
 # @@protoc_insertion_point(module_scope)
@@ -53,9 +53,19 @@ class StrategicMeshStub(object):
                 _registered_method=True)
         self.GetStateReconstitution = channel.unary_unary(
                 '/mesh.StrategicMesh/GetStateReconstitution',
-                request_serializer=proto_dot_mesh__pb2.HandshakeRequest.SerializeToString,
+                request_serializer=proto_dot_mesh__pb2.ReconstitutionRequest.SerializeToString,
                 response_deserializer=proto_dot_mesh__pb2.AgentAction.FromString,
                 _registered_method=True)
+        self.GetStateHistory = channel.unary_unary(
+                '/mesh.StrategicMesh/GetStateHistory',
+                request_serializer=proto_dot_mesh__pb2.ReconstitutionRequest.SerializeToString,
+                response_deserializer=proto_dot_mesh__pb2.StateHistory.FromString,
+                _registered_method=True)
+        self.DiffStates = channel.unary_unary(
+                '/mesh.StrategicMesh/DiffStates',
+                request_serializer=proto_dot_mesh__pb2.StateDiffRequest.SerializeToString,
+                response_deserializer=proto_dot_mesh__pb2.StateDiff.FromString,
+                _registered_method=True)
         self.SynthesizeOutputs = channel.unary_unary(
                 '/mesh.StrategicMesh/SynthesizeOutputs',
                 request_serializer=proto_dot_mesh__pb2.SynthesisRequest.SerializeToString,
@@ -66,6 +76,36 @@ class StrategicMeshStub(object):
                 request_serializer=proto_dot_mesh__pb2.InferenceRequest.SerializeToString,
                 response_deserializer=proto_dot_mesh__pb2.InferenceResponse.FromString,
                 _registered_method=True)
+        self.GenerateStream = channel.unary_stream(
+                '/mesh.StrategicMesh/GenerateStream',
+                request_serializer=proto_dot_mesh__pb2.InferenceRequest.SerializeToString,
+                response_deserializer=proto_dot_mesh__pb2.InferenceChunk.FromString,
+                _registered_method=True)
+        self.GetMeshStats = channel.unary_unary(
+                '/mesh.StrategicMesh/GetMeshStats',
+                request_serializer=proto_dot_mesh__pb2.StatsRequest.SerializeToString,
+                response_deserializer=proto_dot_mesh__pb2.MeshStats.FromString,
+                _registered_method=True)
+        self.GetNeighborGraph = channel.unary_unary(
+                '/mesh.StrategicMesh/GetNeighborGraph',
+                request_serializer=proto_dot_mesh__pb2.NeighborGraphRequest.SerializeToString,
+                response_deserializer=proto_dot_mesh__pb2.NeighborGraph.FromString,
+                _registered_method=True)
+        self.AcquireLock = channel.unary_unary(
+                '/mesh.StrategicMesh/AcquireLock',
+                request_serializer=proto_dot_mesh__pb2.LockRequest.SerializeToString,
+                response_deserializer=proto_dot_mesh__pb2.LockResponse.FromString,
+                _registered_method=True)
+        self.RenewLock = channel.unary_unary(
+                '/mesh.StrategicMesh/RenewLock',
+                request_serializer=proto_dot_mesh__pb2.LockRequest.SerializeToString,
+                response_deserializer=proto_dot_mesh__pb2.LockResponse.FromString,
+                _registered_method=True)
+        self.ReleaseLock = channel.unary_unary(
+                '/mesh.StrategicMesh/ReleaseLock',
+                request_serializer=proto_dot_mesh__pb2.LockRequest.SerializeToString,
+                response_deserializer=proto_dot_mesh__pb2.LockResponse.FromString,
+                _registered_method=True)
 
 
 class StrategicMeshServicer(object):
@@ -74,47 +114,103 @@ class StrategicMeshServicer(object):
     """
 
     def RegisterAgent(self, request, context):
-        """Handshake to enter the mesh
+        """Registers an agent with the mesh.
         """
         context.set_code(grpc.StatusCode.UNIMPLEMENTED)
         context.set_details('Method not implemented!')
         raise NotImplementedError('Method not implemented!')
 
     def ExecuteStrategicAction(self, request, context):
-        """Execute high-complexity planning or reasoning tasks
+        """Executes a high-complexity task requiring strategic planning.
         """
         context.set_code(grpc.StatusCode.UNIMPLEMENTED)
         context.set_details('Method not implemented!')
         raise NotImplementedError('Method not implemented!')
 
     def SemanticSearch(self, request, context):
-        """Semantic search across shared context and research papers
+        """Performs a semantic search over the knowledge base.
         """
         context.set_code(grpc.StatusCode.UNIMPLEMENTED)
         context.set_details('Method not implemented!')
         raise NotImplementedError('Method not implemented!')
 
     def GetStateReconstitution(self, request, context):
-        """Synchronize state for failed node recovery
+        """Retrieves the last known state for a failed agent to allow for recovery,
+        or an earlier version so the agent can roll back past a corrupt step.
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def GetStateHistory(self, request, context):
+        """Lists the retained state versions for an agent.
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def DiffStates(self, request, context):
+        """Compares two retained state versions field by field.
         """
         context.set_code(grpc.StatusCode.UNIMPLEMENTED)
         context.set_details('Method not implemented!')
         raise NotImplementedError('Method not implemented!')
 
     def SynthesizeOutputs(self, request, context):
-        """Synthesize parallel agent outputs (AdaptOrch)
+        """Merges and synthesizes outputs from multiple agents.
         """
         context.set_code(grpc.StatusCode.UNIMPLEMENTED)
         context.set_details('Method not implemented!')
         raise NotImplementedError('Method not implemented!')
 
     def GenerateResponse(self, request, context):
-        """Phase 8: Hardware-Aware Inference
+        """Executes a hardware-aware inference request.
         """
         context.set_code(grpc.StatusCode.UNIMPLEMENTED)
         context.set_details('Method not implemented!')
         raise NotImplementedError('Method not implemented!')
 
+    def GenerateStream(self, request, context):
+        """Streams a hardware-aware inference request token by token. Cancelling
+        the call aborts generation.
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def GetMeshStats(self, request, context):
+        """Retrieves performance and audit statistics for the mesh.
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def GetNeighborGraph(self, request, context):
+        """Retrieves the DSBO neighbor graph with per-edge bandit statistics.
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def AcquireLock(self, request, context):
+        """Strategic lock leases guarded by monotonically increasing fencing tokens.
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def RenewLock(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def ReleaseLock(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
 
 def add_StrategicMeshServicer_to_server(servicer, server):
     rpc_method_handlers = {
@@ -135,9 +231,19 @@ def add_StrategicMeshServicer_to_server(servicer, server):
             ),
             'GetStateReconstitution': grpc.unary_unary_rpc_method_handler(
                     servicer.GetStateReconstitution,
-                    request_deserializer=proto_dot_mesh__pb2.HandshakeRequest.FromString,
+                    request_deserializer=proto_dot_mesh__pb2.ReconstitutionRequest.FromString,
                     response_serializer=proto_dot_mesh__pb2.AgentAction.SerializeToString,
             ),
+            'GetStateHistory': grpc.unary_unary_rpc_method_handler(
+                    servicer.GetStateHistory,
+                    request_deserializer=proto_dot_mesh__pb2.ReconstitutionRequest.FromString,
+                    response_serializer=proto_dot_mesh__pb2.StateHistory.SerializeToString,
+            ),
+            'DiffStates': grpc.unary_unary_rpc_method_handler(
+                    servicer.DiffStates,
+                    request_deserializer=proto_dot_mesh__pb2.StateDiffRequest.FromString,
+                    response_serializer=proto_dot_mesh__pb2.StateDiff.SerializeToString,
+            ),
             'SynthesizeOutputs': grpc.unary_unary_rpc_method_handler(
                     servicer.SynthesizeOutputs,
                     request_deserializer=proto_dot_mesh__pb2.SynthesisRequest.FromString,
@@ -148,6 +254,36 @@ def add_StrategicMeshServicer_to_server(servicer, server):
                     request_deserializer=proto_dot_mesh__pb2.InferenceRequest.FromString,
                     response_serializer=proto_dot_mesh__pb2.InferenceResponse.SerializeToString,
             ),
+            'GenerateStream': grpc.unary_stream_rpc_method_handler(
+                    servicer.GenerateStream,
+                    request_deserializer=proto_dot_mesh__pb2.InferenceRequest.FromString,
+                    response_serializer=proto_dot_mesh__pb2.InferenceChunk.SerializeToString,
+            ),
+            'GetMeshStats': grpc.unary_unary_rpc_method_handler(
+                    servicer.GetMeshStats,
+                    request_deserializer=proto_dot_mesh__pb2.StatsRequest.FromString,
+                    response_serializer=proto_dot_mesh__pb2.MeshStats.SerializeToString,
+            ),
+            'GetNeighborGraph': grpc.unary_unary_rpc_method_handler(
+                    servicer.GetNeighborGraph,
+                    request_deserializer=proto_dot_mesh__pb2.NeighborGraphRequest.FromString,
+                    response_serializer=proto_dot_mesh__pb2.NeighborGraph.SerializeToString,
+            ),
+            'AcquireLock': grpc.unary_unary_rpc_method_handler(
+                    servicer.AcquireLock,
+                    request_deserializer=proto_dot_mesh__pb2.LockRequest.FromString,
+                    response_serializer=proto_dot_mesh__pb2.LockResponse.SerializeToString,
+            ),
+            'RenewLock': grpc.unary_unary_rpc_method_handler(
+                    servicer.RenewLock,
+                    request_deserializer=proto_dot_mesh__pb2.LockRequest.FromString,
+                    response_serializer=proto_dot_mesh__pb2.LockResponse.SerializeToString,
+            ),
+            'ReleaseLock': grpc.unary_unary_rpc_method_handler(
+                    servicer.ReleaseLock,
+                    request_deserializer=proto_dot_mesh__pb2.LockRequest.FromString,
+                    response_serializer=proto_dot_mesh__pb2.LockResponse.SerializeToString,
+            ),
     }
     generic_handler = grpc.method_handlers_generic_handler(
             'mesh.StrategicMesh', rpc_method_handlers)
@@ -257,7 +393,7 @@ class StrategicMesh(object):
             request,
             target,
             '/mesh.StrategicMesh/GetStateReconstitution',
-            proto_dot_mesh__pb2.HandshakeRequest.SerializeToString,
+            proto_dot_mesh__pb2.ReconstitutionRequest.SerializeToString,
             proto_dot_mesh__pb2.AgentAction.FromString,
             options,
             channel_credentials,
@@ -269,6 +405,60 @@ class StrategicMesh(object):
             metadata,
             _registered_method=True)
 
+    @staticmethod
+    def GetStateHistory(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/mesh.StrategicMesh/GetStateHistory',
+            proto_dot_mesh__pb2.ReconstitutionRequest.SerializeToString,
+            proto_dot_mesh__pb2.StateHistory.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def DiffStates(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/mesh.StrategicMesh/DiffStates',
+            proto_dot_mesh__pb2.StateDiffRequest.SerializeToString,
+            proto_dot_mesh__pb2.StateDiff.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
     @staticmethod
     def SynthesizeOutputs(request,
             target,
@@ -322,3 +512,165 @@ class StrategicMesh(object):
             timeout,
             metadata,
             _registered_method=True)
+
+    @staticmethod
+    def GenerateStream(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_stream(
+            request,
+            target,
+            '/mesh.StrategicMesh/GenerateStream',
+            proto_dot_mesh__pb2.InferenceRequest.SerializeToString,
+            proto_dot_mesh__pb2.InferenceChunk.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def GetMeshStats(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/mesh.StrategicMesh/GetMeshStats',
+            proto_dot_mesh__pb2.StatsRequest.SerializeToString,
+            proto_dot_mesh__pb2.MeshStats.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def GetNeighborGraph(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/mesh.StrategicMesh/GetNeighborGraph',
+            proto_dot_mesh__pb2.NeighborGraphRequest.SerializeToString,
+            proto_dot_mesh__pb2.NeighborGraph.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def AcquireLock(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/mesh.StrategicMesh/AcquireLock',
+            proto_dot_mesh__pb2.LockRequest.SerializeToString,
+            proto_dot_mesh__pb2.LockResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def RenewLock(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/mesh.StrategicMesh/RenewLock',
+            proto_dot_mesh__pb2.LockRequest.SerializeToString,
+            proto_dot_mesh__pb2.LockResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def ReleaseLock(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/mesh.StrategicMesh/ReleaseLock',
+            proto_dot_mesh__pb2.LockRequest.SerializeToString,
+            proto_dot_mesh__pb2.LockResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)